@@ -1,3 +1,8 @@
+// Package binaries downloads and locates the Prisma engines and the Prisma CLI used by the Go client.
+//
+// Its API is stable and can be used by custom build tooling, e.g. to pre-fetch engines for a binary target into a
+// Docker image. The versions the Go client was built against are PrismaVersion and EngineVersion, and the current
+// platform can be detected with the platform package.
 package binaries
 
 import (
@@ -20,18 +25,31 @@ var PrismaURL = "https://packaged-cli.prisma.sh/%s-%s-%s-%s.gz"
 // EngineURL points to an S3 bucket URL where the Prisma engines are stored.
 var EngineURL = "https://binaries.prisma.sh/all_commits/%s/%s/%s.gz"
 
+// Engine describes a Prisma engine binary
 type Engine struct {
+	// Name is the name of the engine, e.g. query-engine
 	Name string
-	Env  string
+	// Env is the env var which overrides the path of the engine binary
+	Env string
 }
 
-var Engines = []Engine{{
+// QueryEngine is the engine which executes the queries of the Go client at runtime
+var QueryEngine = Engine{
 	"query-engine",
 	"PRISMA_QUERY_ENGINE_BINARY",
-}, {
+}
+
+// SchemaEngine is the engine which runs migrations and introspection for the Prisma CLI
+var SchemaEngine = Engine{
 	"schema-engine",
 	"PRISMA_SCHEMA_ENGINE_BINARY",
-}}
+}
+
+// Engines lists all engines which are needed to run the generator and the Prisma CLI
+var Engines = []Engine{
+	QueryEngine,
+	SchemaEngine,
+}
 
 // init overrides URLs if env variables are specific for debugging purposes and to
 // be able to provide a fallback if the links above should go down
@@ -66,6 +84,7 @@ func GlobalTempDir(version string) string {
 	return path.Join(temp, baseDirName, "engines", version)
 }
 
+// GlobalUnpackDir returns the path where engines embedded into generated clients are unpacked to
 func GlobalUnpackDir(version string) string {
 	if dir := os.Getenv("PRISMA_UNPACK_DIR"); dir != "" {
 		logger.Debug.Printf("using PRISMA_UNPACK_DIR: %s", dir)
@@ -93,29 +112,94 @@ func GlobalCacheDir() string {
 	return path.Join(cache, baseDirName, "cli", PrismaVersion)
 }
 
-func FetchEngine(dir string, engineName string, binaryName string) error {
+// FetchOptions configures which engine binary Fetch downloads and where it is stored.
+// Empty fields fall back to the defaults of the Go client.
+type FetchOptions struct {
+	// Dir is the directory the engine is stored in, defaulting to GlobalCacheDir
+	Dir string
+	// Engine is the engine to fetch, defaulting to QueryEngine
+	Engine Engine
+	// BinaryTarget is the Prisma binary target, e.g. linux-static-x64 or debian-openssl-3.0.x, defaulting to
+	// platform.BinaryPlatformNameStatic
+	BinaryTarget string
+	// Version is the engine commit hash, defaulting to EngineVersion
+	Version string
+	// URL is the download URL format with the version, binary target and engine name as arguments, defaulting to
+	// EngineURL
+	URL string
+}
+
+func (o FetchOptions) withDefaults() FetchOptions {
+	if o.Dir == "" {
+		o.Dir = GlobalCacheDir()
+	}
+	if o.Engine.Name == "" {
+		o.Engine = QueryEngine
+	}
+	if o.BinaryTarget == "" {
+		o.BinaryTarget = platform.BinaryPlatformNameStatic()
+	}
+	if o.Version == "" {
+		o.Version = EngineVersion
+	}
+	if o.URL == "" {
+		o.URL = EngineURL
+	}
+	return o
+}
+
+// Fetch downloads an engine binary unless it already exists and returns its path.
+//
+// Example:
+//
+//	file, err := binaries.Fetch(binaries.FetchOptions{
+//	  Dir:          "/opt/prisma",
+//	  BinaryTarget: "debian-openssl-3.0.x",
+//	})
+func Fetch(opts FetchOptions) (string, error) {
+	opts = opts.withDefaults()
+	engineName, binaryName := opts.Engine.Name, opts.BinaryTarget
+
 	logger.Debug.Printf("checking %s %s...", engineName, binaryName)
 
-	to := GetEnginePath(dir, engineName, binaryName)
+	to := EnginePath(opts)
 
 	if _, err := os.Stat(to); !os.IsNotExist(err) {
 		logger.Debug.Printf("%s is cached at %s", engineName, to)
-		return nil
+		return to, nil
 	}
 
-	url := platform.CheckForExtension(binaryName, fmt.Sprintf(EngineURL, EngineVersion, binaryName, engineName))
+	url := platform.CheckForExtension(binaryName, fmt.Sprintf(opts.URL, opts.Version, binaryName, engineName))
 
 	logger.Debug.Printf("%s is missing, downloading...", engineName)
 
 	logger.Debug.Printf("downloading %s from %s to %s", engineName, url, to)
 
 	if err := download(url, to); err != nil {
-		return fmt.Errorf("could not download %s to %s: %w", url, to, err)
+		return "", fmt.Errorf("could not download %s to %s: %w", url, to, err)
 	}
 
 	logger.Debug.Printf("%s done", engineName)
 
-	return nil
+	return to, nil
+}
+
+// EnginePath returns the path an engine binary is stored at by Fetch, without downloading it
+func EnginePath(opts FetchOptions) string {
+	opts = opts.withDefaults()
+	name := fmt.Sprintf("prisma-%s-%s", opts.Engine.Name, opts.BinaryTarget)
+	return platform.CheckForExtension(opts.BinaryTarget, path.Join(opts.Dir, opts.Version, name))
+}
+
+// FetchEngine downloads an engine binary for the given binary target into dir unless it already exists.
+// Use Fetch to configure the version or the download URL.
+func FetchEngine(dir string, engineName string, binaryName string) error {
+	_, err := Fetch(FetchOptions{
+		Dir:          dir,
+		Engine:       Engine{Name: engineName},
+		BinaryTarget: binaryName,
+	})
+	return err
 }
 
 // FetchNative fetches the Prisma binaries needed for the generator to a given directory
@@ -128,6 +212,9 @@ func FetchNative(toDir string) error {
 		return fmt.Errorf("toDir must be absolute")
 	}
 
+	_, err := os.Stat(path.Join(toDir, EngineVersion))
+	isNewVersion := os.IsNotExist(err)
+
 	if err := DownloadCLI(toDir); err != nil {
 		return fmt.Errorf("could not download engines: %w", err)
 	}
@@ -138,9 +225,15 @@ func FetchNative(toDir string) error {
 		}
 	}
 
+	// clean up old versions in the global cache when a new version was downloaded
+	if isNewVersion && toDir == GlobalCacheDir() {
+		autoGC()
+	}
+
 	return nil
 }
 
+// DownloadCLI downloads the Prisma CLI for the current platform into toDir unless it already exists
 func DownloadCLI(toDir string) error {
 	cli := PrismaCLIName()
 	to := platform.CheckForExtension(platform.Name(), path.Join(toDir, cli))
@@ -164,8 +257,30 @@ func DownloadCLI(toDir string) error {
 	return nil
 }
 
+// EnsureEngine returns the path of a native engine. If its env variable is not set, the engine is downloaded into the
+// global cache dir first if needed.
+func EnsureEngine(e Engine) (string, error) {
+	if env := os.Getenv(e.Env); env != "" {
+		logger.Debug.Printf("using %s from %s", e.Name, env)
+		return env, nil
+	}
+
+	file, err := Fetch(FetchOptions{Engine: e})
+	if err != nil {
+		return "", fmt.Errorf("fetch %s: %w", e.Name, err)
+	}
+
+	return file, nil
+}
+
+// GetEnginePath returns the path of an engine binary for the given binary target in dir.
+// Use EnginePath to configure the version.
 func GetEnginePath(dir, engineName, binaryName string) string {
-	return platform.CheckForExtension(binaryName, path.Join(dir, EngineVersion, fmt.Sprintf("prisma-%s-%s", engineName, binaryName)))
+	return EnginePath(FetchOptions{
+		Dir:          dir,
+		Engine:       Engine{Name: engineName},
+		BinaryTarget: binaryName,
+	})
 }
 
 func download(url string, to string) error {
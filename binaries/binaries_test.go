@@ -1,13 +1,17 @@
 package binaries
 
 import (
+	"compress/gzip"
 	"fmt"
 	"log"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
 	"testing"
 	"time"
 
+	"github.com/steebchen/prisma-client-go/binaries/platform"
 	"github.com/steebchen/prisma-client-go/test/helpers/massert"
 )
 
@@ -74,3 +78,47 @@ func TestFetch_relativeDir(t *testing.T) {
 	expected := fmt.Errorf("toDir must be absolute")
 	massert.Equal(t, expected, actual)
 }
+
+func TestFetch_options(t *testing.T) {
+	var requested []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		g := gzip.NewWriter(w)
+		_, _ = g.Write([]byte("engine"))
+		_ = g.Close()
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	opts := FetchOptions{
+		Dir:          dir,
+		Engine:       SchemaEngine,
+		BinaryTarget: "debian-openssl-3.0.x",
+		Version:      "abc",
+		URL:          srv.URL + "/%s/%s/%s.gz",
+	}
+
+	file, err := Fetch(opts)
+	if err != nil {
+		t.Fatalf("fetch failed: %s", err)
+	}
+	massert.Equal(t, path.Join(dir, "abc", "prisma-schema-engine-debian-openssl-3.0.x"), file)
+	massert.Equal(t, EnginePath(opts), file)
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	massert.Equal(t, "engine", string(content))
+
+	// a cached engine is not downloaded again
+	if _, err := Fetch(opts); err != nil {
+		t.Fatalf("fetch failed: %s", err)
+	}
+	massert.Equal(t, []string{"/abc/debian-openssl-3.0.x/schema-engine.gz"}, requested)
+}
+
+func TestEnginePath_defaults(t *testing.T) {
+	expected := GetEnginePath(GlobalCacheDir(), QueryEngine.Name, platform.BinaryPlatformNameStatic())
+	massert.Equal(t, expected, EnginePath(FetchOptions{}))
+}
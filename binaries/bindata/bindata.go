@@ -1,10 +1,13 @@
 package bindata
 
 import (
+	"bytes"
+	"compress/gzip"
 	"crypto/sha256"
 	"fmt"
 	"io"
 	"os"
+	"sync"
 
 	"github.com/steebchen/prisma-client-go/binaries"
 	"github.com/steebchen/prisma-client-go/binaries/platform"
@@ -12,7 +15,21 @@ import (
 
 // TODO go fmt files after creation
 
-func WriteFile(name, pkg, from, to string, info platform.Info) error {
+// chunkSize is the uncompressed size of a single chunk of an engine. Chunks are compressed individually so that they
+// can be decompressed in parallel when unpacking.
+const chunkSize = 4 << 20
+
+func WriteFile(name, pkg, from, to, header string, info platform.Info) error {
+	data, err := os.ReadFile(from)
+	if err != nil {
+		return fmt.Errorf("read engine: %w", err)
+	}
+
+	chunks, err := compress(data)
+	if err != nil {
+		return fmt.Errorf("compress engine: %w", err)
+	}
+
 	f, err := os.Create(to)
 	if err != nil {
 		return fmt.Errorf("generate open go file: %w", err)
@@ -21,18 +38,26 @@ func WriteFile(name, pkg, from, to string, info platform.Info) error {
 	//goland:noinspection GoUnhandledErrorResult
 	defer f.Close()
 
-	if err := writeHeader(f, pkg, name, info); err != nil {
+	hash := fmt.Sprintf("%x", sha256.Sum256(data))
+
+	if header != "" {
+		if _, err := fmt.Fprintf(f, "%s\n\n", header); err != nil {
+			return fmt.Errorf("write header: %w", err)
+		}
+	}
+
+	if err := writeHeader(f, pkg, name, hash, len(data), info); err != nil {
 		return fmt.Errorf("write header: %w", err)
 	}
 
-	if err := writeAsset(f, from); err != nil {
+	if err := writeAsset(f, chunks); err != nil {
 		return fmt.Errorf("write asset: %w", err)
 	}
 
 	return nil
 }
 
-func writeHeader(w io.Writer, pkg string, name string, info platform.Info) error {
+func writeHeader(w io.Writer, pkg string, name string, hash string, size int, info platform.Info) error {
 	_, err := fmt.Fprintf(w, `// Code generated by Prisma Client Go. DO NOT EDIT.
 //go:build !codeanalysis && !prisma_ignore && %s && %s
 // +build !codeanalysis,!prisma_ignore,%s,%s
@@ -45,46 +70,77 @@ import (
 )
 
 func init() {
-	unpack.Unpack(data, "%s", "%s")
+	queryEngineAsset = &unpack.Asset{
+		Name:      "%s",
+		Version:   "%s",
+		Hash:      "%s",
+		Size:      %d,
+		ChunkSize: %d,
+		Chunks:    chunks,
+	}
 }
-`, info.Platform, info.Arch, info.Platform, info.Arch, pkg, name, binaries.EngineVersion)
+`, info.Platform, info.Arch, info.Platform, info.Arch, pkg, name, binaries.EngineVersion, hash, size, chunkSize)
 	return err
 }
 
-func writeAsset(w io.Writer, file string) error {
-	fd, err := os.Open(file)
-	if err != nil {
-		return err
+// compress splits data into chunks of chunkSize and gzips them in parallel. gzip is the only format unpack supports,
+// as the standard library has no zstd codec.
+func compress(data []byte) ([][]byte, error) {
+	n := (len(data) + chunkSize - 1) / chunkSize
+	chunks := make([][]byte, n)
+	errs := make([]error, n)
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			end := (i + 1) * chunkSize
+			if end > len(data) {
+				end = len(data)
+			}
+
+			var buf bytes.Buffer
+			g, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			if _, err := g.Write(data[i*chunkSize : end]); err != nil {
+				errs[i] = err
+				return
+			}
+			if err := g.Close(); err != nil {
+				errs[i] = err
+				return
+			}
+			chunks[i] = buf.Bytes()
+		}(i)
 	}
+	wg.Wait()
 
-	//goland:noinspection GoUnhandledErrorResult
-	defer fd.Close()
-
-	h := sha256.New()
-	tr := io.TeeReader(fd, h)
-
-	if err := uncompressedMemcopy(w, tr); err != nil {
-		return err
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	return nil
+	return chunks, nil
 }
 
-func uncompressedMemcopy(w io.Writer, r io.Reader) error {
-	if _, err := fmt.Fprintf(w, `var data = []byte(`); err != nil {
+func writeAsset(w io.Writer, chunks [][]byte) error {
+	if _, err := fmt.Fprintf(w, "\nvar chunks = [][]byte{\n"); err != nil {
 		return err
 	}
 
-	b, err := io.ReadAll(r)
-	if err != nil {
-		return err
-	}
-
-	if _, err := fmt.Fprintf(w, "%+q", b); err != nil {
-		return err
+	for _, chunk := range chunks {
+		if _, err := fmt.Fprintf(w, "\t[]byte(%+q),\n", chunk); err != nil {
+			return err
+		}
 	}
 
-	if _, err := fmt.Fprintf(w, `)`); err != nil {
+	if _, err := fmt.Fprintf(w, "}\n"); err != nil {
 		return err
 	}
 	return nil
@@ -0,0 +1,169 @@
+package binaries
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/steebchen/prisma-client-go/logger"
+)
+
+// DefaultKeepVersions is the number of old versions kept by the automatic garbage collection,
+// so that projects using a slightly older version of the Go client don't need to re-download their engines.
+const DefaultKeepVersions = 2
+
+// GCResult describes which old versions were removed from the global cache and temp dirs
+type GCResult struct {
+	// Removed contains the paths of removed version directories
+	Removed []string
+	// Freed is the number of bytes freed
+	Freed int64
+}
+
+// GC removes CLIs and engines of versions other than the current one from the global cache and temp dirs,
+// keeping the given number of most recently used old versions in each directory.
+func GC(keep int) (*GCResult, error) {
+	if keep < 0 {
+		return nil, fmt.Errorf("keep must not be negative")
+	}
+
+	var result GCResult
+	current := []string{PrismaVersion, EngineVersion}
+
+	var dirs []string
+
+	// cli versions, i.e. <cache>/prisma/binaries/cli/<version>
+	if os.Getenv("PRISMA_GLOBAL_CACHE_DIR") == "" {
+		dirs = append(dirs, path.Dir(GlobalCacheDir()))
+	}
+
+	// engine versions of the current cli version, i.e. <cache>/prisma/binaries/cli/<version>/<engine version>
+	dirs = append(dirs, GlobalCacheDir())
+
+	// unpacked engines, i.e. <temp>/prisma/binaries/engines/<engine version>
+	if os.Getenv("PRISMA_GLOBAL_TEMP_DIR") == "" {
+		dirs = append(dirs, path.Dir(GlobalTempDir(EngineVersion)))
+	}
+
+	for _, dir := range dirs {
+		if err := prune(dir, current, keep, &result); err != nil {
+			return &result, fmt.Errorf("prune %s: %w", dir, err)
+		}
+	}
+
+	return &result, nil
+}
+
+// autoGC runs GC after a new version was downloaded, unless disabled with PRISMA_DISABLE_CACHE_GC.
+// The number of kept old versions can be set with PRISMA_CACHE_KEEP_VERSIONS.
+func autoGC() {
+	if os.Getenv("PRISMA_DISABLE_CACHE_GC") == "true" {
+		return
+	}
+
+	keep := DefaultKeepVersions
+	if env := os.Getenv("PRISMA_CACHE_KEEP_VERSIONS"); env != "" {
+		n, err := strconv.Atoi(env)
+		if err != nil {
+			logger.Info.Printf("warning: invalid PRISMA_CACHE_KEEP_VERSIONS %q, skipping cache gc", env)
+			return
+		}
+		keep = n
+	}
+
+	result, err := GC(keep)
+	if err != nil {
+		logger.Info.Printf("warning: could not clean up old prisma versions: %s", err)
+		return
+	}
+
+	if len(result.Removed) > 0 {
+		logger.Debug.Printf("removed %d old prisma versions, freed %d bytes", len(result.Removed), result.Freed)
+	}
+}
+
+// prune removes all version directories in dir except the current ones and the keep most recently modified ones
+func prune(dir string, current []string, keep int, result *GCResult) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read dir: %w", err)
+	}
+
+	type version struct {
+		path    string
+		modTime int64
+	}
+
+	var versions []version
+outer:
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		for _, c := range current {
+			if entry.Name() == c {
+				continue outer
+			}
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return fmt.Errorf("stat %s: %w", entry.Name(), err)
+		}
+		versions = append(versions, version{
+			path:    path.Join(dir, entry.Name()),
+			modTime: info.ModTime().UnixNano(),
+		})
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].modTime > versions[j].modTime
+	})
+
+	if len(versions) <= keep {
+		return nil
+	}
+
+	for _, v := range versions[keep:] {
+		size, err := dirSize(v.path)
+		if err != nil {
+			return fmt.Errorf("size of %s: %w", v.path, err)
+		}
+
+		logger.Debug.Printf("removing old prisma version %s", v.path)
+
+		if err := os.RemoveAll(v.path); err != nil {
+			return fmt.Errorf("remove %s: %w", v.path, err)
+		}
+
+		result.Removed = append(result.Removed, v.path)
+		result.Freed += size
+	}
+
+	return nil
+}
+
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
@@ -0,0 +1,44 @@
+package binaries
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+
+	now := time.Now()
+	for i, name := range []string{EngineVersion, "old1", "old2", "old3"} {
+		p := path.Join(dir, name)
+		if err := os.MkdirAll(p, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path.Join(p, "engine"), []byte("engine"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		// old1 is the most recent old version
+		modTime := now.Add(-time.Duration(i) * time.Hour)
+		if err := os.Chtimes(p, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var result GCResult
+	if err := prune(dir, []string{EngineVersion}, 1, &result); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []string{path.Join(dir, "old2"), path.Join(dir, "old3")}, result.Removed)
+	assert.Equal(t, int64(12), result.Freed)
+
+	for _, name := range []string{EngineVersion, "old1"} {
+		if _, err := os.Stat(path.Join(dir, name)); err != nil {
+			t.Fatalf("expected %s to be kept: %s", name, err)
+		}
+	}
+}
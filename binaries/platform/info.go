@@ -2,11 +2,15 @@ package platform
 
 import "strings"
 
+// Info describes the platform of a Prisma binary target
 type Info struct {
+	// Platform is the operating system, e.g. linux, darwin or windows
 	Platform string
-	Arch     string
+	// Arch is arm64 for arm64 binary targets and !arm64 otherwise
+	Arch string
 }
 
+// MapBinaryTarget returns the platform of a Prisma binary target, e.g. linux for debian-openssl-3.0.x
 func MapBinaryTarget(name string) Info {
 	return Info{
 		Platform: mapBinaryTargetToPlatform(name),
@@ -67,6 +67,7 @@ func Name() string {
 	return runtime.GOOS
 }
 
+// Arch returns the architecture name used by Prisma binaries, either x64 or arm64
 func Arch() string {
 	switch runtime.GOARCH {
 	case "amd64":
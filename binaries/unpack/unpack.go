@@ -1,10 +1,15 @@
 package unpack
 
 import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
 	"fmt"
+	"io"
 	"os"
 	"path"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/steebchen/prisma-client-go/binaries"
@@ -12,10 +17,216 @@ import (
 	"github.com/steebchen/prisma-client-go/logger"
 )
 
-// TODO check checksum after expanding file
-
 const FileEnv = "PRISMA_INTERNAL_QUERY_ENGINE_PATH"
 
+// Asset is a compressed query engine which is embedded into the generated client
+type Asset struct {
+	// Name is the binary target name, e.g. linux-static-x64
+	Name string
+	// Version is the engine version
+	Version string
+	// Hash is the sha256 hash of the uncompressed engine
+	Hash string
+	// Size is the size of the uncompressed engine
+	Size int
+	// ChunkSize is the uncompressed size of each chunk, except for the last one
+	ChunkSize int
+	// Chunks contains individually gzipped parts of the engine
+	Chunks [][]byte
+}
+
+// unpacked holds the result of unpacking each asset, keyed by engine name and hash, so that multiple generated clients
+// which embed the same engine only unpack it once
+var unpacked sync.Map
+
+type result struct {
+	once sync.Once
+	file string
+	err  error
+}
+
+// Path decompresses the asset on first use and returns its location.
+// Engines are cached by their hash, so they are only decompressed once per machine.
+func (a *Asset) Path() (string, error) {
+	v, _ := unpacked.LoadOrStore(a.Name+"-"+a.Hash, &result{})
+	r := v.(*result)
+
+	r.once.Do(func() {
+		r.file, r.err = unpackAsset(a)
+	})
+
+	return r.file, r.err
+}
+
+// registered is the asset registered by clients generated with earlier versions
+var registered *Asset
+
+// Register registers an embedded query engine, which is unpacked when calling Path.
+//
+// Deprecated: generated clients pass their asset to the query engine directly, so that multiple generated clients can
+// be used in the same binary.
+func Register(a Asset) {
+	registered = &a
+}
+
+// Path decompresses the registered query engine on first use, sets FileEnv and returns its location.
+// If no engine was registered, it returns an empty string.
+//
+// Deprecated: use Asset.Path instead.
+func Path() (string, error) {
+	if registered == nil {
+		return "", nil
+	}
+
+	file, err := registered.Path()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.Setenv(FileEnv, file); err != nil {
+		return "", err
+	}
+
+	return file, nil
+}
+
+func unpackAsset(a *Asset) (string, error) {
+	start := time.Now()
+
+	name := strings.ReplaceAll(a.Name, "_", "-")
+	filename := fmt.Sprintf("prisma-query-engine-%s-%s", name, a.Hash)
+
+	tempDir := binaries.GlobalUnpackDir(a.Version)
+
+	file := platform.CheckForExtension(platform.Name(), path.Join(tempDir, filename))
+
+	// the unpack directory is shared, so a cached engine is only used if its hash matches. This runs once per process,
+	// as Path caches the result.
+	if info, err := os.Stat(file); err == nil && info.Size() == int64(a.Size) {
+		err := verifyFile(file, a.Hash)
+		if err == nil {
+			logger.Debug.Printf("query engine exists, not unpacking. %s. at %s", time.Since(start), file)
+			return file, nil
+		}
+		logger.Debug.Printf("query engine at %s is invalid, unpacking again: %s", file, err)
+	}
+
+	if err := os.MkdirAll(tempDir, 0750); err != nil {
+		return "", fmt.Errorf("mkdirall failed: %w", err)
+	}
+
+	data, err := decompress(a)
+	if err != nil {
+		return "", fmt.Errorf("decompress: %w", err)
+	}
+
+	if err := verify(data, a.Hash); err != nil {
+		return "", fmt.Errorf("decompress: %w", err)
+	}
+
+	// write to a temp file first so that concurrently starting processes never see a partial engine
+	tmp, err := os.CreateTemp(tempDir, filename+".*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("create temp file: %w", err)
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return "", fmt.Errorf("write temp file: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", fmt.Errorf("close temp file: %w", err)
+	}
+
+	if err := os.Chmod(tmp.Name(), os.ModePerm); err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", fmt.Errorf("could not chmod +x %s: %w", tmp.Name(), err)
+	}
+
+	if err := os.Rename(tmp.Name(), file); err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", fmt.Errorf("rename temp file: %w", err)
+	}
+
+	logger.Debug.Printf("unpacked at %s in %s", file, time.Since(start))
+
+	return file, nil
+}
+
+// verify checks that data has the given sha256 hash
+func verify(data []byte, hash string) error {
+	if actual := fmt.Sprintf("%x", sha256.Sum256(data)); actual != hash {
+		return fmt.Errorf("checksum mismatch: expected %s, got %s", hash, actual)
+	}
+	return nil
+}
+
+// verifyFile checks that the content of a file has the given sha256 hash
+func verifyFile(file string, hash string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	if actual := fmt.Sprintf("%x", h.Sum(nil)); actual != hash {
+		return fmt.Errorf("checksum mismatch: expected %s, got %s", hash, actual)
+	}
+	return nil
+}
+
+// decompress decompresses all chunks of an asset in parallel
+func decompress(a *Asset) ([]byte, error) {
+	out := make([]byte, a.Size)
+	errs := make([]error, len(a.Chunks))
+
+	var wg sync.WaitGroup
+	for i, chunk := range a.Chunks {
+		wg.Add(1)
+		go func(i int, chunk []byte) {
+			defer wg.Done()
+
+			g, err := gzip.NewReader(bytes.NewReader(chunk))
+			if err != nil {
+				errs[i] = err
+				return
+			}
+
+			end := (i + 1) * a.ChunkSize
+			if end > a.Size {
+				end = a.Size
+			}
+
+			if _, err := io.ReadFull(g, out[i*a.ChunkSize:end]); err != nil {
+				errs[i] = fmt.Errorf("chunk %d: %w", i, err)
+				return
+			}
+
+			errs[i] = g.Close()
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return out, nil
+}
+
+// Unpack writes an uncompressed query engine to the unpack directory.
+//
+// Deprecated: generated clients register compressed engines with Register instead.
+//
 // noinspection GoUnusedExportedFunction
 func Unpack(data []byte, name string, version string) {
 	start := time.Now()
@@ -24,9 +235,6 @@ func Unpack(data []byte, name string, version string) {
 
 	filename := fmt.Sprintf("prisma-query-engine-%s", name)
 
-	// TODO check if dev env/dev binary in ~/.prisma
-	// TODO check if engine in local dir OR env var
-
 	tempDir := binaries.GlobalUnpackDir(version)
 
 	file := platform.CheckForExtension(platform.Name(), path.Join(tempDir, filename))
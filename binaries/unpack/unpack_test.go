@@ -0,0 +1,139 @@
+package unpack
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func gz(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	g := gzip.NewWriter(&buf)
+	if _, err := g.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func hash(data string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(data)))
+}
+
+func TestUnpackAsset(t *testing.T) {
+	t.Setenv("PRISMA_UNPACK_DIR", t.TempDir())
+
+	a := &Asset{
+		Name:      "linux-static-x64",
+		Version:   "test",
+		Hash:      hash("0123456789"),
+		Size:      10,
+		ChunkSize: 4,
+		Chunks: [][]byte{
+			gz(t, []byte("0123")),
+			gz(t, []byte("4567")),
+			gz(t, []byte("89")),
+		},
+	}
+
+	file, err := unpackAsset(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "0123456789", string(data))
+
+	// a second call uses the cached file
+	a.Chunks = nil
+	cached, err := unpackAsset(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, file, cached)
+}
+
+func TestAssetPathMultiple(t *testing.T) {
+	t.Setenv("PRISMA_UNPACK_DIR", t.TempDir())
+
+	a := &Asset{Name: "linux-static-x64", Version: "a", Hash: hash("a"), Size: 1, ChunkSize: 4, Chunks: [][]byte{gz(t, []byte("a"))}}
+	b := &Asset{Name: "linux-static-x64", Version: "b", Hash: hash("b"), Size: 1, ChunkSize: 4, Chunks: [][]byte{gz(t, []byte("b"))}}
+
+	fileA, err := a.Path()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileB, err := b.Path()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NotEqual(t, fileA, fileB)
+
+	data, err := os.ReadFile(fileB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "b", string(data))
+}
+
+func TestUnpackAssetChecksum(t *testing.T) {
+	t.Setenv("PRISMA_UNPACK_DIR", t.TempDir())
+
+	a := &Asset{
+		Name:      "linux-static-x64",
+		Version:   "test",
+		Hash:      hash("0123"),
+		Size:      4,
+		ChunkSize: 4,
+		Chunks:    [][]byte{gz(t, []byte("4567"))},
+	}
+
+	_, err := unpackAsset(a)
+	assert.ErrorContains(t, err, "checksum mismatch")
+}
+
+func TestUnpackAssetTamperedCache(t *testing.T) {
+	t.Setenv("PRISMA_UNPACK_DIR", t.TempDir())
+
+	a := &Asset{
+		Name:      "linux-static-x64",
+		Version:   "test",
+		Hash:      hash("0123"),
+		Size:      4,
+		ChunkSize: 4,
+		Chunks:    [][]byte{gz(t, []byte("0123"))},
+	}
+
+	file, err := unpackAsset(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a file of the same size but with different content is replaced
+	if err := os.WriteFile(file, []byte("abcd"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := unpackAsset(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, file, again)
+
+	data, err := os.ReadFile(again)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "0123", string(data))
+}
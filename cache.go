@@ -0,0 +1,33 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/steebchen/prisma-client-go/binaries"
+)
+
+// cache runs `cache` subcommands which manage the global binary cache
+func cache(args []string) error {
+	if len(args) == 0 || args[0] != "gc" {
+		return fmt.Errorf("usage: cache gc [--keep=<n>]")
+	}
+
+	flags := flag.NewFlagSet("cache gc", flag.ContinueOnError)
+	keep := flags.Int("keep", 0, "number of old versions to keep")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	result, err := binaries.GC(*keep)
+	if err != nil {
+		return fmt.Errorf("cache gc: %w", err)
+	}
+
+	for _, removed := range result.Removed {
+		fmt.Printf("removed %s\n", removed)
+	}
+	fmt.Printf("removed %d old versions, freed %.1f MB\n", len(result.Removed), float64(result.Freed)/1024/1024)
+
+	return nil
+}
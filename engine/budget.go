@@ -0,0 +1,75 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DeadlineBudget wraps an engine so that each request only uses a fraction of the time remaining until the deadline
+// of its context. Requests without a context deadline are passed through unchanged.
+type DeadlineBudget struct {
+	Engine
+
+	// Fraction is the share of the remaining time a single request may use, between 0 and 1
+	Fraction float64
+}
+
+// NewDeadlineBudget wraps an engine to limit each request to the given fraction of the remaining context deadline
+func NewDeadlineBudget(e Engine, fraction float64) *DeadlineBudget {
+	return &DeadlineBudget{
+		Engine:   e,
+		Fraction: fraction,
+	}
+}
+
+// Connect validates the fraction before connecting the wrapped engine
+func (e *DeadlineBudget) Connect() error {
+	if err := e.validate(); err != nil {
+		return err
+	}
+	return e.Engine.Connect()
+}
+
+func (e *DeadlineBudget) Do(ctx context.Context, payload interface{}, into interface{}) error {
+	if err := e.validate(); err != nil {
+		return err
+	}
+	ctx, cancel := e.budget(ctx)
+	defer cancel()
+
+	return e.Engine.Do(ctx, payload, into)
+}
+
+func (e *DeadlineBudget) Batch(ctx context.Context, payload interface{}, into interface{}) error {
+	if err := e.validate(); err != nil {
+		return err
+	}
+	ctx, cancel := e.budget(ctx)
+	defer cancel()
+
+	return e.Engine.Batch(ctx, payload, into)
+}
+
+// Unwrap returns the wrapped engine
+func (e *DeadlineBudget) Unwrap() Engine {
+	return e.Engine
+}
+
+// validate returns an error if the fraction is not between 0 and 1
+func (e *DeadlineBudget) validate() error {
+	if e.Fraction <= 0 || e.Fraction > 1 {
+		return fmt.Errorf("deadline budget fraction must be between 0 and 1, got %g", e.Fraction)
+	}
+	return nil
+}
+
+func (e *DeadlineBudget) budget(ctx context.Context) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return ctx, func() {}
+	}
+
+	remaining := time.Until(deadline)
+	return context.WithTimeout(ctx, time.Duration(float64(remaining)*e.Fraction))
+}
@@ -0,0 +1,50 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type deadlineEngine struct {
+	Engine
+	deadline time.Time
+	ok       bool
+}
+
+func (e *deadlineEngine) Do(ctx context.Context, payload interface{}, into interface{}) error {
+	e.deadline, e.ok = ctx.Deadline()
+	return nil
+}
+
+func TestDeadlineBudget(t *testing.T) {
+	inner := &deadlineEngine{}
+	e := NewDeadlineBudget(inner, 0.5)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := e.Do(ctx, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.True(t, inner.ok)
+	assert.InDelta(t, 5*time.Second, time.Until(inner.deadline), float64(100*time.Millisecond))
+
+	if err := e.Do(context.Background(), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.False(t, inner.ok)
+}
+
+func TestDeadlineBudgetInvalid(t *testing.T) {
+	for _, fraction := range []float64{-0.5, 1.5} {
+		e := NewDeadlineBudget(&deadlineEngine{}, fraction)
+		assert.EqualError(t, e.Connect(), fmt.Sprintf("deadline budget fraction must be between 0 and 1, got %g", fraction))
+		assert.Error(t, e.Do(context.Background(), nil, nil))
+	}
+}
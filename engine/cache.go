@@ -0,0 +1,59 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// CacheStrategy describes how Prisma Accelerate should cache a query
+type CacheStrategy struct {
+	// TTL is the duration for which a cached result is considered fresh
+	TTL time.Duration
+	// SWR is the duration for which a stale result is served while it's revalidated in the background
+	SWR time.Duration
+}
+
+func (s CacheStrategy) header() string {
+	return fmt.Sprintf("max-age=%d;stale-while-revalidate=%d", int(s.TTL.Seconds()), int(s.SWR.Seconds()))
+}
+
+// CacheInfo contains information about how Prisma Accelerate served a query
+type CacheInfo struct {
+	// Status is one of ttl, swr, miss or none
+	Status string
+	// LastModified is when the cached result was last refreshed
+	LastModified string
+}
+
+// Hit returns whether the result was served from the cache
+func (i CacheInfo) Hit() bool {
+	return i.Status == "ttl" || i.Status == "swr"
+}
+
+type cacheStrategyKey struct{}
+type cacheInfoKey struct{}
+
+// WithCacheStrategy returns a context which makes the data proxy engine send the given cache strategy
+func WithCacheStrategy(ctx context.Context, strategy CacheStrategy) context.Context {
+	return context.WithValue(ctx, cacheStrategyKey{}, strategy)
+}
+
+// WithCacheInfo returns a context which makes the data proxy engine write cache information into info
+func WithCacheInfo(ctx context.Context, info *CacheInfo) context.Context {
+	return context.WithValue(ctx, cacheInfoKey{}, info)
+}
+
+func applyCacheStrategy(ctx context.Context, req *http.Request) {
+	if strategy, ok := ctx.Value(cacheStrategyKey{}).(CacheStrategy); ok {
+		req.Header.Set("cache-control", strategy.header())
+	}
+}
+
+func readCacheInfo(ctx context.Context, res *http.Response) {
+	if info, ok := ctx.Value(cacheInfoKey{}).(*CacheInfo); ok && info != nil {
+		info.Status = res.Header.Get("accelerate-cache-status")
+		info.LastModified = res.Header.Get("last-modified")
+	}
+}
@@ -0,0 +1,90 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrQueryLogDisabled is returned when statements are captured from an engine which doesn't log the statements it
+// executes. The query engine only logs them if a query listener was registered with OnQuery before connecting.
+var ErrQueryLogDisabled = errors.New("the engine does not log executed statements; register a query listener with OnQuery before connecting")
+
+// QueryCapturer is implemented by engines which can collect the statements executed within an interactive transaction
+type QueryCapturer interface {
+	// CaptureTx calls fn and returns the statements executed within the interactive transaction with the given id from
+	// then on until done returns true for a statement, which is included. Statements of other requests to the engine
+	// are not captured.
+	CaptureTx(ctx context.Context, id string, fn func() error, done func(QueryEvent) bool) ([]QueryEvent, error)
+}
+
+// AsQueryCapturer returns the QueryCapturer of an engine, looking through engines which wrap another engine
+func AsQueryCapturer(e Engine) (QueryCapturer, bool) {
+	return find[QueryCapturer](e)
+}
+
+// queryCapture collects statements until a statement is done
+type queryCapture struct {
+	mu       sync.Mutex
+	events   []QueryEvent
+	done     func(QueryEvent) bool
+	finished chan struct{}
+}
+
+func (c *queryCapture) add(event QueryEvent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	select {
+	case <-c.finished:
+		return
+	default:
+	}
+	c.events = append(c.events, event)
+	if c.done(event) {
+		close(c.finished)
+	}
+}
+
+// CaptureTx calls fn and returns the statements the engine executed within the interactive transaction with the
+// given id from then on until done returns true for a statement. As statements are logged asynchronously, it waits
+// for the done statement until ctx is cancelled.
+func (e *QueryEngine) CaptureTx(ctx context.Context, id string, fn func() error, done func(QueryEvent) bool) ([]QueryEvent, error) {
+	e.mu.Lock()
+	if !e.logQueries {
+		e.mu.Unlock()
+		return nil, ErrQueryLogDisabled
+	}
+	if _, ok := e.captures[id]; ok {
+		e.mu.Unlock()
+		return nil, errors.New("the statements of the transaction are already being captured")
+	}
+	c := &queryCapture{
+		done:     done,
+		finished: make(chan struct{}),
+	}
+	if e.captures == nil {
+		e.captures = map[string]*queryCapture{}
+	}
+	e.captures[id] = c
+	e.mu.Unlock()
+
+	defer func() {
+		e.mu.Lock()
+		delete(e.captures, id)
+		e.mu.Unlock()
+	}()
+
+	if err := fn(); err != nil {
+		return nil, err
+	}
+
+	select {
+	case <-c.finished:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]QueryEvent{}, c.events...), nil
+}
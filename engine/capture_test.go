@@ -0,0 +1,54 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaptureTx(t *testing.T) {
+	e := &QueryEngine{logQueries: true}
+	e.emitQuery(QueryEvent{Query: "SELECT 0", TxID: "tx1"})
+
+	events, err := e.CaptureTx(context.Background(), "tx1", func() error {
+		e.emitQuery(QueryEvent{Query: "SELECT 1", TxID: "tx1"})
+		// statements of other requests and transactions are ignored
+		e.emitQuery(QueryEvent{Query: "SELECT 2"})
+		e.emitQuery(QueryEvent{Query: "ROLLBACK", TxID: "tx2"})
+		// statements may be logged after the call returned
+		go func() {
+			e.emitQuery(QueryEvent{Query: "ROLLBACK", TxID: "tx1"})
+			e.emitQuery(QueryEvent{Query: "SELECT 3", TxID: "tx1"})
+		}()
+		return nil
+	}, func(e QueryEvent) bool {
+		return e.Query == "ROLLBACK"
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []QueryEvent{{Query: "SELECT 1", TxID: "tx1"}, {Query: "ROLLBACK", TxID: "tx1"}}, events)
+	assert.Empty(t, e.captures)
+}
+
+func TestCaptureTxErrors(t *testing.T) {
+	done := func(e QueryEvent) bool { return false }
+
+	_, err := (&QueryEngine{}).CaptureTx(context.Background(), "tx1", func() error { return nil }, done)
+	assert.ErrorIs(t, err, ErrQueryLogDisabled)
+
+	e := &QueryEngine{logQueries: true}
+	_, err = e.CaptureTx(context.Background(), "tx1", func() error { return errors.New("failed") }, done)
+	assert.EqualError(t, err, "failed")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = e.CaptureTx(ctx, "tx1", func() error { return nil }, done)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, err = e.CaptureTx(context.Background(), "tx1", func() error {
+		_, err := e.CaptureTx(context.Background(), "tx1", func() error { return nil }, done)
+		return err
+	}, done)
+	assert.EqualError(t, err, "the statements of the transaction are already being captured")
+}
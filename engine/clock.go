@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"time"
+
+	"github.com/steebchen/prisma-client-go/runtime/metadata"
+)
+
+// Clock provides the current time, so that time-dependent logic can be tested with a fake clock
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc is a function which implements Clock
+type ClockFunc func() time.Time
+
+// Now calls f
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SystemClock is the Clock returning the current system time
+var SystemClock Clock = ClockFunc(time.Now)
+
+// ClockEngine wraps an engine to attach a clock. Timestamps which are usually set by the query engine, i.e. fields
+// with @updatedAt or @default(now()), are set by the client using the clock instead.
+type ClockEngine struct {
+	Engine
+
+	Clock Clock
+	// Schema is used to look up the timestamp fields of models
+	Schema metadata.Schema
+}
+
+// NewClockEngine wraps an engine to set timestamps using the given clock
+func NewClockEngine(e Engine, clock Clock, schema metadata.Schema) *ClockEngine {
+	return &ClockEngine{
+		Engine: e,
+		Clock:  clock,
+		Schema: schema,
+	}
+}
+
+func (e *ClockEngine) clock() *ClockEngine {
+	return e
+}
+
+// Unwrap returns the wrapped engine
+func (e *ClockEngine) Unwrap() Engine {
+	return e.Engine
+}
+
+// ClockOf returns the clock attached to an engine, if any
+func ClockOf(e Engine) (Clock, bool) {
+	if c, ok := find[interface{ clock() *ClockEngine }](e); ok {
+		return c.clock().Clock, true
+	}
+	return nil, false
+}
+
+// Now returns the current time of the clock attached to an engine, or the system time if there is none
+func Now(e Engine) time.Time {
+	if c, ok := ClockOf(e); ok {
+		return c.Now()
+	}
+	return SystemClock.Now()
+}
+
+// Timestamps returns the fields of a model which are set to the current time of the clock attached to an engine when
+// writing a record: fields with @updatedAt, and on create also fields with @default(now()). It returns nil if no
+// clock is attached, in which case the query engine sets the timestamps.
+func Timestamps(e Engine, model string, create bool) map[string]time.Time {
+	c, ok := find[interface{ clock() *ClockEngine }](e)
+	if !ok {
+		return nil
+	}
+
+	m, ok := c.clock().Schema.Model(model)
+	if !ok {
+		return nil
+	}
+
+	var now time.Time
+	fields := make(map[string]time.Time)
+	for _, f := range m.Fields {
+		if !f.IsUpdatedAt && !(create && f.DefaultNow) {
+			continue
+		}
+		if now.IsZero() {
+			now = c.clock().Clock.Now()
+		}
+		fields[f.Name] = now
+	}
+	return fields
+}
@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// CoalesceEngine wraps an engine so that identical read queries which run concurrently are sent to it only once, e.g.
+// when many requests miss a cache at the same time. Queries are identical if their requests and scopes are equal.
+type CoalesceEngine struct {
+	Engine
+
+	// Exclude contains the models, e.g. User, and the actions of models, e.g. User.findMany, whose reads are never
+	// coalesced
+	Exclude map[string]bool
+
+	mu sync.Mutex
+	// calls holds the running reads by scope and request
+	calls map[string]*coalescedCall
+}
+
+// coalescedCall is a running read, whose result is available once done is closed
+type coalescedCall struct {
+	done chan struct{}
+	data json.RawMessage
+	err  error
+}
+
+// NewCoalesceEngine wraps an engine to coalesce identical concurrent reads, except those of the excluded models and
+// actions
+func NewCoalesceEngine(e Engine, exclude ...string) *CoalesceEngine {
+	excluded := make(map[string]bool, len(exclude))
+	for _, x := range exclude {
+		excluded[x] = true
+	}
+	return &CoalesceEngine{
+		Engine:  e,
+		Exclude: excluded,
+		calls:   map[string]*coalescedCall{},
+	}
+}
+
+// Unwrap returns the wrapped engine
+func (e *CoalesceEngine) Unwrap() Engine {
+	return e.Engine
+}
+
+func (e *CoalesceEngine) coalescer() *CoalesceEngine {
+	return e
+}
+
+// CoalesceOf returns the coalesce engine wrapped by an engine. Engines of interactive transactions don't coalesce, as
+// their reads may see uncommitted writes.
+func CoalesceOf(e Engine) (*CoalesceEngine, bool) {
+	if InTx(e) {
+		return nil, false
+	}
+	c, ok := find[interface{ coalescer() *CoalesceEngine }](e)
+	if !ok {
+		return nil, false
+	}
+	return c.coalescer(), true
+}
+
+// Excludes returns whether reads of a model with an action are never coalesced
+func (e *CoalesceEngine) Excludes(model string, action string) bool {
+	return e.Exclude[model] || e.Exclude[model+"."+action]
+}
+
+// Coalesce calls fetch for a read query sent to the engine e, which wraps the coalesce engine, unless an identical
+// query is already running, in which case its result is returned. If the running query was canceled by its own
+// context, fetch is called with the context of this query instead.
+func (e *CoalesceEngine) Coalesce(ctx context.Context, engine Engine, query string, fetch func() (json.RawMessage, error)) (json.RawMessage, error) {
+	key := Scope(engine) + "\n" + query
+
+	e.mu.Lock()
+	call, running := e.calls[key]
+	if !running {
+		call = &coalescedCall{done: make(chan struct{})}
+		e.calls[key] = call
+	}
+	e.mu.Unlock()
+
+	if !running {
+		call.data, call.err = fetch()
+		e.mu.Lock()
+		delete(e.calls, key)
+		e.mu.Unlock()
+		close(call.done)
+		return call.data, call.err
+	}
+
+	select {
+	case <-call.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if isCanceled(call.err) && ctx.Err() == nil {
+		return fetch()
+	}
+	return call.data, call.err
+}
+
+// isCanceled returns whether an error was caused by a canceled context or an exceeded deadline
+func isCanceled(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
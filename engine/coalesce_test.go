@@ -0,0 +1,89 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoalesce(t *testing.T) {
+	c := NewCoalesceEngine(nil, "Log", "User.findMany")
+	ctx := context.Background()
+
+	var fetches atomic.Int64
+	release := make(chan struct{})
+	fetch := func() (json.RawMessage, error) {
+		fetches.Add(1)
+		<-release
+		return json.RawMessage(`{"id":1}`), nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]json.RawMessage, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			data, err := c.Coalesce(ctx, c, "query", fetch)
+			assert.NoError(t, err)
+			results[i] = data
+		}(i)
+	}
+	// wait until all queries are waiting for the first one before releasing it
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.EqualValues(t, 1, fetches.Load())
+	for _, data := range results {
+		assert.Equal(t, json.RawMessage(`{"id":1}`), data)
+	}
+
+	// completed queries are not shared
+	_, err := c.Coalesce(ctx, c, "query", fetch)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, fetches.Load())
+	assert.Empty(t, c.calls)
+
+	assert.True(t, c.Excludes("Log", "findUnique"))
+	assert.True(t, c.Excludes("User", "findMany"))
+	assert.False(t, c.Excludes("User", "findUnique"))
+}
+
+func TestCoalesceCanceled(t *testing.T) {
+	c := NewCoalesceEngine(nil)
+
+	started := make(chan struct{})
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := c.Coalesce(leaderCtx, c, "query", func() (json.RawMessage, error) {
+			close(started)
+			<-leaderCtx.Done()
+			return nil, leaderCtx.Err()
+		})
+		done <- err
+	}()
+	<-started
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	// a query waiting for a canceled one is sent with its own context
+	data, err := c.Coalesce(context.Background(), c, "query", func() (json.RawMessage, error) {
+		return json.RawMessage(`{"id":2}`), nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, json.RawMessage(`{"id":2}`), data)
+	assert.ErrorIs(t, <-done, context.Canceled)
+
+	_, ok := CoalesceOf(&TxEngine{Engine: c})
+	assert.False(t, ok)
+}
@@ -0,0 +1,109 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// crashStderrLines is the number of most recent stderr lines kept for crash reports
+	crashStderrLines = 50
+	// crashQueries is the number of most recent query documents kept for crash reports
+	crashQueries = 10
+	// crashWait is how long a failed request waits for the engine process to exit
+	crashWait = 100 * time.Millisecond
+)
+
+// CrashReport describes an unexpected exit of the query engine process
+type CrashReport struct {
+	// Time is when the exit was detected
+	Time time.Time
+	// ExitCode is the exit code of the process, or -1 if it was terminated by a signal
+	ExitCode int
+	// Err is the exit error of the process, e.g. "signal: killed"
+	Err error
+	// Stderr contains the last lines the engine wrote to stderr, including panic messages
+	Stderr []string
+	// Queries contains the last query documents sent to the engine, oldest first
+	Queries []string
+}
+
+// CrashError is returned for requests which failed because the query engine process exited unexpectedly
+type CrashError struct {
+	Report *CrashReport
+	Err    error
+}
+
+func (e *CrashError) Error() string {
+	return fmt.Sprintf("query engine crashed (exit code %d): %s", e.Report.ExitCode, e.Err)
+}
+
+func (e *CrashError) Unwrap() error {
+	return e.Err
+}
+
+// ring keeps the most recent entries up to a maximum
+type ring struct {
+	mu      sync.Mutex
+	max     int
+	entries []string
+}
+
+func newRing(max int) *ring {
+	return &ring{max: max}
+}
+
+func (r *ring) add(entry string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entry)
+	if len(r.entries) > r.max {
+		r.entries = r.entries[len(r.entries)-r.max:]
+	}
+}
+
+func (r *ring) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = nil
+}
+
+func (r *ring) snapshot() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.entries...)
+}
+
+// crashReport returns the crash report of an exited process, which is created once
+func (e *QueryEngine) crashReport(p *process) *CrashReport {
+	p.reportOnce.Do(func() {
+		report := &CrashReport{
+			Time:     time.Now(),
+			ExitCode: -1,
+			Err:      p.err,
+			Stderr:   e.stderrTail.snapshot(),
+			Queries:  e.recentQueries.snapshot(),
+		}
+		if p.cmd.ProcessState != nil {
+			report.ExitCode = p.cmd.ProcessState.ExitCode()
+		}
+		p.report = report
+	})
+	return p.report
+}
+
+// crashed returns the crash report if the process exited although the engine was not disconnected. As a failed
+// request may be noticed before the exit of the process, it waits briefly for the exit unless ctx is done.
+func (e *QueryEngine) crashed(ctx context.Context, p *process) (*CrashReport, bool) {
+	if p == nil || e.isDisconnected() || ctx.Err() != nil {
+		return nil, false
+	}
+	select {
+	case <-p.done:
+		return e.crashReport(p), true
+	case <-time.After(crashWait):
+		return nil, false
+	}
+}
@@ -0,0 +1,56 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrashReport(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := srv.URL
+	srv.Close()
+
+	qe := NewQueryEngine("", false, "", "")
+	qe.httpURL = url
+	qe.connected = true
+
+	cmd := exec.Command("sh", "-c", "exit 3")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	qe.process = newProcess(cmd)
+	<-qe.process.done
+
+	qe.stderrTail.add(`{"is_panic":true,"message":"boom"}`)
+
+	_, err := qe.Request(context.Background(), "POST", "/", map[string]string{"query": "q"}, true)
+
+	var crash *CrashError
+	if !errors.As(err, &crash) {
+		t.Fatalf("expected a crash error, got %v", err)
+	}
+	assert.Equal(t, 3, crash.Report.ExitCode)
+	assert.Equal(t, []string{`{"is_panic":true,"message":"boom"}`}, crash.Report.Stderr)
+	assert.Equal(t, []string{`{"query":"q"}`}, crash.Report.Queries)
+}
+
+func TestRing(t *testing.T) {
+	r := newRing(2)
+	r.add("a")
+	r.add("b")
+	r.add("c")
+	assert.Equal(t, []string{"b", "c"}, r.snapshot())
+
+	r.reset()
+	assert.Empty(t, r.snapshot())
+}
@@ -2,6 +2,7 @@ package engine
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"fmt"
 	"io"
@@ -10,6 +11,7 @@ import (
 	"time"
 
 	"github.com/steebchen/prisma-client-go/logger"
+	"github.com/steebchen/prisma-client-go/runtime/types"
 )
 
 var errNotFound = fmt.Errorf("not found; re-upload schema")
@@ -24,6 +26,11 @@ func request(ctx context.Context, client *http.Client, method string, url string
 		return nil, fmt.Errorf("raw post: %w", err)
 	}
 
+	// request compressed responses explicitly, which also disables the transparent decompression of the transport,
+	// so that the response is decompressed in readBody regardless of the transport used. Only gzip is requested, as
+	// the standard library has no zstd decoder.
+	req.Header.Set("Accept-Encoding", "gzip")
+
 	apply(req)
 
 	req = req.WithContext(ctx)
@@ -31,7 +38,10 @@ func request(ctx context.Context, client *http.Client, method string, url string
 	startReq := time.Now()
 	rawResponse, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("raw post: %w", err)
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("raw post: %w", err)
+		}
+		return nil, fmt.Errorf("raw post: %w: %w", types.ErrConnection, err)
 	}
 	defer func() {
 		if err := rawResponse.Body.Close(); err != nil {
@@ -41,7 +51,9 @@ func request(ctx context.Context, client *http.Client, method string, url string
 	reqDuration := time.Since(startReq)
 	logger.Debug.Printf("[timing] query engine raw request took %s", reqDuration)
 
-	responseBody, err := io.ReadAll(rawResponse.Body)
+	readCacheInfo(ctx, rawResponse)
+
+	responseBody, err := readBody(rawResponse)
 	if err != nil {
 		return nil, fmt.Errorf("raw read: %w", err)
 	}
@@ -71,3 +83,22 @@ func request(ctx context.Context, client *http.Client, method string, url string
 
 	return responseBody, nil
 }
+
+// readBody reads the response body, decompressing it if it's gzipped. Other encodings are not requested and fail.
+func readBody(res *http.Response) ([]byte, error) {
+	switch encoding := res.Header.Get("Content-Encoding"); encoding {
+	case "":
+		return io.ReadAll(res.Body)
+	case "gzip":
+		g, err := gzip.NewReader(res.Body)
+		if err != nil {
+			return nil, fmt.Errorf("gzip reader: %w", err)
+		}
+		//goland:noinspection GoUnhandledErrorResult
+		defer g.Close()
+
+		return io.ReadAll(g)
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+	}
+}
@@ -0,0 +1,44 @@
+package engine
+
+import (
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestGzip(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "gzip", r.Header.Get("Accept-Encoding"))
+
+		w.Header().Set("Content-Encoding", "gzip")
+		g := gzip.NewWriter(w)
+		_, _ = g.Write([]byte(`{"data":{}}`))
+		_ = g.Close()
+	}))
+	defer srv.Close()
+
+	body, err := request(context.Background(), srv.Client(), "POST", srv.URL, []byte(`{}`), func(*http.Request) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, `{"data":{}}`, string(body))
+}
+
+func TestRequestUncompressed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{}}`))
+	}))
+	defer srv.Close()
+
+	body, err := request(context.Background(), srv.Client(), "POST", srv.URL, []byte(`{}`), func(*http.Request) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, `{"data":{}}`, string(body))
+}
@@ -11,3 +11,18 @@ type Engine interface {
 	Batch(ctx context.Context, payload interface{}, into interface{}) error
 	Name() string
 }
+
+// find returns the first engine which implements T, looking through engines which wrap another engine
+func find[T any](e Engine) (T, bool) {
+	for {
+		if t, ok := e.(T); ok {
+			return t, true
+		}
+		w, ok := e.(interface{ Unwrap() Engine })
+		if !ok {
+			var zero T
+			return zero, false
+		}
+		e = w.Unwrap()
+	}
+}
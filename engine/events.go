@@ -0,0 +1,146 @@
+package engine
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/steebchen/prisma-client-go/logger"
+)
+
+// QueryEvent describes a statement which was executed by the query engine
+type QueryEvent struct {
+	// Timestamp is the time the statement was logged by the engine
+	Timestamp time.Time
+	// Query is the statement sent to the database, e.g. SQL
+	Query string
+	// Params contains the parameters of the statement as a JSON array
+	Params string
+	// Duration is the time the database took to execute the statement
+	Duration time.Duration
+	// Target is the component of the engine which executed the statement
+	Target string
+	// TxID is the id of the interactive transaction which executed the statement, if any
+	TxID string
+}
+
+// QueryEmitter is implemented by engines which report the statements they execute
+type QueryEmitter interface {
+	// OnQuery registers fn to be called for every executed statement
+	OnQuery(fn func(QueryEvent))
+}
+
+// AsQueryEmitter returns the QueryEmitter of an engine, looking through engines which wrap another engine
+func AsQueryEmitter(e Engine) (QueryEmitter, bool) {
+	return find[QueryEmitter](e)
+}
+
+// OnQuery registers fn to be called for every statement the query engine executes.
+// Listeners must be registered before Connect is called.
+func (e *QueryEngine) OnQuery(fn func(QueryEvent)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.connected {
+		logger.Info.Printf("OnQuery was called after Connect(); register query listeners before connecting")
+	}
+
+	e.onQuery = append(e.onQuery, fn)
+}
+
+func (e *QueryEngine) hasQueryListeners() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return len(e.onQuery) > 0
+}
+
+func (e *QueryEngine) emitQuery(event QueryEvent) {
+	e.mu.RLock()
+	listeners := e.onQuery
+	capture := e.captures[event.TxID]
+	e.mu.RUnlock()
+
+	if capture != nil {
+		capture.add(event)
+	}
+
+	for _, fn := range listeners {
+		fn(event)
+	}
+}
+
+type queryLog struct {
+	Timestamp time.Time `json:"timestamp"`
+	Target    string    `json:"target"`
+	Fields    struct {
+		Query      string  `json:"query"`
+		Params     string  `json:"params"`
+		DurationMS float64 `json:"duration_ms"`
+	} `json:"fields"`
+	// Span and Spans are the current span of the statement and all of its spans from the root, whose fields include
+	// the id of the interactive transaction which executed it
+	Span  map[string]interface{}   `json:"span"`
+	Spans []map[string]interface{} `json:"spans"`
+}
+
+// txID returns the id of the interactive transaction of a statement, looking through its spans from the innermost
+func (l queryLog) txID() string {
+	if id, ok := l.Span["itx_id"].(string); ok {
+		return id
+	}
+	for i := len(l.Spans) - 1; i >= 0; i-- {
+		if id, ok := l.Spans[i]["itx_id"].(string); ok {
+			return id
+		}
+	}
+	return ""
+}
+
+// parseQueryEvent parses a JSON log line of the query engine, returning false if it's not a query log
+func parseQueryEvent(line []byte) (QueryEvent, bool) {
+	var l queryLog
+	if err := json.Unmarshal(line, &l); err != nil || l.Fields.Query == "" {
+		return QueryEvent{}, false
+	}
+
+	return QueryEvent{
+		Timestamp: l.Timestamp,
+		Query:     l.Fields.Query,
+		Params:    l.Fields.Params,
+		Duration:  time.Duration(l.Fields.DurationMS * float64(time.Millisecond)),
+		Target:    l.Target,
+		TxID:      l.txID(),
+	}, true
+}
+
+// streamStdout emits query events logged by the engine and writes all other output to stdout
+func (e *QueryEngine) streamStdout(cmd *exec.Cmd) error {
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("get stdout pipe: %w", err)
+	}
+
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		const maxCapacity int = 1024 * 1024
+		buf := make([]byte, maxCapacity)
+		scanner.Buffer(buf, maxCapacity)
+
+		for scanner.Scan() {
+			contents := scanner.Bytes()
+			if event, ok := parseQueryEvent(contents); ok {
+				e.emitQuery(event)
+				if !logger.Enabled {
+					continue
+				}
+			}
+
+			_, _ = os.Stdout.Write(append(contents, '\n'))
+		}
+	}()
+
+	return nil
+}
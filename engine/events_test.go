@@ -0,0 +1,43 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQueryEvent(t *testing.T) {
+	line := `{"timestamp":"2024-01-02T03:04:05.000000Z","level":"INFO","fields":{"message":"SELECT 1","query":"SELECT \"public\".\"User\".\"id\" FROM \"public\".\"User\" WHERE \"public\".\"User\".\"id\" = $1","params":"[\"123\"]","duration_ms":12},"target":"quaint::connector::metrics"}`
+
+	event, ok := parseQueryEvent([]byte(line))
+	if !ok {
+		t.Fatalf("expected a query event")
+	}
+
+	assert.Equal(t, QueryEvent{
+		Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Query:     `SELECT "public"."User"."id" FROM "public"."User" WHERE "public"."User"."id" = $1`,
+		Params:    `["123"]`,
+		Duration:  12 * time.Millisecond,
+		Target:    "quaint::connector::metrics",
+	}, event)
+}
+
+func TestParseQueryEventTx(t *testing.T) {
+	line := `{"timestamp":"2024-01-02T03:04:05.000000Z","level":"INFO","fields":{"query":"SELECT 1","params":"[]","duration_ms":0},"target":"quaint::connector::metrics","span":{"name":"quaint:query"},"spans":[{"name":"prisma:engine:itx_runner","itx_id":"tx1"},{"name":"quaint:query"}]}`
+
+	event, ok := parseQueryEvent([]byte(line))
+	if !ok {
+		t.Fatalf("expected a query event")
+	}
+	assert.Equal(t, "tx1", event.TxID)
+}
+
+func TestParseQueryEventOther(t *testing.T) {
+	_, ok := parseQueryEvent([]byte(`{"timestamp":"2024-01-02T03:04:05.000000Z","level":"INFO","fields":{"message":"Started query engine http server"},"target":"query_engine::server"}`))
+	assert.False(t, ok)
+
+	_, ok = parseQueryEvent([]byte(`not json`))
+	assert.False(t, ok)
+}
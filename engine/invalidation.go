@@ -0,0 +1,154 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/steebchen/prisma-client-go/logger"
+	"github.com/steebchen/prisma-client-go/runtime/metadata"
+)
+
+// InvalidationEvent describes a write to a model, so that external caches can drop the affected records
+type InvalidationEvent struct {
+	// Model is the name of the written model as defined in the Prisma schema
+	Model string
+	// Action is the write method, e.g. createOne, updateMany or upsertOne
+	Action string
+	// Keys contains the primary key values of the written record by field name. It is empty for bulk writes, such as
+	// updateMany, whose records are unknown; invalidate by Where or the whole model instead.
+	Keys map[string]interface{}
+	// Where contains the where-conditions of the write by field name, if any
+	Where map[string]interface{}
+}
+
+// Publisher receives an invalidation event after every successful write
+type Publisher interface {
+	Publish(ctx context.Context, event InvalidationEvent)
+}
+
+// PublisherFunc is a function which implements Publisher
+type PublisherFunc func(ctx context.Context, event InvalidationEvent)
+
+// Publish calls f
+func (f PublisherFunc) Publish(ctx context.Context, event InvalidationEvent) {
+	f(ctx, event)
+}
+
+// PublishEngine wraps an engine to attach a publisher, which receives invalidation events for the writes sent to it
+type PublishEngine struct {
+	Engine
+
+	Publisher Publisher
+	// Schema is used to look up the primary key of written models
+	Schema metadata.Schema
+}
+
+// NewPublishEngine wraps an engine to publish invalidation events for its writes
+func NewPublishEngine(e Engine, p Publisher, schema metadata.Schema) *PublishEngine {
+	return &PublishEngine{
+		Engine:    e,
+		Publisher: p,
+		Schema:    schema,
+	}
+}
+
+// event returns the invalidation event of a write
+func (e *PublishEngine) event(model string, action string, where map[string]interface{}, result interface{}) InvalidationEvent {
+	event := InvalidationEvent{
+		Model:  model,
+		Action: action,
+		Where:  where,
+	}
+
+	if m, ok := e.Schema.Model(model); ok && !strings.HasSuffix(action, "Many") {
+		event.Keys = primaryKey(m, result)
+	}
+
+	return event
+}
+
+func (e *PublishEngine) publishEngine() *PublishEngine {
+	return e
+}
+
+// Unwrap returns the wrapped engine
+func (e *PublishEngine) Unwrap() Engine {
+	return e.Engine
+}
+
+// IsWrite returns whether a query method writes records
+func IsWrite(action string) bool {
+	for _, prefix := range []string{"create", "update", "upsert", "delete"} {
+		if strings.HasPrefix(action, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Invalidate publishes an invalidation event for a successful write of a model, if a publisher is attached to the
+// engine, and drops the cached results of reads of the model. The result is the written record, which is used to
+// extract its primary key. Writes within an interactive transaction are only invalidated once it's committed with
+// TxEngine.Commit, and not at all if it's rolled back.
+func Invalidate(ctx context.Context, e Engine, model string, action string, where map[string]interface{}, result interface{}) {
+	if model == "" || !IsWrite(action) {
+		return
+	}
+
+	// build the event right away, as the result may be changed by the caller until the transaction is committed
+	p, hasPublisher := find[interface{ publishEngine() *PublishEngine }](e)
+	var event InvalidationEvent
+	if hasPublisher {
+		event = p.publishEngine().event(model, action, where, result)
+	}
+
+	invalidate := func(ctx context.Context) {
+		InvalidateCache(e, model)
+		if hasPublisher {
+			p.publishEngine().Publisher.Publish(ctx, event)
+		}
+	}
+
+	if tx, ok := find[*TxEngine](e); ok {
+		tx.onCommit(invalidate)
+		return
+	}
+	invalidate(ctx)
+}
+
+// primaryKey extracts the primary key values of a record, falling back to the first unique constraint
+func primaryKey(m metadata.Model, result interface{}) map[string]interface{} {
+	fields := m.PrimaryKey
+	if len(fields) == 0 && len(m.Uniques) > 0 {
+		fields = m.Uniques[0]
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		logger.Default().Debug("could not encode written record", "model", m.Name, "error", err)
+		return nil
+	}
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(data, &record); err != nil || record == nil {
+		return nil
+	}
+
+	keys := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		name := field
+		if f, ok := m.Field(field); ok {
+			name = f.JSONName()
+		}
+		v, ok := record[name]
+		if !ok {
+			return nil
+		}
+		keys[field] = v
+	}
+	return keys
+}
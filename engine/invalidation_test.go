@@ -0,0 +1,93 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/steebchen/prisma-client-go/runtime/metadata"
+)
+
+func TestInvalidate(t *testing.T) {
+	var events []InvalidationEvent
+	publisher := PublisherFunc(func(ctx context.Context, event InvalidationEvent) {
+		events = append(events, event)
+	})
+
+	schema := metadata.Schema{
+		Models: []metadata.Model{{
+			Name:       "User",
+			PrimaryKey: []string{"id"},
+		}, {
+			Name:    "Like",
+			Uniques: [][]string{{"userID", "postID"}},
+		}},
+	}
+
+	e := NewDeadlineBudget(NewPublishEngine(nil, publisher, schema), 0.5)
+	ctx := context.Background()
+
+	type user struct {
+		ID    string `json:"id"`
+		Email string `json:"email"`
+	}
+
+	Invalidate(ctx, e, "User", "createOne", nil, &user{ID: "1", Email: "a@example.com"})
+	Invalidate(ctx, e, "User", "updateMany", map[string]interface{}{"email": "a@example.com"}, &struct{ Count int }{1})
+	Invalidate(ctx, e, "Like", "deleteOne", nil, json.RawMessage(`{"userID":"1","postID":"2"}`))
+	Invalidate(ctx, e, "User", "findUnique", nil, &user{ID: "1"})
+	Invalidate(ctx, e, "", "executeRaw", nil, nil)
+
+	assert.Equal(t, []InvalidationEvent{{
+		Model:  "User",
+		Action: "createOne",
+		Keys:   map[string]interface{}{"id": "1"},
+	}, {
+		Model:  "User",
+		Action: "updateMany",
+		Where:  map[string]interface{}{"email": "a@example.com"},
+	}, {
+		Model:  "Like",
+		Action: "deleteOne",
+		Keys:   map[string]interface{}{"userID": "1", "postID": "2"},
+	}}, events)
+
+	// engines without a publisher are ignored
+	Invalidate(ctx, NewDeadlineBudget(nil, 0.5), "User", "createOne", nil, &user{ID: "2"})
+	assert.Len(t, events, 3)
+}
+
+func TestInvalidateTx(t *testing.T) {
+	var events []InvalidationEvent
+	publisher := PublisherFunc(func(ctx context.Context, event InvalidationEvent) {
+		events = append(events, event)
+	})
+	schema := metadata.Schema{
+		Models: []metadata.Model{{Name: "User", PrimaryKey: []string{"id"}}},
+	}
+
+	transactor := &recordingTransactor{}
+	ctx := context.Background()
+
+	tx := NewTxEngine(NewPublishEngine(transactor, publisher, schema), "tx1")
+	record := map[string]interface{}{"id": "1"}
+	Invalidate(ctx, tx, "User", "createOne", nil, record)
+	// changes of the result after the write don't change the event
+	record["id"] = "2"
+	assert.Empty(t, events)
+
+	assert.NoError(t, tx.Commit(ctx, transactor))
+	assert.Equal(t, []InvalidationEvent{{
+		Model:  "User",
+		Action: "createOne",
+		Keys:   map[string]interface{}{"id": "1"},
+	}}, events)
+
+	tx = NewTxEngine(NewPublishEngine(transactor, publisher, schema), "tx2")
+	Invalidate(ctx, tx, "User", "deleteOne", nil, record)
+	assert.NoError(t, tx.Rollback(ctx, transactor))
+	assert.Len(t, events, 1)
+	assert.Equal(t, []string{"commit tx1", "rollback tx2"}, transactor.calls)
+}
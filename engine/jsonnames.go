@@ -0,0 +1,40 @@
+package engine
+
+import (
+	"github.com/steebchen/prisma-client-go/runtime/metadata"
+)
+
+// JSONNamesEngine wraps an engine whose generated models use JSON names which differ from the field names of the
+// Prisma schema, e.g. snake_case names, so that results are renamed before they are decoded into models
+type JSONNamesEngine struct {
+	Engine
+
+	// Schema holds the JSON names of the fields of each model
+	Schema metadata.Schema
+}
+
+// NewJSONNamesEngine wraps an engine to decode the results of the queries built for it using the JSON names of schema
+func NewJSONNamesEngine(e Engine, schema metadata.Schema) *JSONNamesEngine {
+	return &JSONNamesEngine{
+		Engine: e,
+		Schema: schema,
+	}
+}
+
+func (e *JSONNamesEngine) jsonNames() metadata.Schema {
+	return e.Schema
+}
+
+// Unwrap returns the wrapped engine
+func (e *JSONNamesEngine) Unwrap() Engine {
+	return e.Engine
+}
+
+// JSONNamesOf returns the schema holding the JSON names of the models which results of an engine are decoded into
+func JSONNamesOf(e Engine) (metadata.Schema, bool) {
+	n, ok := find[interface{ jsonNames() metadata.Schema }](e)
+	if !ok {
+		return metadata.Schema{}, false
+	}
+	return n.jsonNames(), true
+}
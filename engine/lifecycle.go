@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
 	"os/exec"
 	"path"
@@ -43,6 +44,10 @@ func (e *QueryEngine) Connect() error {
 		return fmt.Errorf("ensure: %w", err)
 	}
 
+	e.mu.Lock()
+	e.file = file
+	e.mu.Unlock()
+
 	if err := e.spawn(file); err != nil {
 		return fmt.Errorf("spawn: %w", err)
 	}
@@ -67,18 +72,28 @@ func (e *QueryEngine) Disconnect() error {
 	e.mu.Unlock()
 	logger.Debug.Printf("disconnecting...")
 
+	e.mu.RLock()
+	p := e.process
+	e.mu.RUnlock()
+
+	if p == nil {
+		// the engine never became ready
+		return nil
+	}
+
 	if platform.Name() == "windows" {
-		if err := e.cmd.Process.Kill(); err != nil {
+		if err := p.cmd.Process.Kill(); err != nil {
 			return fmt.Errorf("kill process: %w", err)
 		}
 		return nil
 	}
 
-	if err := e.cmd.Process.Signal(os.Interrupt); err != nil {
+	if err := p.cmd.Process.Signal(os.Interrupt); err != nil {
 		return fmt.Errorf("send signal: %w", err)
 	}
 
-	if err := e.cmd.Wait(); err != nil {
+	<-p.done
+	if err := p.err; err != nil {
 		if err.Error() != "signal: interrupt" {
 			return fmt.Errorf("wait for process: %w", err)
 		}
@@ -130,8 +145,29 @@ func (e *QueryEngine) ensure() (string, error) {
 
 		file = prismaQueryEngineBinary
 		forceVersion = false
+	} else if e.binaryPath != "" {
+		logger.Debug.Printf("using sideloaded query engine at %s", e.binaryPath)
+
+		if _, err := os.Stat(e.binaryPath); err != nil {
+			return "", fmt.Errorf("the query engine is sideloaded, but no query engine was found at %s; make sure to ship the query engine binary or set PRISMA_QUERY_ENGINE_BINARY", e.binaryPath)
+		}
+
+		file = e.binaryPath
 	} else {
-		if qe := os.Getenv(unpack.FileEnv); qe != "" {
+		// decompress the embedded query engine, if any, on first use
+		if e.asset != nil {
+			qe, err := e.asset.Path()
+			if err != nil {
+				return "", fmt.Errorf("unpack embedded query engine: %w", err)
+			}
+
+			logger.Debug.Printf("using embedded query engine %s", qe)
+			file = qe
+		} else if _, err := unpack.Path(); err != nil {
+			return "", fmt.Errorf("unpack embedded query engine: %w", err)
+		}
+
+		if qe := os.Getenv(unpack.FileEnv); file == "" && qe != "" {
 			logger.Debug.Printf("using unpacked file env %s %s", unpack.FileEnv, qe)
 
 			if info, err := os.Stat(qe); err == nil {
@@ -227,31 +263,69 @@ func (e *QueryEngine) GetEncodedDatasources() (string, error) {
 	return datasourcesBase64, nil
 }
 
+// spawn starts an engine process with the current schema and makes it serve all requests once it is ready
 func (e *QueryEngine) spawn(file string) error {
+	e.mu.RLock()
+	schema := e.Schema
+	e.mu.RUnlock()
+
+	p, err := e.start(file, schema)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	e.cmd, e.process, e.httpURL = p.cmd, p, p.url
+	e.mu.Unlock()
+
+	go e.supervise(file, p)
+
+	return nil
+}
+
+// start starts an engine process with the given schema and waits until it is ready. The process doesn't serve any
+// requests until it is made the current process; if it doesn't become ready, it is killed.
+func (e *QueryEngine) start(file string, schema string) (*process, error) {
 	port, err := getPort()
 	if err != nil {
-		return fmt.Errorf("get free port: %w", err)
+		return nil, fmt.Errorf("get free port: %w", err)
 	}
 
 	logger.Debug.Printf("running query-engine on port %s", port)
 
-	e.httpURL = "http://localhost:" + port
+	args := []string{"-p", port, "--enable-raw-queries"}
+	e.mu.RLock()
+	if e.metrics {
+		args = append(args, "--enable-metrics")
+	}
+	e.mu.RUnlock()
 
-	e.cmd = exec.Command(file, "-p", port, "--enable-raw-queries")
+	cmd := exec.Command(file, args...)
 
-	e.cmd.SysProcAttr = getSysProcAttr()
+	cmd.SysProcAttr = getSysProcAttr()
 
-	e.cmd.Stdout = os.Stdout
+	logQueries := e.hasQueryListeners()
+	e.mu.Lock()
+	e.logQueries = logQueries
+	e.mu.Unlock()
+	if logQueries {
+		if err := e.streamStdout(cmd); err != nil {
+			return nil, fmt.Errorf("setup stream: %w", err)
+		}
+	} else {
+		cmd.Stdout = os.Stdout
+	}
 
 	e.onEngineError = make(chan string)
+	e.stderrTail.reset()
 
-	if err := e.streamStderr(e.cmd, e.onEngineError); err != nil {
-		return fmt.Errorf("setup stream: %w", err)
+	if err := e.streamStderr(cmd, e.onEngineError); err != nil {
+		return nil, fmt.Errorf("setup stream: %w", err)
 	}
 
-	e.cmd.Env = append(
+	cmd.Env = append(
 		os.Environ(),
-		"PRISMA_DML="+e.Schema,
+		"PRISMA_DML="+schema,
 		"RUST_LOG=error",
 		"RUST_LOG_FORMAT=json",
 		"PRISMA_CLIENT_ENGINE_TYPE=binary",
@@ -260,20 +334,24 @@ func (e *QueryEngine) spawn(file string) error {
 
 	encDS, err := e.GetEncodedDatasources()
 	if err != nil {
-		return fmt.Errorf("get encoded datasources: %w", err)
+		return nil, fmt.Errorf("get encoded datasources: %w", err)
 	}
 
 	if encDS != "" {
-		e.cmd.Env = append(
-			e.cmd.Env,
+		cmd.Env = append(
+			cmd.Env,
 			"OVERWRITE_DATASOURCES="+encDS,
 		)
 	}
 
+	if logQueries {
+		cmd.Env = append(cmd.Env, "PRISMA_LOG_QUERIES=y")
+	}
+
 	// TODO fine tune this using log levels
 	if logger.Enabled {
-		e.cmd.Env = append(
-			e.cmd.Env,
+		cmd.Env = append(
+			cmd.Env,
 			"PRISMA_LOG_QUERIES=y",
 			"RUST_LOG=info",
 		)
@@ -281,10 +359,13 @@ func (e *QueryEngine) spawn(file string) error {
 
 	logger.Debug.Printf("starting engine...")
 
-	if err := e.cmd.Start(); err != nil {
-		return fmt.Errorf("start command: %w", err)
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start command: %w", err)
 	}
 
+	p := newProcess(cmd)
+	p.url = "http://localhost:" + port
+
 	logger.Debug.Printf("connecting to engine...")
 
 	// send a basic readiness healthcheck and retry if unsuccessful
@@ -293,12 +374,16 @@ func (e *QueryEngine) spawn(file string) error {
 		e.mu.Lock()
 		// return an error early if an engine error already happened
 		if e.lastEngineError != "" {
+			err := fmt.Errorf("query engine errored: %w", fmt.Errorf(e.lastEngineError))
 			e.mu.Unlock()
-			return fmt.Errorf("query engine errored: %w", fmt.Errorf(e.lastEngineError))
+			p.kill()
+			return nil, err
 		}
 		e.mu.Unlock()
 
-		body, err := e.Request(context.Background(), "GET", "/status", map[string]interface{}{}, false)
+		body, err := request(context.Background(), e.http, "GET", p.url+"/status", []byte("{}"), func(req *http.Request) {
+			req.Header.Set("content-type", "application/json")
+		})
 		if err != nil {
 			connectErr = err
 			logger.Debug.Printf("could not connect; retrying...")
@@ -329,8 +414,9 @@ func (e *QueryEngine) spawn(file string) error {
 	}
 
 	if connectErr != nil {
-		return fmt.Errorf("readiness query error: %w", connectErr)
+		p.kill()
+		return nil, fmt.Errorf("readiness query error: %w", connectErr)
 	}
 
-	return nil
+	return p, nil
 }
@@ -0,0 +1,46 @@
+package engine
+
+// QueryLimits restricts the shape of query documents, e.g. to protect against pathological queries assembled from
+// user input via dynamic filters. A zero value disables the respective limit.
+type QueryLimits struct {
+	// MaxDepth limits the nesting of the query document, e.g. of nested relation filters or nested includes
+	MaxDepth int
+	// MaxListLength limits the number of values of a single list, e.g. of an In filter
+	MaxListLength int
+	// MaxIncludes limits the number of relations which are fetched with With, including nested ones
+	MaxIncludes int
+	// MaxSize limits the size of the query document in bytes
+	MaxSize int
+}
+
+// LimitEngine wraps an engine to attach query limits, which are enforced for all queries built for it
+type LimitEngine struct {
+	Engine
+
+	Limits QueryLimits
+}
+
+// NewLimitEngine wraps an engine to enforce the given limits for the queries built for it
+func NewLimitEngine(e Engine, limits QueryLimits) *LimitEngine {
+	return &LimitEngine{
+		Engine: e,
+		Limits: limits,
+	}
+}
+
+func (e *LimitEngine) queryLimits() QueryLimits {
+	return e.Limits
+}
+
+// Unwrap returns the wrapped engine
+func (e *LimitEngine) Unwrap() Engine {
+	return e.Engine
+}
+
+// LimitsOf returns the query limits attached to an engine, if any
+func LimitsOf(e Engine) (QueryLimits, bool) {
+	if l, ok := find[interface{ queryLimits() QueryLimits }](e); ok {
+		return l.queryLimits(), true
+	}
+	return QueryLimits{}, false
+}
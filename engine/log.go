@@ -0,0 +1,45 @@
+package engine
+
+import (
+	"log/slog"
+
+	"github.com/steebchen/prisma-client-go/logger"
+)
+
+// LogEngine wraps an engine to attach a logger, which is used for logs of the queries sent to it
+type LogEngine struct {
+	Engine
+
+	Logger *slog.Logger
+}
+
+// NewLogEngine wraps an engine to log its queries with the given logger
+func NewLogEngine(e Engine, l *slog.Logger) *LogEngine {
+	return &LogEngine{
+		Engine: e,
+		Logger: l,
+	}
+}
+
+func (e *LogEngine) logger() *slog.Logger {
+	return e.Logger
+}
+
+// Unwrap returns the wrapped engine
+func (e *LogEngine) Unwrap() Engine {
+	return e.Engine
+}
+
+// LoggerOf returns the logger attached to an engine, or the default logger if there is none
+func LoggerOf(e Engine) *slog.Logger {
+	if l, ok := find[interface{ logger() *slog.Logger }](e); ok {
+		return l.logger()
+	}
+	return logger.Default()
+}
+
+// HasLogger returns whether a logger was attached to an engine with NewLogEngine
+func HasLogger(e Engine) bool {
+	_, ok := find[interface{ logger() *slog.Logger }](e)
+	return ok
+}
@@ -0,0 +1,170 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/steebchen/prisma-client-go/engine/protocol"
+)
+
+// QueryMetric describes a completed query
+type QueryMetric struct {
+	// Model is the name of the queried model as defined in the Prisma schema, or empty for raw queries
+	Model string
+	// Action is the query method, e.g. findMany or createOne
+	Action string
+	// Duration is the time from building the query until its result was received
+	Duration time.Duration
+	// Code is the Prisma error code of a failed query, e.g. P2002, or "unknown" if the error has none. It is empty if
+	// the query succeeded; queries which found no record succeed.
+	Code string
+}
+
+// TxMetric describes a completed transaction
+type TxMetric struct {
+	// Duration is the time from starting the transaction until it was committed or rolled back
+	Duration time.Duration
+	// Interactive is false for batch transactions, which are sent in a single request
+	Interactive bool
+	// Committed reports whether the transaction was committed, rather than rolled back or failed
+	Committed bool
+}
+
+// MetricsRecorder receives client-side measurements of queries and transactions. It's implemented by adapters which
+// export them as counters and histograms of a metrics library, such as Prometheus or OpenTelemetry.
+// Its methods are called synchronously from the queries and must not block.
+type MetricsRecorder interface {
+	ObserveQuery(ctx context.Context, m QueryMetric)
+	ObserveTx(ctx context.Context, m TxMetric)
+}
+
+// MetricsEngine wraps an engine to attach a metrics recorder, which observes the queries and transactions sent to it
+type MetricsEngine struct {
+	Engine
+
+	Recorder MetricsRecorder
+
+	mu sync.Mutex
+	// started holds the open interactive transactions by id
+	started map[string]startedTx
+}
+
+// startedTx is an interactive transaction which was started, but not committed or rolled back yet
+type startedTx struct {
+	start time.Time
+	// expiry observes the transaction as rolled back once the engine rolled it back after its timeout
+	expiry *time.Timer
+}
+
+// NewMetricsEngine wraps an engine to record metrics of its queries and transactions
+func NewMetricsEngine(e Engine, r MetricsRecorder) *MetricsEngine {
+	return &MetricsEngine{
+		Engine:   e,
+		Recorder: r,
+		started:  map[string]startedTx{},
+	}
+}
+
+// Batch sends a batch request and observes it if it's sent as a transaction
+func (e *MetricsEngine) Batch(ctx context.Context, payload interface{}, into interface{}) error {
+	req, ok := payload.(protocol.GQLBatchRequest)
+	if !ok || !req.Transaction {
+		return e.Engine.Batch(ctx, payload, into)
+	}
+
+	start := time.Now()
+	err := e.Engine.Batch(ctx, payload, into)
+	committed := err == nil
+	if res, ok := into.(*protocol.GQLBatchResponse); ok && committed {
+		committed = len(res.Errors) == 0
+		for _, inner := range res.Result {
+			committed = committed && len(inner.Errors) == 0
+		}
+	}
+	e.Recorder.ObserveTx(ctx, TxMetric{
+		Duration:  time.Since(start),
+		Committed: committed,
+	})
+	return err
+}
+
+// Unwrap returns the wrapped engine
+func (e *MetricsEngine) Unwrap() Engine {
+	return e.Engine
+}
+
+func (e *MetricsEngine) metrics() *MetricsEngine {
+	return e
+}
+
+// ObserveQuery passes a query metric to the recorder attached to an engine, if any
+func ObserveQuery(ctx context.Context, e Engine, m QueryMetric) {
+	if me, ok := metricsOf(e); ok {
+		me.Recorder.ObserveQuery(ctx, m)
+	}
+}
+
+func metricsOf(e Engine) (*MetricsEngine, bool) {
+	m, ok := find[interface{ metrics() *MetricsEngine }](e)
+	if !ok {
+		return nil, false
+	}
+	return m.metrics(), true
+}
+
+// measuredTransactor wraps a Transactor to observe the interactive transactions it starts. Transactors which call other
+// measured transactors observe each transaction once, as only the innermost one tracks it.
+type measuredTransactor struct {
+	Transactor
+	m *MetricsEngine
+}
+
+func (t measuredTransactor) StartTx(ctx context.Context, options TxOptions) (string, error) {
+	start := time.Now()
+	id, err := t.Transactor.StartTx(ctx, options)
+	if err != nil {
+		return "", err
+	}
+	t.m.mu.Lock()
+	if _, ok := t.m.started[id]; !ok {
+		// the engine starts the timeout before it responds, so the transaction is rolled back before the timer fires
+		// if it's abandoned
+		t.m.started[id] = startedTx{
+			start: start,
+			expiry: time.AfterFunc(options.timeout(), func() {
+				t.done(context.Background(), id, false)
+			}),
+		}
+	}
+	t.m.mu.Unlock()
+	return id, nil
+}
+
+func (t measuredTransactor) CommitTx(ctx context.Context, id string) error {
+	err := t.Transactor.CommitTx(ctx, id)
+	t.done(ctx, id, err == nil)
+	return err
+}
+
+func (t measuredTransactor) RollbackTx(ctx context.Context, id string) error {
+	err := t.Transactor.RollbackTx(ctx, id)
+	t.done(ctx, id, false)
+	return err
+}
+
+func (t measuredTransactor) done(ctx context.Context, id string, committed bool) {
+	t.m.mu.Lock()
+	tx, ok := t.m.started[id]
+	delete(t.m.started, id)
+	t.m.mu.Unlock()
+	if !ok {
+		return
+	}
+	tx.expiry.Stop()
+	t.m.Recorder.ObserveTx(ctx, TxMetric{
+		Duration:    time.Since(tx.start),
+		Interactive: true,
+		Committed:   committed,
+	})
+}
@@ -0,0 +1,111 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/steebchen/prisma-client-go/engine/protocol"
+)
+
+type recordingMetrics struct {
+	queries []QueryMetric
+	txs     []TxMetric
+}
+
+func (r *recordingMetrics) ObserveQuery(ctx context.Context, m QueryMetric) {
+	r.queries = append(r.queries, m)
+}
+
+func (r *recordingMetrics) ObserveTx(ctx context.Context, m TxMetric) {
+	m.Duration = 0
+	r.txs = append(r.txs, m)
+}
+
+type failingBatchEngine struct {
+	recordingTransactor
+}
+
+func (e *failingBatchEngine) Batch(ctx context.Context, payload interface{}, into interface{}) error {
+	into.(*protocol.GQLBatchResponse).Errors = []protocol.GQLError{{Message: "failed"}}
+	return nil
+}
+
+func TestMetricsEngine(t *testing.T) {
+	ctx := context.Background()
+	r := &recordingMetrics{}
+	e := NewDeadlineBudget(NewMetricsEngine(&failingBatchEngine{}, r), 0.5)
+
+	ObserveQuery(ctx, e, QueryMetric{Model: "User", Action: "findMany"})
+	assert.Equal(t, []QueryMetric{{Model: "User", Action: "findMany"}}, r.queries)
+
+	err := RunInTx(ctx, e, TxOptions{}, func(ctx context.Context, tx Engine) error {
+		return nil
+	})
+	assert.NoError(t, err)
+	err = RunInTx(ctx, e, TxOptions{}, func(ctx context.Context, tx Engine) error {
+		return errors.New("failed")
+	})
+	assert.Error(t, err)
+
+	var res protocol.GQLBatchResponse
+	assert.NoError(t, e.Batch(ctx, protocol.GQLBatchRequest{Transaction: true}, &res))
+
+	assert.Equal(t, []TxMetric{
+		{Interactive: true, Committed: true},
+		{Interactive: true, Committed: false},
+		{Interactive: false, Committed: false},
+	}, r.txs)
+
+	// engines without a recorder are ignored
+	ObserveQuery(ctx, NewDeadlineBudget(nil, 0.5), QueryMetric{Model: "User", Action: "findMany"})
+	assert.Len(t, r.queries, 1)
+}
+
+// sequentialTransactor starts transactions with increasing ids, which are never committed or rolled back
+type sequentialTransactor struct {
+	Engine
+	n int
+}
+
+func (e *sequentialTransactor) StartTx(ctx context.Context, options TxOptions) (string, error) {
+	e.n++
+	return fmt.Sprintf("tx%d", e.n), nil
+}
+
+func (e *sequentialTransactor) CommitTx(ctx context.Context, id string) error   { return nil }
+func (e *sequentialTransactor) RollbackTx(ctx context.Context, id string) error { return nil }
+
+// txMetrics sends the observed transactions to a channel, as expired transactions are observed by a timer
+type txMetrics chan TxMetric
+
+func (r txMetrics) ObserveQuery(ctx context.Context, m QueryMetric) {}
+
+func (r txMetrics) ObserveTx(ctx context.Context, m TxMetric) {
+	m.Duration = 0
+	r <- m
+}
+
+func TestMetricsEngineExpiresTx(t *testing.T) {
+	ctx := context.Background()
+	r := make(txMetrics, 2)
+	e := NewMetricsEngine(&sequentialTransactor{}, r)
+	transactor, ok := AsTransactor(e)
+	assert.True(t, ok)
+
+	_, err := transactor.StartTx(ctx, TxOptions{Timeout: time.Millisecond})
+	assert.NoError(t, err)
+	id, err := transactor.StartTx(ctx, TxOptions{})
+	assert.NoError(t, err)
+
+	// the abandoned transaction is observed as rolled back once its timeout passed
+	assert.Equal(t, TxMetric{Interactive: true, Committed: false}, <-r)
+
+	assert.NoError(t, transactor.CommitTx(ctx, id))
+	assert.Equal(t, TxMetric{Interactive: true, Committed: true}, <-r)
+	assert.Empty(t, e.started)
+}
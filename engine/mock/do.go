@@ -6,29 +6,50 @@ import (
 	"fmt"
 
 	"github.com/steebchen/prisma-client-go/engine/protocol"
+	"github.com/steebchen/prisma-client-go/runtime/builder"
 )
 
-func (e *Engine) Do(_ context.Context, payload interface{}, v interface{}) error {
+func (e *Engine) Do(ctx context.Context, payload interface{}, v interface{}) error {
 	e.expMu.Lock()
 	defer e.expMu.Unlock()
 
 	expectations := *e.expectations
 
+	req := payload.(protocol.GQLRequest)
+	query, hasQuery := builder.QueryFromContext(ctx)
+
+	e.calls = append(e.calls, req.Query)
+
 	n := -1
 	for i, e := range expectations {
-		req := payload.(protocol.GQLRequest)
-		str, err := e.Query.Build()
+		if e.exhausted() {
+			continue
+		}
+		ok, err := e.matches(query, hasQuery, req.Query)
 		if err != nil {
 			return err
 		}
-		if str == req.Query {
+		if ok {
 			n = i
 			break
 		}
 	}
 	if n == -1 {
-		panic("could not find query")
+		panic(fmt.Sprintf("could not find query `%s`", req.Query))
 	}
+
+	if e.ordered {
+		for i := 0; i < n; i++ {
+			if !expectations[i].met() {
+				str, err := expectations[i].Query.Build()
+				if err != nil {
+					return err
+				}
+				return fmt.Errorf("query `%s` was executed out of order, expected `%s` first", req.Query, str)
+			}
+		}
+	}
+
 	var retErr error
 	switch {
 	case expectations[n].Want != nil:
@@ -45,6 +66,7 @@ func (e *Engine) Do(_ context.Context, payload interface{}, v interface{}) error
 		panic("need to define either Want or WantErr")
 	}
 	expectations[n].Success = true
+	expectations[n].Calls++
 	*e.expectations = expectations
 	return retErr
 }
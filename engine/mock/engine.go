@@ -13,6 +13,12 @@ func New(expectations *[]Expectation) *Engine {
 type Engine struct {
 	expectations *[]Expectation
 	expMu        sync.Mutex
+
+	// calls holds all executed queries
+	calls []string
+
+	// ordered requires expectations to be met in the order they were defined
+	ordered bool
 }
 
 func (e *Engine) Name() string {
@@ -26,3 +32,11 @@ func (e *Engine) Connect() error {
 func (e *Engine) Disconnect() error {
 	panic("this is a mock client – you don't need to connect or disconnect this client")
 }
+
+// Calls returns all queries which were executed
+func (e *Engine) Calls() []string {
+	e.expMu.Lock()
+	defer e.expMu.Unlock()
+
+	return append([]string(nil), e.calls...)
+}
@@ -0,0 +1,90 @@
+package mock
+
+import (
+	"reflect"
+
+	"github.com/steebchen/prisma-client-go/runtime/builder"
+)
+
+// Matcher decides whether an executed query satisfies an expected query.
+// Matchers are only invoked for queries with the same operation, method and model as the expectation.
+type Matcher func(expected, actual builder.Query) bool
+
+// Anything matches any query with the expected method and model, regardless of its arguments
+var Anything Matcher = func(expected, actual builder.Query) bool {
+	return true
+}
+
+// Partial matches queries which contain at least the arguments of the expected query.
+// Additional arguments, e.g. fields which were added to a create call, are ignored.
+var Partial Matcher = func(expected, actual builder.Query) bool {
+	for _, e := range expected.Inputs {
+		found := false
+		for _, a := range actual.Inputs {
+			if e.Name == a.Name && reflect.DeepEqual(e.Value, a.Value) && fieldsContain(a.Fields, e.Fields) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Func matches queries for which the given predicate returns true
+func Func(fn func(actual builder.Query) bool) Matcher {
+	return func(expected, actual builder.Query) bool {
+		return fn(actual)
+	}
+}
+
+// fieldsContain returns whether each expected field matches one of the actual fields
+func fieldsContain(actual, expected []builder.Field) bool {
+	for _, e := range expected {
+		found := false
+		for _, a := range actual {
+			if fieldContains(a, e) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func fieldContains(actual, expected builder.Field) bool {
+	if actual.Name != expected.Name || actual.List != expected.List || actual.WrapList != expected.WrapList {
+		return false
+	}
+	if expected.Fields == nil {
+		return reflect.DeepEqual(actual.Value, expected.Value)
+	}
+	return fieldsContain(actual.Fields, expected.Fields)
+}
+
+func (e Expectation) matches(ctxQuery builder.Query, hasQuery bool, query string) (bool, error) {
+	if len(e.Matchers) == 0 || !hasQuery {
+		str, err := e.Query.Build()
+		if err != nil {
+			return false, err
+		}
+		return str == query, nil
+	}
+
+	if e.Query.Operation != ctxQuery.Operation || e.Query.Method != ctxQuery.Method || e.Query.Model != ctxQuery.Model {
+		return false, nil
+	}
+
+	for _, m := range e.Matchers {
+		if !m(e.Query, ctxQuery) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
@@ -1,6 +1,8 @@
 package mock
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/steebchen/prisma-client-go/runtime/builder"
@@ -11,6 +13,32 @@ type Expectation struct {
 	Want    interface{}
 	WantErr error
 	Success bool
+	// Matchers (optional) replace the exact match of Query
+	Matchers []Matcher
+	// Times (optional) is the exact number of times the query is expected to be executed.
+	// If zero, the query is expected to be executed at least once.
+	Times int
+	// AnyTimes allows the query to be executed any number of times, including never
+	AnyTimes bool
+	// Calls is the number of times the query was executed
+	Calls int
+}
+
+// exhausted returns whether the expectation can't be matched by further queries
+func (e Expectation) exhausted() bool {
+	return !e.AnyTimes && e.Times > 0 && e.Calls >= e.Times
+}
+
+// met returns whether the expectation was executed often enough
+func (e Expectation) met() bool {
+	switch {
+	case e.AnyTimes:
+		return true
+	case e.Times > 0:
+		return e.Calls == e.Times
+	default:
+		return e.Calls > 0
+	}
 }
 
 type Query interface {
@@ -19,19 +47,59 @@ type Query interface {
 
 type Mock struct {
 	Expectations *[]Expectation
+	// Engine (optional) is the mock engine, which is used to report the executed queries
+	Engine *Engine
+}
+
+// InOrder requires expectations to be met in the order they were defined
+func (m *Mock) InOrder() {
+	if m.Engine == nil {
+		panic("InOrder requires the mock engine")
+	}
+
+	m.Engine.expMu.Lock()
+	defer m.Engine.expMu.Unlock()
+
+	m.Engine.ordered = true
 }
 
 func (m *Mock) Ensure(t *testing.T) {
+	t.Helper()
+
 	if len(*m.Expectations) == 0 {
 		t.Fatalf("no expectations defined")
 	}
+
+	var report strings.Builder
 	for _, e := range *m.Expectations {
-		if !e.Success {
-			str, err := e.Query.Build()
-			if err != nil {
-				t.Fatalf("could not build query: %s", err)
-			}
-			t.Fatalf("expectation not met for query `%s` and result `%s`, error `%s`", str, e.Want, e.WantErr)
+		if e.met() {
+			continue
 		}
+
+		str, err := e.Query.Build()
+		if err != nil {
+			t.Fatalf("could not build query: %s", err)
+		}
+
+		expected := "at least once"
+		if e.Times > 0 {
+			expected = fmt.Sprintf("%d times", e.Times)
+		}
+
+		_, _ = fmt.Fprintf(&report, "\n  expected query `%s` %s, but it was executed %d times (result `%v`, error `%v`)", str, expected, e.Calls, e.Want, e.WantErr)
 	}
+
+	if report.Len() == 0 {
+		return
+	}
+
+	if m.Engine != nil {
+		calls := m.Engine.Calls()
+		_, _ = fmt.Fprintf(&report, "\n\n  executed queries (%d):", len(calls))
+		for _, call := range calls {
+			_, _ = fmt.Fprintf(&report, "\n    `%s`", call)
+		}
+	}
+
+	t.Fatalf("expectations not met:%s", report.String())
 }
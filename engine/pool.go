@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PoolOptions configures the connection pool of the query engine. Zero values keep the engine defaults.
+type PoolOptions struct {
+	// ConnectionLimit is the maximum number of open connections
+	ConnectionLimit int
+	// PoolTimeout is how long a query waits for a free connection
+	PoolTimeout time.Duration
+	// SocketTimeout is how long a query waits for the database to respond
+	SocketTimeout time.Duration
+}
+
+// IsZero returns whether no pool options are set
+func (o PoolOptions) IsZero() bool {
+	return o == PoolOptions{}
+}
+
+// ApplyPoolOptions sets the pool options as parameters of a connection string, overriding parameters which are
+// already set. Timeouts are rounded up to full seconds.
+func ApplyPoolOptions(connectionString string, options PoolOptions) (string, error) {
+	if options.IsZero() {
+		return connectionString, nil
+	}
+
+	if strings.HasPrefix(connectionString, "sqlserver:") || strings.HasPrefix(connectionString, "prisma:") {
+		return "", fmt.Errorf("pool options are not supported for this connection string")
+	}
+
+	u, err := url.Parse(connectionString)
+	if err != nil {
+		return "", fmt.Errorf("parse connection string: %w", err)
+	}
+
+	query := u.Query()
+	if options.ConnectionLimit > 0 {
+		query.Set("connection_limit", strconv.Itoa(options.ConnectionLimit))
+	}
+	if options.PoolTimeout > 0 {
+		query.Set("pool_timeout", seconds(options.PoolTimeout))
+	}
+	if options.SocketTimeout > 0 {
+		query.Set("socket_timeout", seconds(options.SocketTimeout))
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
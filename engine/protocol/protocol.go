@@ -44,6 +44,32 @@ func (e *UserFacingError) Error() string {
 	return e.Message
 }
 
+// Is reports whether the error code belongs to the target ErrorClass, so that errors can be matched with errors.Is
+func (e *UserFacingError) Is(target error) bool {
+	class, ok := target.(*ErrorClass)
+	if !ok {
+		return false
+	}
+	for _, code := range class.Codes {
+		if code == e.ErrorCode {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrorClass groups error codes of the query engine, e.g. all codes of transient connection errors
+type ErrorClass struct {
+	// Name describes the class of errors
+	Name string
+	// Codes are the error codes of the class, e.g. P2034
+	Codes []string
+}
+
+func (c *ErrorClass) Error() string {
+	return c.Name
+}
+
 type Meta struct {
 	Target interface{} `json:"target"` // can be of type []string or string
 }
@@ -158,6 +158,7 @@ func (e *DataProxyEngine) request(ctx context.Context, method string, path strin
 	logger.Debug.Printf("requesting %s", e.url+path)
 	auth := func(req *http.Request) {
 		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", e.apiKey))
+		applyCacheStrategy(ctx, req)
 	}
 	return request(ctx, e.http, method, e.url+path, payload, auth)
 }
@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"os/exec"
 	"sync"
+
+	"github.com/steebchen/prisma-client-go/binaries/unpack"
 )
 
 func NewQueryEngine(schema string, hasBinaryTargets bool, datasources string, datasourceURL string) *QueryEngine {
@@ -13,6 +15,8 @@ func NewQueryEngine(schema string, hasBinaryTargets bool, datasources string, da
 		datasources:      datasources,
 		datasourceURL:    datasourceURL,
 		http:             &http.Client{},
+		stderrTail:       newRing(crashStderrLines),
+		recentQueries:    newRing(crashQueries),
 	}
 }
 
@@ -23,6 +27,9 @@ type QueryEngine struct {
 	// cmd holds the prisma binary process
 	cmd *exec.Cmd
 
+	// process tracks the exit of the currently running prisma binary
+	process *process
+
 	// http is the internal http client
 	http *http.Client
 
@@ -32,9 +39,28 @@ type QueryEngine struct {
 	// datasourceURL holds the sanitized datasourceURL which is overridden in the datasource above
 	datasourceURL string
 
+	// provider is the active datasource provider, e.g. postgresql or mysql
+	provider string
+
 	// httpURL holds the query-engine httpURL
 	httpURL string
 
+	// binaryPath is the path of a sideloaded query engine binary which is used instead of an embedded one
+	binaryPath string
+
+	// file is the path of the query engine binary, resolved on Connect
+	file string
+
+	// reload serializes schema reloads and redeploys
+	reload sync.Mutex
+
+	// txs holds the process which serves each open interactive transaction, as a redeploy may replace the current
+	// process while transactions are open
+	txs map[string]*openTx
+
+	// asset (optional) is the query engine embedded into the generated client
+	asset *unpack.Asset
+
 	// hasBinaryTargets can be toggled by generated code from Schema.prisma whether binaryTargets
 	// were specified and thus expects binaries in the local path
 	hasBinaryTargets bool
@@ -54,6 +80,30 @@ type QueryEngine struct {
 	// lastEngineError contains the last received error
 	lastEngineError string
 
+	// onQuery contains the listeners for executed statements
+	onQuery []func(QueryEvent)
+
+	// logQueries indicates whether the running engine process logs the statements it executes
+	logQueries bool
+
+	// captures collect the executed statements of interactive transactions for CaptureTx, keyed by transaction id
+	captures map[string]*queryCapture
+
+	// metrics indicates whether the engine collects metrics
+	metrics bool
+
+	// restartPolicy controls how the engine is restarted after it crashed
+	restartPolicy RestartPolicy
+
+	// onEngineEvent contains the listeners for engine crashes and restarts
+	onEngineEvent []func(EngineEvent)
+
+	// stderrTail keeps the last stderr lines of the engine process for crash reports
+	stderrTail *ring
+
+	// recentQueries keeps the last query documents for crash reports
+	recentQueries *ring
+
 	mu sync.RWMutex
 }
 
@@ -61,7 +111,55 @@ func (e *QueryEngine) Name() string {
 	return "query-engine"
 }
 
+// SetBinaryPath sets the path of the query engine binary which is loaded at runtime.
+// The env var PRISMA_QUERY_ENGINE_BINARY still takes precedence over this value.
+func (e *QueryEngine) SetBinaryPath(path string) {
+	e.binaryPath = path
+}
+
+// SetProvider sets the active datasource provider, e.g. postgresql or mysql, which selects the SQL of statements the
+// engine sends itself, such as savepoints
+func (e *QueryEngine) SetProvider(provider string) {
+	e.provider = provider
+}
+
+// Provider returns the active datasource provider, which is empty if it was not set
+func (e *QueryEngine) Provider() string {
+	return e.provider
+}
+
+// SetAsset sets the query engine embedded into the generated client, which is unpacked on first use.
+// Each client passes its own asset, so that multiple generated clients can be used in the same binary.
+func (e *QueryEngine) SetAsset(asset *unpack.Asset) {
+	e.asset = asset
+}
+
 // deprecated
 func (e *QueryEngine) ReplaceSchema(replace func(schema string) string) {
 	e.Schema = replace(e.Schema)
 }
+
+// DatasourceURL returns the connection URL of the datasource, which is empty if the engine reads it from the
+// environment itself
+func (e *QueryEngine) DatasourceURL() string {
+	return e.datasourceURL
+}
+
+// ProviderOf returns the active datasource provider of an engine, looking through engines which wrap another engine
+func ProviderOf(e Engine) string {
+	p, ok := find[interface{ Provider() string }](e)
+	if !ok {
+		return ""
+	}
+	return p.Provider()
+}
+
+// DatasourceURLOf returns the connection URL of the datasource of an engine, looking through engines which wrap
+// another engine
+func DatasourceURLOf(e Engine) (string, bool) {
+	d, ok := find[interface{ DatasourceURL() string }](e)
+	if !ok || d.DatasourceURL() == "" {
+		return "", false
+	}
+	return d.DatasourceURL(), true
+}
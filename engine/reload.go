@@ -0,0 +1,220 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/steebchen/prisma-client-go/binaries/platform"
+	"github.com/steebchen/prisma-client-go/logger"
+)
+
+// DefaultSchemaWatchInterval is how often WatchSchema checks the schema file for changes by default
+const DefaultSchemaWatchInterval = 500 * time.Millisecond
+
+// DefaultDrainTimeout is how long a redeploy waits for the requests and interactive transactions of the replaced
+// engine process by default
+const DefaultDrainTimeout = 30 * time.Second
+
+// SchemaReloader is implemented by engines which can be restarted with a changed schema
+type SchemaReloader interface {
+	// ReloadSchema restarts the engine with the given schema
+	ReloadSchema(schema string) error
+}
+
+// Redeployer is implemented by engines which can replace their process with a new schema or binary without dropping
+// queries
+type Redeployer interface {
+	// Redeploy replaces the engine process according to the options
+	Redeploy(ctx context.Context, options RedeployOptions) error
+}
+
+// AsRedeployer returns the Redeployer of an engine, looking through engines which wrap another engine
+func AsRedeployer(e Engine) (Redeployer, bool) {
+	return find[Redeployer](e)
+}
+
+// RedeployOptions configures the replacement of the query engine process
+type RedeployOptions struct {
+	// Schema is the schema of the new process; the current schema is kept if empty
+	Schema string
+	// BinaryPath is the path of the query engine binary of the new process, e.g. of a newer engine version; the
+	// current binary is kept if empty
+	BinaryPath string
+	// DrainTimeout is how long the old process may finish its in-flight requests and interactive transactions before
+	// it is stopped, DefaultDrainTimeout by default
+	DrainTimeout time.Duration
+}
+
+// ReloadSchema restarts the query engine with the given schema, like Redeploy with the default drain timeout.
+// If the engine is not connected, the schema is used on the next Connect.
+func (e *QueryEngine) ReloadSchema(schema string) error {
+	return e.Redeploy(context.Background(), RedeployOptions{Schema: schema})
+}
+
+// Redeploy replaces the query engine process without dropping queries: a standby process with the new schema or
+// binary is started next to the current one, and once it is ready, all new requests are switched to it at once.
+// In-flight requests and open interactive transactions stay on the old process, which is stopped once they completed,
+// the drain timeout passed or ctx is done. If the new process can't be started, the current process keeps running
+// unchanged. If the engine is not connected, the options are used on the next Connect.
+func (e *QueryEngine) Redeploy(ctx context.Context, options RedeployOptions) error {
+	e.reload.Lock()
+	defer e.reload.Unlock()
+
+	e.mu.Lock()
+	old, file, schema := e.process, e.file, e.Schema
+	if options.Schema != "" {
+		schema = options.Schema
+	}
+	if options.BinaryPath != "" {
+		file = options.BinaryPath
+	}
+	running := e.connected && !e.disconnected && old != nil
+	if !running {
+		e.Schema = schema
+		if options.BinaryPath != "" {
+			e.binaryPath = options.BinaryPath
+		}
+	}
+	e.lastEngineError = ""
+	e.mu.Unlock()
+
+	if !running {
+		return nil
+	}
+
+	logger.Debug.Printf("starting standby query engine...")
+
+	p, err := e.start(file, schema)
+	if err != nil {
+		err = fmt.Errorf("redeploy: %w", err)
+		e.emitEngineEvent(EngineEvent{Type: EngineReloadFailed, Err: err})
+		return err
+	}
+
+	// switch all new requests to the new process at once; the supervisor of the old process ignores its exit, as it
+	// was replaced
+	e.mu.Lock()
+	e.cmd, e.process, e.httpURL = p.cmd, p, p.url
+	e.Schema, e.file = schema, file
+	if options.BinaryPath != "" {
+		e.binaryPath = options.BinaryPath
+	}
+	e.mu.Unlock()
+
+	go e.supervise(file, p)
+
+	timeout := options.DrainTimeout
+	if timeout <= 0 {
+		timeout = DefaultDrainTimeout
+	}
+	if !old.drain(ctx, timeout) {
+		logger.Default().Warn("stopping the replaced query engine with requests in flight", "active", old.active.Load())
+	}
+	e.releaseTxs(old)
+
+	if platform.Name() == "windows" {
+		_ = old.cmd.Process.Kill()
+	} else {
+		_ = old.cmd.Process.Signal(os.Interrupt)
+	}
+	<-old.done
+
+	logger.Debug.Printf("query engine redeployed")
+	e.emitEngineEvent(EngineEvent{Type: EngineReloaded})
+
+	return nil
+}
+
+// drainInterval is how often drain checks whether a process has requests in flight
+const drainInterval = 10 * time.Millisecond
+
+// drain waits until the process has no requests in flight and no open interactive transactions, and returns false if
+// the timeout passed or ctx is done before
+func (p *process) drain(ctx context.Context, timeout time.Duration) bool {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(drainInterval)
+	defer ticker.Stop()
+
+	for p.active.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-deadline.C:
+			return false
+		case <-ticker.C:
+		}
+	}
+	return true
+}
+
+// releaseTxs forgets the open interactive transactions of a stopped process, whose requests then fail
+func (e *QueryEngine) releaseTxs(p *process) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for id, owner := range e.txs {
+		if owner.process == p {
+			owner.expiry.Stop()
+			delete(e.txs, id)
+		}
+	}
+}
+
+// WatchSchema polls the schema file at path and restarts the engine with its contents whenever it changes, until ctx
+// is done. It is meant for development loops only: the generated Go types are not updated, so every change logs a
+// warning that the client has to be regenerated for new or changed models and fields.
+// An interval of 0 uses DefaultSchemaWatchInterval.
+func WatchSchema(ctx context.Context, e Engine, path string, interval time.Duration) error {
+	r, ok := find[SchemaReloader](e)
+	if !ok {
+		return fmt.Errorf("schema reloads are not supported by engine %s", e.Name())
+	}
+
+	current, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read schema: %w", err)
+	}
+
+	if interval <= 0 {
+		interval = DefaultSchemaWatchInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			next, err := os.ReadFile(path)
+			if err != nil {
+				// editors may briefly remove the file while saving
+				logger.Debug.Printf("read schema %s: %s", path, err)
+				continue
+			}
+			if bytes.Equal(next, current) {
+				continue
+			}
+			current = next
+
+			logger.Default().Warn(
+				"schema changed since the client was generated; the Go types may no longer match the database "+
+					"until you regenerate the client with `go run github.com/steebchen/prisma-client-go generate`",
+				"path", path,
+			)
+
+			if err := r.ReloadSchema(string(next)); err != nil {
+				logger.Default().Error("could not reload schema", "path", path, "error", err)
+			}
+		}
+	}()
+
+	return nil
+}
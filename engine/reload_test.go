@@ -0,0 +1,169 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type reloadingEngine struct {
+	Engine
+	schemas chan string
+}
+
+func (e *reloadingEngine) Name() string {
+	return "reloading"
+}
+
+func (e *reloadingEngine) ReloadSchema(schema string) error {
+	e.schemas <- schema
+	return nil
+}
+
+func TestWatchSchema(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	path := filepath.Join(t.TempDir(), "schema.prisma")
+	assert.NoError(t, os.WriteFile(path, []byte("model User {}"), 0644))
+
+	e := &reloadingEngine{schemas: make(chan string, 10)}
+	err := WatchSchema(ctx, NewDeadlineBudget(e, 0.5), path, time.Millisecond)
+	assert.NoError(t, err)
+
+	// an unchanged file doesn't reload
+	select {
+	case schema := <-e.schemas:
+		t.Fatalf("unexpected reload with %q", schema)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	assert.NoError(t, os.WriteFile(path, []byte("model Post {}"), 0644))
+
+	select {
+	case schema := <-e.schemas:
+		assert.Equal(t, "model Post {}", schema)
+	case <-time.After(time.Second):
+		t.Fatal("schema was not reloaded")
+	}
+}
+
+func TestWatchSchemaUnsupported(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.prisma")
+	assert.NoError(t, os.WriteFile(path, []byte("model User {}"), 0644))
+
+	err := WatchSchema(context.Background(), &DataProxyEngine{}, path, 0)
+	assert.EqualError(t, err, "schema reloads are not supported by engine data-proxy")
+}
+
+func TestReloadSchemaDisconnected(t *testing.T) {
+	e := NewQueryEngine("model User {}", false, "", "")
+	assert.NoError(t, e.ReloadSchema("model Post {}"))
+	assert.Equal(t, "model Post {}", e.Schema)
+}
+
+func TestRedeployDisconnected(t *testing.T) {
+	e := NewQueryEngine("model User {}", false, "", "")
+	assert.NoError(t, e.Redeploy(context.Background(), RedeployOptions{Schema: "model Post {}", BinaryPath: "/engine"}))
+	assert.Equal(t, "model Post {}", e.Schema)
+	assert.Equal(t, "/engine", e.binaryPath)
+}
+
+func TestRedeployDrain(t *testing.T) {
+	newServer := func(name string, requests *[]string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*requests = append(*requests, name+" "+r.URL.Path)
+			if r.URL.Path == "/transaction/start" {
+				_, _ = w.Write([]byte(`{"id":"tx1"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"data":{"result":{}}}`))
+		}))
+	}
+
+	var requests []string
+	oldSrv := newServer("old", &requests)
+	defer oldSrv.Close()
+	newSrv := newServer("new", &requests)
+	defer newSrv.Close()
+
+	old := &process{url: oldSrv.URL, done: make(chan struct{})}
+	next := &process{url: newSrv.URL, done: make(chan struct{})}
+
+	e := NewQueryEngine("", false, "", "")
+	e.http = oldSrv.Client()
+	e.connected = true
+	e.process, e.httpURL = old, old.url
+
+	ctx := context.Background()
+	id, err := e.StartTx(ctx, TxOptions{})
+	assert.NoError(t, err)
+
+	// switch like a redeploy does
+	e.mu.Lock()
+	e.process, e.httpURL = next, next.url
+	e.mu.Unlock()
+
+	var v json.RawMessage
+	assert.NoError(t, e.Do(ctx, map[string]string{}, &v))
+	assert.NoError(t, e.Do(withTxID(ctx, id), map[string]string{}, &v))
+
+	assert.False(t, old.drain(ctx, 20*time.Millisecond), "an open transaction must keep the old process")
+
+	assert.NoError(t, e.CommitTx(ctx, id))
+	assert.True(t, old.drain(ctx, time.Second))
+	assert.Equal(t, int64(0), next.active.Load())
+
+	assert.Equal(t, []string{
+		"old /transaction/start",
+		"new /",
+		"old /",
+		"old /transaction/tx1/commit",
+	}, requests)
+}
+
+func TestRedeployDrainAbandonedTx(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/transaction/start":
+			_, _ = w.Write([]byte(`{"id":"tx1"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"errors":[{"error":"Transaction API error: Transaction already closed","user_facing_error":{"message":"Transaction already closed","error_code":"P2028"}}]}`))
+		}
+	}))
+	defer srv.Close()
+
+	p := &process{url: srv.URL, done: make(chan struct{})}
+
+	e := NewQueryEngine("", false, "", "")
+	e.http = srv.Client()
+	e.connected = true
+	e.process, e.httpURL = p, p.url
+
+	ctx := context.Background()
+
+	// an abandoned transaction is released once the engine rolled it back after its timeout
+	_, err := e.StartTx(ctx, TxOptions{Timeout: 20 * time.Millisecond})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), p.active.Load())
+	assert.True(t, p.drain(ctx, time.Second))
+
+	// a transaction is released as soon as the engine reports that it ended
+	id, err := e.StartTx(ctx, TxOptions{Timeout: time.Hour})
+	assert.NoError(t, err)
+	var v json.RawMessage
+	assert.Error(t, e.Do(withTxID(ctx, id), map[string]string{}, &v))
+	assert.True(t, p.drain(ctx, 20*time.Millisecond))
+
+	e.mu.Lock()
+	assert.Empty(t, e.txs)
+	e.mu.Unlock()
+}
@@ -82,25 +82,61 @@ func (e *QueryEngine) Batch(ctx context.Context, payload interface{}, v interfac
 }
 
 func (e *QueryEngine) Request(ctx context.Context, method string, path string, payload interface{}, requiresConnection bool) ([]byte, error) {
+	body, _, err := e.requestTx(ctx, txID(ctx), method, path, payload, requiresConnection)
+	return body, err
+}
+
+// requestTx sends a request to the engine process which serves the interactive transaction with the given id, or to
+// the current process if there is no such transaction, and returns the process which served it
+func (e *QueryEngine) requestTx(ctx context.Context, tx string, method string, path string, payload interface{}, requiresConnection bool) ([]byte, *process, error) {
 	if !e.connected && requiresConnection {
 		logger.Info.Printf("A query was executed before Connect() was called. Make sure to call .Prisma.Connect() before sending any queries.")
-		return nil, fmt.Errorf("client is not connected yet")
+		return nil, nil, fmt.Errorf("client is not connected yet")
 	}
 
 	e.mu.RLock()
 	if e.disconnected {
 		e.mu.RUnlock()
 		logger.Info.Printf("A query was executed after Disconnect() was called. Make sure to not send any queries after calling .Prisma.Disconnect() the client.")
-		return nil, fmt.Errorf("client is already disconnected")
+		return nil, nil, fmt.Errorf("client is already disconnected")
+	}
+	httpURL := e.httpURL
+	p := e.process
+	if owner, ok := e.txs[tx]; ok {
+		// transactions are bound to the process which started them, even if another process took over since
+		httpURL, p = owner.process.url, owner.process
+	}
+	if p != nil {
+		// counted while holding the lock, so that a redeploy which switched processes sees every request
+		p.active.Add(1)
+		defer p.active.Add(-1)
 	}
 	e.mu.RUnlock()
 
 	requestBody, err := json.Marshal(payload)
 	if err != nil {
-		return nil, fmt.Errorf("payload marshal: %w", err)
+		return nil, p, fmt.Errorf("payload marshal: %w", err)
+	}
+
+	if path == "/" {
+		e.recentQueries.add(string(requestBody))
 	}
 
-	return request(ctx, e.http, method, e.httpURL+path, requestBody, func(req *http.Request) {
+	body, err := request(ctx, e.http, method, httpURL+path, requestBody, func(req *http.Request) {
 		req.Header.Set("content-type", "application/json")
+		if id := txID(ctx); id != "" {
+			req.Header.Set("X-transaction-id", id)
+		}
 	})
+	if tx != "" && (txClosed(body) || (err != nil && txClosed([]byte(err.Error())))) {
+		// the engine already ended the transaction, e.g. as it timed out
+		e.endTx(tx)
+	}
+	if err != nil {
+		if report, ok := e.crashed(ctx, p); ok {
+			return nil, p, &CrashError{Report: report, Err: err}
+		}
+		return nil, p, err
+	}
+	return body, p, nil
 }
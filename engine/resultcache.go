@@ -0,0 +1,202 @@
+package engine
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steebchen/prisma-client-go/runtime/metadata"
+)
+
+// ResultCache stores the encoded results of read queries for the read-through cache of a CacheEngine. It's implemented
+// by adapters of cache libraries, such as Redis or ristretto; NewMemoryCache returns an in-memory implementation.
+// Its methods are called synchronously from the queries and should return quickly.
+type ResultCache interface {
+	// Get returns the result stored under a key, if it exists and didn't expire
+	Get(ctx context.Context, key string) ([]byte, bool)
+	// Set stores a result under a key for the given duration
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration)
+}
+
+// CacheEngine wraps an engine to cache the results of read queries in a ResultCache. Results are keyed by the query
+// and a generation of each model it reads, which changes with every write of the model, so that writes make the cached
+// results of earlier reads unreachable.
+type CacheEngine struct {
+	Engine
+
+	Cache ResultCache
+	// TTL is the duration for which results are cached
+	TTL time.Duration
+	// Schema is used to look up the models of the relations a query reads
+	Schema metadata.Schema
+
+	mu sync.Mutex
+	// generations holds the current generation of each model which was written, keyed by model name
+	generations map[string]string
+}
+
+// NewCacheEngine wraps an engine to cache the results of its read queries for the given duration
+func NewCacheEngine(e Engine, c ResultCache, ttl time.Duration, schema metadata.Schema) *CacheEngine {
+	return &CacheEngine{
+		Engine:      e,
+		Cache:       c,
+		TTL:         ttl,
+		Schema:      schema,
+		generations: map[string]string{},
+	}
+}
+
+// Unwrap returns the wrapped engine
+func (e *CacheEngine) Unwrap() Engine {
+	return e.Engine
+}
+
+func (e *CacheEngine) resultCache() *CacheEngine {
+	return e
+}
+
+// Key returns the cache key of a read query which reads the given models and is sent to the engine e, which wraps the
+// cache engine
+func (e *CacheEngine) Key(engine Engine, models []string, query string) string {
+	models = append([]string{}, models...)
+	sort.Strings(models)
+
+	e.mu.Lock()
+	parts := make([]string, len(models))
+	for i, model := range models {
+		generation, ok := e.generations[model]
+		if !ok {
+			generation = "0"
+		}
+		parts[i] = model + "@" + generation
+	}
+	e.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(Scope(engine) + "\n" + query))
+	return strings.Join(parts, ",") + ":" + hex.EncodeToString(sum[:])
+}
+
+// Scope returns what the results of queries sent to an engine depend on besides the queries themselves, such as the
+// session variables or the tenant schema of a client, so that results of different scopes aren't shared
+func Scope(e Engine) string {
+	var scopes []string
+	for {
+		if s, ok := e.(interface{ scope() string }); ok {
+			scopes = append(scopes, s.scope())
+		}
+		w, ok := e.(interface{ Unwrap() Engine })
+		if !ok {
+			return strings.Join(scopes, "\n")
+		}
+		e = w.Unwrap()
+	}
+}
+
+// invalidate starts a new generation of a model. Generations are random rather than counted, so that clients of
+// different processes sharing a cache never use the same generation for different states of a model.
+func (e *CacheEngine) invalidate(model string) {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+
+	e.mu.Lock()
+	e.generations[model] = hex.EncodeToString(b)
+	e.mu.Unlock()
+}
+
+// CacheOf returns the cache engine wrapped by an engine. Engines of interactive transactions have no cache, as their
+// reads may see uncommitted writes.
+func CacheOf(e Engine) (*CacheEngine, bool) {
+	if InTx(e) {
+		return nil, false
+	}
+	c, ok := find[interface{ resultCache() *CacheEngine }](e)
+	if !ok {
+		return nil, false
+	}
+	return c.resultCache(), true
+}
+
+// InvalidateCache drops the cached results of reads of the given models, e.g. after raw queries which wrote them or
+// when another process published an invalidation event
+func InvalidateCache(e Engine, models ...string) {
+	c, ok := find[interface{ resultCache() *CacheEngine }](e)
+	if !ok {
+		return
+	}
+	for _, model := range models {
+		c.resultCache().invalidate(model)
+	}
+}
+
+// MemoryCache is a ResultCache which stores results in memory
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryCache returns an in-memory ResultCache holding at most maxEntries results, or any number if it's 0. If the
+// cache is full, expired results are dropped first, and then arbitrary ones.
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		entries:    map[string]memoryEntry{},
+	}
+}
+
+// Get returns the result stored under a key, if it exists and didn't expire
+func (c *MemoryCache) Get(_ context.Context, key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+// Set stores a result under a key for the given duration
+func (c *MemoryCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.entries[key]; !ok && c.maxEntries > 0 && len(c.entries) >= c.maxEntries {
+		c.evict()
+	}
+	c.entries[key] = memoryEntry{
+		value:   value,
+		expires: time.Now().Add(ttl),
+	}
+}
+
+// evict drops the expired results, or an arbitrary one if none expired
+func (c *MemoryCache) evict() {
+	now := time.Now()
+	for key, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, key)
+		}
+	}
+	if len(c.entries) < c.maxEntries {
+		return
+	}
+	for key := range c.entries {
+		delete(c.entries, key)
+		return
+	}
+}
@@ -0,0 +1,63 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/steebchen/prisma-client-go/runtime/metadata"
+)
+
+func TestCacheEngineKey(t *testing.T) {
+	c := NewCacheEngine(nil, NewMemoryCache(0), time.Minute, metadata.Schema{})
+	e := NewDeadlineBudget(c, 0.5)
+
+	key := c.Key(e, []string{"User", "Post"}, "query")
+	assert.Equal(t, key, c.Key(e, []string{"Post", "User"}, "query"))
+	assert.NotEqual(t, key, c.Key(e, []string{"Post", "User"}, "other"))
+
+	Invalidate(context.Background(), e, "User", "findMany", nil, nil)
+	assert.Equal(t, key, c.Key(e, []string{"User", "Post"}, "query"))
+
+	Invalidate(context.Background(), e, "User", "updateOne", nil, nil)
+	assert.NotEqual(t, key, c.Key(e, []string{"User", "Post"}, "query"))
+	assert.Equal(t, c.Key(e, []string{"Comment"}, "query"), NewCacheEngine(nil, nil, 0, metadata.Schema{}).Key(nil, []string{"Comment"}, "query"))
+
+	// results of other session variables, e.g. tenant schemas, are cached separately
+	tenant := NewTenantSchema(e, "postgresql", "tenant_1")
+	assert.NotEqual(t, c.Key(e, []string{"Post"}, "query"), c.Key(tenant, []string{"Post"}, "query"))
+	assert.Equal(t, c.Key(tenant, []string{"Post"}, "query"), c.Key(NewTenantSchema(e, "postgresql", "tenant_1"), []string{"Post"}, "query"))
+
+	found, ok := CacheOf(e)
+	assert.True(t, ok)
+	assert.Same(t, c, found)
+	_, ok = CacheOf(&TxEngine{Engine: e})
+	assert.False(t, ok)
+}
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(2)
+
+	c.Set(ctx, "a", []byte("1"), time.Minute)
+	c.Set(ctx, "b", []byte("2"), -time.Second)
+	v, ok := c.Get(ctx, "a")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1"), v)
+	_, ok = c.Get(ctx, "b")
+	assert.False(t, ok)
+
+	// expired results are evicted first
+	c.Set(ctx, "b", []byte("2"), -time.Second)
+	c.Set(ctx, "c", []byte("3"), time.Minute)
+	_, ok = c.Get(ctx, "a")
+	assert.True(t, ok)
+	_, ok = c.Get(ctx, "c")
+	assert.True(t, ok)
+
+	// then arbitrary ones
+	c.Set(ctx, "d", []byte("4"), time.Minute)
+	assert.Len(t, c.entries, 2)
+}
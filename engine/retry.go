@@ -0,0 +1,166 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/steebchen/prisma-client-go/engine/protocol"
+	"github.com/steebchen/prisma-client-go/runtime/types"
+)
+
+// RetryPolicy controls how queries which failed due to a transient error are retried
+type RetryPolicy struct {
+	// Retries is the maximum number of retries after the first attempt
+	Retries int
+	// InitialBackoff is the maximum delay before the first retry, 50ms by default
+	InitialBackoff time.Duration
+	// MaxBackoff caps the exponentially growing maximum delay between retries, 2s by default
+	MaxBackoff time.Duration
+	// RetryOn contains the errors which are retried, matched with errors.Is.
+	// By default, transaction conflicts and connection errors are retried.
+	RetryOn []error
+}
+
+// RetryOption configures a RetryPolicy
+type RetryOption func(policy *RetryPolicy)
+
+// Retries sets the maximum number of retries after the first attempt
+func Retries(n int) RetryOption {
+	return func(policy *RetryPolicy) {
+		policy.Retries = n
+	}
+}
+
+// RetryOn sets the errors which are retried, e.g. types.ErrTxConflict
+func RetryOn(errs ...error) RetryOption {
+	return func(policy *RetryPolicy) {
+		policy.RetryOn = errs
+	}
+}
+
+// RetryBackoff sets the maximum delay before the first retry and the cap of the delay between later retries
+func RetryBackoff(initial time.Duration, max time.Duration) RetryOption {
+	return func(policy *RetryPolicy) {
+		policy.InitialBackoff = initial
+		policy.MaxBackoff = max
+	}
+}
+
+// NewRetryPolicy creates a retry policy with the given options applied to the defaults
+func NewRetryPolicy(options ...RetryOption) RetryPolicy {
+	policy := RetryPolicy{
+		Retries:        3,
+		InitialBackoff: 50 * time.Millisecond,
+		MaxBackoff:     2 * time.Second,
+		RetryOn:        []error{types.ErrTxConflict, types.ErrConnection},
+	}
+	for _, option := range options {
+		option(&policy)
+	}
+	return policy
+}
+
+// retryable returns whether an error matches one of the errors which are retried
+func (p RetryPolicy) retryable(err error) bool {
+	for _, target := range p.RetryOn {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns a random delay before the given retry, starting at 1, using full jitter
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	d := p.InitialBackoff
+	for i := 1; i < retry && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
+
+// RetryEngine wraps an engine so that queries which failed due to a transient error are retried with a jittered
+// exponential backoff. Queries within an interactive transaction are not retried, as the transaction can't continue
+// after an error.
+type RetryEngine struct {
+	Engine
+
+	Policy RetryPolicy
+}
+
+// NewRetryEngine wraps an engine to retry queries which failed due to a transient error
+func NewRetryEngine(e Engine, policy RetryPolicy) *RetryEngine {
+	return &RetryEngine{
+		Engine: e,
+		Policy: policy,
+	}
+}
+
+func (e *RetryEngine) Do(ctx context.Context, payload interface{}, into interface{}) error {
+	return e.retry(ctx, func() error {
+		return e.Engine.Do(ctx, payload, into)
+	})
+}
+
+func (e *RetryEngine) Batch(ctx context.Context, payload interface{}, into interface{}) error {
+	return e.retry(ctx, func() error {
+		if err := e.Engine.Batch(ctx, payload, into); err != nil {
+			return err
+		}
+
+		// batch transactions report errors in the response, which are handled by the caller unless they are retried
+		if response, ok := into.(*protocol.GQLBatchResponse); ok {
+			if err := batchError(response); err != nil && e.Policy.retryable(err) {
+				*response = protocol.GQLBatchResponse{}
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Unwrap returns the wrapped engine
+func (e *RetryEngine) Unwrap() Engine {
+	return e.Engine
+}
+
+func (e *RetryEngine) retry(ctx context.Context, fn func() error) error {
+	if txID(ctx) != "" {
+		return fn()
+	}
+
+	for retry := 1; ; retry++ {
+		err := fn()
+		if err == nil || retry > e.Policy.Retries || !e.Policy.retryable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(e.Policy.backoff(retry)):
+		}
+	}
+}
+
+// batchError returns the first user facing error of a batch response
+func batchError(response *protocol.GQLBatchResponse) error {
+	errs := response.Errors
+	for _, result := range response.Result {
+		errs = append(errs, result.Errors...)
+	}
+	for _, e := range errs {
+		if e.UserFacingError != nil {
+			return e.UserFacingError
+		}
+	}
+	return nil
+}
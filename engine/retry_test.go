@@ -0,0 +1,91 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/steebchen/prisma-client-go/engine/protocol"
+	"github.com/steebchen/prisma-client-go/runtime/types"
+)
+
+type flakyEngine struct {
+	Engine
+	errs  []error
+	calls int
+}
+
+func (e *flakyEngine) Do(ctx context.Context, payload interface{}, into interface{}) error {
+	e.calls++
+	if len(e.errs) == 0 {
+		return nil
+	}
+	err := e.errs[0]
+	e.errs = e.errs[1:]
+	return err
+}
+
+func TestRetryEngine(t *testing.T) {
+	conflict := fmt.Errorf("user facing error: %w", &protocol.UserFacingError{ErrorCode: "P2034"})
+	unique := fmt.Errorf("user facing error: %w", &protocol.UserFacingError{ErrorCode: "P2002"})
+	policy := NewRetryPolicy(RetryBackoff(time.Millisecond, time.Millisecond))
+
+	tests := []struct {
+		name  string
+		errs  []error
+		err   error
+		calls int
+	}{{
+		name:  "conflict",
+		errs:  []error{conflict, conflict},
+		calls: 3,
+	}, {
+		name:  "connection",
+		errs:  []error{fmt.Errorf("raw post: %w: %w", types.ErrConnection, errors.New("reset"))},
+		calls: 2,
+	}, {
+		name:  "not retryable",
+		errs:  []error{unique},
+		err:   unique,
+		calls: 1,
+	}, {
+		name:  "too many retries",
+		errs:  []error{conflict, conflict, conflict, conflict},
+		err:   conflict,
+		calls: 4,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flaky := &flakyEngine{errs: tt.errs}
+			err := NewRetryEngine(flaky, policy).Do(context.Background(), nil, nil)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.calls, flaky.calls)
+		})
+	}
+
+	t.Run("interactive transaction", func(t *testing.T) {
+		flaky := &flakyEngine{errs: []error{conflict}}
+		err := NewTxEngine(NewRetryEngine(flaky, policy), "tx1").Do(context.Background(), nil, nil)
+		assert.Equal(t, conflict, err)
+		assert.Equal(t, 1, flaky.calls)
+	})
+
+	t.Run("only selected errors", func(t *testing.T) {
+		flaky := &flakyEngine{errs: []error{conflict}}
+		err := NewRetryEngine(flaky, NewRetryPolicy(RetryOn(types.ErrConnection))).Do(context.Background(), nil, nil)
+		assert.Equal(t, conflict, err)
+		assert.Equal(t, 1, flaky.calls)
+	})
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := NewRetryPolicy(RetryBackoff(10*time.Millisecond, 40*time.Millisecond))
+	for retry := 1; retry < 6; retry++ {
+		assert.LessOrEqual(t, policy.backoff(retry), 40*time.Millisecond)
+		assert.GreaterOrEqual(t, policy.backoff(retry), time.Duration(0))
+	}
+}
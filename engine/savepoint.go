@@ -0,0 +1,75 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/steebchen/prisma-client-go/engine/protocol"
+)
+
+// savepoint holds the statements managing a savepoint, which depend on the provider
+type savepoint struct {
+	create   string
+	rollback string
+	// release is empty for providers which can't release savepoints, such as SQL Server
+	release string
+}
+
+// newSavepoint returns the statements of the savepoint with the given name. An empty provider uses the standard SQL
+// syntax, which all supported relational providers but SQL Server understand.
+func newSavepoint(provider string, name string) savepoint {
+	if provider == "sqlserver" {
+		return savepoint{
+			create:   "SAVE TRANSACTION " + name,
+			rollback: "ROLLBACK TRANSACTION " + name,
+		}
+	}
+	return savepoint{
+		create:   "SAVEPOINT " + name,
+		rollback: "ROLLBACK TO SAVEPOINT " + name,
+		release:  "RELEASE SAVEPOINT " + name,
+	}
+}
+
+// runInSavepoint calls fn within a savepoint of the interactive transaction of e, which is rolled back if fn returns
+// an error and released otherwise. MongoDB has no savepoints, so fn runs directly within the transaction there, and
+// an error is left to the outer transaction.
+func runInSavepoint(ctx context.Context, e Engine, tx *TxEngine, fn func(ctx context.Context, tx Engine) error) error {
+	provider := ProviderOf(e)
+	if provider == "mongodb" {
+		return fn(ctx, e)
+	}
+
+	sp := newSavepoint(provider, fmt.Sprintf("prisma_savepoint_%d", tx.savepoints.Add(1)))
+
+	if err := execStatement(ctx, e, sp.create); err != nil {
+		return fmt.Errorf("create savepoint: %w", err)
+	}
+
+	if err := fn(ctx, e); err != nil {
+		if rollbackErr := execStatement(context.Background(), e, sp.rollback); rollbackErr != nil {
+			return fmt.Errorf("%w (rollback to savepoint failed: %s)", err, rollbackErr)
+		}
+		return err
+	}
+
+	if sp.release == "" {
+		return nil
+	}
+	if err := execStatement(ctx, e, sp.release); err != nil {
+		return fmt.Errorf("release savepoint: %w", err)
+	}
+	return nil
+}
+
+// execStatement executes a raw statement without parameters
+func execStatement(ctx context.Context, e Engine, statement string) error {
+	query, _ := json.Marshal(statement)
+	payload := protocol.GQLRequest{
+		Query:     fmt.Sprintf(`mutation {result: executeRaw(query:%s,parameters:"[]")}`, query),
+		Variables: map[string]interface{}{},
+	}
+	var count int
+	return e.Do(ctx, payload, &count)
+}
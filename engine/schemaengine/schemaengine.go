@@ -0,0 +1,171 @@
+// Package schemaengine runs the Prisma schema engine and communicates with it via JSON-RPC.
+package schemaengine
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"sync"
+
+	"github.com/steebchen/prisma-client-go/binaries"
+	"github.com/steebchen/prisma-client-go/logger"
+)
+
+type request struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      int         `json:"id"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+type response struct {
+	ID     int             `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *Error          `json:"error"`
+}
+
+// Error is an error returned by the schema engine
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Message string `json:"message"`
+	} `json:"data"`
+}
+
+func (e *Error) Error() string {
+	if e.Data.Message != "" {
+		return e.Data.Message
+	}
+	return e.Message
+}
+
+// SchemaEngine is a running schema engine process which accepts JSON-RPC requests over stdin
+type SchemaEngine struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+
+	mu sync.Mutex
+	id int
+}
+
+// Start starts the schema engine for the given schema file. The schema engine is downloaded on first use unless
+// PRISMA_SCHEMA_ENGINE_BINARY is set. env is added to the environment of the schema engine process.
+func Start(ctx context.Context, schemaPath string, env []string) (*SchemaEngine, error) {
+	file, err := binaries.EnsureEngine(binaries.SchemaEngine)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Debug.Printf("starting %s with schema %s", file, schemaPath)
+
+	cmd := exec.CommandContext(ctx, file, "--datamodels", schemaPath) //nolint:gosec
+	cmd.Dir = path.Dir(schemaPath)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stderr = logWriter{}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("stdin pipe: %w", err)
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("stdout pipe: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start schema engine: %w", err)
+	}
+
+	return &SchemaEngine{
+		cmd:    cmd,
+		stdin:  stdin,
+		stdout: bufio.NewReader(stdout),
+	}, nil
+}
+
+// Call sends a JSON-RPC request to the schema engine and decodes its result into v
+func (e *SchemaEngine) Call(method string, params interface{}, v interface{}) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.id++
+	req := request{
+		JSONRPC: "2.0",
+		ID:      e.id,
+		Method:  method,
+		Params:  params,
+	}
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+
+	logger.Debug.Printf("schema engine request: %s", data)
+
+	if _, err := e.stdin.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("write request: %w", err)
+	}
+
+	for {
+		line, err := e.stdout.ReadBytes('\n')
+		if err != nil {
+			return fmt.Errorf("read response: %w", err)
+		}
+
+		logger.Debug.Printf("schema engine response: %s", line)
+
+		var res response
+		if err := json.Unmarshal(line, &res); err != nil {
+			return fmt.Errorf("unmarshal response: %w", err)
+		}
+
+		// ignore responses to other requests
+		if res.ID != req.ID {
+			continue
+		}
+
+		if res.Error != nil {
+			return fmt.Errorf("%s: %w", method, res.Error)
+		}
+
+		if v == nil {
+			return nil
+		}
+
+		if err := json.Unmarshal(res.Result, v); err != nil {
+			return fmt.Errorf("unmarshal result: %w", err)
+		}
+
+		return nil
+	}
+}
+
+// Close stops the schema engine and waits for it to exit
+func (e *SchemaEngine) Close() error {
+	if err := e.stdin.Close(); err != nil {
+		return fmt.Errorf("close stdin: %w", err)
+	}
+
+	if err := e.cmd.Wait(); err != nil {
+		return fmt.Errorf("wait for schema engine: %w", err)
+	}
+
+	return nil
+}
+
+// logWriter forwards schema engine logs to the debug logger
+type logWriter struct{}
+
+func (logWriter) Write(p []byte) (int, error) {
+	logger.Debug.Printf("schema engine: %s", p)
+	return len(p), nil
+}
@@ -0,0 +1,205 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/steebchen/prisma-client-go/engine/protocol"
+)
+
+// Setting is a session variable which is set for the transaction of a query, e.g. app.tenant_id for row-level
+// security policies
+type Setting struct {
+	Name  string
+	Value string
+}
+
+// SessionSettings wraps an engine so that queries run with session variables which are local to their transaction,
+// e.g. to drive row-level security policies or to use the tables of a tenant's database schema. Each query runs in an
+// interactive transaction which sets the variables first, like SET LOCAL, so that a single engine process and its
+// connection pool serve all sessions. Interactive transactions started through the engine set the variables once
+// when they start.
+type SessionSettings struct {
+	Engine
+
+	// Provider is the datasource provider; only postgresql and cockroachdb are supported
+	Provider string
+	// Settings are the session variables of the queries, which are set in order
+	Settings []Setting
+}
+
+// NewSessionSettings wraps an engine to run its queries with the given session variables. If e already sets session
+// variables, a copy of it with the given variables added is returned, as transactions can't be nested.
+func NewSessionSettings(e Engine, provider string, settings ...Setting) *SessionSettings {
+	if s, ok := e.(*SessionSettings); ok {
+		return &SessionSettings{
+			Engine:   s.Engine,
+			Provider: s.Provider,
+			Settings: append(append([]Setting{}, s.Settings...), settings...),
+		}
+	}
+	return &SessionSettings{
+		Engine:   e,
+		Provider: provider,
+		Settings: settings,
+	}
+}
+
+// NewTenantSchema wraps an engine so that queries use the tables of another database schema than the one of the
+// datasource URL, e.g. the schema of a tenant in a schema-per-tenant setup, by setting their search path
+func NewTenantSchema(e Engine, provider string, schema string) *SessionSettings {
+	return NewSessionSettings(e, provider, Setting{
+		Name:  "search_path",
+		Value: `"` + strings.ReplaceAll(schema, `"`, `""`) + `"`,
+	})
+}
+
+// SupportsSessionSettings returns whether queries can set session variables for the given provider
+func SupportsSessionSettings(provider string) bool {
+	switch provider {
+	case "postgresql", "postgres", "cockroachdb":
+		return true
+	}
+	return false
+}
+
+func (e *SessionSettings) Do(ctx context.Context, payload interface{}, into interface{}) error {
+	return e.run(ctx, func(ctx context.Context) error {
+		return e.Engine.Do(ctx, payload, into)
+	})
+}
+
+func (e *SessionSettings) Batch(ctx context.Context, payload interface{}, into interface{}) error {
+	return e.run(ctx, func(ctx context.Context) error {
+		return e.Engine.Batch(ctx, payload, into)
+	})
+}
+
+// SetLocal sets session variables for the rest of the interactive transaction which e sends its requests in, like
+// SET LOCAL. Within a savepoint, the variables are reset if it's rolled back.
+func SetLocal(ctx context.Context, e Engine, provider string, settings ...Setting) error {
+	if !SupportsSessionSettings(provider) {
+		return fmt.Errorf("session variables are not supported for provider %s", provider)
+	}
+	if !InTx(e) {
+		return fmt.Errorf("session variables can only be set within an interactive transaction")
+	}
+	if len(settings) == 0 {
+		return nil
+	}
+	var count int
+	if err := e.Do(ctx, settingsQuery(settings), &count); err != nil {
+		return fmt.Errorf("set session variables: %w", err)
+	}
+	return nil
+}
+
+// run calls fn within an interactive transaction which sets the session variables. If the context already belongs
+// to a transaction started by StartTx, fn runs in it directly; if the wrapped engine sends its requests within a
+// transaction, the variables are set for its remaining statements.
+func (e *SessionSettings) run(ctx context.Context, fn func(ctx context.Context) error) error {
+	if txID(ctx) != "" {
+		return fn(ctx)
+	}
+	if !SupportsSessionSettings(e.Provider) {
+		return fmt.Errorf("session variables are not supported for provider %s", e.Provider)
+	}
+	if InTx(e.Engine) {
+		if err := e.set(ctx); err != nil {
+			return err
+		}
+		return fn(ctx)
+	}
+
+	id, err := e.StartTx(ctx, TxOptions{})
+	if err != nil {
+		return err
+	}
+
+	transactor, _ := AsTransactor(e.Engine)
+	if err := fn(withTxID(ctx, id)); err != nil {
+		_ = transactor.RollbackTx(context.Background(), id)
+		return err
+	}
+
+	return transactor.CommitTx(ctx, id)
+}
+
+// set sets the session variables in the transaction of ctx
+func (e *SessionSettings) set(ctx context.Context) error {
+	if len(e.Settings) == 0 {
+		return nil
+	}
+	var count int
+	if err := e.Engine.Do(ctx, settingsQuery(e.Settings), &count); err != nil {
+		return fmt.Errorf("set session variables: %w", err)
+	}
+	return nil
+}
+
+// StartTx starts an interactive transaction which sets the session variables
+func (e *SessionSettings) StartTx(ctx context.Context, options TxOptions) (string, error) {
+	transactor, ok := AsTransactor(e.Engine)
+	if !ok {
+		return "", fmt.Errorf("engine %s does not support interactive transactions", e.Engine.Name())
+	}
+
+	id, err := transactor.StartTx(ctx, options)
+	if err != nil {
+		return "", err
+	}
+
+	if err := e.set(withTxID(ctx, id)); err != nil {
+		_ = transactor.RollbackTx(context.Background(), id)
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (e *SessionSettings) CommitTx(ctx context.Context, id string) error {
+	transactor, ok := AsTransactor(e.Engine)
+	if !ok {
+		return fmt.Errorf("engine %s does not support interactive transactions", e.Engine.Name())
+	}
+	return transactor.CommitTx(ctx, id)
+}
+
+func (e *SessionSettings) RollbackTx(ctx context.Context, id string) error {
+	transactor, ok := AsTransactor(e.Engine)
+	if !ok {
+		return fmt.Errorf("engine %s does not support interactive transactions", e.Engine.Name())
+	}
+	return transactor.RollbackTx(ctx, id)
+}
+
+// Unwrap returns the wrapped engine
+func (e *SessionSettings) Unwrap() Engine {
+	return e.Engine
+}
+
+// scope identifies the session variables, which the results of the queries depend on
+func (e *SessionSettings) scope() string {
+	data, _ := json.Marshal(e.Settings)
+	return string(data)
+}
+
+// settingsQuery returns a request setting session variables for the current transaction with set_config, which
+// takes the names and values as parameters
+func settingsQuery(settings []Setting) protocol.GQLRequest {
+	calls := make([]string, len(settings))
+	params := make([]string, 0, 2*len(settings))
+	for i, s := range settings {
+		calls[i] = fmt.Sprintf("set_config($%d, $%d, true)", 2*i+1, 2*i+2)
+		params = append(params, s.Name, s.Value)
+	}
+	query, _ := json.Marshal("SELECT " + strings.Join(calls, ", "))
+	encoded, _ := json.Marshal(params)
+	parameters, _ := json.Marshal(string(encoded))
+	return protocol.GQLRequest{
+		Query:     fmt.Sprintf(`mutation {result: executeRaw(query:%s,parameters:%s)}`, query, parameters),
+		Variables: map[string]interface{}{},
+	}
+}
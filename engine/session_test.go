@@ -0,0 +1,131 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenantSchema(t *testing.T) {
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r.URL.Path+" "+r.Header.Get("X-transaction-id"))
+
+		switch {
+		case r.URL.Path == "/transaction/start":
+			_, _ = w.Write([]byte(`{"id":"tx1"}`))
+		case strings.Contains(string(body), "set_config"):
+			_, _ = w.Write([]byte(`{"data":{"result":0}}`))
+		default:
+			_, _ = w.Write([]byte(`{"data":{"result":{}}}`))
+		}
+	}))
+	defer srv.Close()
+
+	qe := NewQueryEngine("", false, "", "")
+	qe.http = srv.Client()
+	qe.httpURL = srv.URL
+	qe.connected = true
+
+	e := NewTenantSchema(qe, "postgresql", "tenant_42")
+
+	var v json.RawMessage
+	if err := e.Do(context.Background(), map[string]string{}, &v); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{
+		"/transaction/start ",
+		"/ tx1",
+		"/ tx1",
+		"/transaction/tx1/commit ",
+	}, requests)
+
+	requests = nil
+	err := RunInTx(context.Background(), e, TxOptions{}, func(ctx context.Context, tx Engine) error {
+		if err := tx.Do(ctx, map[string]string{}, &v); err != nil {
+			return err
+		}
+		return tx.Do(ctx, map[string]string{}, &v)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{
+		"/transaction/start ",
+		"/ tx1",
+		"/ tx1",
+		"/ tx1",
+		"/transaction/tx1/commit ",
+	}, requests, "transactions must set the search path once")
+}
+
+func TestSetLocal(t *testing.T) {
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r.URL.Path+" "+r.Header.Get("X-transaction-id"))
+
+		switch {
+		case r.URL.Path == "/transaction/start":
+			_, _ = w.Write([]byte(`{"id":"tx1"}`))
+		case strings.Contains(string(body), "set_config"):
+			assert.Contains(t, string(body), "app.tenant_id")
+			_, _ = w.Write([]byte(`{"data":{"result":0}}`))
+		default:
+			_, _ = w.Write([]byte(`{"data":{"result":{}}}`))
+		}
+	}))
+	defer srv.Close()
+
+	qe := NewQueryEngine("", false, "", "")
+	qe.http = srv.Client()
+	qe.httpURL = srv.URL
+	qe.connected = true
+
+	setting := Setting{Name: "app.tenant_id", Value: "42"}
+	assert.EqualError(t, SetLocal(context.Background(), qe, "postgresql", setting),
+		"session variables can only be set within an interactive transaction")
+	assert.EqualError(t, SetLocal(context.Background(), qe, "mysql", setting),
+		"session variables are not supported for provider mysql")
+
+	err := RunInTx(context.Background(), qe, TxOptions{}, func(ctx context.Context, tx Engine) error {
+		return SetLocal(ctx, tx, "postgresql", setting)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"/transaction/start ",
+		"/ tx1",
+		"/transaction/tx1/commit ",
+	}, requests)
+}
+
+func TestSessionSettingsUnsupported(t *testing.T) {
+	e := NewSessionSettings(&deadlineEngine{}, "mysql", Setting{Name: "app.tenant_id", Value: "42"})
+	assert.Error(t, e.Do(context.Background(), nil, nil))
+}
+
+func TestNewSessionSettingsMerges(t *testing.T) {
+	inner := &deadlineEngine{}
+	e := NewSessionSettings(NewTenantSchema(inner, "postgresql", `a"b`), "postgresql", Setting{Name: "app.tenant_id", Value: "42"})
+
+	assert.Equal(t, inner, e.Engine)
+	assert.Equal(t, []Setting{
+		{Name: "search_path", Value: `"a""b"`},
+		{Name: "app.tenant_id", Value: "42"},
+	}, e.Settings)
+}
+
+func TestSettingsQuery(t *testing.T) {
+	assert.Equal(t,
+		`mutation {result: executeRaw(query:"SELECT set_config($1, $2, true), set_config($3, $4, true)",`+
+			`parameters:"[\"search_path\",\"\\\"a\\\"\",\"app.tenant_id\",\"42\"]")}`,
+		settingsQuery([]Setting{{Name: "search_path", Value: `"a"`}, {Name: "app.tenant_id", Value: "42"}}).Query,
+	)
+}
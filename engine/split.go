@@ -0,0 +1,52 @@
+package engine
+
+// DefaultInListLimit returns the maximum number of values of an In filter which are sent in a single query for a
+// provider. It stays below the maximum number of bind parameters of a statement, leaving room for other parameters.
+// A limit of 0 means that In filters are never split.
+func DefaultInListLimit(provider string) int {
+	switch provider {
+	case "postgresql", "postgres", "cockroachdb":
+		return 32000
+	case "mysql":
+		return 65000
+	case "sqlserver":
+		return 2000
+	case "sqlite":
+		return 999
+	}
+	return 0
+}
+
+// SplitEngine wraps an engine to split queries whose In filters exceed a limit into multiple queries
+type SplitEngine struct {
+	Engine
+
+	// Limit is the maximum number of values of an In filter in a single query
+	Limit int
+}
+
+// NewSplitEngine wraps an engine to split queries whose In filters contain more than limit values
+func NewSplitEngine(e Engine, limit int) *SplitEngine {
+	return &SplitEngine{
+		Engine: e,
+		Limit:  limit,
+	}
+}
+
+func (e *SplitEngine) inListLimit() int {
+	return e.Limit
+}
+
+// Unwrap returns the wrapped engine
+func (e *SplitEngine) Unwrap() Engine {
+	return e.Engine
+}
+
+// InListLimitOf returns the maximum number of values of an In filter in a single query of an engine, or 0 if In
+// filters are not split
+func InListLimitOf(e Engine) int {
+	if s, ok := find[interface{ inListLimit() int }](e); ok {
+		return s.inListLimit()
+	}
+	return 0
+}
@@ -0,0 +1,188 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/steebchen/prisma-client-go/engine/protocol"
+	"github.com/steebchen/prisma-client-go/logger"
+)
+
+// SQLiteOptions configures how the query engine uses a SQLite database. Zero values keep the engine defaults.
+type SQLiteOptions struct {
+	// JournalMode sets the journal mode of the database, e.g. WAL, which lets readers continue while a write is in
+	// progress. It's set when the engine connects and stored in the database file, so it applies to all connections.
+	JournalMode string
+	// BusyTimeout is how long a query waits for a lock held by another connection or process before it fails with
+	// SQLITE_BUSY. The query engine only accepts it in full seconds as the socket_timeout parameter of the connection
+	// string, so it's rounded up, e.g. 1500ms waits up to 2s.
+	BusyTimeout time.Duration
+	// ForeignKeys sets whether foreign key constraints are enforced, which the query engine enables on every connection
+	// by default. The pragma only applies to a single connection, so disabling them limits the engine to one connection
+	// and sets the pragma again before each request outside of interactive transactions, as the engine may replace the
+	// connection.
+	ForeignKeys *bool
+}
+
+// foreignKeysDisabled returns whether the options disable the enforcement of foreign keys
+func (o SQLiteOptions) foreignKeysDisabled() bool {
+	return o.ForeignKeys != nil && !*o.ForeignKeys
+}
+
+// journalModes are the journal modes SQLite supports
+var journalModes = []string{"DELETE", "TRUNCATE", "PERSIST", "MEMORY", "WAL", "OFF"}
+
+// ApplySQLiteOptions sets the SQLite options which the query engine supports as parameters of a connection string,
+// overriding parameters which are already set
+func ApplySQLiteOptions(connectionString string, options SQLiteOptions) (string, error) {
+	if options.BusyTimeout <= 0 && !options.foreignKeysDisabled() {
+		return connectionString, nil
+	}
+
+	u, err := url.Parse(connectionString)
+	if err != nil {
+		return "", fmt.Errorf("parse connection string: %w", err)
+	}
+
+	query := u.Query()
+	if options.BusyTimeout > 0 {
+		// the query engine uses the socket timeout of SQLite connections as busy timeout
+		query.Set("socket_timeout", seconds(options.BusyTimeout))
+		if options.BusyTimeout%time.Second != 0 {
+			logger.Default().Warn("the SQLite busy timeout is rounded up to full seconds", "busyTimeout", options.BusyTimeout)
+		}
+	}
+	if options.foreignKeysDisabled() {
+		// the pragma is set per connection, so it must be the only one
+		query.Set("connection_limit", "1")
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+// SQLiteEngine wraps an engine to apply SQLite options which are set with pragmas once it connected
+type SQLiteEngine struct {
+	Engine
+
+	Options SQLiteOptions
+}
+
+// NewSQLiteEngine wraps an engine to apply the given SQLite options when it connects
+func NewSQLiteEngine(e Engine, options SQLiteOptions) *SQLiteEngine {
+	return &SQLiteEngine{
+		Engine:  e,
+		Options: options,
+	}
+}
+
+func (e *SQLiteEngine) Connect() error {
+	mode := strings.ToUpper(e.Options.JournalMode)
+	if mode != "" && !slices.Contains(journalModes, mode) {
+		return fmt.Errorf("invalid journal mode %q, expected one of %s", e.Options.JournalMode, strings.Join(journalModes, ", "))
+	}
+
+	if err := e.Engine.Connect(); err != nil {
+		return err
+	}
+
+	if mode != "" {
+		var result json.RawMessage
+		if err := e.Engine.Do(context.Background(), pragmaQuery("journal_mode", mode), &result); err != nil {
+			return fmt.Errorf("set journal mode: %w", err)
+		}
+		// SQLite answers with the resulting mode, which differs e.g. for in-memory databases
+		if !strings.Contains(strings.ToUpper(string(result)), `"`+mode+`"`) {
+			logger.Default().Warn("could not change the journal mode of the SQLite database", "mode", mode, "result", string(result))
+		}
+	}
+
+	if e.Options.ForeignKeys != nil {
+		if err := e.setForeignKeys(context.Background()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *SQLiteEngine) Do(ctx context.Context, payload interface{}, into interface{}) error {
+	if err := e.reapplyForeignKeys(ctx); err != nil {
+		return err
+	}
+	return e.Engine.Do(ctx, payload, into)
+}
+
+func (e *SQLiteEngine) Batch(ctx context.Context, payload interface{}, into interface{}) error {
+	if err := e.reapplyForeignKeys(ctx); err != nil {
+		return err
+	}
+	return e.Engine.Batch(ctx, payload, into)
+}
+
+func (e *SQLiteEngine) StartTx(ctx context.Context, options TxOptions) (string, error) {
+	transactor, ok := AsTransactor(e.Engine)
+	if !ok {
+		return "", fmt.Errorf("engine %s does not support interactive transactions", e.Name())
+	}
+	// the pragma has no effect within a transaction, so it's set on the connection before
+	if err := e.reapplyForeignKeys(ctx); err != nil {
+		return "", err
+	}
+	return transactor.StartTx(ctx, options)
+}
+
+func (e *SQLiteEngine) CommitTx(ctx context.Context, id string) error {
+	transactor, ok := AsTransactor(e.Engine)
+	if !ok {
+		return fmt.Errorf("engine %s does not support interactive transactions", e.Name())
+	}
+	return transactor.CommitTx(ctx, id)
+}
+
+func (e *SQLiteEngine) RollbackTx(ctx context.Context, id string) error {
+	transactor, ok := AsTransactor(e.Engine)
+	if !ok {
+		return fmt.Errorf("engine %s does not support interactive transactions", e.Name())
+	}
+	return transactor.RollbackTx(ctx, id)
+}
+
+// reapplyForeignKeys disables foreign keys again before a request outside of an interactive transaction, as the query
+// engine enables them on every connection it opens
+func (e *SQLiteEngine) reapplyForeignKeys(ctx context.Context) error {
+	if !e.Options.foreignKeysDisabled() || txID(ctx) != "" {
+		return nil
+	}
+	return e.setForeignKeys(ctx)
+}
+
+func (e *SQLiteEngine) setForeignKeys(ctx context.Context) error {
+	value := "ON"
+	if e.Options.foreignKeysDisabled() {
+		value = "OFF"
+	}
+	var result json.RawMessage
+	if err := e.Engine.Do(ctx, pragmaQuery("foreign_keys", value), &result); err != nil {
+		return fmt.Errorf("set foreign keys: %w", err)
+	}
+	return nil
+}
+
+// Unwrap returns the wrapped engine
+func (e *SQLiteEngine) Unwrap() Engine {
+	return e.Engine
+}
+
+// pragmaQuery returns a request setting a pragma, whose value must be validated
+func pragmaQuery(name string, value string) protocol.GQLRequest {
+	return protocol.GQLRequest{
+		Query:     fmt.Sprintf(`mutation {result: queryRaw(query:"PRAGMA %s = %s",parameters:"[]")}`, name, value),
+		Variables: map[string]interface{}{},
+	}
+}
@@ -0,0 +1,84 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/steebchen/prisma-client-go/engine/protocol"
+)
+
+func TestApplySQLiteOptions(t *testing.T) {
+	url, err := ApplySQLiteOptions("file:./dev.db?connection_limit=1", SQLiteOptions{BusyTimeout: 1500 * time.Millisecond})
+	assert.NoError(t, err)
+	assert.Equal(t, "file:./dev.db?connection_limit=1&socket_timeout=2", url)
+
+	url, err = ApplySQLiteOptions("file:./dev.db", SQLiteOptions{JournalMode: "WAL"})
+	assert.NoError(t, err)
+	assert.Equal(t, "file:./dev.db", url)
+}
+
+// pragmaEngine records the queries it receives and answers raw queries with a fixed result
+type pragmaEngine struct {
+	Engine
+	connected bool
+	queries   []string
+	result    string
+}
+
+func (e *pragmaEngine) Connect() error {
+	e.connected = true
+	return nil
+}
+
+func (e *pragmaEngine) Do(ctx context.Context, payload interface{}, into interface{}) error {
+	e.queries = append(e.queries, payload.(protocol.GQLRequest).Query)
+	return json.Unmarshal([]byte(e.result), into)
+}
+
+func TestSQLiteEngine(t *testing.T) {
+	inner := &pragmaEngine{result: `[{"journal_mode":"wal"}]`}
+	e := NewSQLiteEngine(inner, SQLiteOptions{JournalMode: "wal"})
+
+	assert.NoError(t, e.Connect())
+	assert.True(t, inner.connected)
+	assert.Equal(t, []string{`mutation {result: queryRaw(query:"PRAGMA journal_mode = WAL",parameters:"[]")}`}, inner.queries)
+}
+
+func TestSQLiteEngineInvalidJournalMode(t *testing.T) {
+	inner := &pragmaEngine{}
+	e := NewSQLiteEngine(inner, SQLiteOptions{JournalMode: "WAL; DROP TABLE users"})
+
+	assert.Error(t, e.Connect())
+	assert.False(t, inner.connected)
+}
+
+func TestSQLiteEngineForeignKeys(t *testing.T) {
+	disabled := false
+	options := SQLiteOptions{ForeignKeys: &disabled}
+
+	url, err := ApplySQLiteOptions("file:./dev.db?connection_limit=5", options)
+	assert.NoError(t, err)
+	assert.Equal(t, "file:./dev.db?connection_limit=1", url)
+
+	inner := &pragmaEngine{result: `[]`}
+	e := NewSQLiteEngine(inner, options)
+	assert.NoError(t, e.Connect())
+
+	var result json.RawMessage
+	assert.NoError(t, e.Do(context.Background(), protocol.GQLRequest{Query: "query"}, &result))
+	assert.NoError(t, e.Do(withTxID(context.Background(), "tx"), protocol.GQLRequest{Query: "in tx"}, &result))
+
+	pragma := `mutation {result: queryRaw(query:"PRAGMA foreign_keys = OFF",parameters:"[]")}`
+	assert.Equal(t, []string{pragma, pragma, "query", "in tx"}, inner.queries)
+
+	enabled := true
+	inner = &pragmaEngine{result: `[]`}
+	e = NewSQLiteEngine(inner, SQLiteOptions{ForeignKeys: &enabled})
+	assert.NoError(t, e.Connect())
+	assert.NoError(t, e.Do(context.Background(), protocol.GQLRequest{Query: "query"}, &result))
+	assert.Equal(t, []string{`mutation {result: queryRaw(query:"PRAGMA foreign_keys = ON",parameters:"[]")}`, "query"}, inner.queries)
+}
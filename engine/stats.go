@@ -0,0 +1,262 @@
+package engine
+
+import (
+	"math"
+	"math/bits"
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// Relation load strategies which QueryStats chooses for queries including relations
+const (
+	StrategyJoin  = "join"
+	StrategyQuery = "query"
+)
+
+// StatsOptions configures the query statistics of a client
+type StatsOptions struct {
+	// SampleRate is the fraction of read queries whose result sizes are recorded, 0.1 by default. Sampled results are
+	// decoded twice, so keep it low for busy services.
+	SampleRate float64
+	// Adaptive sets the relation load strategy of queries which include relations and don't set one, based on the
+	// recorded result sizes of their shape. It requires the relationJoins preview feature.
+	Adaptive bool
+	// MinSamples is the number of samples of a shape before a strategy is chosen for it, 20 by default
+	MinSamples int
+	// MaxJoinFanout is the highest mean number of related records per record of any included relation for which
+	// relations are loaded with joins; shapes with a higher fanout load relations with a query per relation. 50 by
+	// default.
+	MaxJoinFanout float64
+}
+
+// Distribution summarizes observed sizes in power-of-two buckets
+type Distribution struct {
+	// Count is the number of observations
+	Count int
+	// Sum is the sum of all observed sizes
+	Sum int
+	// Max is the largest observed size
+	Max int
+	// Buckets counts observations by size: bucket 0 holds size 0, bucket i holds sizes from 2^(i-1) to 2^i-1
+	Buckets [32]int
+}
+
+func (d *Distribution) add(size int) {
+	d.Count++
+	d.Sum += size
+	if size > d.Max {
+		d.Max = size
+	}
+	d.Buckets[min(bits.Len(uint(size)), len(d.Buckets)-1)]++
+}
+
+// Mean returns the mean observed size
+func (d Distribution) Mean() float64 {
+	if d.Count == 0 {
+		return 0
+	}
+	return float64(d.Sum) / float64(d.Count)
+}
+
+// Quantile returns an upper bound of the q-quantile of the observed sizes, e.g. q=0.95 for the 95th percentile,
+// which is the largest size of its bucket but never more than Max
+func (d Distribution) Quantile(q float64) int {
+	if d.Count == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(q * float64(d.Count)))
+	seen := 0
+	for i, n := range d.Buckets {
+		seen += n
+		if seen >= rank && n > 0 {
+			return min((1<<i)-1, d.Max)
+		}
+	}
+	return d.Max
+}
+
+// ShapeStats contains the statistics of a query shape, which is the model, method, filtered fields and included
+// relations of a query, regardless of the filtered values
+type ShapeStats struct {
+	// Shape identifies the shape, e.g. Post.findMany(where:published) with:comments
+	Shape string
+	// Records is the distribution of the number of returned records
+	Records Distribution
+	// Fanout contains the distribution of the number of related records per returned record, by relation path, e.g.
+	// comments or comments.author
+	Fanout map[string]Distribution
+	// Strategy is the relation load strategy chosen for the shape, or empty if none was chosen or Adaptive isn't set
+	Strategy string
+	// Pinned is true if the strategy was pinned with Pin
+	Pinned bool
+}
+
+// QueryStats records result sizes per query shape and chooses relation load strategies from them
+type QueryStats struct {
+	options StatsOptions
+
+	mu     sync.Mutex
+	shapes map[string]*ShapeStats
+	pins   map[string]string
+}
+
+// NewQueryStats creates query statistics, setting defaults for unset options
+func NewQueryStats(options StatsOptions) *QueryStats {
+	if options.SampleRate == 0 {
+		options.SampleRate = 0.1
+	}
+	if options.MinSamples == 0 {
+		options.MinSamples = 20
+	}
+	if options.MaxJoinFanout == 0 {
+		options.MaxJoinFanout = 50
+	}
+	return &QueryStats{
+		options: options,
+		shapes:  map[string]*ShapeStats{},
+		pins:    map[string]string{},
+	}
+}
+
+// Sample decides whether the result of a query is recorded
+func (s *QueryStats) Sample() bool {
+	return s.options.SampleRate >= 1 || rand.Float64() < s.options.SampleRate
+}
+
+// Record records the number of returned records of a query shape and the number of related records per record by
+// relation path
+func (s *QueryStats) Record(shape string, records int, fanout map[string][]int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats, ok := s.shapes[shape]
+	if !ok {
+		stats = &ShapeStats{Shape: shape, Fanout: map[string]Distribution{}}
+		s.shapes[shape] = stats
+	}
+
+	stats.Records.add(records)
+	for path, sizes := range fanout {
+		d := stats.Fanout[path]
+		for _, size := range sizes {
+			d.add(size)
+		}
+		stats.Fanout[path] = d
+	}
+}
+
+// Strategy returns the relation load strategy for a query shape if Adaptive is set, which is the pinned strategy if
+// there is one, or a strategy chosen from its statistics if the shape has enough samples
+func (s *QueryStats) Strategy(shape string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// relation load strategies require the relationJoins preview feature, without which Adaptive is turned off
+	if !s.options.Adaptive {
+		return "", false
+	}
+	if strategy, ok := s.pins[shape]; ok {
+		return strategy, true
+	}
+	stats, ok := s.shapes[shape]
+	if !ok {
+		return "", false
+	}
+	return s.choose(stats)
+}
+
+// choose chooses a strategy from the statistics of a shape: joins load all relations in a single query, but repeat
+// or aggregate the data of large relations, which is cheaper with a query per relation
+func (s *QueryStats) choose(stats *ShapeStats) (string, bool) {
+	if stats.Records.Count < s.options.MinSamples || len(stats.Fanout) == 0 {
+		return "", false
+	}
+	for _, d := range stats.Fanout {
+		if d.Mean() > s.options.MaxJoinFanout {
+			return StrategyQuery, true
+		}
+	}
+	return StrategyJoin, true
+}
+
+// Pin sets the relation load strategy of a query shape regardless of its statistics, e.g. after inspecting them with
+// Shapes. The strategy is either StrategyJoin or StrategyQuery. Like chosen strategies, pinned ones are only applied
+// if Adaptive is set.
+func (s *QueryStats) Pin(shape string, strategy string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pins[shape] = strategy
+}
+
+// Unpin removes the pinned strategy of a query shape
+func (s *QueryStats) Unpin(shape string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.pins, shape)
+}
+
+// Shapes returns the statistics of all recorded query shapes, sorted by shape
+func (s *QueryStats) Shapes() []ShapeStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	shapes := make([]ShapeStats, 0, len(s.shapes))
+	for _, stats := range s.shapes {
+		v := *stats
+		v.Fanout = make(map[string]Distribution, len(stats.Fanout))
+		for path, d := range stats.Fanout {
+			v.Fanout[path] = d
+		}
+		strategy, pinned := s.pins[v.Shape]
+		v.Pinned = pinned
+		switch {
+		case !s.options.Adaptive:
+		case pinned:
+			v.Strategy = strategy
+		default:
+			v.Strategy, _ = s.choose(stats)
+		}
+		shapes = append(shapes, v)
+	}
+	sort.Slice(shapes, func(i, j int) bool {
+		return shapes[i].Shape < shapes[j].Shape
+	})
+	return shapes
+}
+
+// Reset removes all recorded statistics, keeping pinned strategies
+func (s *QueryStats) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.shapes = map[string]*ShapeStats{}
+}
+
+// StatsEngine wraps an engine to record query statistics for the queries built for it
+type StatsEngine struct {
+	Engine
+
+	Stats *QueryStats
+}
+
+// NewStatsEngine wraps an engine to record query statistics with the given options
+func NewStatsEngine(e Engine, options StatsOptions) *StatsEngine {
+	return &StatsEngine{
+		Engine: e,
+		Stats:  NewQueryStats(options),
+	}
+}
+
+// Unwrap returns the wrapped engine
+func (e *StatsEngine) Unwrap() Engine {
+	return e.Engine
+}
+
+// StatsOf returns the query statistics of an engine, if it records them
+func StatsOf(e Engine) (*QueryStats, bool) {
+	s, ok := find[*StatsEngine](e)
+	if !ok {
+		return nil, false
+	}
+	return s.Stats, true
+}
@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistribution(t *testing.T) {
+	var d Distribution
+	for _, size := range []int{0, 1, 2, 3, 10, 100} {
+		d.add(size)
+	}
+
+	assert.Equal(t, 6, d.Count)
+	assert.Equal(t, 116.0/6, d.Mean())
+	assert.Equal(t, 0, d.Quantile(0.1))
+	assert.Equal(t, 3, d.Quantile(0.5))
+	assert.Equal(t, 100, d.Quantile(1))
+}
+
+func TestQueryStatsStrategy(t *testing.T) {
+	s := NewQueryStats(StatsOptions{Adaptive: true, MinSamples: 2, MaxJoinFanout: 5})
+
+	s.Record("small", 2, map[string][]int{"comments": {1, 2}})
+	_, ok := s.Strategy("small")
+	assert.False(t, ok, "a strategy must not be chosen before MinSamples")
+
+	s.Record("small", 1, map[string][]int{"comments": {4}})
+	strategy, ok := s.Strategy("small")
+	assert.True(t, ok)
+	assert.Equal(t, StrategyJoin, strategy)
+
+	s.Record("large", 1, map[string][]int{"comments": {3}, "likes": {200}})
+	s.Record("large", 1, map[string][]int{"comments": {3}, "likes": {100}})
+	strategy, _ = s.Strategy("large")
+	assert.Equal(t, StrategyQuery, strategy)
+
+	s.Pin("large", StrategyJoin)
+	strategy, _ = s.Strategy("large")
+	assert.Equal(t, StrategyJoin, strategy)
+
+	s.Reset()
+	shapes := s.Shapes()
+	assert.Equal(t, 0, len(shapes))
+	strategy, ok = s.Strategy("large")
+	assert.True(t, ok, "pins must be kept on reset")
+	assert.Equal(t, StrategyJoin, strategy)
+
+	s.Unpin("large")
+	_, ok = s.Strategy("large")
+	assert.False(t, ok)
+}
+
+func TestQueryStatsNotAdaptive(t *testing.T) {
+	s := NewQueryStats(StatsOptions{MinSamples: 1})
+	s.Record("shape", 1, map[string][]int{"comments": {1}})
+
+	_, ok := s.Strategy("shape")
+	assert.False(t, ok)
+
+	shapes := s.Shapes()
+	assert.Equal(t, 1, len(shapes))
+	assert.Equal(t, "", shapes[0].Strategy)
+	assert.False(t, shapes[0].Pinned)
+
+	// pins don't apply either, e.g. as the relationJoins preview feature is not enabled
+	s.Pin("shape", StrategyJoin)
+	_, ok = s.Strategy("shape")
+	assert.False(t, ok)
+
+	shapes = s.Shapes()
+	assert.Equal(t, "", shapes[0].Strategy)
+	assert.True(t, shapes[0].Pinned)
+}
+
+func TestStatsOf(t *testing.T) {
+	e := NewStatsEngine(&deadlineEngine{}, StatsOptions{})
+	stats, ok := StatsOf(e)
+	assert.True(t, ok)
+	assert.Equal(t, e.Stats, stats)
+
+	_, ok = StatsOf(&deadlineEngine{})
+	assert.False(t, ok)
+}
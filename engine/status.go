@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// StatusReporter is implemented by engines which can report their status and metrics
+type StatusReporter interface {
+	// Status returns the status as reported by the engine
+	Status(ctx context.Context) (json.RawMessage, error)
+	// Metrics returns the metrics of the engine including connection pool stats, if metrics are enabled
+	Metrics(ctx context.Context) (json.RawMessage, error)
+}
+
+// AsStatusReporter returns the StatusReporter of an engine, looking through engines which wrap another engine
+func AsStatusReporter(e Engine) (StatusReporter, bool) {
+	return find[StatusReporter](e)
+}
+
+func (e *QueryEngine) Status(ctx context.Context) (json.RawMessage, error) {
+	body, err := e.Request(ctx, "GET", "/status", map[string]interface{}{}, true)
+	if err != nil {
+		return nil, fmt.Errorf("engine status: %w", err)
+	}
+	return body, nil
+}
+
+// EnableMetrics makes the engine collect metrics such as connection pool stats. It must be called before Connect.
+func (e *QueryEngine) EnableMetrics() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.metrics = true
+}
+
+func (e *QueryEngine) Metrics(ctx context.Context) (json.RawMessage, error) {
+	e.mu.RLock()
+	enabled := e.metrics
+	e.mu.RUnlock()
+	if !enabled {
+		return nil, fmt.Errorf("engine metrics are not enabled")
+	}
+
+	body, err := e.Request(ctx, "GET", "/metrics?format=json", map[string]interface{}{}, true)
+	if err != nil {
+		return nil, fmt.Errorf("engine metrics: %w", err)
+	}
+	return body, nil
+}
+
+// EnableMetrics enables metrics of an engine, looking through engines which wrap another engine. It returns false if
+// the engine doesn't support metrics.
+func EnableMetrics(e Engine) bool {
+	m, ok := find[interface{ EnableMetrics() }](e)
+	if ok {
+		m.EnableMetrics()
+	}
+	return ok
+}
@@ -44,6 +44,7 @@ func (e *QueryEngine) streamStderr(cmd *exec.Cmd, onError chan<- string) error {
 
 		for scanner.Scan() {
 			contents := scanner.Bytes()
+			e.stderrTail.add(string(contents))
 			var message Messsage
 			if err := json.Unmarshal(contents, &message); err != nil {
 				log.Printf("failed to unmarshal message: %s", err.Error())
@@ -0,0 +1,27 @@
+package engine
+
+// StrictEngine wraps an engine to decode query results strictly: results which contain fields the Go types don't know,
+// or which miss fields the query requested, fail with a DecodeError instead of leaving values zeroed
+type StrictEngine struct {
+	Engine
+}
+
+// NewStrictEngine wraps an engine to decode the results of the queries built for it strictly
+func NewStrictEngine(e Engine) *StrictEngine {
+	return &StrictEngine{
+		Engine: e,
+	}
+}
+
+func (e *StrictEngine) strict() {}
+
+// Unwrap returns the wrapped engine
+func (e *StrictEngine) Unwrap() Engine {
+	return e.Engine
+}
+
+// IsStrict returns whether results of an engine are decoded strictly
+func IsStrict(e Engine) bool {
+	_, ok := find[interface{ strict() }](e)
+	return ok
+}
@@ -0,0 +1,220 @@
+package engine
+
+import (
+	"os/exec"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/steebchen/prisma-client-go/logger"
+)
+
+// EngineEventType describes what happened to the query engine process
+type EngineEventType string
+
+const (
+	// EngineCrashed is emitted when the query engine exited unexpectedly
+	EngineCrashed EngineEventType = "crashed"
+	// EngineRestarted is emitted when the query engine was restarted successfully after a crash
+	EngineRestarted EngineEventType = "restarted"
+	// EngineRestartFailed is emitted when a restart attempt failed
+	EngineRestartFailed EngineEventType = "restartFailed"
+	// EngineReloaded is emitted when the query engine was restarted with a changed schema
+	EngineReloaded EngineEventType = "reloaded"
+	// EngineReloadFailed is emitted when restarting the query engine with a changed schema failed
+	EngineReloadFailed EngineEventType = "reloadFailed"
+)
+
+// EngineEvent describes a crash or restart of the query engine process
+type EngineEvent struct {
+	// Type is the kind of event
+	Type EngineEventType
+	// Err is the exit error of a crash or the error of a failed restart attempt
+	Err error
+	// Attempt is the restart attempt, starting at 1; it is 0 for crash events
+	Attempt int
+	// Report describes the crash, including the stderr tail and the last queries; it is only set for crash events
+	Report *CrashReport
+}
+
+// EngineEventEmitter is implemented by engines which report crashes and restarts of their process
+type EngineEventEmitter interface {
+	// OnEngineEvent registers fn to be called when the engine process crashes or is restarted
+	OnEngineEvent(fn func(EngineEvent))
+}
+
+// AsEngineEventEmitter returns the EngineEventEmitter of an engine, looking through engines which wrap another engine
+func AsEngineEventEmitter(e Engine) (EngineEventEmitter, bool) {
+	return find[EngineEventEmitter](e)
+}
+
+// RestartPolicy controls how the query engine is restarted after it crashed.
+// The zero value restarts the engine indefinitely with the default backoff.
+type RestartPolicy struct {
+	// Disabled turns off automatic restarts
+	Disabled bool
+	// InitialBackoff is the delay before the first restart attempt, defaulting to 100ms
+	InitialBackoff time.Duration
+	// MaxBackoff caps the exponentially growing delay between attempts, defaulting to 30s
+	MaxBackoff time.Duration
+	// MaxAttempts limits the restart attempts per crash; 0 means no limit
+	MaxAttempts int
+}
+
+const (
+	defaultInitialBackoff = 100 * time.Millisecond
+	defaultMaxBackoff     = 30 * time.Second
+)
+
+// backoff returns the delay before the given restart attempt, starting at 1
+func (p RestartPolicy) backoff(attempt int) time.Duration {
+	initial := p.InitialBackoff
+	if initial <= 0 {
+		initial = defaultInitialBackoff
+	}
+	max := p.MaxBackoff
+	if max <= 0 {
+		max = defaultMaxBackoff
+	}
+
+	d := initial
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d >= max {
+			return max
+		}
+	}
+	if d > max {
+		return max
+	}
+	return d
+}
+
+// SetRestartPolicy sets how the query engine is restarted after it crashed.
+func (e *QueryEngine) SetRestartPolicy(policy RestartPolicy) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.restartPolicy = policy
+}
+
+// SetRestartPolicy sets the restart policy of an engine, looking through engines which wrap another engine.
+// It returns false if the engine doesn't support restarts.
+func SetRestartPolicy(e Engine, policy RestartPolicy) bool {
+	r, ok := find[interface{ SetRestartPolicy(RestartPolicy) }](e)
+	if ok {
+		r.SetRestartPolicy(policy)
+	}
+	return ok
+}
+
+// OnEngineEvent registers fn to be called when the query engine process crashes or is restarted.
+func (e *QueryEngine) OnEngineEvent(fn func(EngineEvent)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onEngineEvent = append(e.onEngineEvent, fn)
+}
+
+func (e *QueryEngine) emitEngineEvent(event EngineEvent) {
+	e.mu.RLock()
+	listeners := e.onEngineEvent
+	e.mu.RUnlock()
+
+	for _, fn := range listeners {
+		fn(event)
+	}
+}
+
+// process waits for a started command to exit, so that both the supervisor and Disconnect can observe it
+type process struct {
+	cmd  *exec.Cmd
+	done chan struct{}
+	err  error
+
+	// url is the address of the HTTP server of the process
+	url string
+	// active counts the requests and open interactive transactions of the process
+	active atomic.Int64
+
+	report     *CrashReport
+	reportOnce sync.Once
+}
+
+// kill stops a process which never became ready and waits for its exit
+func (p *process) kill() {
+	_ = p.cmd.Process.Kill()
+	<-p.done
+}
+
+func newProcess(cmd *exec.Cmd) *process {
+	p := &process{
+		cmd:  cmd,
+		done: make(chan struct{}),
+	}
+	go func() {
+		p.err = cmd.Wait()
+		close(p.done)
+	}()
+	return p
+}
+
+func (e *QueryEngine) isDisconnected() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.disconnected
+}
+
+// isReplaced reports whether p was stopped on purpose because a new process took over, e.g. after a schema reload
+func (e *QueryEngine) isReplaced(p *process) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.process != p
+}
+
+// supervise waits for the engine process to exit and restarts it if it crashed
+func (e *QueryEngine) supervise(file string, p *process) {
+	<-p.done
+
+	if e.isDisconnected() || e.isReplaced(p) {
+		return
+	}
+
+	logger.Debug.Printf("query engine crashed: %v", p.err)
+	e.emitEngineEvent(EngineEvent{Type: EngineCrashed, Err: p.err, Report: e.crashReport(p)})
+
+	e.mu.RLock()
+	policy := e.restartPolicy
+	e.mu.RUnlock()
+
+	if policy.Disabled {
+		return
+	}
+
+	for attempt := 1; policy.MaxAttempts == 0 || attempt <= policy.MaxAttempts; attempt++ {
+		select {
+		case <-time.After(policy.backoff(attempt)):
+		case <-e.closed:
+			return
+		}
+
+		if e.isDisconnected() {
+			return
+		}
+
+		e.mu.Lock()
+		e.lastEngineError = ""
+		e.mu.Unlock()
+
+		logger.Debug.Printf("restarting query engine (attempt %d)...", attempt)
+
+		// spawn starts a new supervisor once the engine is ready
+		err := e.spawn(file)
+		if err == nil {
+			logger.Debug.Printf("query engine restarted")
+			e.emitEngineEvent(EngineEvent{Type: EngineRestarted, Attempt: attempt})
+			return
+		}
+
+		logger.Debug.Printf("restarting query engine failed: %s", err)
+		e.emitEngineEvent(EngineEvent{Type: EngineRestartFailed, Err: err, Attempt: attempt})
+	}
+}
@@ -0,0 +1,35 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestartPolicyBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   RestartPolicy
+		attempts []time.Duration
+	}{{
+		name:     "default",
+		policy:   RestartPolicy{},
+		attempts: []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond},
+	}, {
+		name:     "capped",
+		policy:   RestartPolicy{InitialBackoff: time.Second, MaxBackoff: 3 * time.Second},
+		attempts: []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second},
+	}, {
+		name:     "initial above max",
+		policy:   RestartPolicy{InitialBackoff: time.Minute},
+		attempts: []time.Duration{30 * time.Second, 30 * time.Second},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, expected := range tt.attempts {
+				assert.Equal(t, expected, tt.policy.backoff(i+1), "attempt %d", i+1)
+			}
+		})
+	}
+}
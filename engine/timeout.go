@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/steebchen/prisma-client-go/engine/protocol"
+)
+
+// txGrace is added to the transaction timeout, so that the statement timeout of the database fires first and the
+// query fails with a database error instead of an expired transaction
+const txGrace = time.Second
+
+// StatementTimeout wraps an engine so that the context deadline of a query is enforced by the database. Queries with
+// a context deadline run in an interactive transaction which sets the statement timeout to the remaining time, so
+// the database cancels a statement once the caller gave up on it, instead of only aborting the HTTP round trip.
+// Queries without a deadline, queries which are already part of an interactive transaction and batches are passed
+// through unchanged.
+type StatementTimeout struct {
+	Engine
+
+	// Provider is the datasource provider; only postgresql and cockroachdb are supported
+	Provider string
+}
+
+// NewStatementTimeout wraps an engine to cancel statements in the database once their context deadline passed
+func NewStatementTimeout(e Engine, provider string) *StatementTimeout {
+	return &StatementTimeout{
+		Engine:   e,
+		Provider: provider,
+	}
+}
+
+// SupportsStatementTimeout returns whether statement timeouts can be set for the given provider
+func SupportsStatementTimeout(provider string) bool {
+	switch provider {
+	case "postgresql", "postgres", "cockroachdb":
+		return true
+	}
+	return false
+}
+
+func (e *StatementTimeout) Do(ctx context.Context, payload interface{}, into interface{}) error {
+	deadline, ok := ctx.Deadline()
+	if !ok || txID(ctx) != "" || !SupportsStatementTimeout(e.Provider) {
+		return e.Engine.Do(ctx, payload, into)
+	}
+
+	transactor, ok := AsTransactor(e.Engine)
+	if !ok {
+		return e.Engine.Do(ctx, payload, into)
+	}
+
+	remaining := time.Until(deadline)
+	if remaining <= 0 {
+		return ctx.Err()
+	}
+
+	id, err := transactor.StartTx(ctx, TxOptions{
+		MaxWait: remaining,
+		Timeout: remaining + txGrace,
+	})
+	if err != nil {
+		return err
+	}
+
+	txCtx := withTxID(ctx, id)
+
+	var count int
+	if err := e.Engine.Do(txCtx, statementTimeoutQuery(remaining), &count); err != nil {
+		e.rollback(transactor, id)
+		return fmt.Errorf("set statement timeout: %w", err)
+	}
+
+	if err := e.Engine.Do(txCtx, payload, into); err != nil {
+		e.rollback(transactor, id)
+		return err
+	}
+
+	return transactor.CommitTx(ctx, id)
+}
+
+// Unwrap returns the wrapped engine
+func (e *StatementTimeout) Unwrap() Engine {
+	return e.Engine
+}
+
+// rollback rolls back a transaction even if the context of the query is already done
+func (e *StatementTimeout) rollback(transactor Transactor, id string) {
+	ctx, cancel := context.WithTimeout(context.Background(), txGrace)
+	defer cancel()
+	_ = transactor.RollbackTx(ctx, id)
+}
+
+// statementTimeoutQuery returns a request setting the statement timeout of the current transaction, rounded up to
+// full milliseconds
+func statementTimeoutQuery(timeout time.Duration) protocol.GQLRequest {
+	ms := int64(math.Ceil(float64(timeout) / float64(time.Millisecond)))
+	return protocol.GQLRequest{
+		Query:     fmt.Sprintf(`mutation {result: executeRaw(query:"SET LOCAL statement_timeout = %d",parameters:"[]")}`, ms),
+		Variables: map[string]interface{}{},
+	}
+}
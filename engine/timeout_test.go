@@ -0,0 +1,65 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatementTimeout(t *testing.T) {
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r.URL.Path+" "+r.Header.Get("X-transaction-id"))
+
+		switch {
+		case r.URL.Path == "/transaction/start":
+			_, _ = w.Write([]byte(`{"id":"tx1"}`))
+		case strings.Contains(string(body), "SET LOCAL statement_timeout"):
+			_, _ = w.Write([]byte(`{"data":{"result":0}}`))
+		default:
+			_, _ = w.Write([]byte(`{"data":{"result":{}}}`))
+		}
+	}))
+	defer srv.Close()
+
+	qe := NewQueryEngine("", false, "", "")
+	qe.http = srv.Client()
+	qe.httpURL = srv.URL
+	qe.connected = true
+
+	e := NewStatementTimeout(qe, "postgresql")
+
+	var v json.RawMessage
+	if err := e.Do(context.Background(), map[string]string{}, &v); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"/ "}, requests)
+
+	requests = nil
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := e.Do(ctx, map[string]string{}, &v); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{
+		"/transaction/start ",
+		"/ tx1",
+		"/ tx1",
+		"/transaction/tx1/commit ",
+	}, requests)
+}
+
+func TestStatementTimeoutQuery(t *testing.T) {
+	assert.Equal(t,
+		`mutation {result: executeRaw(query:"SET LOCAL statement_timeout = 1501",parameters:"[]")}`,
+		statementTimeoutQuery(1500*time.Millisecond+time.Microsecond).Query,
+	)
+}
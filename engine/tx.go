@@ -0,0 +1,271 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Transactor is implemented by engines which support interactive transactions
+type Transactor interface {
+	// StartTx starts an interactive transaction and returns its id
+	StartTx(ctx context.Context, options TxOptions) (string, error)
+	CommitTx(ctx context.Context, id string) error
+	RollbackTx(ctx context.Context, id string) error
+}
+
+// DefaultTxTimeout is the maximum time an interactive transaction may run before it is rolled back by the engine, if
+// TxOptions.Timeout is not set
+const DefaultTxTimeout = 5 * time.Second
+
+// TxOptions configures an interactive transaction
+type TxOptions struct {
+	// MaxWait is the maximum time to wait for the transaction to start
+	MaxWait time.Duration
+	// Timeout is the maximum time the transaction may run before it is rolled back by the engine
+	Timeout time.Duration
+}
+
+// timeout returns the time after which the engine rolls back the transaction
+func (o TxOptions) timeout() time.Duration {
+	if o.Timeout > 0 {
+		return o.Timeout
+	}
+	return DefaultTxTimeout
+}
+
+// AsTransactor returns the Transactor of an engine, looking through engines which wrap another engine
+func AsTransactor(e Engine) (Transactor, bool) {
+	t, ok := find[Transactor](e)
+	if !ok {
+		return nil, false
+	}
+	if m, ok := metricsOf(e); ok {
+		return measuredTransactor{Transactor: t, m: m}, true
+	}
+	return t, true
+}
+
+// InTx returns whether the requests of an engine are sent within an interactive transaction
+func InTx(e Engine) bool {
+	_, ok := find[*TxEngine](e)
+	return ok
+}
+
+// TxIDOf returns the id of the interactive transaction within which an engine sends its requests
+func TxIDOf(e Engine) (string, bool) {
+	tx, ok := find[*TxEngine](e)
+	if !ok {
+		return "", false
+	}
+	return tx.ID, true
+}
+
+// RunInTx calls fn with an engine which sends all requests within an interactive transaction, which is committed if
+// fn returns nil and rolled back otherwise. If e already sends its requests within an interactive transaction, fn runs
+// within a savepoint of it instead, which is rolled back if fn returns an error, so that code which requires a
+// transaction composes with callers which already opened one. The outer transaction continues in either case. On
+// MongoDB, which has no savepoints, fn runs directly within the outer transaction.
+func RunInTx(ctx context.Context, e Engine, options TxOptions, fn func(ctx context.Context, tx Engine) error) error {
+	if tx, ok := find[*TxEngine](e); ok {
+		return runInSavepoint(ctx, e, tx, fn)
+	}
+
+	transactor, ok := AsTransactor(e)
+	if !ok {
+		return fmt.Errorf("engine %s does not support interactive transactions", e.Name())
+	}
+
+	id, err := transactor.StartTx(ctx, options)
+	if err != nil {
+		return err
+	}
+
+	tx := NewTxEngine(e, id)
+	if err := fn(ctx, tx); err != nil {
+		if rollbackErr := tx.Rollback(context.Background(), transactor); rollbackErr != nil {
+			return fmt.Errorf("%w (rollback failed: %s)", err, rollbackErr)
+		}
+		return err
+	}
+
+	return tx.Commit(ctx, transactor)
+}
+
+type txKey struct{}
+
+// withTxID marks all requests sent with the returned context to be part of the interactive transaction
+func withTxID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, txKey{}, id)
+}
+
+func txID(ctx context.Context) string {
+	id, _ := ctx.Value(txKey{}).(string)
+	return id
+}
+
+// TxEngine wraps an engine so that all requests are sent within an interactive transaction
+type TxEngine struct {
+	Engine
+
+	// ID is the id of the interactive transaction as returned by StartTx
+	ID string
+
+	// savepoints counts the savepoints created within the transaction to give each a unique name
+	savepoints atomic.Int64
+
+	mu sync.Mutex
+	// committed holds the invalidations of the writes sent within the transaction, which are applied once it's
+	// committed, so that reads outside of it don't cache data which isn't committed yet
+	committed []func(ctx context.Context)
+}
+
+// NewTxEngine wraps an engine to send all requests within the interactive transaction with the given id
+func NewTxEngine(e Engine, id string) *TxEngine {
+	return &TxEngine{
+		Engine: e,
+		ID:     id,
+	}
+}
+
+func (e *TxEngine) Do(ctx context.Context, payload interface{}, into interface{}) error {
+	return e.Engine.Do(withTxID(ctx, e.ID), payload, into)
+}
+
+func (e *TxEngine) Batch(ctx context.Context, payload interface{}, into interface{}) error {
+	return e.Engine.Batch(withTxID(ctx, e.ID), payload, into)
+}
+
+// Unwrap returns the wrapped engine
+func (e *TxEngine) Unwrap() Engine {
+	return e.Engine
+}
+
+// Commit commits the transaction with the given transactor, and then drops the cached results of the models written
+// within it and publishes their invalidation events
+func (e *TxEngine) Commit(ctx context.Context, transactor Transactor) error {
+	if err := transactor.CommitTx(ctx, e.ID); err != nil {
+		e.discard()
+		return err
+	}
+
+	e.mu.Lock()
+	committed := e.committed
+	e.committed = nil
+	e.mu.Unlock()
+
+	for _, fn := range committed {
+		fn(ctx)
+	}
+	return nil
+}
+
+// Rollback rolls back the transaction with the given transactor and discards the invalidations of its writes
+func (e *TxEngine) Rollback(ctx context.Context, transactor Transactor) error {
+	e.discard()
+	return transactor.RollbackTx(ctx, e.ID)
+}
+
+// onCommit registers fn to be called once the transaction is committed with Commit
+func (e *TxEngine) onCommit(fn func(ctx context.Context)) {
+	e.mu.Lock()
+	e.committed = append(e.committed, fn)
+	e.mu.Unlock()
+}
+
+func (e *TxEngine) discard() {
+	e.mu.Lock()
+	e.committed = nil
+	e.mu.Unlock()
+}
+
+type txStartResponse struct {
+	ID string `json:"id"`
+}
+
+func (e *QueryEngine) StartTx(ctx context.Context, options TxOptions) (string, error) {
+	payload := map[string]interface{}{}
+	if options.MaxWait > 0 {
+		payload["max_wait"] = options.MaxWait.Milliseconds()
+	}
+	if options.Timeout > 0 {
+		payload["timeout"] = options.Timeout.Milliseconds()
+	}
+
+	body, p, err := e.requestTx(ctx, "", "POST", "/transaction/start", payload, true)
+	if err != nil {
+		return "", fmt.Errorf("start transaction: %w", err)
+	}
+
+	var response txStartResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("start transaction: json unmarshal: %w", err)
+	}
+
+	if p != nil {
+		id := response.ID
+		e.mu.Lock()
+		if e.txs == nil {
+			e.txs = map[string]*openTx{}
+		}
+		// an open transaction keeps its process from being stopped by a redeploy until it ends, or until the engine
+		// rolled it back after its timeout if it's abandoned
+		e.txs[id] = &openTx{
+			process: p,
+			expiry: time.AfterFunc(options.timeout(), func() {
+				e.endTx(id)
+			}),
+		}
+		p.active.Add(1)
+		e.mu.Unlock()
+	}
+
+	return response.ID, nil
+}
+
+func (e *QueryEngine) CommitTx(ctx context.Context, id string) error {
+	defer e.endTx(id)
+	if _, _, err := e.requestTx(ctx, id, "POST", "/transaction/"+id+"/commit", map[string]interface{}{}, true); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (e *QueryEngine) RollbackTx(ctx context.Context, id string) error {
+	defer e.endTx(id)
+	if _, _, err := e.requestTx(ctx, id, "POST", "/transaction/"+id+"/rollback", map[string]interface{}{}, true); err != nil {
+		return fmt.Errorf("rollback transaction: %w", err)
+	}
+	return nil
+}
+
+// openTx is an interactive transaction which was started by the query engine
+type openTx struct {
+	// process is the engine process which serves the transaction
+	process *process
+	// expiry ends the transaction once the engine rolled it back after its timeout
+	expiry *time.Timer
+}
+
+// endTx releases the process of a committed, rolled back or expired transaction
+func (e *QueryEngine) endTx(id string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if tx, ok := e.txs[id]; ok {
+		tx.expiry.Stop()
+		tx.process.active.Add(-1)
+		delete(e.txs, id)
+	}
+}
+
+// txClosedCode is the code of the errors of the engine for transactions which already ended
+const txClosedCode = `"error_code":"P2028"`
+
+// txClosed returns whether a response of the engine reports that the transaction already ended
+func txClosed(body []byte) bool {
+	return bytes.Contains(body, []byte(txClosedCode))
+}
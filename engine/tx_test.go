@@ -0,0 +1,179 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/steebchen/prisma-client-go/engine/protocol"
+)
+
+func TestInteractiveTx(t *testing.T) {
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r.URL.Path+" "+r.Header.Get("X-transaction-id")+" "+string(body))
+
+		if r.URL.Path == "/transaction/start" {
+			_, _ = w.Write([]byte(`{"id":"tx1"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"result":{}}}`))
+	}))
+	defer srv.Close()
+
+	qe := NewQueryEngine("", false, "", "")
+	qe.http = srv.Client()
+	qe.httpURL = srv.URL
+	qe.connected = true
+
+	ctx := context.Background()
+
+	transactor, ok := AsTransactor(NewDeadlineBudget(qe, 0.5))
+	if !ok {
+		t.Fatalf("expected the query engine to be a transactor")
+	}
+
+	id, err := transactor.StartTx(ctx, TxOptions{Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "tx1", id)
+
+	var v json.RawMessage
+	if err := NewTxEngine(qe, id).Do(ctx, map[string]string{}, &v); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := transactor.RollbackTx(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []string{
+		`/transaction/start  {"timeout":5000}`,
+		`/ tx1 {}`,
+		`/transaction/tx1/rollback  {}`,
+	}, requests)
+}
+
+func TestAsTransactorDataProxy(t *testing.T) {
+	_, ok := AsTransactor(NewDeadlineBudget(&DataProxyEngine{}, 0.5))
+	assert.False(t, ok)
+}
+
+type recordingTransactor struct {
+	Engine
+	calls    []string
+	provider string
+	// fail makes requests fail whose query contains it
+	fail string
+}
+
+func (e *recordingTransactor) Name() string {
+	return "recording"
+}
+
+func (e *recordingTransactor) Do(ctx context.Context, payload interface{}, into interface{}) error {
+	e.calls = append(e.calls, txID(ctx)+" "+payload.(protocol.GQLRequest).Query)
+	if e.fail != "" && strings.Contains(payload.(protocol.GQLRequest).Query, e.fail) {
+		return errors.New("failed")
+	}
+	return nil
+}
+
+func (e *recordingTransactor) Provider() string {
+	return e.provider
+}
+
+func (e *recordingTransactor) StartTx(ctx context.Context, options TxOptions) (string, error) {
+	e.calls = append(e.calls, "start")
+	return "tx1", nil
+}
+
+func (e *recordingTransactor) CommitTx(ctx context.Context, id string) error {
+	e.calls = append(e.calls, "commit "+id)
+	return nil
+}
+
+func (e *recordingTransactor) RollbackTx(ctx context.Context, id string) error {
+	e.calls = append(e.calls, "rollback "+id)
+	return nil
+}
+
+func TestRunInTx(t *testing.T) {
+	ctx := context.Background()
+
+	e := &recordingTransactor{}
+	err := RunInTx(ctx, e, TxOptions{}, func(ctx context.Context, tx Engine) error {
+		assert.True(t, InTx(tx))
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"start", "commit tx1"}, e.calls)
+
+	e = &recordingTransactor{}
+	failed := errors.New("failed")
+	err = RunInTx(ctx, e, TxOptions{}, func(ctx context.Context, tx Engine) error {
+		return failed
+	})
+	assert.Equal(t, failed, err)
+	assert.Equal(t, []string{"start", "rollback tx1"}, e.calls)
+
+	// an existing transaction continues in a savepoint
+	e = &recordingTransactor{}
+	outer := NewTxEngine(e, "outer")
+	err = RunInTx(ctx, outer, TxOptions{}, func(ctx context.Context, tx Engine) error {
+		return RunInTx(ctx, tx, TxOptions{}, func(ctx context.Context, tx Engine) error {
+			return nil
+		})
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`outer mutation {result: executeRaw(query:"SAVEPOINT prisma_savepoint_1",parameters:"[]")}`,
+		`outer mutation {result: executeRaw(query:"SAVEPOINT prisma_savepoint_2",parameters:"[]")}`,
+		`outer mutation {result: executeRaw(query:"RELEASE SAVEPOINT prisma_savepoint_2",parameters:"[]")}`,
+		`outer mutation {result: executeRaw(query:"RELEASE SAVEPOINT prisma_savepoint_1",parameters:"[]")}`,
+	}, e.calls)
+
+	// a failing nested transaction rolls back to its savepoint only
+	e = &recordingTransactor{provider: "sqlserver"}
+	err = RunInTx(ctx, NewTxEngine(e, "outer"), TxOptions{}, func(ctx context.Context, tx Engine) error {
+		return failed
+	})
+	assert.Equal(t, failed, err)
+	assert.Equal(t, []string{
+		`outer mutation {result: executeRaw(query:"SAVE TRANSACTION prisma_savepoint_1",parameters:"[]")}`,
+		`outer mutation {result: executeRaw(query:"ROLLBACK TRANSACTION prisma_savepoint_1",parameters:"[]")}`,
+	}, e.calls)
+
+	e = &recordingTransactor{fail: "ROLLBACK TO"}
+	err = RunInTx(ctx, NewTxEngine(e, "outer"), TxOptions{}, func(ctx context.Context, tx Engine) error {
+		return failed
+	})
+	assert.ErrorIs(t, err, failed)
+	assert.EqualError(t, err, "failed (rollback to savepoint failed: failed)")
+
+	// MongoDB has no savepoints, so a nested transaction reuses the outer one
+	e = &recordingTransactor{provider: "mongodb"}
+	mongo := NewTxEngine(e, "outer")
+	err = RunInTx(ctx, mongo, TxOptions{}, func(ctx context.Context, tx Engine) error {
+		assert.Same(t, mongo, tx)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, e.calls)
+
+	err = RunInTx(ctx, mongo, TxOptions{}, func(ctx context.Context, tx Engine) error {
+		return failed
+	})
+	assert.Equal(t, failed, err)
+	assert.Empty(t, e.calls)
+}
@@ -1,6 +1,9 @@
 package dmmf
 
 import (
+	"reflect"
+	"strings"
+
 	"github.com/steebchen/prisma-client-go/generator/types"
 )
 
@@ -12,6 +15,9 @@ const (
 	FieldKindScalar FieldKind = "scalar"
 	FieldKindObject FieldKind = "object"
 	FieldKindEnum   FieldKind = "enum"
+	// FieldKindComposite is not part of the DMMF, which describes composite type fields as objects. Such fields are
+	// marked with MarkCompositeFields instead, so that they are not treated as relations.
+	FieldKindComposite FieldKind = "composite"
 )
 
 // IncludeInStruct shows whether to include a field in a model struct.
@@ -24,6 +30,11 @@ func (v FieldKind) IsRelation() bool {
 	return v == FieldKindObject
 }
 
+// IsComposite returns whether field is an embedded document of a composite type
+func (v FieldKind) IsComposite() bool {
+	return v == FieldKindComposite
+}
+
 // DatamodelFieldKind describes a scalar, object or enum.
 type DatamodelFieldKind string
 
@@ -210,6 +221,8 @@ type Enum struct {
 	Values []EnumValue  `json:"values"`
 	// DBName (optional)
 	DBName types.String `json:"dBName"`
+	// Documentation (optional) contains the triple-slash comments of the enum
+	Documentation string `json:"documentation"`
 }
 
 // EnumValue contains detailed information about an enum type.
@@ -223,6 +236,38 @@ type EnumValue struct {
 type Datamodel struct {
 	Models []Model `json:"models"`
 	Enums  []Enum  `json:"enums"`
+	// Types contains the composite types, which are only supported by MongoDB
+	Types []Model `json:"types"`
+}
+
+// MarkCompositeFields sets the kind of model and composite type fields which embed a composite type to
+// FieldKindComposite, as the DMMF describes them like relations.
+func (d *Datamodel) MarkCompositeFields() {
+	composite := map[string]bool{}
+	for _, t := range d.Types {
+		composite[t.Name.String()] = true
+	}
+
+	for _, models := range [][]Model{d.Models, d.Types} {
+		for i := range models {
+			for j, field := range models[i].Fields {
+				if field.Kind == FieldKindObject && composite[field.Type.String()] {
+					models[i].Fields[j].Kind = FieldKindComposite
+				}
+			}
+		}
+	}
+}
+
+// MarkViews marks the models with the given names as views, which the DMMF describes like models
+func (d *Datamodel) MarkViews(names []string) {
+	for _, name := range names {
+		for i := range d.Models {
+			if d.Models[i].Name.String() == name {
+				d.Models[i].IsView = true
+			}
+		}
+	}
 }
 
 type UniqueIndex struct {
@@ -236,10 +281,16 @@ type Model struct {
 	Name       types.String `json:"name"`
 	IsEmbedded bool         `json:"isEmbedded"`
 	// DBName (optional)
-	DBName        types.String  `json:"dbName"`
+	DBName types.String `json:"dbName"`
+	// Schema (optional) is the database schema of the model set with @@schema when using the multiSchema preview feature
+	Schema        types.String  `json:"schema"`
 	Fields        []Field       `json:"fields"`
 	UniqueIndexes []UniqueIndex `json:"uniqueIndexes"`
 	PrimaryKey    PrimaryKey    `json:"primaryKey"`
+	// Documentation (optional) contains the triple-slash comments of the model
+	Documentation string `json:"documentation"`
+	// IsView is set for views of the views preview feature, which are read-only
+	IsView bool `json:"-"`
 }
 
 type PrimaryKey struct {
@@ -256,10 +307,94 @@ func (k PrimaryKey) IsFieldInPrimary(field types.String) bool {
 	return false
 }
 
+// TableName returns the database name of the model
+func (m Model) TableName() string {
+	if m.DBName != "" {
+		return m.DBName.String()
+	}
+	return m.Name.String()
+}
+
+// PrimaryKeyFields returns the names of the fields which make up the primary key
+func (m Model) PrimaryKeyFields() []types.String {
+	if len(m.PrimaryKey.Fields) > 0 {
+		return m.PrimaryKey.Fields
+	}
+	var fields []types.String
+	for _, f := range m.Fields {
+		if f.IsID {
+			fields = append(fields, f.Name)
+		}
+	}
+	return fields
+}
+
+// PrimaryKeyName returns the name of the unique input of the primary key, which is the field name of single-field
+// keys and the name of the compound key otherwise
+func (m Model) PrimaryKeyName() string {
+	if m.PrimaryKey.Name != "" {
+		return m.PrimaryKey.Name.String()
+	}
+	var names []string
+	for _, f := range m.PrimaryKeyFields() {
+		names = append(names, f.String())
+	}
+	return strings.Join(names, "_")
+}
+
+// UniqueConstraint is a unique constraint by which records of a model can be looked up
+type UniqueConstraint struct {
+	// Name is the name of the unique input, which is the field name of single fields and the name of the compound key
+	// otherwise
+	Name   string
+	Fields []types.String
+}
+
+// UniqueConstraints returns the primary key followed by the unique fields and compound unique keys of the model
+func (m Model) UniqueConstraints() []UniqueConstraint {
+	constraints := []UniqueConstraint{{
+		Name:   m.PrimaryKeyName(),
+		Fields: m.PrimaryKeyFields(),
+	}}
+	for _, f := range m.Fields {
+		if f.IsUnique {
+			constraints = append(constraints, UniqueConstraint{
+				Name:   f.Name.String(),
+				Fields: []types.String{f.Name},
+			})
+		}
+	}
+	for _, index := range m.UniqueIndexes {
+		name := index.InternalName
+		if name == "" {
+			var names []string
+			for _, f := range index.Fields {
+				names = append(names, f.String())
+			}
+			name = strings.Join(names, "_")
+		}
+		constraints = append(constraints, UniqueConstraint{
+			Name:   name,
+			Fields: index.Fields,
+		})
+	}
+	return constraints
+}
+
 func (m Model) Actions() []string {
 	return []string{"Set", "Equals"}
 }
 
+// HasReadonlyAfterCreateFields returns whether any field is marked with `/// @readonly-after-create`
+func (m Model) HasReadonlyAfterCreateFields() bool {
+	for _, field := range m.Fields {
+		if field.IsReadonlyAfterCreate() {
+			return true
+		}
+	}
+	return false
+}
+
 // RelationFieldsPlusOne returns all fields plus an empty one, so it's easier to iterate through it in some gotpl files
 func (m Model) RelationFieldsPlusOne() []Field {
 	var fields []Field
@@ -286,6 +421,8 @@ type Field struct {
 	DBName      types.String `json:"dBName"`
 	IsGenerated bool         `json:"isGenerated"`
 	IsUpdatedAt bool         `json:"isUpdatedAt"`
+	// RelationFromFields (optional)
+	RelationFromFields []types.String `json:"relationFromFields"`
 	// RelationToFields (optional)
 	RelationToFields []interface{} `json:"relationToFields"`
 	// RelationOnDelete (optional)
@@ -294,10 +431,117 @@ type Field struct {
 	RelationName types.String `json:"relationName"`
 	// HasDefaultValue
 	HasDefaultValue bool `json:"hasDefaultValue"`
+	// Default (optional) is the default value, or a function such as now() with its name and arguments
+	Default interface{} `json:"default"`
+	// Documentation (optional) contains the triple-slash comments of the field
+	Documentation string `json:"documentation"`
+	// NativeType (optional) is the native database type of the field and its arguments, e.g. ["Uuid", []]
+	NativeType []interface{} `json:"nativeType"`
+}
+
+// ColumnName returns the database name of a scalar field
+func (f Field) ColumnName() string {
+	if f.Kind.IsRelation() {
+		return ""
+	}
+	if f.DBName != "" {
+		return f.DBName.String()
+	}
+	return f.Name.String()
+}
+
+// HasDirective returns whether a line of the field documentation starts with the given directive, e.g. `@lazy`
+func (f Field) HasDirective(directive string) bool {
+	return hasDirective(f.Documentation, directive)
+}
+
+// IsLazy returns whether the field is marked with `/// @lazy` and thus not fetched by default
+func (f Field) IsLazy() bool {
+	return f.HasDirective("@lazy")
+}
+
+// IsReadonlyAfterCreate returns whether the field is marked with `/// @readonly-after-create`, which means it can
+// only be set when creating a record
+func (f Field) IsReadonlyAfterCreate() bool {
+	return f.HasDirective("@readonly-after-create")
+}
+
+// HasDefaultNow returns whether the field defaults to the current time with @default(now())
+func (f Field) HasDefaultNow() bool {
+	d, ok := f.Default.(map[string]interface{})
+	return ok && d["name"] == "now"
+}
+
+// IsComputed returns whether the field is marked with `/// @computed`, which means it's a generated or computed
+// column whose value is set by the database and thus can't be written
+func (f Field) IsComputed() bool {
+	return f.HasDirective("@computed")
+}
+
+// HasDefaultFunc returns whether the field is marked with `/// @default-func`, which means its default is computed by
+// the client with a function registered with DefaultFunc, so that it doesn't have to be set on create
+func (f Field) HasDefaultFunc() bool {
+	return f.HasDirective("@default-func")
+}
+
+// IsVersion returns whether the field is marked with `/// @version`, which means it holds the version of a record for
+// optimistic locking with UpdateIfVersion
+func (f Field) IsVersion() bool {
+	return f.HasDirective("@version")
+}
+
+// NativeTypeName returns the name of the native database type of the field, e.g. Uuid for @db.Uuid, or an empty string
+func (f Field) NativeTypeName() string {
+	if len(f.NativeType) == 0 {
+		return ""
+	}
+	name, _ := f.NativeType[0].(string)
+	return name
+}
+
+// GoTypeAnnotation returns the Go type of a field marked with `/// @gotype:<package>.<Type>`, or an empty string
+func (f Field) GoTypeAnnotation() string {
+	for _, line := range strings.Split(f.Documentation, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && strings.HasPrefix(fields[0], "@gotype:") {
+			return strings.TrimPrefix(fields[0], "@gotype:")
+		}
+	}
+	return ""
+}
+
+// GoTags returns the struct tags of a field marked with `/// @gotag: validate:"email"`, which are added to the tags of
+// its struct field. The tags of multiple @gotag lines are joined.
+func (f Field) GoTags() string {
+	var tags []string
+	for _, line := range strings.Split(f.Documentation, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "@gotag:") {
+			if tag := strings.TrimSpace(strings.TrimPrefix(line, "@gotag:")); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return strings.Join(tags, " ")
+}
+
+// GoTagJSONName returns the JSON name a field is given with `/// @gotag: json:"name"`, if any
+func (f Field) GoTagJSONName() (string, bool) {
+	return reflect.StructTag(f.GoTags()).Lookup("json")
+}
+
+func hasDirective(documentation string, directive string) bool {
+	for _, line := range strings.Split(documentation, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && fields[0] == directive {
+			return true
+		}
+	}
+	return false
 }
 
 func (f Field) RequiredOnCreate(key PrimaryKey) bool {
-	if !f.IsRequired || f.IsUpdatedAt || f.HasDefaultValue || f.IsReadOnly || f.IsList {
+	if !f.IsRequired || f.IsUpdatedAt || f.HasDefaultValue || f.IsReadOnly || f.IsList || f.IsComputed() || f.HasDefaultFunc() {
 		return false
 	}
 
@@ -339,6 +583,21 @@ func (f Field) RelationMethods() []RelationMethod {
 	}}
 }
 
+// CompositeMethods returns a mapping for the PQL methods which filter by the fields of embedded documents
+func (f Field) CompositeMethods() []RelationMethod {
+	if f.IsList {
+		return f.RelationMethods()
+	}
+
+	return []RelationMethod{{
+		Name:   "Is",
+		Action: "is",
+	}, {
+		Name:   "IsNot",
+		Action: "isNot",
+	}}
+}
+
 // Schema provides the GraphQL/PQL AST.
 type Schema struct {
 	// RootQueryType (optional)
@@ -24,6 +24,9 @@ type AST struct {
 
 	// OrderBys describe a list of what FindMany operations can order by
 	OrderBys []OrderBy `json:"orderBys"`
+
+	// Relations describe all foreign key relations between models
+	Relations []Relation `json:"relations"`
 }
 
 func New(document *dmmf.Document) *AST {
@@ -37,6 +40,7 @@ func New(document *dmmf.Document) *AST {
 
 	// fetch data
 	ast.Models = ast.models()
+	ast.Relations = ast.relations()
 
 	// fetch data which is needed for the query api, which require ast types
 	ast.ReadFilters = ast.readFilters()
@@ -19,6 +19,16 @@ type Method struct {
 	Type types.Type
 }
 
+// ComparesValue returns whether the method compares the field with values of its own type, e.g. `lt` or `in`, as
+// opposed to methods such as `contains`
+func (m Method) ComparesValue() bool {
+	switch m.Action {
+	case "equals", "not", "in", "notIn", "lt", "lte", "gt", "gte":
+		return true
+	}
+	return false
+}
+
 // Filter defines the data struct for the virtual types method
 type Filter struct {
 	// Name of a filter, which can be a scala like `Int`, or a field name like `Age`
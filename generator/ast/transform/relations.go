@@ -0,0 +1,113 @@
+package transform
+
+import (
+	"fmt"
+
+	"github.com/steebchen/prisma-client-go/generator/ast/dmmf"
+	"github.com/steebchen/prisma-client-go/generator/types"
+)
+
+// Relation describes a foreign key relation between two models, using database names
+type Relation struct {
+	// Name is the relation name as defined in the Prisma schema
+	Name types.String `json:"name"`
+	// Model is the model which holds the foreign key
+	Model types.String `json:"model"`
+	// Table is the database name of Model
+	Table string `json:"table"`
+	// Schema is the database schema of Model, which is only set when using the multiSchema preview feature
+	Schema string `json:"schema"`
+	// IDColumns are the database names of the primary key of Model
+	IDColumns []string `json:"idColumns"`
+	// Columns are the database names of the foreign key fields
+	Columns []string `json:"columns"`
+	// References is the model the foreign key points to
+	References types.String `json:"references"`
+	// ReferencesTable is the database name of References
+	ReferencesTable string `json:"referencesTable"`
+	// ReferencesSchema is the database schema of References
+	ReferencesSchema string `json:"referencesSchema"`
+	// ReferencesColumns are the database names of the referenced fields
+	ReferencesColumns []string `json:"referencesColumns"`
+}
+
+func (r *AST) relations() []Relation {
+	var relations []Relation
+	for _, model := range r.dmmf.Datamodel.Models {
+		for _, field := range model.Fields {
+			if !field.Kind.IsRelation() || len(field.RelationFromFields) == 0 {
+				continue
+			}
+
+			target := r.dmmfModel(field.Type.String())
+			if target == nil {
+				continue
+			}
+
+			var columns []string
+			for _, f := range field.RelationFromFields {
+				columns = append(columns, columnName(model, f.String()))
+			}
+
+			var references []string
+			for _, f := range field.RelationToFields {
+				references = append(references, columnName(*target, fmt.Sprintf("%v", f)))
+			}
+
+			relations = append(relations, Relation{
+				Name:              field.RelationName,
+				Model:             model.Name,
+				Table:             tableName(model),
+				Schema:            model.Schema.String(),
+				IDColumns:         idColumns(model),
+				Columns:           columns,
+				References:        target.Name,
+				ReferencesTable:   tableName(*target),
+				ReferencesSchema:  target.Schema.String(),
+				ReferencesColumns: references,
+			})
+		}
+	}
+	return relations
+}
+
+func (r *AST) dmmfModel(name string) *dmmf.Model {
+	for i, m := range r.dmmf.Datamodel.Models {
+		if m.Name.String() == name {
+			return &r.dmmf.Datamodel.Models[i]
+		}
+	}
+	return nil
+}
+
+func tableName(m dmmf.Model) string {
+	if m.DBName != "" {
+		return m.DBName.String()
+	}
+	return m.Name.String()
+}
+
+func columnName(m dmmf.Model, field string) string {
+	for _, f := range m.Fields {
+		if f.Name.String() == field && f.DBName != "" {
+			return f.DBName.String()
+		}
+	}
+	return field
+}
+
+func idColumns(m dmmf.Model) []string {
+	var columns []string
+	for _, f := range m.PrimaryKey.Fields {
+		columns = append(columns, columnName(m, f.String()))
+	}
+	if len(columns) > 0 {
+		return columns
+	}
+	for _, f := range m.Fields {
+		if f.IsID {
+			columns = append(columns, columnName(m, f.Name.String()))
+		}
+	}
+	return columns
+}
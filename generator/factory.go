@@ -0,0 +1,227 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/steebchen/prisma-client-go/generator/ast/dmmf"
+	"github.com/steebchen/prisma-client-go/generator/types"
+)
+
+// FactoryField is a field which the generated factory of a model can set
+type FactoryField struct {
+	dmmf.Field
+	// Required is set for fields which are filled with a fake value, or a record created by the factory of the related
+	// model, if they're not set
+	Required bool
+	// GoType is the type of the field, qualified with the package of the client
+	GoType string
+	// Fake is an expression which returns a fake value of a scalar field
+	Fake string
+	// Reference is the field of the related model which the foreign key of a relation references
+	Reference types.String
+}
+
+// HasFactories returns whether the factory package is generated
+func (r *Root) HasFactories() bool {
+	return r.Generator.Config.Factories == "true"
+}
+
+// Factories returns the models for which factories are generated, which are those whose required fields can all be
+// filled
+func (r *Root) Factories() []dmmf.Model {
+	supported := map[string]bool{}
+	var models []dmmf.Model
+	for _, model := range r.DMMF.Datamodel.Models {
+		if r.hasFactory(model, supported) {
+			models = append(models, model)
+		}
+	}
+	return models
+}
+
+// hasFactory returns whether a factory can fill the required fields of a model, including those of the models of
+// its required relations. Views can't be created, and models which are being checked count as unsupported, which
+// breaks cycles.
+func (r *Root) hasFactory(model dmmf.Model, supported map[string]bool) bool {
+	if model.IsView {
+		return false
+	}
+	if ok, checked := supported[model.Name.String()]; checked {
+		return ok
+	}
+	supported[model.Name.String()] = false
+
+	for _, field := range model.Fields {
+		if !field.RequiredOnCreate(model.PrimaryKey) {
+			continue
+		}
+		f, ok := r.factoryField(model, field)
+		if !ok {
+			return false
+		}
+		if f.Kind.IsRelation() {
+			related, ok := r.model(f.Type.String())
+			if !ok || !r.hasFactory(related, supported) {
+				return false
+			}
+		} else if f.Fake == "" {
+			return false
+		}
+	}
+
+	supported[model.Name.String()] = true
+	return true
+}
+
+// FactoryFields returns the fields of a model which its factory can set
+func (r *Root) FactoryFields(model dmmf.Model) []FactoryField {
+	var fields []FactoryField
+	for _, field := range model.Fields {
+		if f, ok := r.factoryField(model, field); ok {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// FactoryUsesFake returns whether any factory fills a field with a value of the fake package
+func (r *Root) FactoryUsesFake() bool {
+	for _, model := range r.Factories() {
+		for _, f := range r.FactoryFields(model) {
+			if f.Required && strings.HasPrefix(f.Fake, "fake.") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// factoryField returns the factory field of a non-list scalar, enum or relation field. Fields mapped to custom Go
+// types, foreign keys, which are set by linking the relation, and relations with compound foreign keys aren't
+// supported.
+func (r *Root) factoryField(model dmmf.Model, field dmmf.Field) (FactoryField, bool) {
+	if field.IsList || field.IsComputed() || field.IsReadOnly || r.CustomType(model.Name, field.Name) != "" {
+		return FactoryField{}, false
+	}
+
+	f := FactoryField{
+		Field:    field,
+		Required: field.RequiredOnCreate(model.PrimaryKey),
+	}
+
+	switch {
+	case field.Kind.IsRelation():
+		if len(field.RelationFromFields) != 1 || len(field.RelationToFields) != 1 {
+			return FactoryField{}, false
+		}
+		reference, ok := field.RelationToFields[0].(string)
+		if !ok {
+			return FactoryField{}, false
+		}
+		// the foreign key is linked with the referenced value of the record, which has to be set
+		related, ok := r.model(field.Type.String())
+		if !ok {
+			return FactoryField{}, false
+		}
+		if referenced, ok := fieldByName(related, reference); !ok || !referenced.IsRequired || r.CustomType(related.Name, referenced.Name) != "" {
+			return FactoryField{}, false
+		}
+		f.GoType = "*db." + field.Type.GoCase() + "Model"
+		f.Reference = types.String(reference)
+	case field.Kind == dmmf.FieldKindEnum:
+		f.GoType = "db." + field.Type.GoCase()
+		for _, enum := range r.DMMF.Datamodel.Enums {
+			if enum.Name.String() == field.Type.String() && len(enum.Values) > 0 {
+				f.Fake = "db." + enum.Name.GoCase() + enum.Values[0].Name.GoCase()
+			}
+		}
+	case field.Kind == dmmf.FieldKindScalar:
+		f.GoType, f.Fake = r.fakeScalar(field)
+		if f.GoType == "" {
+			return FactoryField{}, false
+		}
+	default:
+		return FactoryField{}, false
+	}
+	return f, true
+}
+
+// fakeScalar returns the Go type of a scalar field and an expression of a fake value of it
+func (r *Root) fakeScalar(field dmmf.Field) (string, string) {
+	switch field.Type {
+	case "String":
+		return "string", fmt.Sprintf("fake.String(%q, %q)", field.Name, field.NativeTypeName())
+	case "Int":
+		return "int", "fake.Int()"
+	case "Float":
+		return "float64", "fake.Float()"
+	case "Boolean":
+		return "bool", "false"
+	case "DateTime":
+		return "db.DateTime", "fake.Time()"
+	case "Json":
+		return "db.JSON", "fake.JSON()"
+	case "Bytes":
+		return "db.Bytes", fmt.Sprintf("fake.Bytes(%q)", field.Name)
+	case "Decimal":
+		return "db.Decimal", "fake.Decimal()"
+	case "BigInt":
+		if r.BigIntAsBigInt() {
+			return "db.BigInt", "fake.BigInteger()"
+		}
+		return "db.BigInt", "db.BigInt(fake.Int())"
+	}
+	return "", ""
+}
+
+// generateFactories writes the factory package into the factory directory of the client, which creates records with
+// fake values for tests
+func generateFactories(input *Root) error {
+	if !input.HasFactories() {
+		return nil
+	}
+
+	pkg, err := resolveImport(input.Generator.Output.Value)
+	if err != nil {
+		return err
+	}
+
+	t, err := template.ParseFS(templateFS, "templates/factory.gotpl")
+	if err != nil {
+		return fmt.Errorf("could not parse factory template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if header := input.FileHeader(); header != "" {
+		buf.WriteString(header + "\n\n")
+	}
+	if err := t.Execute(&buf, struct {
+		*Root
+		Import string
+	}{input, pkg}); err != nil {
+		return fmt.Errorf("could not write factory template: %w", err)
+	}
+
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("could not format factory source: %w", err)
+	}
+
+	dir := path.Join(input.Generator.Output.Value, "factory")
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return fmt.Errorf("could not create factory directory: %w", err)
+	}
+
+	file := path.Join(dir, "factory_gen.go")
+	if err := os.WriteFile(file, formatted, 0644); err != nil {
+		return fmt.Errorf("could not write %s: %w", file, err)
+	}
+
+	return nil
+}
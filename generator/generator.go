@@ -2,13 +2,18 @@ package generator
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path"
+	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/steebchen/prisma-client-go/generator/ast/dmmf"
 	"github.com/steebchen/prisma-client-go/generator/ast/transform"
 	"github.com/steebchen/prisma-client-go/generator/types"
+	"github.com/steebchen/prisma-client-go/helpers/strcase"
 	"github.com/steebchen/prisma-client-go/logger"
 )
 
@@ -26,6 +31,9 @@ type Root struct {
 	// BinaryPaths (optional)
 	BinaryPaths BinaryPaths    `json:"binaryPaths"`
 	AST         *transform.AST `json:"ast"`
+
+	// goTypes holds the Go types of Json fields annotated with @gotype, keyed by model and field name
+	goTypes map[string]GoType
 }
 
 func (r *Root) EscapedDatamodel() string {
@@ -52,12 +60,516 @@ func (r *Root) GetEngineType() string {
 	return "binary"
 }
 
+// IsSideloadBinary returns whether the query engine is loaded at runtime instead of being embedded
+func (r *Root) IsSideloadBinary() bool {
+	return r.Generator.Config.SideloadBinary == "true" || r.Generator.Config.SideloadBinaryPath != ""
+}
+
+// IsSQLite returns whether the datasource is a SQLite database
+func (r *Root) IsSQLite() bool {
+	return len(r.Datasources) > 0 && r.Datasources[0].ActiveProvider == ProviderSQLite
+}
+
+// HasTestClient returns whether a NewTestClient helper backed by a temporary SQLite database is generated
+func (r *Root) HasTestClient() bool {
+	return r.IsSQLite() && r.GetEngineType() != "dataproxy"
+}
+
+// GenerateInterfaces returns whether the DBClient and model action interfaces should be generated
+func (r *Root) GenerateInterfaces() bool {
+	return r.Generator.Config.GenerateInterfaces == "true"
+}
+
+// SupportsReturning returns whether the datasource supports returning the records of bulk writes, e.g. with
+// updateManyAndReturn
+func (r *Root) SupportsReturning() bool {
+	if len(r.Datasources) == 0 {
+		return false
+	}
+	switch r.Datasources[0].ActiveProvider {
+	case ProviderPostgreSQL, ProviderSQLite, ProviderCockroachDB:
+		return true
+	}
+	return false
+}
+
+// SupportsSkipDuplicates returns whether createMany can skip records which already exist with skipDuplicates
+func (r *Root) SupportsSkipDuplicates() bool {
+	if len(r.Datasources) == 0 {
+		return false
+	}
+	switch r.Datasources[0].ActiveProvider {
+	case ProviderPostgreSQL, ProviderCockroachDB, ProviderMySQL:
+		return true
+	}
+	return false
+}
+
+// SupportsSessionSettings returns whether clients can run their queries with session variables, e.g. with WithSchema
+// or WithSessionVar
+func (r *Root) SupportsSessionSettings() bool {
+	if len(r.Datasources) == 0 {
+		return false
+	}
+	switch r.Datasources[0].ActiveProvider {
+	case ProviderPostgreSQL, ProviderCockroachDB:
+		return true
+	}
+	return false
+}
+
+// SupportsRelationJoins returns whether queries can choose how relations are loaded with relationLoadStrategy, which
+// the engine only exposes when the relationJoins preview feature is enabled for a database supporting it
+func (r *Root) SupportsRelationJoins() bool {
+	for _, enum := range r.DMMF.Schema.EnumTypes.Prisma {
+		if enum.Name.String() == "RelationLoadStrategy" {
+			return true
+		}
+	}
+	return false
+}
+
+// LoaderKey returns the primary key field by which records of a model are batched by its generated loader, or nil if
+// the model has no single-field primary key of a type which can be used as a map key
+func (r *Root) LoaderKey(model dmmf.Model) *dmmf.Field {
+	keys := model.PrimaryKeyFields()
+	if len(keys) != 1 {
+		return nil
+	}
+	for _, field := range model.Fields {
+		if field.Name == keys[0] && r.isMapKey(model, field) {
+			return &field
+		}
+	}
+	return nil
+}
+
+// isMapKey returns whether values of a field have a Go type which can be used as a map key
+func (r *Root) isMapKey(model dmmf.Model, field dmmf.Field) bool {
+	if field.IsList || r.CustomType(model.Name, field.Name) != "" {
+		return false
+	}
+	if field.Kind == dmmf.FieldKindEnum {
+		return true
+	}
+	switch field.Type {
+	case "String", "Int":
+		return true
+	case "BigInt":
+		return !r.BigIntAsBigInt()
+	}
+	return false
+}
+
+// RelationLoad describes a relation whose records can be loaded for existing models with a single query
+type RelationLoad struct {
+	// Field is the relation field which is set on the models
+	Field dmmf.Field
+	// Local is the field of the models which identifies their related records
+	Local dmmf.Field
+	// Remote is the field of the related model which matches Local
+	Remote dmmf.Field
+}
+
+// RelationLoads returns the relations of a model which get a generated Load function, which are all relations with a
+// single-field foreign key of a type which can be used as a map key. Implicit many-to-many relations are not included.
+func (r *Root) RelationLoads(model dmmf.Model) []RelationLoad {
+	var loads []RelationLoad
+	for _, field := range model.Fields {
+		if !field.Kind.IsRelation() {
+			continue
+		}
+		target, ok := r.model(field.Type.String())
+		if !ok {
+			continue
+		}
+
+		var local, remote string
+		if len(field.RelationFromFields) == 1 && len(field.RelationToFields) == 1 {
+			local = field.RelationFromFields[0].String()
+			remote = fmt.Sprint(field.RelationToFields[0])
+		} else {
+			for _, f := range target.Fields {
+				opposite := f.RelationName == field.RelationName && (target.Name != model.Name || f.Name != field.Name)
+				if opposite && len(f.RelationFromFields) == 1 && len(f.RelationToFields) == 1 {
+					local = fmt.Sprint(f.RelationToFields[0])
+					remote = f.RelationFromFields[0].String()
+				}
+			}
+		}
+
+		l, lok := fieldByName(model, local)
+		rf, rok := fieldByName(target, remote)
+		if !lok || !rok || !r.isMapKey(model, l) || !r.isMapKey(target, rf) || l.Type != rf.Type {
+			continue
+		}
+		loads = append(loads, RelationLoad{
+			Field:  field,
+			Local:  l,
+			Remote: rf,
+		})
+	}
+	return loads
+}
+
+// model returns the model with the given name
+func (r *Root) model(name string) (dmmf.Model, bool) {
+	for _, m := range r.DMMF.Datamodel.Models {
+		if m.Name.String() == name {
+			return m, true
+		}
+	}
+	return dmmf.Model{}, false
+}
+
+func fieldByName(model dmmf.Model, name string) (dmmf.Field, bool) {
+	for _, f := range model.Fields {
+		if f.Name.String() == name {
+			return f, true
+		}
+	}
+	return dmmf.Field{}, false
+}
+
+// CompoundKeyFields returns the fields of a compound primary key or unique constraint in key order, or nil if any of
+// them is not a scalar or enum field, in which case no key constructor taking values is generated
+func (r *Root) CompoundKeyFields(model transform.Model, key transform.Index) []dmmf.Field {
+	var fields []dmmf.Field
+	for _, name := range key.Fields {
+		field, ok := fieldByName(model.OldModel, name.String())
+		if !ok || field.IsList || (field.Kind != dmmf.FieldKindScalar && field.Kind != dmmf.FieldKindEnum) {
+			return nil
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// VersionField returns the field of a model marked with `/// @version`, which enables UpdateIfVersion, or nil if the
+// model has none. Views can't be updated and thus have no version field.
+func (r *Root) VersionField(model dmmf.Model) *dmmf.Field {
+	if model.IsView {
+		return nil
+	}
+	for _, field := range model.Fields {
+		if field.IsVersion() {
+			return &field
+		}
+	}
+	return nil
+}
+
+// HasLoaders returns whether a loader is generated for any model
+func (r *Root) HasLoaders() bool {
+	for _, model := range r.DMMF.Datamodel.Models {
+		if r.LoaderKey(model) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// GQLGen returns whether helpers for gqlgen resolvers are generated
+func (r *Root) GQLGen() bool {
+	return r.Generator.Config.GQLGen != ""
+}
+
+// BigIntAsBigInt returns whether BigInt fields are generated as arbitrary-precision integers based on math/big.Int
+func (r *Root) BigIntAsBigInt() bool {
+	return r.Generator.Config.BigIntType == "big.Int"
+}
+
+// RelationAccessErrors returns whether relation accessors return a NotLoadedError for relations which weren't fetched
+func (r *Root) RelationAccessErrors() bool {
+	return r.Generator.Config.RelationAccessors == "error"
+}
+
+// TracksNullRelations returns whether models record which optional relations were fetched but don't exist, so that
+// their accessors can tell them apart from relations which weren't fetched
+func (r *Root) TracksNullRelations(model dmmf.Model) bool {
+	if !r.RelationAccessErrors() {
+		return false
+	}
+	for _, field := range model.Fields {
+		if field.Kind.IsRelation() && !field.IsList && !field.IsRequired {
+			return true
+		}
+	}
+	return false
+}
+
+// HasNullRelationTracking returns whether any model records which optional relations were fetched but don't exist
+func (r *Root) HasNullRelationTracking() bool {
+	for _, model := range r.DMMF.Datamodel.Models {
+		if r.TracksNullRelations(model) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasIdempotencyKeys returns whether the schema contains the IdempotencyKey model, which enables idempotent creates
+func (r *Root) HasIdempotencyKeys() bool {
+	for _, model := range r.DMMF.Datamodel.Models {
+		if model.Name.String() == "IdempotencyKey" {
+			return true
+		}
+	}
+	return false
+}
+
+// WritableModels returns the models which have create, update and delete actions, which are all models except views
+func (r *Root) WritableModels() []dmmf.Model {
+	var models []dmmf.Model
+	for _, model := range r.DMMF.Datamodel.Models {
+		if !model.IsView {
+			models = append(models, model)
+		}
+	}
+	return models
+}
+
+// HasSeeds returns whether the schema contains the AppliedSeed model, which enables the Seed function
+func (r *Root) HasSeeds() bool {
+	for _, model := range r.DMMF.Datamodel.Models {
+		if model.Name.String() == "AppliedSeed" {
+			return true
+		}
+	}
+	return false
+}
+
+// HasSchemas returns whether any model sets its database schema with @@schema of the multiSchema preview feature
+func (r *Root) HasSchemas() bool {
+	for _, model := range r.DMMF.Datamodel.Models {
+		if model.Schema != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// HasNestedCreateMany returns whether the related records of a relation field can be created with a nested
+// createMany, which the engine only supports on the list side of one-to-many relations
+func (r *Root) HasNestedCreateMany(field dmmf.Field) bool {
+	if !field.Kind.IsRelation() || !field.IsList {
+		return false
+	}
+	for _, model := range r.DMMF.Datamodel.Models {
+		if model.Name.String() != field.Type.String() {
+			continue
+		}
+		for _, f := range model.Fields {
+			if f.RelationName == field.RelationName && len(f.RelationFromFields) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasCustomJSON returns whether models need a generated MarshalJSON method to match the json config
+func (r *Root) HasCustomJSON() bool {
+	return r.Generator.Config.JSONOmitEmpty == "false" || (r.Generator.Config.JSONFieldOrder != "" && r.Generator.Config.JSONFieldOrder != "struct")
+}
+
+// JSONOmitEmpty returns whether nil optional fields are omitted when encoding models to JSON
+func (r *Root) JSONOmitEmpty() bool {
+	return r.Generator.Config.JSONOmitEmpty != "false"
+}
+
+// JSONName returns the name of a model field when encoding models to JSON, which is the name set with a json tag in a
+// @gotag annotation, or the field name according to the jsonTags config
+func (r *Root) JSONName(field dmmf.Field) string {
+	if name, ok := field.GoTagJSONName(); ok {
+		return name
+	}
+	switch r.Generator.Config.JSONTags {
+	case "camelCase":
+		return strcase.ToLowerCamel(string(field.Name))
+	case "snake_case":
+		return strcase.ToSnake(string(field.Name))
+	default:
+		return string(field.Name)
+	}
+}
+
+// JSONTag returns the json struct tag of a model field. Optional fields and relations are omitted if they are nil.
+func (r *Root) JSONTag(field dmmf.Field) string {
+	if !field.IsRequired || field.Kind.IsRelation() {
+		return fmt.Sprintf("`json:\"%s,omitempty\"`", r.JSONName(field))
+	}
+	return fmt.Sprintf("`json:\"%s\"`", r.JSONName(field))
+}
+
+// StructTag returns the struct tag of a model field, which is the json tag followed by the other tags of its @gotag
+// annotations. A json tag of an annotation only sets the name of the generated json tag.
+func (r *Root) StructTag(field dmmf.Field) string {
+	tag := r.JSONTag(field)
+	keys, _ := structTagKeys(field.GoTags())
+	for _, key := range keys {
+		if key == "json" {
+			continue
+		}
+		value, _ := reflect.StructTag(field.GoTags()).Lookup(key)
+		tag = strings.TrimSuffix(tag, "`") + " " + key + ":" + strconv.Quote(value) + "`"
+	}
+	return tag
+}
+
+// RenamesJSON returns whether the JSON names of any model field differ from the field names in the schema, in which
+// case query results are renamed before they are decoded into models
+func (r *Root) RenamesJSON() bool {
+	for _, model := range r.DMMF.Datamodel.Models {
+		for _, field := range model.Fields {
+			if r.JSONName(field) != string(field.Name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// JSONFields returns the fields of a model in the order they are encoded to JSON
+func (r *Root) JSONFields(model dmmf.Model) []dmmf.Field {
+	var scalars, relations []dmmf.Field
+	for _, field := range model.Fields {
+		if field.Kind.IsRelation() {
+			relations = append(relations, field)
+		} else {
+			scalars = append(scalars, field)
+		}
+	}
+
+	switch r.Generator.Config.JSONFieldOrder {
+	case "schema":
+		return model.Fields
+	case "alphabetical":
+		fields := append(scalars, relations...)
+		sort.SliceStable(fields, func(i, j int) bool {
+			return fields[i].Name < fields[j].Name
+		})
+		return fields
+	default:
+		return append(scalars, relations...)
+	}
+}
+
+// FileHeader returns the header option as Go line comments, which keeps build constraints of generated files valid
+func (r *Root) FileHeader() string {
+	return commentLines(r.Generator.Config.Header, "//")
+}
+
+// DocComment returns triple-slash documentation of the schema as the lines of a Go doc comment. Annotations such as
+// `@lazy` are left out, and `@deprecated <reason>` becomes a Deprecated paragraph, so that editors and linters flag
+// usages.
+func (r *Root) DocComment(documentation string) string {
+	var lines []string
+	deprecated := ""
+	isDeprecated := false
+	for _, line := range strings.Split(strings.ReplaceAll(documentation, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		fields := strings.Fields(trimmed)
+		switch {
+		case len(fields) > 0 && fields[0] == "@deprecated":
+			isDeprecated = true
+			deprecated = strings.TrimSpace(strings.TrimPrefix(trimmed, "@deprecated"))
+		case strings.HasPrefix(trimmed, "@"):
+		default:
+			lines = append(lines, strings.TrimRight(line, " \t"))
+		}
+	}
+
+	text := strings.Trim(strings.Join(lines, "\n"), "\n")
+	if isDeprecated {
+		if deprecated == "" {
+			deprecated = "marked as deprecated in the Prisma schema."
+		}
+		if text != "" {
+			text += "\n\n"
+		}
+		text += "Deprecated: " + deprecated
+	}
+	return commentLines(text, "//")
+}
+
+// commentLines prefixes each line of a text with a comment marker, keeping lines which already start with it
+func commentLines(text string, marker string) string {
+	text = strings.TrimRight(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if strings.TrimSpace(text) == "" {
+		return ""
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, marker):
+		case strings.TrimSpace(line) == "":
+			lines[i] = marker
+		default:
+			lines[i] = marker + " " + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 // Config describes the options for the Prisma Client Go generator
 type Config struct {
 	EngineType        string       `json:"engineType"`
 	Package           types.String `json:"package"`
 	DisableGitignore  string       `json:"disableGitignore"`
 	DisableGoBinaries string       `json:"disableGoBinaries"`
+	// SideloadBinary skips embedding the query engine into the Go binary; it is loaded at runtime instead
+	SideloadBinary string `json:"sideloadBinary"`
+	// SideloadBinaryPath (optional) is the path where a sideloaded query engine is expected at runtime
+	SideloadBinaryPath string `json:"sideloadBinaryPath"`
+	// JSONOmitEmpty controls whether nil optional fields are omitted (default) or encoded as null
+	JSONOmitEmpty string `json:"jsonOmitEmpty"`
+	// JSONFieldOrder controls the order of fields when encoding models to JSON; one of struct (default), schema
+	// or alphabetical
+	JSONFieldOrder string `json:"jsonFieldOrder"`
+	// JSONTags controls the names in the json struct tags of models; one of schema (default), camelCase or snake_case
+	JSONTags string `json:"jsonTags"`
+	// GenerateInterfaces additionally emits a DBClient interface and per-model action interfaces
+	GenerateInterfaces string `json:"generateInterfaces"`
+	// BigIntType controls the Go type of BigInt fields; one of int64 (default) or big.Int
+	BigIntType string `json:"bigIntType"`
+	// RelationAccessors controls how accessors of relations which weren't fetched behave; one of legacy (default), which
+	// panics for required relations and returns no value otherwise, or error, which returns a NotLoadedError and adds
+	// Must variants which panic
+	RelationAccessors string `json:"relationAccessors"`
+	// GQLGen (optional) is the path of a file, relative to the schema, to which gqlgen model bindings are written; it
+	// also enables the With helpers and the Loaders type for gqlgen resolvers
+	GQLGen string `json:"gqlgen"`
+	// RESTHandlers additionally emits net/http handlers with list, get, create, update and delete endpoints per model
+	RESTHandlers string `json:"restHandlers"`
+	// Factories additionally emits a factory package next to the client with builders which create records for tests
+	Factories string `json:"factories"`
+	// Header (optional) is written as a comment at the top of all generated files, e.g. a license; use \n for multiple
+	// lines
+	Header string `json:"header"`
+	// IncludeModels (optional) lists the models to generate code for; all models are generated by default
+	IncludeModels StringList `json:"includeModels"`
+	// ExcludeModels (optional) lists models which no code is generated for
+	ExcludeModels StringList `json:"excludeModels"`
+	// TypeOverrides maps Prisma scalar types or native database types to Go types, e.g. Uuid=github.com/google/uuid.UUID
+	TypeOverrides StringList `json:"typeOverrides"`
+}
+
+// StringList is a generator option which can be set to a single string or to a list of strings
+type StringList []string
+
+func (l *StringList) UnmarshalJSON(data []byte) error {
+	var list []string
+	if err := json.Unmarshal(data, &list); err == nil {
+		*l = list
+		return nil
+	}
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return fmt.Errorf("expected a string or a list of strings: %w", err)
+	}
+	*l = StringList{str}
+	return nil
 }
 
 // Generator describes a generator defined in the Prisma schema.
@@ -89,10 +601,11 @@ type Provider string
 //
 //goland:noinspection GoUnusedConst
 const (
-	ProviderMySQL      Provider = "mysql"
-	ProviderMongo      Provider = "mongo"
-	ProviderSQLite     Provider = "sqlite"
-	ProviderPostgreSQL Provider = "postgresql"
+	ProviderMySQL       Provider = "mysql"
+	ProviderMongo       Provider = "mongo"
+	ProviderSQLite      Provider = "sqlite"
+	ProviderPostgreSQL  Provider = "postgresql"
+	ProviderCockroachDB Provider = "cockroachdb"
 )
 
 // Datasource describes a Prisma data source of any database type.
@@ -151,7 +664,7 @@ func (r *Root) GetSanitizedDatasourceURL() string {
 	// prefix with sqlite: to make it a valid connection string again
 	url = "file:" + url
 
-	logger.Debug.Printf("sanitizing relative sqlite path %s\n", url)
+	logger.Default().Debug("sanitizing relative sqlite path", "url", url)
 
 	return url
 }
@@ -0,0 +1,47 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/steebchen/prisma-client-go/generator/ast/dmmf"
+)
+
+func TestRoot_StructTag(t *testing.T) {
+	tests := []struct {
+		name     string
+		jsonTags string
+		field    dmmf.Field
+		want     string
+	}{{
+		name:  "json only",
+		field: dmmf.Field{Name: "email", IsRequired: true},
+		want:  "`json:\"email\"`",
+	}, {
+		name:  "extra tags",
+		field: dmmf.Field{Name: "email", IsRequired: true, Documentation: `@gotag: validate:"required,email" bson:"email"`},
+		want:  "`json:\"email\" validate:\"required,email\" bson:\"email\"`",
+	}, {
+		name:  "json name of optional field",
+		field: dmmf.Field{Name: "email", Documentation: `@gotag: validate:"email" json:"emailAddress"`},
+		want:  "`json:\"emailAddress,omitempty\" validate:\"email\"`",
+	}, {
+		name:     "json name takes precedence over jsonTags",
+		jsonTags: "snake_case",
+		field:    dmmf.Field{Name: "emailAddress", IsRequired: true, Documentation: `@gotag: json:"mail"`},
+		want:     "`json:\"mail\"`",
+	}, {
+		name:     "jsonTags",
+		jsonTags: "snake_case",
+		field:    dmmf.Field{Name: "emailAddress", IsRequired: true, Documentation: `@gotag: validate:"email"`},
+		want:     "`json:\"email_address\" validate:\"email\"`",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var root Root
+			root.Generator.Config.JSONTags = tt.jsonTags
+			if got := root.StructTag(tt.field); got != tt.want {
+				t.Errorf("StructTag() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package generator
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/steebchen/prisma-client-go/generator/types"
+	"github.com/steebchen/prisma-client-go/helpers/gocase"
+)
+
+// GoType is the Go type a field is generated as instead of its default type, either because it's a Json field
+// annotated with `/// @gotype:<package>.<Type>` or because its type is mapped with the typeOverrides option
+type GoType struct {
+	// Import is the import path of the package which declares the type
+	Import string
+	// Alias is the name the package is imported as in the generated client
+	Alias string
+	// Name is the name of the type
+	Name string
+	// JSON indicates that the type is stored in a Json field, whose values are sent to the engine as JSON strings
+	JSON bool
+}
+
+func (t GoType) String() string {
+	return t.Alias + "." + t.Name
+}
+
+// GoType returns the Go type of a Json field, or an empty string if the field is not mapped to a Go type
+func (r *Root) GoType(model types.String, field types.String) string {
+	t, ok := r.goTypes[model.String()+"."+field.String()]
+	if !ok || !t.JSON {
+		return ""
+	}
+	return t.String()
+}
+
+// ScalarType returns the Go type of a non-Json field which is mapped with typeOverrides, or an empty string
+func (r *Root) ScalarType(model types.String, field types.String) string {
+	t, ok := r.goTypes[model.String()+"."+field.String()]
+	if !ok || t.JSON {
+		return ""
+	}
+	return t.String()
+}
+
+// CustomType returns the Go type of a field which is mapped to a custom Go type, or an empty string
+func (r *Root) CustomType(model types.String, field types.String) string {
+	t, ok := r.goTypes[model.String()+"."+field.String()]
+	if !ok {
+		return ""
+	}
+	return t.String()
+}
+
+// HasCustomTypes returns whether a model has fields which are mapped to custom Go types
+func (r *Root) HasCustomTypes(model types.String) bool {
+	for key := range r.goTypes {
+		if strings.HasPrefix(key, model.String()+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// GoTypeImports returns the packages of all custom Go types
+func (r *Root) GoTypeImports() []GoType {
+	var imports []GoType
+	seen := map[string]bool{}
+	for _, model := range r.DMMF.Datamodel.Models {
+		for _, field := range model.Fields {
+			t, ok := r.goTypes[model.Name.String()+"."+field.Name.String()]
+			if !ok || seen[t.Import] {
+				continue
+			}
+			seen[t.Import] = true
+			imports = append(imports, t)
+		}
+	}
+	return imports
+}
+
+// resolveGoTypes resolves the custom Go types of fields from `@gotype` annotations of Json fields and from the
+// typeOverrides option. Relative package paths such as ./types are resolved relative to the schema file, using the
+// module path from the nearest go.mod.
+func resolveGoTypes(input *Root) error {
+	input.goTypes = map[string]GoType{}
+	aliases := map[string]string{}
+
+	overrides := map[string]string{}
+	for _, override := range input.Generator.Config.TypeOverrides {
+		key, value, ok := strings.Cut(override, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || key == "" || value == "" {
+			return fmt.Errorf("invalid typeOverrides entry %q, expected <Type>=<package>.<Type>, e.g. DateTime=github.com/acme/app/dates.Time", override)
+		}
+		overrides[key] = value
+	}
+
+	for _, model := range input.DMMF.Datamodel.Models {
+		for _, field := range model.Fields {
+			if field.Kind.IsRelation() {
+				continue
+			}
+
+			annotation := field.GoTypeAnnotation()
+			if annotation != "" && (field.Type != "Json" || field.IsList) {
+				return fmt.Errorf("field %s.%s has a @gotype annotation, but only non-list Json fields can be mapped to Go types", model.Name, field.Name)
+			}
+			if annotation == "" {
+				// native types such as Uuid take precedence over scalar types such as String
+				if override, ok := overrides[field.NativeTypeName()]; ok && field.NativeTypeName() != "" {
+					annotation = override
+				} else if override, ok := overrides[field.Type.String()]; ok {
+					annotation = override
+				}
+			}
+			if annotation == "" {
+				continue
+			}
+
+			if field.Type == "Json" && field.IsList {
+				return fmt.Errorf("field %s.%s is a Json list, which can't be mapped to a Go type", model.Name, field.Name)
+			}
+
+			i := strings.LastIndex(annotation, ".")
+			if i <= strings.LastIndex(annotation, "/") || i == len(annotation)-1 {
+				return fmt.Errorf("invalid Go type %q of field %s.%s, expected <package>.<Type>, e.g. ./types.Settings", annotation, model.Name, field.Name)
+			}
+			pkg, name := annotation[:i], annotation[i+1:]
+
+			if strings.HasPrefix(pkg, ".") {
+				resolved, err := resolveImport(path.Join(path.Dir(input.SchemaPath), pkg))
+				if err != nil {
+					return fmt.Errorf("resolve Go type of field %s.%s: %w", model.Name, field.Name, err)
+				}
+				pkg = resolved
+			}
+
+			alias, ok := aliases[pkg]
+			if !ok {
+				// prefix the alias, so that it doesn't collide with the packages imported by the client
+				alias = "custom" + gocase.ToUpper(path.Base(pkg))
+				if len(aliases) > 0 {
+					alias = fmt.Sprintf("%s%d", alias, len(aliases)+1)
+				}
+				aliases[pkg] = alias
+			}
+
+			input.goTypes[model.Name.String()+"."+field.Name.String()] = GoType{
+				Import: pkg,
+				Alias:  alias,
+				Name:   name,
+				JSON:   field.Type == "Json",
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolveImport returns the import path of a package directory by looking up the module path from the nearest go.mod
+func resolveImport(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("absolute path: %w", err)
+	}
+
+	for root := dir; ; root = filepath.Dir(root) {
+		module, err := readModulePath(filepath.Join(root, "go.mod"))
+		if err != nil {
+			return "", err
+		}
+		if module != "" {
+			rel, err := filepath.Rel(root, dir)
+			if err != nil {
+				return "", fmt.Errorf("relative path: %w", err)
+			}
+			return path.Join(module, filepath.ToSlash(rel)), nil
+		}
+		if filepath.Dir(root) == root {
+			return "", fmt.Errorf("could not find go.mod for %s", dir)
+		}
+	}
+}
+
+// readModulePath returns the module path declared in a go.mod file, or an empty string if the file doesn't exist
+func readModulePath(file string) (string, error) {
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("open %s: %w", file, err)
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("read %s: %w", file, err)
+	}
+	return "", fmt.Errorf("%s has no module directive", file)
+}
@@ -0,0 +1,63 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+)
+
+// generateGQLGen writes the gqlgen model bindings of the generated models and enums to the file of the gqlgen option,
+// so that gqlgen uses them instead of generating its own types. Relation fields are bound to resolvers, which can
+// fetch them with the generated loaders.
+func generateGQLGen(input *Root) error {
+	if !input.GQLGen() {
+		return nil
+	}
+
+	pkg, err := resolveImport(input.Generator.Output.Value)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	if header := commentLines(input.Generator.Config.Header, "#"); header != "" {
+		b.WriteString(header + "\n\n")
+	}
+	b.WriteString("# gqlgen model bindings generated by Prisma Client Go. DO NOT EDIT.\n")
+	b.WriteString("# Copy or merge the models section into your gqlgen.yml.\n")
+	b.WriteString("models:\n")
+
+	for _, model := range input.DMMF.Datamodel.Models {
+		fmt.Fprintf(&b, "  %s:\n    model:\n      - %s.%sModel\n", model.Name, pkg, model.Name.GoCase())
+
+		var relations []string
+		for _, field := range model.Fields {
+			if field.Kind.IsRelation() {
+				relations = append(relations, field.Name.String())
+			}
+		}
+		if len(relations) == 0 {
+			continue
+		}
+		b.WriteString("    fields:\n")
+		for _, name := range relations {
+			fmt.Fprintf(&b, "      %s:\n        resolver: true\n", name)
+		}
+	}
+
+	for _, enum := range input.DMMF.Datamodel.Enums {
+		fmt.Fprintf(&b, "  %s:\n    model:\n      - %s.%s\n", enum.Name, pkg, enum.Name.GoCase())
+	}
+
+	for _, t := range input.DMMF.Datamodel.Types {
+		fmt.Fprintf(&b, "  %s:\n    model:\n      - %s.%s\n", t.Name, pkg, t.Name.GoCase())
+	}
+
+	file := path.Join(path.Dir(input.SchemaPath), input.Generator.Config.GQLGen)
+	if err := os.WriteFile(file, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("could not write %s: %w", file, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package generator
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/steebchen/prisma-client-go/generator/ast/dmmf"
+	"github.com/steebchen/prisma-client-go/generator/types"
+)
+
+// RESTField is a scalar field which the generated REST handlers read from request bodies and query parameters
+type RESTField struct {
+	dmmf.Field
+	// Relation is set if the field is the foreign key of a relation, which is linked instead of setting the field
+	Relation *dmmf.Field
+	// Reference is the field of the related model which the foreign key references
+	Reference types.String
+}
+
+// Filterable returns whether lists can be filtered and ordered by the field
+func (f RESTField) Filterable() bool {
+	return f.Type != "Json" && f.Type != "Bytes"
+}
+
+// Updatable returns whether the field can be changed by update requests
+func (f RESTField) Updatable() bool {
+	return !f.IsID && !f.IsReadonlyAfterCreate()
+}
+
+// HasRESTHandlers returns whether REST handlers are generated
+func (r *Root) HasRESTHandlers() bool {
+	return r.Generator.Config.RESTHandlers == "true"
+}
+
+// RESTPath returns the path of the REST endpoints of a model, e.g. blog-posts for BlogPost
+func (r *Root) RESTPath(model dmmf.Model) string {
+	var b strings.Builder
+	name := []rune(model.Name.GoCasePlural())
+	for i, c := range name {
+		if i > 0 && unicode.IsUpper(c) && (unicode.IsLower(name[i-1]) || i+1 < len(name) && unicode.IsLower(name[i+1])) {
+			b.WriteRune('-')
+		}
+		b.WriteRune(unicode.ToLower(c))
+	}
+	return b.String()
+}
+
+// RESTFields returns the non-list scalar fields of a model which the REST handlers support. Fields mapped to custom
+// Go types and foreign keys of compound relations aren't supported.
+func (r *Root) RESTFields(model dmmf.Model) []RESTField {
+	var fields []RESTField
+	for _, field := range model.Fields {
+		if !field.Kind.IncludeInStruct() || field.IsList || field.IsComputed() || r.CustomType(model.Name, field.Name) != "" {
+			continue
+		}
+		f := RESTField{Field: field}
+		if field.IsReadOnly {
+			relation, reference := foreignKeyRelation(model, field.Name)
+			if relation == nil {
+				continue
+			}
+			f.Relation, f.Reference = relation, reference
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// RESTForeignKey returns the foreign key of a relation which the REST handlers read to link it, or nil
+func (r *Root) RESTForeignKey(model dmmf.Model, relation dmmf.Field) *RESTField {
+	for _, f := range r.RESTFields(model) {
+		if f.Relation != nil && f.Relation.Name == relation.Name {
+			return &f
+		}
+	}
+	return nil
+}
+
+// RESTCreatable returns whether records of a model can be created with the REST handlers, which requires that the
+// model is not a view and that all fields which are required on create are supported
+func (r *Root) RESTCreatable(model dmmf.Model) bool {
+	if model.IsView {
+		return false
+	}
+	supported := map[types.String]bool{}
+	for _, f := range r.RESTFields(model) {
+		if f.Relation != nil {
+			supported[f.Relation.Name] = true
+		} else {
+			supported[f.Name] = true
+		}
+	}
+	for _, field := range model.Fields {
+		if field.RequiredOnCreate(model.PrimaryKey) && !supported[field.Name] {
+			return false
+		}
+	}
+	return true
+}
+
+// foreignKeyRelation returns the relation whose only foreign key is the given field, and the field of the related model
+// it references
+func foreignKeyRelation(model dmmf.Model, name types.String) (*dmmf.Field, types.String) {
+	for _, field := range model.Fields {
+		if !field.Kind.IsRelation() || len(field.RelationFromFields) != 1 || field.RelationFromFields[0] != name {
+			continue
+		}
+		if len(field.RelationToFields) != 1 {
+			return nil, ""
+		}
+		reference, ok := field.RelationToFields[0].(string)
+		if !ok {
+			return nil, ""
+		}
+		return &field, types.String(reference)
+	}
+	return nil, ""
+}
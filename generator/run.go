@@ -8,6 +8,7 @@ import (
 	"go/format"
 	"os"
 	"path"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -31,7 +32,7 @@ func addDefaults(input *Root) {
 			targets = append(targets, BinaryTarget{Value: t})
 		}
 		input.Generator.BinaryTargets = targets
-		logger.Debug.Printf("overriding binary targets: %+v", targets)
+		logger.Default().Debug("overriding binary targets", "targets", targets)
 	}
 }
 
@@ -43,8 +44,56 @@ func Run(input *Root) error {
 		fmt.Printf("\nwarning: prisma CLI version mismatch detected. CLI version: %s, internal version: %s (%s); please see https://github.com/steebchen/prisma-client-go/issues/1099 for details\n\n", input.Version, binaries.EngineVersion, binaries.PrismaVersion)
 	}
 
+	switch input.Generator.Config.JSONFieldOrder {
+	case "", "struct", "schema", "alphabetical":
+	default:
+		return fmt.Errorf("invalid jsonFieldOrder %q, expected one of struct, schema or alphabetical", input.Generator.Config.JSONFieldOrder)
+	}
+
+	switch input.Generator.Config.JSONTags {
+	case "", "schema", "camelCase", "snake_case":
+	default:
+		return fmt.Errorf("invalid jsonTags %q, expected one of schema, camelCase or snake_case", input.Generator.Config.JSONTags)
+	}
+
+	switch input.Generator.Config.BigIntType {
+	case "", "int64", "big.Int":
+	default:
+		return fmt.Errorf("invalid bigIntType %q, expected one of int64 or big.Int", input.Generator.Config.BigIntType)
+	}
+
+	switch input.Generator.Config.RelationAccessors {
+	case "", "legacy", "error":
+	default:
+		return fmt.Errorf("invalid relationAccessors %q, expected one of legacy or error", input.Generator.Config.RelationAccessors)
+	}
+
+	if err := filterModels(input); err != nil {
+		return err
+	}
+
+	if err := validateComputedFields(input); err != nil {
+		return err
+	}
+
+	if err := validateDefaultFuncFields(input); err != nil {
+		return err
+	}
+
+	if err := validateVersionFields(input); err != nil {
+		return err
+	}
+
+	if err := validateGoTags(input); err != nil {
+		return err
+	}
+
+	if err := resolveGoTypes(input); err != nil {
+		return err
+	}
+
 	if input.Generator.Config.DisableGitignore != "true" && input.Generator.Config.DisableGoBinaries != "true" {
-		logger.Debug.Printf("writing gitignore file")
+		logger.Default().Debug("writing gitignore file")
 		// generate a gitignore into the folder
 		var gitignore = "# gitignore generated by Prisma Client Go. DO NOT EDIT.\n*_gen.go\n"
 		if err := os.MkdirAll(input.Generator.Output.Value, os.ModePerm); err != nil {
@@ -59,6 +108,14 @@ func Run(input *Root) error {
 		return fmt.Errorf("generate client: %w", err)
 	}
 
+	if err := generateGQLGen(input); err != nil {
+		return fmt.Errorf("generate gqlgen bindings: %w", err)
+	}
+
+	if err := generateFactories(input); err != nil {
+		return fmt.Errorf("generate factories: %w", err)
+	}
+
 	if err := generateBinaries(input); err != nil {
 		return fmt.Errorf("generate binaries: %w", err)
 	}
@@ -69,6 +126,143 @@ func Run(input *Root) error {
 //go:embed templates/*.gotpl templates/actions/*.gotpl
 var templateFS embed.FS
 
+// validateComputedFields makes sure that required computed fields have a default, as the query engine would require a
+// value on create otherwise
+func validateComputedFields(input *Root) error {
+	for _, model := range input.DMMF.Datamodel.Models {
+		for _, field := range model.Fields {
+			if field.IsComputed() && field.IsRequired && !field.HasDefaultValue {
+				return fmt.Errorf("computed field %s.%s is required but has no default; add @default(dbgenerated()) or make it optional", model.Name, field.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// validateDefaultFuncFields makes sure that client-side defaults are only used on fields which the client can set
+func validateDefaultFuncFields(input *Root) error {
+	for _, model := range input.DMMF.Datamodel.Models {
+		for _, field := range model.Fields {
+			if !field.HasDefaultFunc() {
+				continue
+			}
+			if field.Kind.IsRelation() || field.IsList || field.IsComputed() || field.IsUpdatedAt || field.GoTypeAnnotation() != "" {
+				return fmt.Errorf("field %s.%s has a @default-func annotation, but only writable non-list scalar fields can have client-side defaults", model.Name, field.Name)
+			}
+			if field.HasDefaultValue {
+				return fmt.Errorf("field %s.%s has both @default and a @default-func annotation; remove one of them", model.Name, field.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// validateVersionFields makes sure that version fields for optimistic locking are required Int fields, at most one per
+// model
+func validateVersionFields(input *Root) error {
+	for _, model := range input.DMMF.Datamodel.Models {
+		var version string
+		for _, field := range model.Fields {
+			if !field.IsVersion() {
+				continue
+			}
+			if field.Type != "Int" || !field.IsRequired || field.IsList || field.IsComputed() || field.GoTypeAnnotation() != "" {
+				return fmt.Errorf("field %s.%s has a @version annotation, but only required Int fields can hold versions", model.Name, field.Name)
+			}
+			if version != "" {
+				return fmt.Errorf("model %s has more than one @version field: %s and %s", model.Name, version, field.Name)
+			}
+			version = field.Name.String()
+		}
+	}
+	return nil
+}
+
+// validateGoTags makes sure that the struct tags of @gotag annotations are well-formed, as they're copied into the
+// generated code, and don't repeat keys. A json tag may only set a name, which must not collide with the JSON name of
+// another field, as results are decoded by these names.
+func validateGoTags(input *Root) error {
+	for _, model := range input.DMMF.Datamodel.Models {
+		names := map[string]string{}
+		for _, field := range model.Fields {
+			name := input.JSONName(field)
+			if other, ok := names[name]; ok {
+				return fmt.Errorf("fields %s.%s and %s.%s have the same JSON name %q", model.Name, other, model.Name, field.Name, name)
+			}
+			names[name] = field.Name.String()
+		}
+
+		for _, field := range model.Fields {
+			tags := field.GoTags()
+			if tags == "" {
+				continue
+			}
+			keys, err := structTagKeys(tags)
+			if err != nil {
+				return fmt.Errorf("field %s.%s has an invalid @gotag annotation: %w", model.Name, field.Name, err)
+			}
+			seen := map[string]bool{}
+			for _, key := range keys {
+				if key == "json" {
+					name, _ := field.GoTagJSONName()
+					if name == "" || name == "-" || strings.Contains(name, ",") {
+						return fmt.Errorf("field %s.%s sets the json tag with @gotag to %q, but it may only set a name; omitempty is generated for optional fields", model.Name, field.Name, name)
+					}
+				}
+				if seen[key] {
+					return fmt.Errorf("field %s.%s sets the %s tag more than once with @gotag", model.Name, field.Name, key)
+				}
+				seen[key] = true
+			}
+		}
+	}
+	return nil
+}
+
+// structTagKeys returns the keys of a struct tag in the conventional format of key:"value" pairs separated by spaces
+func structTagKeys(tag string) ([]string, error) {
+	if strings.Contains(tag, "`") {
+		return nil, fmt.Errorf("struct tags can't contain backquotes")
+	}
+
+	var keys []string
+	for {
+		tag = strings.TrimLeft(tag, " ")
+		if tag == "" {
+			return keys, nil
+		}
+
+		i := 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			return nil, fmt.Errorf("expected key:\"value\" pairs in %q", tag)
+		}
+		key := tag[:i]
+		tag = tag[i+1:]
+
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			return nil, fmt.Errorf("unterminated value of %s", key)
+		}
+		if _, err := strconv.Unquote(tag[:i+1]); err != nil {
+			return nil, fmt.Errorf("invalid value of %s: %w", key, err)
+		}
+		keys = append(keys, key)
+		tag = tag[i+1:]
+		if tag != "" && tag[0] != ' ' {
+			return nil, fmt.Errorf("expected a space after the value of %s", key)
+		}
+	}
+}
+
 func generateClient(input *Root) error {
 	var buf bytes.Buffer
 
@@ -77,14 +271,20 @@ func generateClient(input *Root) error {
 		"_header",
 		"client",
 		"enums",
+		"composite",
 		"errors",
 		"fields",
+		"interfaces",
 		"mock",
 		"models",
 		"query",
 		"actions/actions",
 		"actions/create",
 		"actions/find",
+		"actions/loader",
+		"actions/gqlgen",
+		"actions/rest",
+		"actions/hooks",
 		"actions/transaction",
 		"actions/upsert",
 		"actions/raw",
@@ -99,6 +299,10 @@ func generateClient(input *Root) error {
 		templates = append(templates, t)
 	}
 
+	if header := input.FileHeader(); header != "" {
+		buf.WriteString(header + "\n\n")
+	}
+
 	// Then process all remaining templates
 	for _, tpl := range templates {
 		buf.Write([]byte(fmt.Sprintf("// --- template %s ---\n", tpl.Name())))
@@ -142,14 +346,19 @@ func generateBinaries(input *Root) error {
 	}
 
 	if input.GetEngineType() == "dataproxy" {
-		logger.Debug.Printf("using data proxy; not fetching any engines")
+		logger.Default().Debug("using data proxy; not fetching any engines")
+		return nil
+	}
+
+	if input.IsSideloadBinary() {
+		logger.Default().Debug("sideloading the query engine at runtime; not embedding any engines")
 		return nil
 	}
 
 	var targets []string
 	var isNonLinux bool
 
-	logger.Debug.Printf("defined binary targets: %v", input.Generator.BinaryTargets)
+	logger.Default().Debug("defined binary targets", "targets", input.Generator.BinaryTargets)
 
 	for _, target := range input.Generator.BinaryTargets {
 		targets = append(targets, target.Value)
@@ -164,13 +373,13 @@ func generateBinaries(input *Root) error {
 		targets = add(targets, "native")
 	}
 
-	logger.Debug.Printf("final binary targets: %v", targets)
+	logger.Default().Debug("final binary targets", "targets", targets)
 
 	// TODO refactor
 	for _, name := range targets {
 		if name == "native" {
 			name = platform.BinaryPlatformNameStatic()
-			logger.Debug.Printf("swapping 'native' binary target with '%s'", name)
+			logger.Default().Debug("swapping native binary target", "target", name)
 		}
 
 		name = TransformBinaryTarget(name)
@@ -181,14 +390,14 @@ func generateBinaries(input *Root) error {
 		}
 	}
 
-	if err := generateQueryEngineFiles(targets, input.Generator.Config.Package.String(), input.Generator.Output.Value); err != nil {
+	if err := generateQueryEngineFiles(targets, input.Generator.Config.Package.String(), input.Generator.Output.Value, input.FileHeader()); err != nil {
 		return fmt.Errorf("could not write template data: %w", err)
 	}
 
 	return nil
 }
 
-func generateQueryEngineFiles(binaryTargets []string, pkg, outputDir string) error {
+func generateQueryEngineFiles(binaryTargets []string, pkg, outputDir, header string) error {
 	for _, name := range binaryTargets {
 		if name == "native" {
 			name = platform.BinaryPlatformNameStatic()
@@ -204,11 +413,11 @@ func generateQueryEngineFiles(binaryTargets []string, pkg, outputDir string) err
 		to := path.Join(outputDir, filename)
 
 		// TODO check if already exists, but make sure version matches
-		if err := bindata.WriteFile(name, pkg, enginePath, to, info); err != nil {
+		if err := bindata.WriteFile(name, pkg, enginePath, to, header, info); err != nil {
 			return fmt.Errorf("generate write go file: %w", err)
 		}
 
-		logger.Debug.Printf("write go file at %s", filename)
+		logger.Default().Debug("write go file", "path", filename)
 	}
 
 	return nil
@@ -227,7 +436,7 @@ func TransformBinaryTarget(name string) string {
 	// TODO this is a temp fix as the exact alpine libraries are not working
 	if name == "linux" || strings.Contains(name, "musl") {
 		name = "linux-static-" + platform.Arch()
-		logger.Debug.Printf("overriding binary name with '%s' due to linux or musl", name)
+		logger.Default().Debug("overriding binary name due to linux or musl", "name", name)
 	}
 	return name
 }
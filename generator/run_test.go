@@ -0,0 +1,120 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/steebchen/prisma-client-go/generator/ast/dmmf"
+	"github.com/steebchen/prisma-client-go/generator/types"
+)
+
+func rootWithModels(models ...dmmf.Model) *Root {
+	var root Root
+	root.DMMF.Datamodel.Models = models
+	return &root
+}
+
+func TestValidateVersionFields(t *testing.T) {
+	id := dmmf.Field{Kind: dmmf.FieldKindScalar, Name: "id", Type: "String", IsRequired: true, IsID: true}
+	version := func(name string, typ string, required bool) dmmf.Field {
+		return dmmf.Field{Kind: dmmf.FieldKindScalar, Name: types.String(name), Type: types.Type(typ), IsRequired: required, Documentation: "@version"}
+	}
+
+	tests := []struct {
+		name   string
+		fields []dmmf.Field
+		err    string
+	}{{
+		name:   "valid",
+		fields: []dmmf.Field{id, version("version", "Int", true)},
+	}, {
+		name:   "no version field",
+		fields: []dmmf.Field{id},
+	}, {
+		name:   "not an int",
+		fields: []dmmf.Field{id, version("version", "String", true)},
+		err:    "field Document.version has a @version annotation, but only required Int fields can hold versions",
+	}, {
+		name:   "optional",
+		fields: []dmmf.Field{id, version("version", "Int", false)},
+		err:    "field Document.version has a @version annotation, but only required Int fields can hold versions",
+	}, {
+		name:   "multiple",
+		fields: []dmmf.Field{id, version("a", "Int", true), version("b", "Int", true)},
+		err:    "model Document has more than one @version field: a and b",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateVersionFields(rootWithModels(dmmf.Model{Name: "Document", Fields: tt.fields}))
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %s", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("expected error %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestValidateGoTags(t *testing.T) {
+	field := func(name string, documentation string) dmmf.Field {
+		return dmmf.Field{Kind: dmmf.FieldKindScalar, Name: types.String(name), Type: "String", IsRequired: true, Documentation: documentation}
+	}
+
+	tests := []struct {
+		name     string
+		jsonTags string
+		fields   []dmmf.Field
+		err      string
+	}{{
+		name:   "valid",
+		fields: []dmmf.Field{field("email", `@gotag: validate:"email" bson:"email"`)},
+	}, {
+		name:   "json name",
+		fields: []dmmf.Field{field("email", `@gotag: json:"emailAddress" validate:"email"`)},
+	}, {
+		name:   "malformed",
+		fields: []dmmf.Field{field("email", `@gotag: validate:email`)},
+		err:    "field User.email has an invalid @gotag annotation",
+	}, {
+		name:   "repeated key",
+		fields: []dmmf.Field{field("email", "@gotag: validate:\"email\"\n@gotag: validate:\"required\"")},
+		err:    "field User.email sets the validate tag more than once with @gotag",
+	}, {
+		name:   "json options",
+		fields: []dmmf.Field{field("email", `@gotag: json:"email,omitempty"`)},
+		err:    `field User.email sets the json tag with @gotag to "email,omitempty", but it may only set a name`,
+	}, {
+		name:   "json ignored",
+		fields: []dmmf.Field{field("email", `@gotag: json:"-"`)},
+		err:    `field User.email sets the json tag with @gotag to "-", but it may only set a name`,
+	}, {
+		name:   "json name collision",
+		fields: []dmmf.Field{field("email", ""), field("mail", `@gotag: json:"email"`)},
+		err:    `fields User.email and User.mail have the same JSON name "email"`,
+	}, {
+		name:     "json name collision with jsonTags",
+		jsonTags: "snake_case",
+		fields:   []dmmf.Field{field("createdAt", ""), field("created_at", "")},
+		err:      `fields User.createdAt and User.created_at have the same JSON name "created_at"`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := rootWithModels(dmmf.Model{Name: "User", Fields: tt.fields})
+			root.Generator.Config.JSONTags = tt.jsonTags
+			err := validateGoTags(root)
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %s", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("expected error %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -4,15 +4,276 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"regexp"
+	"slices"
+	"strings"
 
+	"github.com/steebchen/prisma-client-go/generator/ast/dmmf"
 	"github.com/steebchen/prisma-client-go/generator/ast/transform"
 )
 
 // Transform builds the AST from the flat DMMF so it can be used properly in templates
 func Transform(input *Root) {
+	input.DMMF.Datamodel.MarkCompositeFields()
+	input.DMMF.Datamodel.MarkViews(viewNames(input.Datamodel))
 	input.AST = transform.New(&input.DMMF)
 	if os.Getenv("DEBUG") != "" {
 		d, _ := json.MarshalIndent(input.AST, "", "  ")
 		fmt.Printf("AST: %s\n", string(d))
 	}
 }
+
+var viewBlock = regexp.MustCompile(`(?m)^\s*view\s+(\w+)\s*\{`)
+
+// viewNames returns the names of the views declared in a Prisma schema
+func viewNames(schema string) []string {
+	var names []string
+	for _, match := range viewBlock.FindAllStringSubmatch(schema, -1) {
+		names = append(names, match[1])
+	}
+	return names
+}
+
+// filterModels drops the models which aren't selected by the includeModels and excludeModels options, so that no code
+// is generated for them, along with their types in the schema, and rebuilds the AST. Relation fields of the remaining
+// models which point to dropped models are dropped as well, and their foreign key fields can be set directly instead.
+func filterModels(input *Root) error {
+	include := input.Generator.Config.IncludeModels
+	exclude := input.Generator.Config.ExcludeModels
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil
+	}
+
+	known := map[string]bool{}
+	for _, model := range input.DMMF.Datamodel.Models {
+		known[model.Name.String()] = true
+	}
+	for _, name := range include {
+		if !known[name] {
+			return fmt.Errorf("includeModels contains %q, which is not a model of the schema", name)
+		}
+	}
+	for _, name := range exclude {
+		if !known[name] {
+			return fmt.Errorf("excludeModels contains %q, which is not a model of the schema", name)
+		}
+	}
+
+	keep := map[string]bool{}
+	for name := range known {
+		keep[name] = len(include) == 0
+	}
+	for _, name := range include {
+		keep[name] = true
+	}
+	for _, name := range exclude {
+		keep[name] = false
+	}
+
+	var models []dmmf.Model
+	for _, model := range input.DMMF.Datamodel.Models {
+		if !keep[model.Name.String()] {
+			continue
+		}
+
+		var fields []dmmf.Field
+		settable := map[string]bool{}
+		for _, field := range model.Fields {
+			if field.Kind.IsRelation() && !keep[field.Type.String()] {
+				for _, f := range field.RelationFromFields {
+					settable[f.String()] = true
+				}
+				continue
+			}
+			fields = append(fields, field)
+		}
+		for i, field := range fields {
+			if settable[field.Name.String()] {
+				fields[i].IsReadOnly = false
+			}
+		}
+
+		model.Fields = fields
+		models = append(models, model)
+	}
+	if len(models) == 0 {
+		return fmt.Errorf("includeModels and excludeModels leave no models to generate")
+	}
+
+	filterSchema(&input.DMMF, keep)
+
+	input.DMMF.Datamodel.Models = models
+	input.AST = transform.New(&input.DMMF)
+	return nil
+}
+
+// filterSchema drops the input, output and enum types of the schema which belong to dropped models, as well as fields
+// and arguments of the remaining types which refer to them, so that the AST is built from the selected models only
+func filterSchema(document *dmmf.Document, keep map[string]bool) {
+	var models []string
+	for name := range keep {
+		models = append(models, name)
+	}
+
+	dropped := func(kind schemaTypeKind, name string) bool {
+		owner := schemaTypeOwner(kind, name, models)
+		return owner != "" && !keep[owner]
+	}
+	droppedInput := func(input dmmf.SchemaInputType) bool {
+		switch input.Location {
+		case "inputObjectTypes":
+			return dropped(schemaInputType, input.Type.String())
+		case "enumTypes":
+			return dropped(schemaEnumType, input.Type.String())
+		}
+		return false
+	}
+
+	var inputs []dmmf.CoreType
+	for _, t := range document.Schema.InputObjectTypes.Prisma {
+		if dropped(schemaInputType, t.Name.String()) {
+			continue
+		}
+		t.Fields = filterInputFields(t.Fields, droppedInput)
+		inputs = append(inputs, t)
+	}
+	document.Schema.InputObjectTypes.Prisma = inputs
+
+	var outputs []dmmf.OutputType
+	for _, t := range document.Schema.OutputObjectTypes.Prisma {
+		if dropped(schemaOutputType, t.Name.String()) {
+			continue
+		}
+		var fields []dmmf.SchemaField
+		for _, field := range t.Fields {
+			if field.OutputType.Kind == dmmf.FieldKindObject && dropped(schemaOutputType, field.OutputType.Type.String()) {
+				continue
+			}
+			field.Args = filterInputFields(field.Args, droppedInput)
+			fields = append(fields, field)
+		}
+		t.Fields = fields
+		outputs = append(outputs, t)
+	}
+	document.Schema.OutputObjectTypes.Prisma = outputs
+
+	var enums []dmmf.SchemaEnum
+	for _, enum := range document.Schema.EnumTypes.Prisma {
+		if !dropped(schemaEnumType, enum.Name.String()) {
+			enums = append(enums, enum)
+		}
+	}
+	document.Schema.EnumTypes.Prisma = enums
+
+	var operations []dmmf.ModelOperation
+	for _, operation := range document.Mappings.ModelOperations {
+		if keep[operation.Model.String()] {
+			operations = append(operations, operation)
+		}
+	}
+	document.Mappings.ModelOperations = operations
+}
+
+// filterInputFields drops the input types of fields which refer to dropped types, and fields which have no input type
+// left
+func filterInputFields(fields []dmmf.OuterInputType, dropped func(input dmmf.SchemaInputType) bool) []dmmf.OuterInputType {
+	var result []dmmf.OuterInputType
+	for _, field := range fields {
+		var inputs []dmmf.SchemaInputType
+		for _, input := range field.InputTypes {
+			if !dropped(input) {
+				inputs = append(inputs, input)
+			}
+		}
+		if len(field.InputTypes) > 0 && len(inputs) == 0 {
+			continue
+		}
+		field.InputTypes = inputs
+		result = append(result, field)
+	}
+	return result
+}
+
+type schemaTypeKind int
+
+const (
+	schemaInputType schemaTypeKind = iota
+	schemaOutputType
+	schemaEnumType
+)
+
+// schemaTypeOwner returns the model a type of the schema belongs to, or an empty string for types which don't belong
+// to a model, such as StringFilter or SortOrder. Types are matched by the names Prisma derives from model names, e.g.
+// UserWhereInput, UserCreateWithoutPostsInput, AggregateUser or UserScalarFieldEnum, so that a model Date doesn't
+// own DateTimeFilter. If multiple models match, the longest name wins.
+func schemaTypeOwner(kind schemaTypeKind, name string, models []string) string {
+	owner := ""
+	for _, model := range models {
+		if len(model) <= len(owner) {
+			continue
+		}
+		switch kind {
+		case schemaInputType:
+			rest, ok := strings.CutPrefix(name, model)
+			if ok && (hasAnyPrefix(rest, modelInputPrefixes) || strings.HasSuffix(rest, "CompoundUniqueInput")) {
+				owner = model
+			}
+		case schemaOutputType:
+			if rest, ok := strings.CutPrefix(name, model); ok && slices.Contains(modelOutputSuffixes, rest) {
+				owner = model
+			}
+			if name == "Aggregate"+model || name == "CreateMany"+model+"AndReturnOutputType" || name == "UpdateMany"+model+"AndReturnOutputType" {
+				owner = model
+			}
+		case schemaEnumType:
+			if name == model+"ScalarFieldEnum" || name == model+"OrderByRelevanceFieldEnum" {
+				owner = model
+			}
+		}
+	}
+	return owner
+}
+
+// modelInputPrefixes are the beginnings of the input types of a model after its name, e.g. WhereInput of
+// UserWhereInput
+var modelInputPrefixes = []string{
+	"WhereInput",
+	"WhereUniqueInput",
+	"ScalarWhere",
+	"OrderBy",
+	"Create",
+	"Update",
+	"Upsert",
+	"Unchecked",
+	"RelationFilter",
+	"NullableRelationFilter",
+	"ScalarRelationFilter",
+	"NullableScalarRelationFilter",
+	"ListRelationFilter",
+	"Count",
+	"Avg",
+	"Sum",
+	"Min",
+	"Max",
+}
+
+// modelOutputSuffixes are the output types of a model after its name, where the model itself has no suffix
+var modelOutputSuffixes = []string{
+	"",
+	"CountOutputType",
+	"GroupByOutputType",
+	"CountAggregateOutputType",
+	"AvgAggregateOutputType",
+	"SumAggregateOutputType",
+	"MinAggregateOutputType",
+	"MaxAggregateOutputType",
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
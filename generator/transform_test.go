@@ -0,0 +1,282 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/steebchen/prisma-client-go/generator/ast/dmmf"
+	"github.com/steebchen/prisma-client-go/generator/types"
+)
+
+// blogDocument returns a DMMF document with a User model and a Post model with a relation to User, along with a
+// subset of the input, output and enum types Prisma derives from them
+func blogDocument() dmmf.Document {
+	str := dmmf.Field{Kind: dmmf.FieldKindScalar, Type: "String", IsRequired: true}
+	field := func(f dmmf.Field, name string) dmmf.Field {
+		f.Name = types.String(name)
+		return f
+	}
+	authorID := field(str, "authorID")
+	authorID.IsReadOnly = true
+
+	input := func(name string, location string) dmmf.SchemaInputType {
+		return dmmf.SchemaInputType{Type: types.Type(name), Location: location}
+	}
+	fields := func(fields ...dmmf.OuterInputType) []dmmf.OuterInputType {
+		return fields
+	}
+	outer := func(name string, inputs ...dmmf.SchemaInputType) dmmf.OuterInputType {
+		return dmmf.OuterInputType{Name: types.String(name), InputTypes: inputs}
+	}
+
+	var document dmmf.Document
+	document.Datamodel.Models = []dmmf.Model{{
+		Name: "User",
+		Fields: []dmmf.Field{
+			field(str, "id"),
+			{Kind: dmmf.FieldKindObject, Name: "posts", Type: "Post", IsList: true, RelationName: "PostToUser"},
+		},
+	}, {
+		Name: "Post",
+		Fields: []dmmf.Field{
+			field(str, "id"),
+			{Kind: dmmf.FieldKindObject, Name: "author", Type: "User", IsRequired: true, RelationName: "PostToUser", RelationFromFields: []types.String{"authorID"}},
+			authorID,
+		},
+	}, {
+		Name:   "Date",
+		Fields: []dmmf.Field{field(str, "id")},
+	}}
+	document.Schema.InputObjectTypes.Prisma = []dmmf.CoreType{
+		{Name: "StringFilter", Fields: fields(outer("equals", input("String", "scalar")))},
+		{Name: "DateTimeFilter", Fields: fields(outer("equals", input("DateTime", "scalar")))},
+		{Name: "UserWhereInput", Fields: fields(outer("id", input("StringFilter", "inputObjectTypes")))},
+		{Name: "UserScalarRelationFilter", Fields: fields(outer("is", input("UserWhereInput", "inputObjectTypes")))},
+		{Name: "UserCreateNestedOneWithoutPostsInput"},
+		{Name: "PostWhereInput", Fields: fields(
+			outer("id", input("StringFilter", "inputObjectTypes")),
+			outer("authorID", input("StringFilter", "inputObjectTypes")),
+			outer("author", input("UserScalarRelationFilter", "inputObjectTypes"), input("UserWhereInput", "inputObjectTypes")),
+		)},
+		{Name: "PostCreateInput", Fields: fields(
+			outer("id", input("String", "scalar")),
+			outer("author", input("UserCreateNestedOneWithoutPostsInput", "inputObjectTypes")),
+		)},
+		{Name: "PostUncheckedCreateInput", Fields: fields(
+			outer("id", input("String", "scalar")),
+			outer("authorID", input("String", "scalar")),
+		)},
+	}
+	document.Schema.OutputObjectTypes.Prisma = []dmmf.OutputType{{
+		Name: "Query",
+		Fields: []dmmf.SchemaField{{
+			Name:       "findManyUser",
+			OutputType: dmmf.SchemaOutputType{Type: "User", Kind: dmmf.FieldKindObject, IsList: true},
+			Args:       fields(outer("distinct", input("UserScalarFieldEnum", "enumTypes"))),
+		}, {
+			Name:       "findManyPost",
+			OutputType: dmmf.SchemaOutputType{Type: "Post", Kind: dmmf.FieldKindObject, IsList: true},
+			Args:       fields(outer("distinct", input("PostScalarFieldEnum", "enumTypes"))),
+		}, {
+			Name:       "aggregateUser",
+			OutputType: dmmf.SchemaOutputType{Type: "AggregateUser", Kind: dmmf.FieldKindObject},
+		}},
+	}, {
+		Name: "User",
+	}, {
+		Name: "AggregateUser",
+	}, {
+		Name: "Post",
+		Fields: []dmmf.SchemaField{{
+			Name:       "author",
+			OutputType: dmmf.SchemaOutputType{Type: "User", Kind: dmmf.FieldKindObject},
+		}, {
+			Name:       "authorID",
+			OutputType: dmmf.SchemaOutputType{Type: "String", Kind: dmmf.FieldKindScalar},
+		}},
+	}}
+	document.Schema.EnumTypes.Prisma = []dmmf.SchemaEnum{
+		{Name: "SortOrder"},
+		{Name: "UserScalarFieldEnum"},
+		{Name: "PostScalarFieldEnum"},
+	}
+	document.Mappings.ModelOperations = []dmmf.ModelOperation{{Model: "User"}, {Model: "Post"}, {Model: "Date"}}
+	return document
+}
+
+func filterBlog(t *testing.T, include []string, exclude []string) (*Root, error) {
+	t.Helper()
+	root := &Root{DMMF: blogDocument()}
+	root.Generator.Config.IncludeModels = include
+	root.Generator.Config.ExcludeModels = exclude
+	return root, filterModels(root)
+}
+
+func TestFilterModels_errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		err     string
+	}{{
+		name:    "unknown included model",
+		include: []string{"Post", "Comment"},
+		err:     `includeModels contains "Comment", which is not a model of the schema`,
+	}, {
+		name:    "unknown excluded model",
+		exclude: []string{"Comment"},
+		err:     `excludeModels contains "Comment", which is not a model of the schema`,
+	}, {
+		name:    "all models excluded",
+		exclude: []string{"User", "Post", "Date"},
+		err:     "includeModels and excludeModels leave no models to generate",
+	}, {
+		name:    "included models excluded",
+		include: []string{"Post"},
+		exclude: []string{"Post"},
+		err:     "includeModels and excludeModels leave no models to generate",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := filterBlog(t, tt.include, tt.exclude)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("expected error %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestFilterModels(t *testing.T) {
+	root, err := filterBlog(t, nil, []string{"User"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var models []string
+	for _, model := range root.DMMF.Datamodel.Models {
+		models = append(models, model.Name.String())
+	}
+	assertStrings(t, []string{"Post", "Date"}, models)
+
+	post := root.DMMF.Datamodel.Models[0]
+	var fields []string
+	for _, field := range post.Fields {
+		fields = append(fields, field.Name.String())
+		if field.Name == "authorID" && field.IsReadOnly {
+			t.Errorf("expected the foreign key of the dropped relation to be writable")
+		}
+	}
+	assertStrings(t, []string{"id", "authorID"}, fields)
+
+	var inputs []string
+	for _, input := range root.DMMF.Schema.InputObjectTypes.Prisma {
+		var names []string
+		for _, field := range input.Fields {
+			names = append(names, field.Name.String())
+		}
+		inputs = append(inputs, input.Name.String()+"("+strings.Join(names, ",")+")")
+	}
+	assertStrings(t, []string{
+		"StringFilter(equals)",
+		"DateTimeFilter(equals)",
+		"PostWhereInput(id,authorID)",
+		"PostCreateInput(id)",
+		"PostUncheckedCreateInput(id,authorID)",
+	}, inputs)
+
+	var outputs []string
+	for _, output := range root.DMMF.Schema.OutputObjectTypes.Prisma {
+		var names []string
+		for _, field := range output.Fields {
+			names = append(names, field.Name.String())
+		}
+		outputs = append(outputs, output.Name.String()+"("+strings.Join(names, ",")+")")
+	}
+	assertStrings(t, []string{"Query(findManyPost)", "Post(authorID)"}, outputs)
+
+	var enums []string
+	for _, enum := range root.DMMF.Schema.EnumTypes.Prisma {
+		enums = append(enums, enum.Name.String())
+	}
+	assertStrings(t, []string{"SortOrder", "PostScalarFieldEnum"}, enums)
+
+	var operations []string
+	for _, operation := range root.DMMF.Mappings.ModelOperations {
+		operations = append(operations, operation.Model.String())
+	}
+	assertStrings(t, []string{"Post", "Date"}, operations)
+
+	for _, model := range root.AST.Models {
+		if model.Name == "User" {
+			t.Errorf("expected no AST model for User")
+		}
+	}
+}
+
+func TestFilterModels_include(t *testing.T) {
+	root, err := filterBlog(t, []string{"User"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var models []string
+	for _, model := range root.DMMF.Datamodel.Models {
+		models = append(models, model.Name.String())
+	}
+	assertStrings(t, []string{"User"}, models)
+
+	// the relation to Post is dropped, and types which don't belong to a model are kept, even if their name starts
+	// with a dropped model such as Date
+	var fields []string
+	for _, field := range root.DMMF.Datamodel.Models[0].Fields {
+		fields = append(fields, field.Name.String())
+	}
+	assertStrings(t, []string{"id"}, fields)
+
+	var inputs []string
+	for _, input := range root.DMMF.Schema.InputObjectTypes.Prisma {
+		inputs = append(inputs, input.Name.String())
+	}
+	assertStrings(t, []string{"StringFilter", "DateTimeFilter", "UserWhereInput", "UserScalarRelationFilter", "UserCreateNestedOneWithoutPostsInput"}, inputs)
+}
+
+func TestSchemaTypeOwner(t *testing.T) {
+	models := []string{"User", "UserProfile", "Date", "Sort"}
+	tests := []struct {
+		kind schemaTypeKind
+		name string
+		want string
+	}{
+		{schemaInputType, "UserWhereInput", "User"},
+		{schemaInputType, "UserCreateWithoutPostsInput", "User"},
+		{schemaInputType, "UserUncheckedUpdateManyInput", "User"},
+		{schemaInputType, "UserEmailNameCompoundUniqueInput", "User"},
+		{schemaInputType, "UserProfileWhereInput", "UserProfile"},
+		{schemaInputType, "UsernameFilter", ""},
+		{schemaInputType, "DateTimeFilter", ""},
+		{schemaInputType, "SortOrderInput", ""},
+		{schemaInputType, "StringFilter", ""},
+		{schemaOutputType, "User", "User"},
+		{schemaOutputType, "AggregateUser", "User"},
+		{schemaOutputType, "UserGroupByOutputType", "User"},
+		{schemaOutputType, "CreateManyUserProfileAndReturnOutputType", "UserProfile"},
+		{schemaOutputType, "Query", ""},
+		{schemaEnumType, "UserScalarFieldEnum", "User"},
+		{schemaEnumType, "UserOrderByRelevanceFieldEnum", "User"},
+		{schemaEnumType, "SortOrder", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := schemaTypeOwner(tt.kind, tt.name, models); got != tt.want {
+				t.Errorf("schemaTypeOwner(%s) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func assertStrings(t *testing.T, want []string, got []string) {
+	t.Helper()
+	if strings.Join(want, " ") != strings.Join(got, " ") {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
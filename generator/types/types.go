@@ -2,6 +2,7 @@ package types
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/steebchen/prisma-client-go/helpers/gocase"
 	"github.com/steebchen/prisma-client-go/helpers/strcase"
@@ -37,6 +38,22 @@ func (s String) Tag(isRequired bool) string {
 	return fmt.Sprintf("`json:\"%s\"`", s)
 }
 
+// GoCasePlural transforms strings into Go-style casing and pluralizes them, e.g. `category` into `Categories`.
+func (s String) GoCasePlural() string {
+	str := s.GoCase()
+	lower := strings.ToLower(str)
+	switch {
+	case str == "":
+		return str
+	case strings.HasSuffix(lower, "y") && len(lower) > 1 && !strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return str[:len(str)-1] + "ies"
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "z"),
+		strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return str + "es"
+	}
+	return str + "s"
+}
+
 // PrismaGoCase transforms `relevance` into `Relevance_`
 func (s String) PrismaGoCase() string {
 	return strcase.ToUpperCamel(string(s)) + "_"
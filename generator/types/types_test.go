@@ -30,3 +30,35 @@ func TestString_GoCase(t *testing.T) {
 		})
 	}
 }
+
+func TestString_GoCasePlural(t *testing.T) {
+	tests := []struct {
+		have String
+		want string
+	}{{
+		have: "user",
+		want: "Users",
+	}, {
+		have: "Category",
+		want: "Categories",
+	}, {
+		have: "day",
+		want: "Days",
+	}, {
+		have: "address",
+		want: "Addresses",
+	}, {
+		have: "batch",
+		want: "Batches",
+	}, {
+		have: "APIKey",
+		want: "APIKeys",
+	}}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s -> %s", tt.have, tt.want), func(t *testing.T) {
+			if got := tt.have.GoCasePlural(); got != tt.want {
+				t.Errorf("GoCasePlural() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package strcase
+
+import (
+	"strings"
+)
+
+// ToSnake converts a string to snake_case, keeping acronyms together, e.g. userID into user_id
+func ToSnake(s string) string {
+	s = strings.TrimSpace(s)
+
+	n := strings.Builder{}
+	n.Grow(len(s) + 4)
+	for i, v := range []byte(s) {
+		vIsCap := v >= 'A' && v <= 'Z'
+		if v == '-' || v == ' ' || v == '.' {
+			v = '_'
+		}
+		if vIsCap && i > 0 {
+			prev := s[i-1]
+			prevIsLow := prev >= 'a' && prev <= 'z' || prev >= '0' && prev <= '9'
+			prevIsCap := prev >= 'A' && prev <= 'Z'
+			nextIsLow := i+1 < len(s) && s[i+1] >= 'a' && s[i+1] <= 'z'
+			if prevIsLow || prevIsCap && nextIsLow {
+				n.WriteByte('_')
+			}
+		}
+		if vIsCap {
+			v += 'a' - 'A'
+		}
+		n.WriteByte(v)
+	}
+	return n.String()
+}
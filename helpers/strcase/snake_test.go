@@ -0,0 +1,28 @@
+package strcase
+
+import (
+	"testing"
+)
+
+func TestToSnake(t *testing.T) {
+	cases := [][]string{
+		{"testCase", "test_case"},
+		{"TestCase", "test_case"},
+		{"test_case", "test_case"},
+		{"test", "test"},
+		{"userID", "user_id"},
+		{"userIDValue", "user_id_value"},
+		{"ID", "id"},
+		{"odd-fix", "odd_fix"},
+		{"numbers2And55", "numbers2_and55"},
+		{"", ""},
+	}
+	for _, i := range cases {
+		in := i[0]
+		out := i[1]
+		result := ToSnake(in)
+		if result != out {
+			t.Errorf("%q (%q != %q)", in, result, out)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+// Package introspect reads the schema of a live database, like `prisma db pull`, without requiring Node or the
+// Prisma CLI.
+package introspect
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/steebchen/prisma-client-go/binaries"
+	"github.com/steebchen/prisma-client-go/engine/schemaengine"
+	"github.com/steebchen/prisma-client-go/generator/ast/dmmf"
+	"github.com/steebchen/prisma-client-go/logger"
+)
+
+const urlEnv = "PRISMA_INTROSPECT_DATABASE_URL"
+
+type introspectResult struct {
+	Schema struct {
+		Files []struct {
+			Path    string `json:"path"`
+			Content string `json:"content"`
+		} `json:"files"`
+	} `json:"schema"`
+	Warnings *string `json:"warnings"`
+}
+
+// Run introspects the database at databaseURL and returns the DMMF of the resulting Prisma schema,
+// e.g. to diff a live database against a checked-in schema.
+func Run(ctx context.Context, databaseURL string) (*dmmf.Document, error) {
+	schema, err := Schema(ctx, databaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	document, err := Parse(ctx, schema)
+	if err != nil {
+		return nil, fmt.Errorf("parse introspected schema: %w", err)
+	}
+
+	return document, nil
+}
+
+// Schema introspects the database at databaseURL and returns the resulting Prisma schema
+func Schema(ctx context.Context, databaseURL string) (string, error) {
+	provider, err := providerFromURL(databaseURL)
+	if err != nil {
+		return "", err
+	}
+
+	// sqlite paths are relative to the schema, which lives in a temp dir
+	if provider == "sqlite" {
+		if file := strings.TrimPrefix(databaseURL, "file:"); !filepath.IsAbs(file) {
+			abs, err := filepath.Abs(file)
+			if err != nil {
+				return "", fmt.Errorf("sqlite path: %w", err)
+			}
+			databaseURL = "file:" + abs
+		}
+	}
+
+	dir, err := os.MkdirTemp("", "prisma-introspect-*")
+	if err != nil {
+		return "", fmt.Errorf("create temp dir: %w", err)
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer os.RemoveAll(dir)
+
+	schemaPath := filepath.Join(dir, "schema.prisma")
+	content := datasource(provider)
+	if err := os.WriteFile(schemaPath, []byte(content), 0644); err != nil {
+		return "", fmt.Errorf("write schema: %w", err)
+	}
+
+	e, err := schemaengine.Start(ctx, schemaPath, []string{urlEnv + "=" + databaseURL})
+	if err != nil {
+		return "", err
+	}
+
+	var result introspectResult
+	err = e.Call("introspect", map[string]interface{}{
+		"schema": map[string]interface{}{
+			"files": []map[string]string{{
+				"path":    schemaPath,
+				"content": content,
+			}},
+		},
+		"baseDirectoryPath":  dir,
+		"force":              false,
+		"compositeTypeDepth": -1,
+	}, &result)
+
+	if closeErr := e.Close(); err == nil && closeErr != nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("introspect: %w", err)
+	}
+
+	if result.Warnings != nil {
+		logger.Debug.Printf("introspection warnings: %s", *result.Warnings)
+	}
+
+	var files []string
+	for _, f := range result.Schema.Files {
+		files = append(files, f.Content)
+	}
+
+	return strings.Join(files, "\n"), nil
+}
+
+// Parse uses the query engine to build the DMMF of a Prisma schema
+func Parse(ctx context.Context, schema string) (*dmmf.Document, error) {
+	file, err := binaries.EnsureEngine(binaries.QueryEngine)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.CommandContext(ctx, file, "cli", "dmmf") //nolint:gosec
+	cmd.Env = append(os.Environ(), "PRISMA_DML="+schema, urlEnv+"=")
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("run query engine: %w: %s", err, stderr.String())
+	}
+
+	var document dmmf.Document
+	if err := json.Unmarshal(out, &document); err != nil {
+		return nil, fmt.Errorf("unmarshal dmmf: %w", err)
+	}
+
+	return &document, nil
+}
+
+func datasource(provider string) string {
+	return fmt.Sprintf("datasource db {\n  provider = %q\n  url      = env(%q)\n}\n", provider, urlEnv)
+}
+
+// providerFromURL returns the Prisma datasource provider for a connection string
+func providerFromURL(url string) (string, error) {
+	scheme, _, ok := strings.Cut(url, ":")
+	if !ok {
+		return "", fmt.Errorf("invalid database url")
+	}
+
+	switch scheme {
+	case "postgres", "postgresql":
+		return "postgresql", nil
+	case "mysql":
+		return "mysql", nil
+	case "sqlserver":
+		return "sqlserver", nil
+	case "file":
+		return "sqlite", nil
+	case "mongodb", "mongodb+srv":
+		return "mongodb", nil
+	default:
+		return "", fmt.Errorf("unsupported database url scheme %q", scheme)
+	}
+}
@@ -1,28 +1,78 @@
 package logger
 
 import (
-	"io"
+	"context"
 	"log"
+	"log/slog"
 	"os"
+	"strings"
+	"sync/atomic"
 )
 
-const flag = log.Ldate | log.Lmicroseconds
+// v sets the log level, e.g. debug, info, warn or error. Any other non-empty value enables debug logs.
+var v = os.Getenv("PRISMA_CLIENT_GO_LOG")
 
-// TODO add log levels
+var level = parseLevel(v)
 
-var v = os.Getenv("PRISMA_CLIENT_GO_LOG")
-var Enabled = v != ""
+// Enabled is true if debug logs are enabled
+var Enabled = level <= slog.LevelDebug
 
+// Deprecated: use Default instead
 var Debug *log.Logger
+
+// Deprecated: use Default instead
 var Info *log.Logger
 
+var defaultLogger atomic.Pointer[slog.Logger]
+
 func init() {
-	discard := log.New(io.Discard, "", 0)
+	defaultLogger.Store(slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: level,
+	})).With("logger", "prisma-client-go"))
+
+	Debug = slog.NewLogLogger(defaultHandler{}, slog.LevelDebug)
+	Info = slog.NewLogLogger(defaultHandler{}, slog.LevelInfo)
+}
+
+// Default returns the logger which is used when no logger was passed to a client or the generator
+func Default() *slog.Logger {
+	return defaultLogger.Load()
+}
+
+// SetDefault replaces the default logger, e.g. to change the output of the generator
+func SetDefault(l *slog.Logger) {
+	defaultLogger.Store(l)
+}
 
-	Debug = discard
-	if Enabled {
-		Debug = log.New(os.Stdout, "[prisma-client-go] DEBUG: ", flag)
+func parseLevel(v string) slog.Level {
+	switch strings.ToLower(v) {
+	case "":
+		return slog.LevelInfo
+	case "info":
+		return slog.LevelInfo
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
 	}
+	return slog.LevelDebug
+}
+
+// defaultHandler sends records to the handler of the current default logger
+type defaultHandler struct{}
+
+func (defaultHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return Default().Handler().Enabled(ctx, level)
+}
+
+func (defaultHandler) Handle(ctx context.Context, r slog.Record) error {
+	return Default().Handler().Handle(ctx, r)
+}
+
+func (defaultHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return Default().Handler().WithAttrs(attrs)
+}
 
-	Info = log.New(os.Stdout, "[prisma-client-go] INFO: ", flag)
+func (defaultHandler) WithGroup(name string) slog.Handler {
+	return Default().Handler().WithGroup(name)
 }
@@ -0,0 +1,17 @@
+package logger
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLevel(t *testing.T) {
+	assert.Equal(t, slog.LevelInfo, parseLevel(""))
+	assert.Equal(t, slog.LevelWarn, parseLevel("WARN"))
+	assert.Equal(t, slog.LevelError, parseLevel("error"))
+	assert.Equal(t, slog.LevelDebug, parseLevel("debug"))
+	// any other value enables debug logs, as PRISMA_CLIENT_GO_LOG=1 did before log levels existed
+	assert.Equal(t, slog.LevelDebug, parseLevel("1"))
+}
@@ -23,6 +23,13 @@ func main() {
 			}
 			os.Exit(0)
 			return
+		case "cache":
+			if err := cache(args[1:]); err != nil {
+				log.Printf("error: %s", err)
+				os.Exit(1)
+			}
+			os.Exit(0)
+			return
 		case "init":
 			// override default init flags
 			args = append(args, "--generator-provider", "go run github.com/steebchen/prisma-client-go")
@@ -0,0 +1,172 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/steebchen/prisma-client-go/engine/schemaengine"
+	"github.com/steebchen/prisma-client-go/generator/ast/dmmf"
+	"github.com/steebchen/prisma-client-go/introspect"
+	"github.com/steebchen/prisma-client-go/runtime/raw"
+)
+
+// Option configures Deploy and Push
+type Option func(*options)
+
+type options struct {
+	comments bool
+}
+
+// WithComments sets the triple-slash comments of models and fields as database comments on the respective tables
+// and columns after the schema was applied, e.g. via `COMMENT ON` on PostgreSQL. Comments of MySQL columns are not
+// supported, as they require repeating the whole column definition.
+func WithComments() Option {
+	return func(o *options) {
+		o.comments = true
+	}
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+var datasourceProvider = regexp.MustCompile(`(?s)datasource\s+\w+\s*\{[^}]*?provider\s*=\s*"([^"]+)"`)
+var datasourceURLLiteral = regexp.MustCompile(`(?m)^\s*url\s*=\s*"([^"]+)"`)
+
+// applyComments sets the documentation of the schema as comments in the database
+func applyComments(ctx context.Context, schemaPath string, databaseURL string) error {
+	content, err := os.ReadFile(schemaPath)
+	if err != nil {
+		return fmt.Errorf("read schema: %w", err)
+	}
+	schema := string(content)
+
+	match := datasourceProvider.FindStringSubmatch(schema)
+	if match == nil {
+		return fmt.Errorf("could not find datasource provider")
+	}
+	provider := match[1]
+
+	document, err := introspect.Parse(ctx, schema)
+	if err != nil {
+		return fmt.Errorf("parse schema: %w", err)
+	}
+
+	script, err := commentScript(provider, document.Datamodel.Models)
+	if err != nil {
+		return err
+	}
+	if script == "" {
+		return nil
+	}
+
+	if databaseURL == "" {
+		if databaseURL, err = schemaURL(schema); err != nil {
+			return err
+		}
+	}
+
+	e, err := schemaengine.Start(ctx, schemaPath, nil)
+	if err != nil {
+		return err
+	}
+
+	err = e.Call("dbExecute", map[string]interface{}{
+		"datasourceType": map[string]interface{}{
+			"tag": "url",
+			"url": databaseURL,
+		},
+		"script": script,
+	}, nil)
+
+	if closeErr := e.Close(); err == nil && closeErr != nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("apply comments: %w", err)
+	}
+
+	return nil
+}
+
+// schemaURL returns the datasource url of the schema, reading it from the environment if needed
+func schemaURL(schema string) (string, error) {
+	if match := datasourceURLLiteral.FindStringSubmatch(schema); match != nil {
+		return match[1], nil
+	}
+
+	name, err := urlEnvName(schema)
+	if err != nil {
+		return "", err
+	}
+
+	url := os.Getenv(name)
+	if url == "" {
+		return "", fmt.Errorf("env var %s which is defined in the Prisma schema is not set", name)
+	}
+	return url, nil
+}
+
+// commentScript returns the statements which set the documentation of models and fields as database comments
+func commentScript(provider string, models []dmmf.Model) (string, error) {
+	var statements []string
+
+	switch provider {
+	case "postgresql", "postgres", "cockroachdb":
+		for _, model := range models {
+			table := raw.Ident(provider, model.TableName())
+			statements = append(statements, fmt.Sprintf("COMMENT ON TABLE %s IS %s;", table, commentLiteral(model.Documentation)))
+
+			for _, field := range model.Fields {
+				if field.Kind.IsRelation() {
+					continue
+				}
+				column := raw.Ident(provider, field.ColumnName())
+				statements = append(statements, fmt.Sprintf("COMMENT ON COLUMN %s.%s IS %s;", table, column, commentLiteral(field.Documentation)))
+			}
+		}
+	case "mysql":
+		for _, model := range models {
+			table := raw.Ident(provider, model.TableName())
+			c := strings.ReplaceAll(comment(model.Documentation), `\`, `\\`)
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s COMMENT = %s;", table, quoteString(c)))
+		}
+	default:
+		return "", fmt.Errorf("database comments are not supported for provider %q", provider)
+	}
+
+	return strings.Join(statements, "\n"), nil
+}
+
+// comment returns the documentation without generator directives such as `@lazy`
+func comment(documentation string) string {
+	var lines []string
+	for _, line := range strings.Split(documentation, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "@") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// commentLiteral returns the comment as a string literal, or NULL to remove a comment which was set before
+func commentLiteral(documentation string) string {
+	c := comment(documentation)
+	if c == "" {
+		return "NULL"
+	}
+	return quoteString(c)
+}
+
+func quoteString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,54 @@
+package migrate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/steebchen/prisma-client-go/generator/ast/dmmf"
+)
+
+var commentModels = []dmmf.Model{{
+	Name:          "User",
+	DBName:        "users",
+	Documentation: "A registered user.\n@lazy",
+	Fields: []dmmf.Field{{
+		Name:          "email",
+		DBName:        "email_address",
+		Kind:          dmmf.FieldKindScalar,
+		Documentation: "The user's login",
+	}, {
+		Name: "name",
+		Kind: dmmf.FieldKindScalar,
+	}, {
+		Name:          "posts",
+		Kind:          dmmf.FieldKindObject,
+		Documentation: "ignored",
+	}},
+}}
+
+func TestCommentScriptPostgres(t *testing.T) {
+	script, err := commentScript("postgresql", commentModels)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, `COMMENT ON TABLE "users" IS 'A registered user.';
+COMMENT ON COLUMN "users"."email_address" IS 'The user''s login';
+COMMENT ON COLUMN "users"."name" IS NULL;`, script)
+}
+
+func TestCommentScriptMySQL(t *testing.T) {
+	script, err := commentScript("mysql", commentModels)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "ALTER TABLE `users` COMMENT = 'A registered user.';", script)
+}
+
+func TestCommentScriptUnsupported(t *testing.T) {
+	if _, err := commentScript("sqlite", commentModels); err == nil {
+		t.Fatalf("expected an error for sqlite")
+	}
+}
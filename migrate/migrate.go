@@ -0,0 +1,159 @@
+// Package migrate applies migrations and pushes schemas from Go, without requiring Node or the Prisma CLI.
+//
+// It uses the Prisma schema engine, which is downloaded on first use unless PRISMA_SCHEMA_ENGINE_BINARY is set.
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/steebchen/prisma-client-go/engine/schemaengine"
+)
+
+// DeployResult contains the result of applying migrations
+type DeployResult struct {
+	// AppliedMigrationNames lists the names of migrations which were applied
+	AppliedMigrationNames []string `json:"appliedMigrationNames"`
+}
+
+// PushResult contains the result of pushing a schema
+type PushResult struct {
+	// ExecutedSteps is the number of steps which were executed
+	ExecutedSteps int `json:"executedSteps"`
+	// Warnings lists possible data loss. If force was not set, the schema was not pushed.
+	Warnings []string `json:"warnings"`
+	// Unexecutable lists changes which could not be applied
+	Unexecutable []string `json:"unexecutable"`
+}
+
+// Deploy applies all pending migrations in the migrations directory next to the schema, like `prisma migrate deploy`.
+// If databaseURL is not empty, it overrides the datasource url of the schema.
+func Deploy(ctx context.Context, schemaPath string, databaseURL string, opts ...Option) (*DeployResult, error) {
+	schemaPath, err := filepath.Abs(schemaPath)
+	if err != nil {
+		return nil, fmt.Errorf("schema path: %w", err)
+	}
+
+	env, err := datasourceEnv(schemaPath, databaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := schemaengine.Start(ctx, schemaPath, env)
+	if err != nil {
+		return nil, err
+	}
+
+	var result DeployResult
+	err = e.Call("applyMigrations", map[string]interface{}{
+		"migrationsDirectoryPath": filepath.Join(filepath.Dir(schemaPath), "migrations"),
+	}, &result)
+
+	if closeErr := e.Close(); err == nil && closeErr != nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("migrate deploy: %w", err)
+	}
+
+	if newOptions(opts).comments {
+		if err := applyComments(ctx, schemaPath, databaseURL); err != nil {
+			return &result, err
+		}
+	}
+
+	return &result, nil
+}
+
+// Push pushes the schema to the database without using migrations, like `prisma db push`.
+// If databaseURL is not empty, it overrides the datasource url of the schema.
+// Changes which may cause data loss are only applied when force is set; otherwise, an error is returned.
+func Push(ctx context.Context, schemaPath string, databaseURL string, force bool, opts ...Option) (*PushResult, error) {
+	schemaPath, err := filepath.Abs(schemaPath)
+	if err != nil {
+		return nil, fmt.Errorf("schema path: %w", err)
+	}
+
+	content, err := os.ReadFile(schemaPath)
+	if err != nil {
+		return nil, fmt.Errorf("read schema: %w", err)
+	}
+
+	env, err := datasourceEnv(schemaPath, databaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := schemaengine.Start(ctx, schemaPath, env)
+	if err != nil {
+		return nil, err
+	}
+
+	var result PushResult
+	err = e.Call("schemaPush", map[string]interface{}{
+		"schema": map[string]interface{}{
+			"files": []map[string]string{{
+				"path":    schemaPath,
+				"content": string(content),
+			}},
+		},
+		"force": force,
+	}, &result)
+
+	if closeErr := e.Close(); err == nil && closeErr != nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("db push: %w", err)
+	}
+
+	if len(result.Unexecutable) > 0 {
+		return &result, fmt.Errorf("db push: changes could not be applied: %s", strings.Join(result.Unexecutable, "; "))
+	}
+
+	if len(result.Warnings) > 0 && !force {
+		return &result, fmt.Errorf("db push: changes may cause data loss, use force to apply them: %s", strings.Join(result.Warnings, "; "))
+	}
+
+	if newOptions(opts).comments {
+		if err := applyComments(ctx, schemaPath, databaseURL); err != nil {
+			return &result, err
+		}
+	}
+
+	return &result, nil
+}
+
+var datasourceURLEnv = regexp.MustCompile(`(?m)^\s*url\s*=\s*env\(\s*"([^"]+)"\s*\)`)
+
+// datasourceEnv returns the environment which makes the schema engine connect to databaseURL
+func datasourceEnv(schemaPath string, databaseURL string) ([]string, error) {
+	if databaseURL == "" {
+		return nil, nil
+	}
+
+	content, err := os.ReadFile(schemaPath)
+	if err != nil {
+		return nil, fmt.Errorf("read schema: %w", err)
+	}
+
+	name, err := urlEnvName(string(content))
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{fmt.Sprintf("%s=%s", name, databaseURL)}, nil
+}
+
+// urlEnvName returns the name of the env variable the datasource url is read from
+func urlEnvName(schema string) (string, error) {
+	match := datasourceURLEnv.FindStringSubmatch(schema)
+	if match == nil {
+		return "", fmt.Errorf("cannot override database url: the datasource url must be set via env(\"...\")")
+	}
+	return match[1], nil
+}
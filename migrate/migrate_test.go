@@ -0,0 +1,29 @@
+package migrate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestURLEnvName(t *testing.T) {
+	name, err := urlEnvName(`
+datasource db {
+  provider = "postgresql"
+  url      = env("DATABASE_URL")
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "DATABASE_URL", name)
+
+	if _, err := urlEnvName(`
+datasource db {
+  provider = "sqlite"
+  url      = "file:dev.db"
+}
+`); err == nil {
+		t.Fatalf("expected an error for a literal url")
+	}
+}
@@ -0,0 +1,58 @@
+// Package batch sends multiple independent read queries in a single request to the query engine, e.g. to cut the
+// latency of endpoints which fetch data of several models at once.
+package batch
+
+import (
+	"context"
+
+	"github.com/steebchen/prisma-client-go/engine"
+	"github.com/steebchen/prisma-client-go/runtime/builder"
+)
+
+// Query is implemented by the read queries of the generated client
+type Query interface {
+	ExtractQuery() builder.Query
+}
+
+type Batcher struct {
+	Engine engine.Engine
+}
+
+// Batch returns a batch of read queries, which are sent in a single request to the query engine when calling Exec.
+// The queries run independently and outside of a transaction, so they may see different states of the database.
+//
+// Example:
+//
+//	results, err := client.Prisma.Batch(
+//	  client.User.FindMany(),
+//	  client.Post.FindUnique(db.Post.ID.Equals(id)),
+//	).Exec(ctx)
+//	if err != nil {
+//	  return err
+//	}
+//	var users []db.UserModel
+//	if err := results[0].Into(&users); err != nil {
+//	  return err
+//	}
+func (r Batcher) Batch(queries ...Query) Exec {
+	return Exec{
+		engine:  r.Engine,
+		queries: queries,
+	}
+}
+
+type Exec struct {
+	engine  engine.Engine
+	queries []Query
+}
+
+// Exec sends the queries and returns the result of each query in the order of the queries. The returned error is only
+// set if the request as a whole failed; errors of single queries are returned by Into of their result.
+func (r Exec) Exec(ctx context.Context) ([]builder.QueryResult, error) {
+	queries := make([]builder.Query, len(r.queries))
+	for i, q := range r.queries {
+		queries[i] = q.ExtractQuery()
+		queries[i].Engine = r.engine
+	}
+	return builder.ExecQueries(ctx, queries)
+}
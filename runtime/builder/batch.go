@@ -0,0 +1,165 @@
+package builder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/steebchen/prisma-client-go/engine"
+	"github.com/steebchen/prisma-client-go/engine/protocol"
+)
+
+// ExecBatch executes queries in a single batched request without a transaction, in which the engine combines
+// findUnique queries of the same model into one database query. Equal queries are only sent once. It returns the
+// record of each query in the order of the queries, which is nil if the query found no record.
+func ExecBatch[T any](ctx context.Context, queries []Query) ([]*T, error) {
+	if len(queries) == 0 {
+		return nil, nil
+	}
+	q := queries[0]
+	if q.Engine == nil {
+		return nil, fmt.Errorf("client.Prisma.Connect() needs to be called before sending queries")
+	}
+
+	// index of the request of each query, so that equal queries share a request
+	indexes := make([]int, len(queries))
+	seen := map[string]int{}
+	var requests []protocol.GQLRequest
+	for i, query := range queries {
+		payload, err := query.payload()
+		if err != nil {
+			return nil, err
+		}
+		index, ok := seen[payload.Query]
+		if !ok {
+			index = len(requests)
+			seen[payload.Query] = index
+			requests = append(requests, payload)
+		}
+		indexes[i] = index
+	}
+
+	l := engine.LoggerOf(q.Engine)
+	l.DebugContext(ctx, "query built", "model", q.Model, "action", q.Method, "duration", time.Since(q.Start))
+
+	var result protocol.GQLBatchResponse
+	payload := protocol.GQLBatchRequest{
+		Batch: requests,
+	}
+	err := q.Engine.Batch(ctx, payload, &result)
+	if err == nil {
+		err = batchError(result, len(requests))
+	}
+	q.log(ctx, l, err)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*T, len(requests))
+	for i, inner := range result.Result {
+		if err := queries[slices.Index(indexes, i)].unmarshal(inner.Data.Result, &records[i]); err != nil {
+			return nil, fmt.Errorf("json data result unmarshal: %w", err)
+		}
+	}
+
+	results := make([]*T, len(queries))
+	for i, index := range indexes {
+		results[i] = records[index]
+	}
+	return results, nil
+}
+
+// QueryResult is the result of a query sent with ExecQueries
+type QueryResult struct {
+	Query Query
+	// Data is the raw result of the query
+	Data json.RawMessage
+	// Err is the error of the query, which doesn't affect the other queries of the batch
+	Err error
+}
+
+// Into decodes the result of the query into v. It returns the error of the query if it failed, or a NotFoundError if
+// a query for a single record found none.
+func (r QueryResult) Into(v interface{}) error {
+	if r.Err != nil {
+		return r.Err
+	}
+	if bytes.Equal(bytes.TrimSpace(r.Data), []byte("null")) {
+		return r.Query.NotFound()
+	}
+	if err := r.Query.unmarshal(r.Data, v); err != nil {
+		return fmt.Errorf("json data result unmarshal: %w", err)
+	}
+	return nil
+}
+
+// ExecQueries executes independent read queries in a single batched request without a transaction and returns the
+// result of each query in the order of the queries. A failing query doesn't fail the others, so its error is reported
+// in its result; the returned error is only set if the request as a whole failed.
+func ExecQueries(ctx context.Context, queries []Query) ([]QueryResult, error) {
+	if len(queries) == 0 {
+		return nil, nil
+	}
+	q := queries[0]
+	if q.Engine == nil {
+		return nil, fmt.Errorf("client.Prisma.Connect() needs to be called before sending queries")
+	}
+
+	requests := make([]protocol.GQLRequest, len(queries))
+	for i, query := range queries {
+		if query.Operation != "query" {
+			return nil, fmt.Errorf("%s.%s can't be batched, as only read queries are supported; use a transaction for writes", query.Model, query.Method)
+		}
+		payload, err := query.payload()
+		if err != nil {
+			return nil, err
+		}
+		requests[i] = payload
+	}
+
+	l := engine.LoggerOf(q.Engine)
+	l.DebugContext(ctx, "query built", "model", q.Model, "action", "batch", "duration", time.Since(q.Start))
+
+	var result protocol.GQLBatchResponse
+	payload := protocol.GQLBatchRequest{
+		Batch: requests,
+	}
+	err := q.Engine.Batch(ctx, payload, &result)
+	if err == nil && len(result.Errors) > 0 {
+		err = gqlError(result.Errors[0])
+	}
+	if err == nil && len(result.Result) != len(queries) {
+		err = fmt.Errorf("expected %d batch results, got %d", len(queries), len(result.Result))
+	}
+	if err != nil {
+		q.log(ctx, l, err)
+		return nil, err
+	}
+
+	results := make([]QueryResult, len(queries))
+	for i, inner := range result.Result {
+		results[i] = QueryResult{
+			Query: queries[i],
+			Data:  inner.Data.Result,
+		}
+		if len(inner.Errors) > 0 {
+			results[i].Err = gqlError(inner.Errors[0])
+		}
+		queries[i].log(ctx, l, results[i].Err)
+	}
+	return results, nil
+}
+
+// UniqueKey returns a key which identifies the where-condition of a findUnique query, so that results of ExecBatch can
+// be looked up by their condition
+func UniqueKey(fields []Field) string {
+	var q Query
+	key, err := q.buildFields(false, false, TransformEquals(fields))
+	if err != nil {
+		return fmt.Sprintf("%v", fields)
+	}
+	return key
+}
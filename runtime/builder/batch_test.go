@@ -0,0 +1,111 @@
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/steebchen/prisma-client-go/engine/protocol"
+	"github.com/steebchen/prisma-client-go/runtime/types"
+)
+
+// batchEngine records the batch request and answers with a record for id 1, an error for id 3 and no record otherwise
+type batchEngine struct {
+	payload protocol.GQLBatchRequest
+}
+
+func (e *batchEngine) Connect() error    { return nil }
+func (e *batchEngine) Disconnect() error { return nil }
+func (e *batchEngine) Name() string      { return "batch" }
+
+func (e *batchEngine) Do(ctx context.Context, payload interface{}, into interface{}) error {
+	return fmt.Errorf("not supported")
+}
+
+func (e *batchEngine) Batch(ctx context.Context, payload interface{}, into interface{}) error {
+	e.payload = payload.(protocol.GQLBatchRequest)
+	var result protocol.GQLBatchResponse
+	for _, r := range e.payload.Batch {
+		data := `null`
+		if r.Query == `query {result: findUniquePost(where:{id:1,}) {id title }}` {
+			data = `{"id":1,"title":"a"}`
+		}
+		response := protocol.GQLResponse{Data: protocol.Data{Result: json.RawMessage(data)}}
+		if r.Query == `query {result: findUniquePost(where:{id:3,}) {id title }}` {
+			response.Errors = []protocol.GQLError{{Message: "failed"}}
+		}
+		result.Result = append(result.Result, response)
+	}
+	v, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(v, into)
+}
+
+func newUniqueQuery(e *batchEngine, id int) Query {
+	q := NewQuery()
+	q.Engine = e
+	q.Operation = "query"
+	q.Method = "findUnique"
+	q.Model = "Post"
+	q.Inputs = []Input{{
+		Name:   "where",
+		Fields: []Field{{Name: "id", Value: id}},
+	}}
+	q.Outputs = []Output{{Name: "id"}, {Name: "title"}}
+	return q
+}
+
+func TestExecBatch(t *testing.T) {
+	e := &batchEngine{}
+	queries := []Query{newUniqueQuery(e, 1), newUniqueQuery(e, 2), newUniqueQuery(e, 1)}
+
+	records, err := ExecBatch[record](context.Background(), queries)
+	assert.NoError(t, err)
+	assert.Equal(t, []*record{{ID: 1, Title: "a"}, nil, {ID: 1, Title: "a"}}, records)
+
+	assert.False(t, e.payload.Transaction)
+	assert.Equal(t, 2, len(e.payload.Batch))
+}
+
+func TestExecBatchEmpty(t *testing.T) {
+	records, err := ExecBatch[record](context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(records))
+}
+
+func TestExecQueries(t *testing.T) {
+	e := &batchEngine{}
+	queries := []Query{newUniqueQuery(e, 1), newUniqueQuery(e, 2), newUniqueQuery(e, 3)}
+
+	results, err := ExecQueries(context.Background(), queries)
+	assert.NoError(t, err)
+	if !assert.Len(t, results, 3) {
+		return
+	}
+	assert.False(t, e.payload.Transaction)
+
+	var r record
+	assert.NoError(t, results[0].Into(&r))
+	assert.Equal(t, record{ID: 1, Title: "a"}, r)
+
+	assert.ErrorIs(t, results[1].Into(&r), types.ErrNotFound)
+	assert.EqualError(t, results[2].Into(&r), "pql error: failed")
+
+	write := NewQuery()
+	write.Engine = e
+	write.Operation = "mutation"
+	write.Method = "deleteMany"
+	write.Model = "Post"
+	_, err = ExecQueries(context.Background(), []Query{newUniqueQuery(e, 1), write})
+	assert.EqualError(t, err, "Post.deleteMany can't be batched, as only read queries are supported; use a transaction for writes")
+}
+
+func TestUniqueKey(t *testing.T) {
+	assert.Equal(t, `{id:1,}`, UniqueKey([]Field{{Name: "id", Value: 1}}))
+	assert.Equal(t, UniqueKey([]Field{{Name: "id", Fields: []Field{{Name: "equals", Value: 1}}}}), UniqueKey([]Field{{Name: "id", Value: 1}}))
+}
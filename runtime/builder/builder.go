@@ -3,26 +3,32 @@ package builder
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"strings"
 	"time"
 
+	"github.com/shopspring/decimal"
+
 	"github.com/steebchen/prisma-client-go/engine"
 	"github.com/steebchen/prisma-client-go/engine/protocol"
-	"github.com/steebchen/prisma-client-go/logger"
+	"github.com/steebchen/prisma-client-go/runtime/types"
 )
 
 type MethodFormat string
 
 const (
-	FindRaw      MethodFormat = "findRaw"
-	AggregateRaw MethodFormat = "aggregateRaw"
+	FindRaw             MethodFormat = "findRaw"
+	AggregateRaw        MethodFormat = "aggregateRaw"
+	UpdateManyAndReturn MethodFormat = "updateManyAndReturn"
 )
 
 var (
 	MethodFormatMaping = map[MethodFormat]string{
-		FindRaw:      "find%sRaw",      // find{Model}Raw
-		AggregateRaw: "aggregate%sRaw", // aggregate{Model}Raw
+		FindRaw:             "find%sRaw",             // find{Model}Raw
+		AggregateRaw:        "aggregate%sRaw",        // aggregate{Model}Raw
+		UpdateManyAndReturn: "updateMany%sAndReturn", // updateMany{Model}AndReturn
 	}
 )
 
@@ -92,9 +98,65 @@ type Query struct {
 	Start time.Time
 
 	TxResult chan []byte
+
+	// CacheStrategy (optional) contains Prisma Accelerate cache hints
+	CacheStrategy *engine.CacheStrategy
+}
+
+// NotFound returns a NotFoundError describing the model and where-conditions of the query
+func (q Query) NotFound() error {
+	return &types.NotFoundError{
+		Model:  q.Model,
+		Action: q.Method,
+		Where:  q.Where(),
+	}
+}
+
+// Where returns the where-conditions of the query by field name
+func (q Query) Where() map[string]interface{} {
+	for _, input := range q.Inputs {
+		if input.Name == "where" {
+			return fieldMap(input.Fields)
+		}
+	}
+	return map[string]interface{}{}
+}
+
+// Data returns the data written by a create or update by field name
+func (q Query) Data() map[string]interface{} {
+	for _, input := range q.Inputs {
+		// the data of createMany is a list of records
+		if input.Name == "data" && !input.WrapList {
+			return fieldMap(input.Fields)
+		}
+	}
+	return map[string]interface{}{}
+}
+
+// fieldMap converts fields to a map by field name, which contains nested maps for fields with a subselection
+func fieldMap(fields []Field) map[string]interface{} {
+	m := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		if f.Fields != nil {
+			m[f.Name] = fieldMap(f.Fields)
+			continue
+		}
+		m[f.Name] = f.Value
+	}
+	return m
 }
 
 func (q Query) Build() (string, error) {
+	q = q.withTimestamps()
+	q = q.withDefaults()
+
+	limits, hasLimits := engine.LimitsOf(q.Engine)
+	if hasLimits {
+		if err := q.checkLimits(limits); err != nil {
+			return "", err
+		}
+	}
+
 	var builder strings.Builder
 
 	builder.WriteString(q.Operation + " " + q.Name)
@@ -109,6 +171,10 @@ func (q Query) Build() (string, error) {
 
 	builder.WriteString("}")
 
+	if hasLimits && limits.MaxSize > 0 && builder.Len() > limits.MaxSize {
+		return "", &types.QueryLimitError{Limit: "size", Max: limits.MaxSize, Actual: builder.Len()}
+	}
+
 	return builder.String(), nil
 }
 
@@ -119,6 +185,8 @@ func (q Query) BuildInner() (string, error) {
 		builder.WriteString(fmt.Sprintf(MethodFormatMaping[FindRaw], q.Model))
 	case AggregateRaw:
 		builder.WriteString(fmt.Sprintf(MethodFormatMaping[AggregateRaw], q.Model))
+	case UpdateManyAndReturn:
+		builder.WriteString(fmt.Sprintf(MethodFormatMaping[UpdateManyAndReturn], q.Model))
 	default:
 		builder.WriteString(q.Method + q.Model)
 	}
@@ -227,6 +295,12 @@ func (q Query) buildFields(list bool, wrapList bool, fields []Field) (string, er
 	// this is necessary for json filters and more
 	uniques := make(map[string]*Field)
 	for i, f := range fields {
+		// unnamed fields are list entries, e.g. the records of a nested createMany, which must not be joined
+		if f.Name == "" {
+			final = append(final, f)
+			continue
+		}
+
 		if _, ok := uniques[f.Name]; ok {
 			// check if field is a model operation
 			if f.Fields != nil && f.Name != "AND" && f.Name != "OR" && f.Name != "NOT" {
@@ -252,8 +326,17 @@ func (q Query) buildFields(list bool, wrapList bool, fields []Field) (string, er
 		if err := checkFields(f, f.Fields); err != nil {
 			return "", err
 		}
+		switch f.Value.(type) {
+		case Exists:
+			return "", ErrUnresolvedExists
+		case Ref:
+			return "", fmt.Errorf("field %q references another model, which is only supported in exists subqueries", f.Name)
+		}
+
+		// unnamed fields with a subselection are list entries which are objects themselves
+		wrap := wrapList && (f.Name != "" || f.Fields == nil)
 
-		if wrapList {
+		if wrap {
 			builder.WriteString("{")
 		}
 
@@ -285,7 +368,7 @@ func (q Query) buildFields(list bool, wrapList bool, fields []Field) (string, er
 			builder.WriteString("]")
 		}
 
-		if wrapList {
+		if wrap {
 			builder.WriteString("}")
 		}
 
@@ -312,16 +395,67 @@ func checkFields(parent Field, fields []Field) error {
 	return nil
 }
 
-func (q Query) Exec(ctx context.Context, into interface{}) error {
+func (q Query) payload() (protocol.GQLRequest, error) {
 	str, err := q.Build()
 	if err != nil {
-		return err
+		return protocol.GQLRequest{}, err
 	}
-	payload := protocol.GQLRequest{
+	return protocol.GQLRequest{
 		Query:     str,
 		Variables: map[string]interface{}{},
+	}, nil
+}
+
+func (q Query) Exec(ctx context.Context, into interface{}) error {
+	q, err := q.resolveExists(ctx)
+	if err != nil {
+		return err
+	}
+
+	q = q.withRelationStrategy()
+
+	if limit := engine.InListLimitOf(q.Engine); limit > 0 {
+		if chunks, ok := q.splitIn(limit); ok {
+			// check the limits of the whole query, as the chunks may stay below them
+			if limits, ok := engine.LimitsOf(q.Engine); ok {
+				if err := q.checkLimits(limits); err != nil {
+					return err
+				}
+			}
+			return q.execSplit(ctx, chunks, into)
+		}
+	}
+
+	payload, err := q.payload()
+	if err != nil {
+		return err
+	}
+
+	if c, key, ok := q.cacheKey(payload); ok && !isDryRun(ctx) {
+		return q.execCached(ctx, c, key, payload, into)
+	}
+
+	if d := dedupOf(ctx); d != nil && !isDryRun(ctx) {
+		if q.Operation == "query" {
+			return d.exec(ctx, q, payload, into)
+		}
+		defer d.clear()
 	}
-	return q.Do(ctx, payload, into)
+
+	return q.fetch(ctx, payload, into)
+}
+
+// Debug returns the request which would be sent to the engine as indented JSON, without executing it
+func (q Query) Debug() (string, error) {
+	payload, err := q.payload()
+	if err != nil {
+		return "", err
+	}
+	v, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(v), nil
 }
 
 func (q Query) Do(ctx context.Context, payload interface{}, into interface{}) error {
@@ -329,20 +463,145 @@ func (q Query) Do(ctx context.Context, payload interface{}, into interface{}) er
 		return fmt.Errorf("client.Prisma.Connect() needs to be called before sending queries")
 	}
 
-	logger.Debug.Printf("[timing] building %q", time.Since(q.Start))
+	l := engine.LoggerOf(q.Engine)
+	l.DebugContext(ctx, "query built", "model", q.Model, "action", q.Method, "duration", time.Since(q.Start))
+
+	if q.CacheStrategy != nil {
+		ctx = engine.WithCacheStrategy(ctx, *q.CacheStrategy)
+	}
 
-	err := q.Engine.Do(ctx, payload, into)
-	now := time.Now()
-	totalDuration := now.Sub(q.Start)
-	logger.Debug.Printf("[timing] TOTAL %q", totalDuration)
+	ctx = context.WithValue(ctx, queryKey{}, q)
+
+	dryRun := isDryRun(ctx)
+	if !dryRun {
+		if err := q.RunBeforeHooks(ctx); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	stats, sampled := q.sampleStats()
+	if (engine.IsStrict(q.Engine) || sampled || q.renamesJSON()) && len(q.Outputs) > 0 {
+		var data json.RawMessage
+		if err = q.Engine.Do(ctx, payload, &data); err == nil {
+			if sampled {
+				q.recordStats(stats, data)
+			}
+			err = q.unmarshal(data, into)
+		}
+	} else {
+		err = q.Engine.Do(ctx, payload, into)
+	}
+	q.log(ctx, l, err)
+	if err == nil && !dryRun {
+		engine.Invalidate(ctx, q.Engine, q.Model, q.Method, q.Where(), into)
+		err = q.RunAfterHooks(ctx, into)
+	}
 	return err
 }
 
+// log logs the result of a query with its model, action and total duration, and records it in the metrics of the engine.
+// Failed queries are logged at warn level if the engine has a logger attached, and at debug level otherwise.
+func (q Query) log(ctx context.Context, l *slog.Logger, err error) {
+	duration := time.Since(q.Start)
+	attrs := []any{"model", q.Model, "action", q.Method, "duration", duration}
+	metric := engine.QueryMetric{Model: q.Model, Action: q.Method, Duration: duration}
+
+	if err == nil || errors.Is(err, types.ErrNotFound) {
+		engine.ObserveQuery(ctx, q.Engine, metric)
+		l.DebugContext(ctx, "query", attrs...)
+		return
+	}
+
+	metric.Code = "unknown"
+	var ufe *protocol.UserFacingError
+	if errors.As(err, &ufe) {
+		attrs = append(attrs, "code", ufe.ErrorCode)
+		metric.Code = ufe.ErrorCode
+	}
+	engine.ObserveQuery(ctx, q.Engine, metric)
+
+	// the error is returned to the caller anyway, so it's only logged prominently if a logger was configured
+	level := slog.LevelDebug
+	if engine.HasLogger(q.Engine) {
+		level = slog.LevelWarn
+	}
+	l.Log(ctx, level, "query failed", append(attrs, "error", err)...)
+}
+
+type queryKey struct{}
+
+// QueryFromContext returns the query which is being executed, e.g. to let the mock engine match queries by structure
+func QueryFromContext(ctx context.Context) (Query, bool) {
+	q, ok := ctx.Value(queryKey{}).(Query)
+	return q, ok
+}
+
+// Value encodes a value of the query document. Decimals are always encoded as strings, so that they keep their
+// precision regardless of decimal.MarshalJSONWithoutQuotes, and custom scalar types are encoded with their registered
+// codec.
 func Value(value interface{}) []byte {
-	v, err := json.Marshal(value)
+	value, err := types.EncodeScalar(value)
+	if err != nil {
+		panic(err)
+	}
+
+	v, err := json.Marshal(preciseDecimal(value))
 	if err != nil {
 		panic(err)
 	}
 
 	return v
 }
+
+// preciseDecimal converts decimal values to strings, as the engine would parse numbers as floats
+func preciseDecimal(value interface{}) interface{} {
+	switch v := value.(type) {
+	case decimal.Decimal:
+		return v.String()
+	case *decimal.Decimal:
+		if v == nil {
+			return nil
+		}
+		return v.String()
+	case decimal.NullDecimal:
+		if !v.Valid {
+			return nil
+		}
+		return v.Decimal.String()
+	case []decimal.Decimal:
+		values := make([]string, len(v))
+		for i, d := range v {
+			values[i] = d.String()
+		}
+		return values
+	}
+	return value
+}
+
+// Action returns a field which applies a single action with the given value, e.g. `name: { equals: value }`
+func Action(name string, action string, value interface{}) Field {
+	return Field{
+		Name: name,
+		Fields: []Field{{
+			Name:  action,
+			Value: value,
+		}},
+	}
+}
+
+// WrapSet wraps the value of a scalar field in a `set` action, as required by update operations.
+// JSON values and fields which already contain actions are returned unchanged.
+func WrapSet(field Field) Field {
+	if _, isJSON := field.Value.(types.JSON); field.Value == nil || isJSON {
+		return field
+	}
+
+	field.Fields = []Field{{
+		Name:  "set",
+		Value: field.Value,
+	}}
+	field.Value = nil
+
+	return field
+}
@@ -0,0 +1,320 @@
+package builder
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/steebchen/prisma-client-go/engine"
+	"github.com/steebchen/prisma-client-go/logger"
+	"github.com/steebchen/prisma-client-go/runtime/metadata"
+	"github.com/steebchen/prisma-client-go/runtime/types"
+)
+
+func TestWrapSet(t *testing.T) {
+	assert.Equal(t, Field{
+		Name:   "name",
+		Fields: []Field{{Name: "set", Value: "foo"}},
+	}, WrapSet(Field{Name: "name", Value: "foo"}))
+
+	json := Field{Name: "meta", Value: types.JSON(`{}`)}
+	assert.Equal(t, json, WrapSet(json))
+
+	action := Action("views", "increment", 1)
+	assert.Equal(t, action, WrapSet(action))
+}
+
+func TestValueDecimal(t *testing.T) {
+	d := decimal.RequireFromString("12345678901234567890.123456789")
+
+	decimal.MarshalJSONWithoutQuotes = true
+	defer func() {
+		decimal.MarshalJSONWithoutQuotes = false
+	}()
+
+	assert.Equal(t, `"12345678901234567890.123456789"`, string(Value(d)))
+	assert.Equal(t, `"12345678901234567890.123456789"`, string(Value(&d)))
+	assert.Equal(t, `null`, string(Value((*decimal.Decimal)(nil))))
+	assert.Equal(t, `null`, string(Value(decimal.NullDecimal{})))
+	assert.Equal(t, `["1.5","12345678901234567890.123456789"]`, string(Value([]decimal.Decimal{decimal.RequireFromString("1.5"), d})))
+}
+
+func TestQuery_Debug(t *testing.T) {
+	q := NewQuery()
+	q.Operation = "query"
+	q.Method = "findUnique"
+	q.Model = "User"
+	q.Inputs = []Input{{
+		Name:   "where",
+		Fields: []Field{{Name: "id", Value: "123"}},
+	}}
+	q.Outputs = []Output{{Name: "id"}}
+
+	actual, err := q.Debug()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, `{
+  "query": "query {result: findUniqueUser(where:{id:\"123\",}) {id }}",
+  "variables": {}
+}`, actual)
+}
+
+func TestQuery_BuildUnnamedFields(t *testing.T) {
+	q := NewQuery()
+	q.Operation = "mutation"
+	q.Method = "createOne"
+	q.Model = "Post"
+	q.Inputs = []Input{{
+		Name: "data",
+		Fields: []Field{{
+			Name: "comments",
+			Fields: []Field{{
+				Name: "createMany",
+				Fields: []Field{{
+					Name: "data",
+					List: true,
+					Fields: []Field{
+						{Fields: []Field{{Name: "content", Value: "a"}}},
+						{Fields: []Field{{Name: "content", Value: "b"}}},
+					},
+				}},
+			}},
+		}},
+	}}
+	q.Outputs = []Output{{Name: "id"}}
+
+	actual, err := q.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, `mutation {result: createOnePost(data:{comments:{createMany:{data:[{content:"a",},{content:"b",},],},},}) {id }}`, actual)
+}
+
+func TestQuery_BuildUpdateManyAndReturn(t *testing.T) {
+	q := NewQuery()
+	q.Operation = "mutation"
+	q.Method = "updateManyAndReturn"
+	q.Model = "User"
+	q.Inputs = []Input{{
+		Name:   "data",
+		Fields: []Field{{Name: "name", Fields: []Field{{Name: "set", Value: "a"}}}},
+	}}
+	q.Outputs = []Output{{Name: "id"}}
+
+	actual, err := q.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, `mutation {result: updateManyUserAndReturn(data:{name:{set:"a",},}) {id }}`, actual)
+}
+
+func TestQuery_NotFound(t *testing.T) {
+	q := NewQuery()
+	q.Method = "findUnique"
+	q.Model = "User"
+	q.Inputs = []Input{{
+		Name: "where",
+		Fields: []Field{{
+			Name:   "email_name",
+			Fields: []Field{{Name: "email", Value: "john@example.com"}, {Name: "name", Value: "John"}},
+		}},
+	}}
+
+	err := q.NotFound()
+	assert.ErrorIs(t, err, types.ErrNotFound)
+	assert.Equal(t, &types.NotFoundError{
+		Model:  "User",
+		Action: "findUnique",
+		Where: map[string]interface{}{
+			"email_name": map[string]interface{}{"email": "john@example.com", "name": "John"},
+		},
+	}, err)
+}
+
+func TestQuery_BuildLimits(t *testing.T) {
+	newQuery := func(limits engine.QueryLimits) Query {
+		q := NewQuery()
+		q.Engine = engine.NewLimitEngine(nil, limits)
+		q.Operation = "query"
+		q.Method = "findMany"
+		q.Model = "User"
+		q.Inputs = []Input{{
+			Name: "where",
+			Fields: []Field{{
+				Name:   "id",
+				Fields: []Field{{Name: "in", Value: []string{"a", "b", "c"}}},
+			}},
+		}}
+		q.Outputs = []Output{{Name: "id"}, {
+			Name:    "posts",
+			Outputs: []Output{{Name: "id"}, {Name: "comments", Outputs: []Output{{Name: "id"}}}},
+		}}
+		return q
+	}
+
+	tests := []struct {
+		name   string
+		limits engine.QueryLimits
+		want   *types.QueryLimitError
+	}{{
+		name:   "within limits",
+		limits: engine.QueryLimits{MaxDepth: 3, MaxListLength: 3, MaxIncludes: 2, MaxSize: 1000},
+	}, {
+		name:   "depth",
+		limits: engine.QueryLimits{MaxDepth: 2},
+		want:   &types.QueryLimitError{Limit: "depth", Max: 2, Actual: 3},
+	}, {
+		name:   "list length",
+		limits: engine.QueryLimits{MaxListLength: 2},
+		want:   &types.QueryLimitError{Limit: "list length", Max: 2, Actual: 3},
+	}, {
+		name:   "includes",
+		limits: engine.QueryLimits{MaxIncludes: 1},
+		want:   &types.QueryLimitError{Limit: "includes", Max: 1, Actual: 2},
+	}, {
+		name:   "size",
+		limits: engine.QueryLimits{MaxSize: 10},
+		want:   &types.QueryLimitError{Limit: "size", Max: 10, Actual: 92},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newQuery(tt.limits).Build()
+			if tt.want == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, types.ErrQueryLimit)
+			assert.Equal(t, tt.want, err)
+		})
+	}
+}
+
+func TestQuery_BuildTimestamps(t *testing.T) {
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	schema := metadata.Schema{
+		Models: []metadata.Model{{
+			Name: "Post",
+			Fields: []metadata.Field{
+				{Name: "id"},
+				{Name: "createdAt", DefaultNow: true},
+				{Name: "updatedAt", IsUpdatedAt: true},
+			},
+		}, {
+			Name: "Comment",
+			Fields: []metadata.Field{
+				{Name: "id"},
+				{Name: "createdAt", DefaultNow: true},
+			},
+		}},
+	}
+	clock := engine.ClockFunc(func() time.Time {
+		return now
+	})
+
+	tests := []struct {
+		name   string
+		model  string
+		method string
+		inputs []Input
+		want   string
+	}{{
+		name:   "create",
+		model:  "Post",
+		method: "createOne",
+		inputs: []Input{{Name: "data", Fields: []Field{{Name: "id", Value: "a"}}}},
+		want:   `mutation {result: createOnePost(data:{id:"a",createdAt:"2020-01-02T03:04:05Z",updatedAt:"2020-01-02T03:04:05Z",}) {id }}`,
+	}, {
+		name:   "create with explicit timestamp",
+		model:  "Post",
+		method: "createOne",
+		inputs: []Input{{Name: "data", Fields: []Field{{Name: "createdAt", Value: "2000-01-01T00:00:00Z"}}}},
+		want:   `mutation {result: createOnePost(data:{createdAt:"2000-01-01T00:00:00Z",updatedAt:"2020-01-02T03:04:05Z",}) {id }}`,
+	}, {
+		name:   "update",
+		model:  "Post",
+		method: "updateMany",
+		inputs: []Input{{Name: "data", Fields: []Field{{Name: "id", Value: "b"}}}},
+		want:   `mutation {result: updateManyPost(data:{id:"b",updatedAt:"2020-01-02T03:04:05Z",}) {id }}`,
+	}, {
+		name:   "create many",
+		model:  "Post",
+		method: "createMany",
+		inputs: []Input{{Name: "data", WrapList: true, Fields: []Field{
+			{Fields: []Field{{Name: "id", Value: "a"}}},
+			{Fields: []Field{{Name: "id", Value: "b"}, {Name: "createdAt", Value: "2000-01-01T00:00:00Z"}}},
+		}}},
+		want: `mutation {result: createManyPost(data:[{id:"a",createdAt:"2020-01-02T03:04:05Z",updatedAt:"2020-01-02T03:04:05Z",},{id:"b",createdAt:"2000-01-01T00:00:00Z",updatedAt:"2020-01-02T03:04:05Z",},]) {id }}`,
+	}, {
+		name:   "upsert",
+		model:  "Post",
+		method: "upsertOne",
+		inputs: []Input{
+			{Name: "where", Fields: []Field{{Name: "id", Value: "a"}}},
+			{Name: "create", Fields: []Field{{Name: "id", Value: "a"}}},
+			{Name: "update", Fields: []Field{{Name: "id", Value: "a"}}},
+		},
+		want: `mutation {result: upsertOnePost(where:{id:"a",},create:{id:"a",createdAt:"2020-01-02T03:04:05Z",updatedAt:"2020-01-02T03:04:05Z",},update:{id:"a",updatedAt:"2020-01-02T03:04:05Z",}) {id }}`,
+	}, {
+		name:   "upsert without updatedAt",
+		model:  "Comment",
+		method: "upsertOne",
+		inputs: []Input{
+			{Name: "where", Fields: []Field{{Name: "id", Value: "a"}}},
+			{Name: "update", Fields: []Field{{Name: "id", Value: "a"}}},
+			{Name: "create", Fields: []Field{{Name: "id", Value: "a"}}},
+		},
+		want: `mutation {result: upsertOneComment(where:{id:"a",},update:{id:"a",},create:{id:"a",createdAt:"2020-01-02T03:04:05Z",}) {id }}`,
+	}, {
+		name:   "delete",
+		model:  "Post",
+		method: "deleteMany",
+		want:   `mutation {result: deleteManyPost {id }}`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := NewQuery()
+			q.Engine = engine.NewClockEngine(nil, clock, schema)
+			q.Operation = "mutation"
+			q.Method = tt.method
+			q.Model = tt.model
+			q.Inputs = tt.inputs
+			q.Outputs = []Output{{Name: "id"}}
+
+			actual, err := q.Build()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, actual)
+		})
+	}
+}
+
+func TestQueryLogFailed(t *testing.T) {
+	var out bytes.Buffer
+	l := slog.New(slog.NewTextHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	failing := newUniqueQuery(nil, 2)
+
+	// failed queries are logged at warn level with a configured logger
+	failing.Engine = engine.NewLogEngine(&countingEngine{}, l)
+	var r record
+	assert.Error(t, failing.Exec(context.Background(), &r))
+	assert.Contains(t, out.String(), `level=WARN msg="query failed" model=Post action=findUnique`)
+
+	// and at debug level with the default logger
+	out.Reset()
+	previous := logger.Default()
+	logger.SetDefault(l)
+	defer logger.SetDefault(previous)
+
+	failing.Engine = &countingEngine{}
+	assert.Error(t, failing.Exec(context.Background(), &r))
+	assert.Contains(t, out.String(), `level=DEBUG msg="query failed" model=Post action=findUnique`)
+}
@@ -0,0 +1,109 @@
+package builder
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/steebchen/prisma-client-go/engine"
+	"github.com/steebchen/prisma-client-go/engine/protocol"
+	"github.com/steebchen/prisma-client-go/runtime/metadata"
+)
+
+// cacheKey returns the result cache of the engine and the key of a read query, if its results are cached
+func (q Query) cacheKey(payload protocol.GQLRequest) (*engine.CacheEngine, string, bool) {
+	if q.Operation != "query" || q.Model == "" {
+		return nil, "", false
+	}
+	c, ok := engine.CacheOf(q.Engine)
+	if !ok {
+		return nil, "", false
+	}
+	return c, c.Key(q.Engine, q.models(c.Schema), payload.Query), true
+}
+
+// execCached returns the cached result of a read query, or sends it and caches its result
+func (q Query) execCached(ctx context.Context, c *engine.CacheEngine, key string, payload protocol.GQLRequest, into interface{}) error {
+	if data, ok := c.Cache.Get(ctx, key); ok {
+		return q.unmarshal(data, into)
+	}
+
+	var data json.RawMessage
+	var err error
+	if d := dedupOf(ctx); d != nil {
+		err = d.exec(ctx, q, payload, &data)
+	} else {
+		err = q.fetch(ctx, payload, &data)
+	}
+	if err != nil {
+		return err
+	}
+
+	c.Cache.Set(ctx, key, data, c.TTL)
+	return q.unmarshal(data, into)
+}
+
+// models returns the model of a query and the models of all relations it fetches, filters or orders by, whose writes
+// change its result
+func (q Query) models(schema metadata.Schema) []string {
+	seen := map[string]bool{q.Model: true}
+	models := []string{q.Model}
+
+	var walkFields func(model string, fields []Field)
+	var walkOutputs func(model string, outputs []Output)
+
+	// related returns the model of a relation field, and whether the name refers to any field of the model
+	related := func(model string, name string) (string, bool) {
+		m, ok := schema.Model(model)
+		if !ok {
+			return "", false
+		}
+		f, ok := m.Field(name)
+		if !ok {
+			return "", false
+		}
+		if !f.IsRelation() {
+			return "", true
+		}
+		if !seen[f.Type] {
+			seen[f.Type] = true
+			models = append(models, f.Type)
+		}
+		return f.Type, true
+	}
+
+	walkFields = func(model string, fields []Field) {
+		for _, f := range fields {
+			target, isField := related(model, f.Name)
+			switch {
+			case target != "":
+				walkFields(target, f.Fields)
+			case !isField:
+				// operators such as AND or some keep the model of their parent
+				walkFields(model, f.Fields)
+			}
+		}
+	}
+
+	walkOutputs = func(model string, outputs []Output) {
+		for _, o := range outputs {
+			target, isField := related(model, o.Name)
+			if target == "" {
+				if isField {
+					continue
+				}
+				target = model
+			}
+			for _, input := range o.Inputs {
+				walkFields(target, input.Fields)
+			}
+			walkOutputs(target, o.Outputs)
+		}
+	}
+
+	for _, input := range q.Inputs {
+		walkFields(q.Model, input.Fields)
+	}
+	walkOutputs(q.Model, q.Outputs)
+
+	return models
+}
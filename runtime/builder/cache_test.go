@@ -0,0 +1,156 @@
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/steebchen/prisma-client-go/engine"
+	"github.com/steebchen/prisma-client-go/runtime/metadata"
+)
+
+var cacheSchema = metadata.Schema{
+	Models: []metadata.Model{{
+		Name: "Post",
+		Fields: []metadata.Field{
+			{Name: "id", Kind: "scalar", Type: "Int"},
+			{Name: "title", Kind: "scalar", Type: "String"},
+			{Name: "author", Kind: "object", Type: "User"},
+			{Name: "comments", Kind: "object", Type: "Comment", IsList: true},
+		},
+	}, {
+		Name: "User",
+		Fields: []metadata.Field{
+			{Name: "id", Kind: "scalar", Type: "Int"},
+			{Name: "team", Kind: "object", Type: "Team"},
+		},
+	}},
+}
+
+func TestCache(t *testing.T) {
+	e := &countingEngine{}
+	c := engine.NewCacheEngine(e, engine.NewMemoryCache(0), time.Minute, cacheSchema)
+	read := newUniqueQuery(nil, 1)
+	read.Engine = c
+	ctx := context.Background()
+
+	var a, b record
+	assert.NoError(t, read.Exec(ctx, &a))
+	assert.NoError(t, read.Exec(ctx, &b))
+	assert.Equal(t, record{ID: 1, Title: "1"}, a)
+	assert.Equal(t, a, b)
+	assert.EqualValues(t, 1, e.requests.Load())
+
+	// a write of the model drops the results
+	write := NewQuery()
+	write.Engine = c
+	write.Operation = "mutation"
+	write.Method = "deleteMany"
+	write.Model = "Post"
+	write.Outputs = []Output{{Name: "count"}}
+	var count json.RawMessage
+	assert.NoError(t, write.Exec(ctx, &count))
+	assert.NoError(t, read.Exec(ctx, &a))
+	assert.Equal(t, "3", a.Title)
+
+	// writes of other models and explicit invalidation
+	engine.InvalidateCache(c, "User")
+	assert.NoError(t, read.Exec(ctx, &a))
+	assert.EqualValues(t, 3, e.requests.Load())
+	engine.InvalidateCache(c, "Post")
+	assert.NoError(t, read.Exec(ctx, &a))
+	assert.EqualValues(t, 4, e.requests.Load())
+
+	// failed reads are not cached
+	failing := newUniqueQuery(nil, 2)
+	failing.Engine = c
+	assert.Error(t, failing.Exec(ctx, &a))
+	assert.Error(t, failing.Exec(ctx, &a))
+	assert.EqualValues(t, 6, e.requests.Load())
+
+	// reads within interactive transactions are not cached
+	read.Engine = &engine.TxEngine{Engine: c, ID: "tx"}
+	assert.NoError(t, read.Exec(ctx, &a))
+	assert.NoError(t, read.Exec(ctx, &a))
+	assert.EqualValues(t, 8, e.requests.Load())
+}
+
+// nopTransactor commits and rolls back transactions without sending requests
+type nopTransactor struct{}
+
+func (nopTransactor) StartTx(ctx context.Context, options engine.TxOptions) (string, error) {
+	return "tx", nil
+}
+
+func (nopTransactor) CommitTx(ctx context.Context, id string) error {
+	return nil
+}
+
+func (nopTransactor) RollbackTx(ctx context.Context, id string) error {
+	return nil
+}
+
+func TestCacheTx(t *testing.T) {
+	e := &countingEngine{}
+	c := engine.NewCacheEngine(e, engine.NewMemoryCache(0), time.Minute, cacheSchema)
+	read := newUniqueQuery(nil, 1)
+	read.Engine = c
+	ctx := context.Background()
+
+	write := func(tx *engine.TxEngine) {
+		q := NewQuery()
+		q.Engine = tx
+		q.Operation = "mutation"
+		q.Method = "deleteMany"
+		q.Model = "Post"
+		q.Outputs = []Output{{Name: "count"}}
+		var count json.RawMessage
+		assert.NoError(t, q.Exec(ctx, &count))
+	}
+
+	// a read outside of the transaction between its write and its commit is cached, as it may only see the data
+	// before the commit, but the commit drops it
+	tx := engine.NewTxEngine(c, "tx")
+	write(tx)
+	var a record
+	assert.NoError(t, read.Exec(ctx, &a))
+	assert.Equal(t, "2", a.Title)
+	assert.NoError(t, read.Exec(ctx, &a))
+	assert.Equal(t, "2", a.Title)
+	assert.NoError(t, tx.Commit(ctx, nopTransactor{}))
+	assert.NoError(t, read.Exec(ctx, &a))
+	assert.Equal(t, "3", a.Title)
+
+	// writes of rolled back transactions keep the results
+	tx = engine.NewTxEngine(c, "tx")
+	write(tx)
+	assert.NoError(t, tx.Rollback(ctx, nopTransactor{}))
+	assert.NoError(t, read.Exec(ctx, &a))
+	assert.Equal(t, "3", a.Title)
+	assert.EqualValues(t, 4, e.requests.Load())
+}
+
+func TestQueryModels(t *testing.T) {
+	q := newUniqueQuery(nil, 1)
+	q.Inputs = append(q.Inputs, Input{
+		Name: "where",
+		Fields: []Field{{
+			Name: "AND",
+			Fields: []Field{{
+				Name: "author",
+				Fields: []Field{{
+					Name:   "is",
+					Fields: []Field{{Name: "team", Fields: []Field{{Name: "is"}}}},
+				}},
+			}},
+		}},
+	})
+	assert.Equal(t, []string{"Post", "User", "Team"}, q.models(cacheSchema))
+
+	q = newUniqueQuery(nil, 1)
+	q.Outputs = append(q.Outputs, Output{Name: "comments", Outputs: []Output{{Name: "id"}}})
+	assert.Equal(t, []string{"Post", "Comment"}, q.models(cacheSchema))
+}
@@ -0,0 +1,27 @@
+package builder
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/steebchen/prisma-client-go/engine"
+	"github.com/steebchen/prisma-client-go/engine/protocol"
+)
+
+// fetch sends a query, sharing the result of an identical read which is already running if the engine coalesces reads
+func (q Query) fetch(ctx context.Context, payload protocol.GQLRequest, into interface{}) error {
+	c, ok := engine.CoalesceOf(q.Engine)
+	if !ok || q.Operation != "query" || c.Excludes(q.Model, q.Method) || isDryRun(ctx) {
+		return q.Do(ctx, payload, into)
+	}
+
+	data, err := c.Coalesce(ctx, q.Engine, payload.Query, func() (json.RawMessage, error) {
+		var data json.RawMessage
+		err := q.Do(ctx, payload, &data)
+		return data, err
+	})
+	if err != nil {
+		return err
+	}
+	return q.unmarshal(data, into)
+}
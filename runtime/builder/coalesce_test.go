@@ -0,0 +1,43 @@
+package builder
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/steebchen/prisma-client-go/engine"
+)
+
+func TestCoalesce(t *testing.T) {
+	e := &countingEngine{release: make(chan struct{})}
+	read := newUniqueQuery(nil, 1)
+	read.Engine = engine.NewCoalesceEngine(e)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	results := make([]record, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, read.Exec(ctx, &results[i]))
+		}(i)
+	}
+	// wait until all queries are waiting for the first one before releasing it
+	time.Sleep(50 * time.Millisecond)
+	close(e.release)
+	wg.Wait()
+
+	assert.EqualValues(t, 1, e.requests.Load())
+	for _, r := range results {
+		assert.Equal(t, record{ID: 1, Title: "1"}, r)
+	}
+
+	// excluded models are always sent
+	read.Engine = engine.NewCoalesceEngine(e, "Post")
+	assert.NoError(t, read.Exec(ctx, &results[0]))
+	assert.EqualValues(t, 2, e.requests.Load())
+}
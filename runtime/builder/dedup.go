@@ -0,0 +1,111 @@
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/steebchen/prisma-client-go/engine"
+	"github.com/steebchen/prisma-client-go/engine/protocol"
+)
+
+type dedupCtxKey struct{}
+
+// dedup memoizes the results of read queries sent with a context
+type dedup struct {
+	mu      sync.Mutex
+	results map[dedupKey]*dedupResult
+}
+
+// dedupKey identifies a read query by the engine it's sent to and its request
+type dedupKey struct {
+	engine engine.Engine
+	query  string
+}
+
+// dedupResult is the result of a read query, which is available once done is closed
+type dedupResult struct {
+	done chan struct{}
+	data json.RawMessage
+	err  error
+}
+
+// WithDedup returns a context in which identical read queries share their result, e.g. for the context of an HTTP
+// request whose handlers fetch the same records in several layers. A read query which is already running or completed
+// with the same request to the same client isn't sent again but returns the same result, decoded into separate values.
+// Writes sent with the context clear all results, so that later reads see them; failed reads are not kept.
+//
+// Example:
+//
+//	ctx := db.WithDedup(r.Context())
+//	user, err := client.User.FindUnique(db.User.ID.Equals(id)).Exec(ctx)
+//	// ...
+//	// sent to the engine only once
+//	user, err = client.User.FindUnique(db.User.ID.Equals(id)).Exec(ctx)
+func WithDedup(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dedupCtxKey{}, &dedup{
+		results: map[dedupKey]*dedupResult{},
+	})
+}
+
+func dedupOf(ctx context.Context) *dedup {
+	d, _ := ctx.Value(dedupCtxKey{}).(*dedup)
+	return d
+}
+
+// exec sends a read query unless a query with the same request is already running or completed, and decodes the
+// shared result into into. If the running query was canceled by its own context, the query is sent again with ctx.
+func (d *dedup) exec(ctx context.Context, q Query, payload protocol.GQLRequest, into interface{}) error {
+	key := dedupKey{engine: q.Engine, query: payload.Query}
+
+	d.mu.Lock()
+	r, ok := d.results[key]
+	if !ok {
+		r = &dedupResult{done: make(chan struct{})}
+		d.results[key] = r
+	}
+	d.mu.Unlock()
+
+	if ok {
+		select {
+		case <-r.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if (errors.Is(r.err, context.Canceled) || errors.Is(r.err, context.DeadlineExceeded)) && ctx.Err() == nil {
+			// the query was canceled by the context of its sender, which doesn't apply to this one
+			return d.exec(ctx, q, payload, into)
+		}
+	} else {
+		r.err = q.fetch(ctx, payload, &r.data)
+		if r.err != nil {
+			d.mu.Lock()
+			if d.results[key] == r {
+				delete(d.results, key)
+			}
+			d.mu.Unlock()
+		}
+		close(r.done)
+	}
+
+	if r.err != nil {
+		return r.err
+	}
+	return q.unmarshal(r.data, into)
+}
+
+// ClearDedup drops the read results shared within a context created with WithDedup, e.g. after writes which were not
+// sent as single queries, such as transactions
+func ClearDedup(ctx context.Context) {
+	if d := dedupOf(ctx); d != nil {
+		d.clear()
+	}
+}
+
+// clear drops all results after a write
+func (d *dedup) clear() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.results = map[dedupKey]*dedupResult{}
+}
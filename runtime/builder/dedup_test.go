@@ -0,0 +1,144 @@
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/steebchen/prisma-client-go/engine/protocol"
+)
+
+// countingEngine counts its requests and answers with a record whose title is the number of the request
+type countingEngine struct {
+	batchEngine
+	requests atomic.Int64
+	// release blocks requests until it's closed, if set
+	release chan struct{}
+}
+
+func (e *countingEngine) Do(ctx context.Context, payload interface{}, into interface{}) error {
+	n := e.requests.Add(1)
+	if e.release != nil {
+		<-e.release
+	}
+	if payload.(protocol.GQLRequest).Query == `query {result: findUniquePost(where:{id:2,}) {id title }}` {
+		return fmt.Errorf("failed")
+	}
+	return json.Unmarshal([]byte(fmt.Sprintf(`{"id":1,"title":"%d"}`, n)), into)
+}
+
+func TestWithDedup(t *testing.T) {
+	e := &countingEngine{}
+	read := newUniqueQuery(nil, 1)
+	read.Engine = e
+
+	ctx := WithDedup(context.Background())
+
+	var a, b record
+	assert.NoError(t, read.Exec(ctx, &a))
+	assert.NoError(t, read.Exec(ctx, &b))
+	assert.Equal(t, record{ID: 1, Title: "1"}, a)
+	assert.Equal(t, a, b)
+	assert.EqualValues(t, 1, e.requests.Load())
+
+	// a write clears the results
+	write := NewQuery()
+	write.Engine = e
+	write.Operation = "mutation"
+	write.Method = "deleteMany"
+	write.Model = "Post"
+	write.Outputs = []Output{{Name: "count"}}
+	var count json.RawMessage
+	assert.NoError(t, write.Exec(ctx, &count))
+	assert.NoError(t, read.Exec(ctx, &a))
+	assert.Equal(t, "3", a.Title)
+
+	// other contexts and failed reads are not shared
+	assert.NoError(t, read.Exec(context.Background(), &a))
+	failing := newUniqueQuery(nil, 2)
+	failing.Engine = e
+	assert.Error(t, failing.Exec(ctx, &a))
+	assert.Error(t, failing.Exec(ctx, &a))
+	assert.EqualValues(t, 6, e.requests.Load())
+}
+
+func TestWithDedupConcurrent(t *testing.T) {
+	e := &countingEngine{release: make(chan struct{})}
+	read := newUniqueQuery(nil, 1)
+	read.Engine = e
+
+	ctx := WithDedup(context.Background())
+
+	var wg sync.WaitGroup
+	results := make([]record, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, read.Exec(ctx, &results[i]))
+		}(i)
+	}
+	// wait until the first query is in flight before releasing it
+	for e.requests.Load() == 0 {
+		runtime.Gosched()
+	}
+	close(e.release)
+	wg.Wait()
+
+	assert.EqualValues(t, 1, e.requests.Load())
+	for _, r := range results {
+		assert.Equal(t, record{ID: 1, Title: "1"}, r)
+	}
+}
+
+// cancelingEngine blocks the first request until its context is done
+type cancelingEngine struct {
+	countingEngine
+}
+
+func (e *cancelingEngine) Do(ctx context.Context, payload interface{}, into interface{}) error {
+	if e.requests.Add(1) == 1 {
+		<-ctx.Done()
+		return fmt.Errorf("raw post: %w", ctx.Err())
+	}
+	return json.Unmarshal([]byte(`{"id":1,"title":"2"}`), into)
+}
+
+func TestWithDedupCanceled(t *testing.T) {
+	e := &cancelingEngine{}
+	read := newUniqueQuery(nil, 1)
+	read.Engine = e
+
+	ctx := WithDedup(context.Background())
+	leaderCtx, cancel := context.WithCancel(ctx)
+
+	leader := make(chan error, 1)
+	go func() {
+		var r record
+		leader <- read.Exec(leaderCtx, &r)
+	}()
+	for e.requests.Load() == 0 {
+		runtime.Gosched()
+	}
+
+	waiter := make(chan error, 1)
+	var r record
+	go func() {
+		waiter <- read.Exec(ctx, &r)
+	}()
+	// let the waiter wait for the running query before canceling it
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	assert.ErrorIs(t, <-leader, context.Canceled)
+	assert.NoError(t, <-waiter)
+	assert.Equal(t, record{ID: 1, Title: "2"}, r)
+	assert.EqualValues(t, 2, e.requests.Load())
+}
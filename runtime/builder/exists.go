@@ -0,0 +1,201 @@
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrUnresolvedExists is returned when a query containing an EXISTS filter is built without being executed, e.g. in a
+// transaction, or when the filter is nested in a relation filter
+var ErrUnresolvedExists = errors.New("exists filters are only supported in the top-level where-conditions of queries " +
+	"executed with Exec")
+
+// Ref references a field of a model. It correlates a subquery with the query it filters.
+type Ref struct {
+	Model string
+	Field string
+	// Optional is set if the field may be null
+	Optional bool
+}
+
+// Exists is the value of a filter matching records for which a subquery finds at least one record, or none if Not is
+// set. The where-conditions of the subquery must contain exactly one top-level field whose value is a Ref to a field
+// of the filtered model, which correlates the subquery with it, e.g. Order.userId = User.id.
+//
+// The query engine can't express correlated subqueries, so the filter is resolved before the query is sent: the
+// subquery is executed first, and the filter is replaced with an In (or NotIn) filter on the referenced field with the
+// distinct values of the correlated field. The subquery and the query are not executed atomically, unless they run in
+// an interactive transaction.
+type Exists struct {
+	Query Query
+	Not   bool
+}
+
+// resolveExists executes the subqueries of the EXISTS filters in the where-conditions of the query and replaces them
+// with the equivalent In filters
+func (q Query) resolveExists(ctx context.Context) (Query, error) {
+	for i, input := range q.Inputs {
+		if input.Name != "where" || !containsExists(input.Fields) {
+			continue
+		}
+
+		fields, err := q.resolveFields(ctx, input.Fields, false)
+		if err != nil {
+			return q, err
+		}
+
+		inputs := make([]Input, len(q.Inputs))
+		copy(inputs, q.Inputs)
+		inputs[i].Fields = fields
+		q.Inputs = inputs
+	}
+	return q, nil
+}
+
+// resolveFields resolves the EXISTS filters of where-conditions, including those nested in AND, OR and NOT. The
+// fields are the entries of a logical operator if list is set, and otherwise the fields of a single object, in which
+// the resolved filters are combined with AND so that they don't collide with other filters on the same field.
+func (q Query) resolveFields(ctx context.Context, fields []Field, list bool) ([]Field, error) {
+	resolved := make([]Field, 0, len(fields))
+	var conditions []Field
+	for _, f := range fields {
+		switch {
+		case isExists(f):
+			field, err := q.resolve(ctx, f.Value.(Exists))
+			if err != nil {
+				return nil, err
+			}
+			if list {
+				// entries of logical operators on the same field would be joined, so each is wrapped in AND
+				resolved = append(resolved, Field{Name: "AND", List: true, WrapList: true, Fields: []Field{field}})
+				continue
+			}
+			conditions = append(conditions, field)
+		case isOperator(f) && containsExists(f.Fields):
+			inner, err := q.resolveFields(ctx, f.Fields, true)
+			if err != nil {
+				return nil, err
+			}
+			f.Fields = inner
+			resolved = append(resolved, f)
+		default:
+			resolved = append(resolved, f)
+		}
+	}
+
+	if len(conditions) == 0 {
+		return resolved, nil
+	}
+	for i, f := range resolved {
+		if f.Name == "AND" {
+			resolved[i].Fields = append(append([]Field{}, f.Fields...), conditions...)
+			return resolved, nil
+		}
+	}
+	return append(resolved, Field{
+		Name:     "AND",
+		List:     true,
+		WrapList: true,
+		Fields:   conditions,
+	}), nil
+}
+
+// resolve executes the subquery of an EXISTS filter and returns the equivalent In filter
+func (q Query) resolve(ctx context.Context, e Exists) (Field, error) {
+	sub := e.Query
+	sub.Engine = q.Engine
+
+	var ref Ref
+	var correlated string
+	found := 0
+	for i, input := range sub.Inputs {
+		if input.Name != "where" {
+			continue
+		}
+
+		var where []Field
+		for _, f := range input.Fields {
+			if r, ok := f.Value.(Ref); ok {
+				ref, correlated = r, f.Name
+				found++
+				continue
+			}
+			where = append(where, f)
+		}
+
+		inputs := make([]Input, len(sub.Inputs))
+		copy(inputs, sub.Inputs)
+		inputs[i].Fields = where
+		sub.Inputs = inputs
+	}
+
+	if found != 1 {
+		return Field{}, fmt.Errorf("exists subquery on %s must reference the filtered model exactly once with EqualsRef, "+
+			"found %d references", sub.Model, found)
+	}
+	if ref.Model != q.Model {
+		return Field{}, fmt.Errorf("exists subquery on %s references %s.%s, but filters %s",
+			sub.Model, ref.Model, ref.Field, q.Model)
+	}
+
+	sub.Outputs = []Output{{Name: correlated}}
+
+	var rows []map[string]json.RawMessage
+	if err := sub.Exec(ctx, &rows); err != nil {
+		return Field{}, fmt.Errorf("exists subquery on %s: %w", sub.Model, err)
+	}
+
+	// null never equals a value, so records referencing nothing don't match
+	seen := make(map[string]bool, len(rows))
+	values := make([]json.RawMessage, 0, len(rows))
+	for _, row := range rows {
+		v := row[correlated]
+		if len(v) == 0 || string(v) == "null" || seen[string(v)] {
+			continue
+		}
+		seen[string(v)] = true
+		values = append(values, v)
+	}
+
+	if !e.Not {
+		return Action(ref.Field, "in", values), nil
+	}
+	if !ref.Optional {
+		return Action(ref.Field, "notIn", values), nil
+	}
+	// NOT IN never matches null, but no record of the subquery references null. The conditions on the same field are
+	// wrapped in AND, as they would be joined otherwise.
+	return Field{
+		Name:     "OR",
+		List:     true,
+		WrapList: true,
+		Fields: []Field{
+			{Name: "AND", List: true, WrapList: true, Fields: []Field{Action(ref.Field, "notIn", values)}},
+			Action(ref.Field, "equals", json.RawMessage("null")),
+		},
+	}, nil
+}
+
+func isExists(f Field) bool {
+	_, ok := f.Value.(Exists)
+	return ok
+}
+
+func isOperator(f Field) bool {
+	return f.Name == "AND" || f.Name == "OR" || f.Name == "NOT"
+}
+
+// containsExists reports whether fields contain an EXISTS filter which can be resolved
+func containsExists(fields []Field) bool {
+	for _, f := range fields {
+		if isExists(f) {
+			return true
+		}
+		if isOperator(f) && containsExists(f.Fields) {
+			return true
+		}
+	}
+	return false
+}
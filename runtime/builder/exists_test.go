@@ -0,0 +1,114 @@
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/steebchen/prisma-client-go/engine/protocol"
+)
+
+// existsEngine answers queries on Order with the given rows and records the other queries
+type existsEngine struct {
+	orders  string
+	queries []string
+}
+
+func (e *existsEngine) Connect() error    { return nil }
+func (e *existsEngine) Disconnect() error { return nil }
+func (e *existsEngine) Name() string      { return "exists" }
+
+func (e *existsEngine) Batch(ctx context.Context, payload interface{}, into interface{}) error {
+	return fmt.Errorf("not supported")
+}
+
+func (e *existsEngine) Do(ctx context.Context, payload interface{}, into interface{}) error {
+	q, _ := QueryFromContext(ctx)
+	e.queries = append(e.queries, payload.(protocol.GQLRequest).Query)
+	if q.Model == "Order" {
+		return json.Unmarshal([]byte(e.orders), into)
+	}
+	return json.Unmarshal([]byte(`[]`), into)
+}
+
+func existsQuery(e *existsEngine, not bool, optional bool, where ...Field) Query {
+	sub := NewQuery()
+	sub.Operation = "query"
+	sub.Method = "findMany"
+	sub.Model = "Order"
+	sub.Inputs = []Input{{
+		Name: "where",
+		Fields: []Field{
+			{Name: "userId", Value: Ref{Model: "User", Field: "id", Optional: optional}},
+			Action("total", "gt", 10),
+		},
+	}}
+
+	q := NewQuery()
+	q.Engine = e
+	q.Operation = "query"
+	q.Method = "findMany"
+	q.Model = "User"
+	q.Inputs = []Input{{
+		Name:   "where",
+		Fields: append(where, Field{Name: "exists", Value: Exists{Query: sub, Not: not}}),
+	}}
+	q.Outputs = []Output{{Name: "id"}}
+	return q
+}
+
+func TestExists(t *testing.T) {
+	e := &existsEngine{orders: `[{"userId":"a"},{"userId":"b"},{"userId":"a"},{"userId":null}]`}
+	q := existsQuery(e, false, false, Action("id", "in", []string{"a", "c"}))
+
+	var users []map[string]interface{}
+	assert.NoError(t, q.Exec(context.Background(), &users))
+
+	assert.Len(t, e.queries, 2)
+	assert.Contains(t, e.queries[0], `findManyOrder(where:{total:{gt:10,},}) {userId }`)
+	assert.Contains(t, e.queries[1], `findManyUser(where:{id:{in:["a","c"],},AND:[{id:{in:["a","b"],}},],})`)
+}
+
+func TestNotExists(t *testing.T) {
+	e := &existsEngine{orders: `[{"userId":"a"}]`}
+
+	var users []map[string]interface{}
+	assert.NoError(t, existsQuery(e, true, false).Exec(context.Background(), &users))
+	assert.Contains(t, e.queries[1], `AND:[{id:{notIn:["a"],}},]`)
+
+	e.queries = nil
+	assert.NoError(t, existsQuery(e, true, true).Exec(context.Background(), &users))
+	assert.Contains(t, e.queries[1], `AND:[{OR:[{AND:[{id:{notIn:["a"],}},]},{id:{equals:null,}},]},]`)
+}
+
+func TestExistsOr(t *testing.T) {
+	e := &existsEngine{orders: `[{"userId":"b"}]`}
+	q := existsQuery(e, false, false)
+	where := q.Inputs[0].Fields
+	q.Inputs[0].Fields = []Field{{
+		Name:     "OR",
+		List:     true,
+		WrapList: true,
+		Fields:   append([]Field{Action("id", "equals", "c")}, where...),
+	}}
+
+	var users []map[string]interface{}
+	assert.NoError(t, q.Exec(context.Background(), &users))
+	assert.Contains(t, e.queries[1], `findManyUser(where:{OR:[{id:{equals:"c",}},{AND:[{id:{in:["b"],}},]},],})`)
+}
+
+func TestExistsInvalid(t *testing.T) {
+	e := &existsEngine{orders: `[]`}
+
+	q := existsQuery(e, false, false)
+	q.Model = "Post"
+	var users []map[string]interface{}
+	assert.EqualError(t, q.Exec(context.Background(), &users),
+		"exists subquery on Order references User.id, but filters Post")
+
+	_, err := existsQuery(e, false, false).Debug()
+	assert.ErrorIs(t, err, ErrUnresolvedExists)
+}
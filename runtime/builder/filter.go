@@ -0,0 +1,229 @@
+package builder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/steebchen/prisma-client-go/runtime/metadata"
+	"github.com/steebchen/prisma-client-go/runtime/types"
+)
+
+// filterOperations contains the operations which untyped filters support by Prisma type, in addition to equals
+var filterOperations = map[string][]string{
+	"String":   {"not", "in", "notIn", "lt", "lte", "gt", "gte", "contains", "startsWith", "endsWith"},
+	"Int":      {"not", "in", "notIn", "lt", "lte", "gt", "gte"},
+	"BigInt":   {"not", "in", "notIn", "lt", "lte", "gt", "gte"},
+	"Float":    {"not", "in", "notIn", "lt", "lte", "gt", "gte"},
+	"Decimal":  {"not", "in", "notIn", "lt", "lte", "gt", "gte"},
+	"DateTime": {"not", "in", "notIn", "lt", "lte", "gt", "gte"},
+	"Boolean":  {"not"},
+	"enum":     {"not", "in", "notIn"},
+}
+
+// FilterFromJSON decodes a JSON object of untyped filters and converts it with FilterFromMap
+func FilterFromJSON(schema metadata.Schema, model string, data []byte) ([]Field, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var filters map[string]interface{}
+	if err := decoder.Decode(&filters); err != nil {
+		return nil, &types.InvalidFilterError{Model: model, Reason: fmt.Sprintf("invalid JSON: %s", err)}
+	}
+	return FilterFromMap(schema, model, filters)
+}
+
+// FilterFromMap converts untyped filters into where fields of a model. Keys are either the name of a field, which
+// matches records whose field equals the value, or the name of a field and an operation separated by an underscore,
+// e.g. email_contains or age_gte. Keys and values are validated against the schema, so that user input can be passed
+// safely; values may also be strings, e.g. from query parameters, which are parsed according to the type of the field.
+func FilterFromMap(schema metadata.Schema, model string, filters map[string]interface{}) ([]Field, error) {
+	m, ok := schema.Model(model)
+	if !ok {
+		return nil, fmt.Errorf("unknown model %s", model)
+	}
+
+	keys := make([]string, 0, len(filters))
+	for key := range filters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fields := make([]Field, 0, len(keys))
+	for _, key := range keys {
+		field, operation, ok := filterKey(m, key)
+		if !ok {
+			return nil, &types.InvalidFilterError{Model: model, Key: key, Reason: "unknown field"}
+		}
+
+		value, err := filterValue(schema, field, operation, filters[key])
+		if err != nil {
+			return nil, &types.InvalidFilterError{Model: model, Key: key, Reason: err.Error()}
+		}
+
+		fields = append(fields, Action(field.Name, operation, value))
+	}
+	return fields, nil
+}
+
+// filterKey splits a filter key into its field and operation, which is equals if the key is just a field name
+func filterKey(model metadata.Model, key string) (metadata.Field, string, bool) {
+	if field, ok := model.Field(key); ok {
+		return field, "equals", true
+	}
+	i := strings.LastIndex(key, "_")
+	if i < 0 {
+		return metadata.Field{}, "", false
+	}
+	field, ok := model.Field(key[:i])
+	return field, key[i+1:], ok
+}
+
+// filterValue validates an operation on a field and converts its value to the type of the field
+func filterValue(schema metadata.Schema, field metadata.Field, operation string, value interface{}) (interface{}, error) {
+	typ := field.Type
+	if field.Kind == "enum" {
+		typ = "enum"
+	}
+	operations, ok := filterOperations[typ]
+	if !ok || field.IsList || field.Kind == "object" || field.Kind == "composite" {
+		return nil, fmt.Errorf("field %s can't be filtered", field.Name)
+	}
+	if operation != "equals" && !slices.Contains(operations, operation) {
+		return nil, fmt.Errorf("unknown operation %s for a field of type %s", operation, field.Type)
+	}
+
+	if operation == "in" || operation == "notIn" {
+		rv := reflect.ValueOf(value)
+		if value == nil || rv.Kind() != reflect.Slice {
+			return nil, fmt.Errorf("expected a list")
+		}
+		values := make([]interface{}, rv.Len())
+		for i := range values {
+			v, err := scalarValue(schema, field, rv.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			values[i] = v
+		}
+		return values, nil
+	}
+
+	if value == nil {
+		if field.IsRequired || (operation != "equals" && operation != "not") {
+			return nil, fmt.Errorf("value can't be null")
+		}
+		// a typed nil is encoded as null, as untyped nil values are omitted from queries
+		return (*string)(nil), nil
+	}
+	return scalarValue(schema, field, value)
+}
+
+// scalarValue converts a single value to the type of a field
+func scalarValue(schema metadata.Schema, field metadata.Field, value interface{}) (interface{}, error) {
+	if field.Kind == "enum" {
+		s, ok := value.(string)
+		enum, _ := schema.Enum(field.Type)
+		if !ok || !slices.Contains(enum.Values, s) {
+			return nil, fmt.Errorf("expected one of %s", strings.Join(enum.Values, ", "))
+		}
+		return s, nil
+	}
+
+	switch field.Type {
+	case "String":
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+		return nil, fmt.Errorf("expected a string")
+	case "Boolean":
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			if b, err := strconv.ParseBool(v); err == nil {
+				return b, nil
+			}
+		}
+		return nil, fmt.Errorf("expected a boolean")
+	case "Int", "BigInt":
+		if i, ok := intValue(value); ok {
+			return i, nil
+		}
+		return nil, fmt.Errorf("expected an integer")
+	case "Float":
+		if f, ok := floatValue(value); ok {
+			return f, nil
+		}
+		return nil, fmt.Errorf("expected a number")
+	case "Decimal":
+		if d, err := decimal.NewFromString(fmt.Sprint(value)); err == nil {
+			return d, nil
+		}
+		return nil, fmt.Errorf("expected a decimal")
+	case "DateTime":
+		switch v := value.(type) {
+		case time.Time:
+			return v, nil
+		case string:
+			if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf("expected an RFC 3339 date")
+	}
+	return nil, fmt.Errorf("field %s can't be filtered", field.Name)
+}
+
+// intValue converts integers, integral floats, JSON numbers and numeric strings to an int64 without losing precision
+func intValue(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		i, err := v.Int64()
+		return i, err == nil
+	case string:
+		i, err := strconv.ParseInt(v, 10, 64)
+		return i, err == nil
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint()), rv.Uint() <= math.MaxInt64
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		return int64(f), f == math.Trunc(f) && math.Abs(f) < 1<<63
+	}
+	return 0, false
+}
+
+// floatValue converts numbers, JSON numbers and numeric strings to a float
+func floatValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
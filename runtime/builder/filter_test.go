@@ -0,0 +1,112 @@
+package builder
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/steebchen/prisma-client-go/runtime/metadata"
+	"github.com/steebchen/prisma-client-go/runtime/types"
+)
+
+var filterSchema = metadata.Schema{
+	Models: []metadata.Model{{
+		Name: "User",
+		Fields: []metadata.Field{
+			{Name: "id", Type: "String", Kind: "scalar", IsRequired: true, IsID: true},
+			{Name: "email", Type: "String", Kind: "scalar", IsRequired: true},
+			{Name: "first_name", Type: "String", Kind: "scalar"},
+			{Name: "age", Type: "Int", Kind: "scalar"},
+			{Name: "role", Type: "Role", Kind: "enum", IsRequired: true},
+			{Name: "tags", Type: "String", Kind: "scalar", IsList: true},
+			{Name: "posts", Type: "Post", Kind: "object", IsList: true},
+		},
+	}},
+	Enums: []metadata.Enum{{
+		Name:   "Role",
+		Values: []string{"USER", "ADMIN"},
+	}},
+}
+
+// whereString renders filters as the where input of a query
+func whereString(t *testing.T, fields []Field) string {
+	q := NewQuery()
+	s, err := q.buildInputs([]Input{{Name: "where", Fields: fields}})
+	assert.NoError(t, err)
+	return s
+}
+
+func TestFilterFromMap(t *testing.T) {
+	fields, err := FilterFromMap(filterSchema, "User", map[string]interface{}{
+		"email_contains": "@example.com",
+		"first_name":     "alice",
+		"age_gte":        "18",
+		"role_in":        []string{"USER", "ADMIN"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `(where:{age:{gte:18,},email:{contains:"@example.com",},first_name:{equals:"alice",},role:{in:["USER","ADMIN"],},})`, whereString(t, fields))
+}
+
+func TestFilterFromMapNull(t *testing.T) {
+	fields, err := FilterFromMap(filterSchema, "User", map[string]interface{}{
+		"age": nil,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `(where:{age:{equals:null,},})`, whereString(t, fields))
+}
+
+func TestFilterFromJSON(t *testing.T) {
+	fields, err := FilterFromJSON(filterSchema, "User", []byte(`{"age_lt": 9007199254740993}`))
+	assert.NoError(t, err)
+	assert.Equal(t, `(where:{age:{lt:9007199254740993,},})`, whereString(t, fields))
+}
+
+func TestFilterFromMapInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters map[string]interface{}
+		err     *types.InvalidFilterError
+	}{{
+		name:    "unknown field",
+		filters: map[string]interface{}{"password": "x"},
+		err:     &types.InvalidFilterError{Model: "User", Key: "password", Reason: "unknown field"},
+	}, {
+		name:    "unknown operation",
+		filters: map[string]interface{}{"age_contains": "1"},
+		err:     &types.InvalidFilterError{Model: "User", Key: "age_contains", Reason: "unknown operation contains for a field of type Int"},
+	}, {
+		name:    "invalid value",
+		filters: map[string]interface{}{"age": "x"},
+		err:     &types.InvalidFilterError{Model: "User", Key: "age", Reason: "expected an integer"},
+	}, {
+		name:    "invalid enum",
+		filters: map[string]interface{}{"role": "ROOT"},
+		err:     &types.InvalidFilterError{Model: "User", Key: "role", Reason: "expected one of USER, ADMIN"},
+	}, {
+		name:    "null on required field",
+		filters: map[string]interface{}{"email": nil},
+		err:     &types.InvalidFilterError{Model: "User", Key: "email", Reason: "value can't be null"},
+	}, {
+		name:    "list field",
+		filters: map[string]interface{}{"tags": "a"},
+		err:     &types.InvalidFilterError{Model: "User", Key: "tags", Reason: "field tags can't be filtered"},
+	}, {
+		name:    "relation",
+		filters: map[string]interface{}{"posts": "a"},
+		err:     &types.InvalidFilterError{Model: "User", Key: "posts", Reason: "field posts can't be filtered"},
+	}, {
+		name:    "in without list",
+		filters: map[string]interface{}{"id_in": "a"},
+		err:     &types.InvalidFilterError{Model: "User", Key: "id_in", Reason: "expected a list"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := FilterFromMap(filterSchema, "User", tt.filters)
+			var filterErr *types.InvalidFilterError
+			assert.True(t, errors.As(err, &filterErr))
+			assert.Equal(t, tt.err, filterErr)
+			assert.ErrorIs(t, err, types.ErrInvalidFilter)
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package builder
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// fieldHooks holds the client-side normalizers and defaults of fields, keyed by model and field name
+type fieldHooks struct {
+	mu          sync.RWMutex
+	normalizers map[string]interface{}
+	defaults    map[string]map[string]func() interface{}
+}
+
+var hooks = fieldHooks{
+	normalizers: map[string]interface{}{},
+	defaults:    map[string]map[string]func() interface{}{},
+}
+
+// RegisterNormalizer registers fn to normalize the values of a field before they are written by creates and updates,
+// e.g. to trim or lowercase emails. Registering another normalizer for the same field replaces the previous one.
+// Normalizers are global, so register them once at startup, e.g. in an init function.
+func RegisterNormalizer[T any](model, field string, fn func(T) T) {
+	hooks.mu.Lock()
+	defer hooks.mu.Unlock()
+	hooks.normalizers[model+"."+field] = fn
+}
+
+// Normalize applies the normalizer of a field to a value, if one is registered
+func Normalize[T any](model, field string, value T) T {
+	hooks.mu.RLock()
+	fn, ok := hooks.normalizers[model+"."+field].(func(T) T)
+	hooks.mu.RUnlock()
+	if !ok {
+		return value
+	}
+	return fn(value)
+}
+
+// RegisterDefault registers fn to provide the value of a field on create if it isn't set explicitly. Registering
+// another default for the same field replaces the previous one. Defaults are global, so register them once at startup.
+func RegisterDefault(model, field string, fn func() interface{}) {
+	hooks.mu.Lock()
+	defer hooks.mu.Unlock()
+	if hooks.defaults[model] == nil {
+		hooks.defaults[model] = map[string]func() interface{}{}
+	}
+	hooks.defaults[model][field] = fn
+}
+
+// withDefaults sets the fields with a client-side default which are not set explicitly on create. Only the created
+// model is handled; nested creates of related records are left as they are.
+func (q Query) withDefaults() Query {
+	if q.Operation != "mutation" {
+		return q
+	}
+
+	var name string
+	switch q.Method {
+	case "createOne":
+		name = "data"
+	case "upsertOne":
+		name = "create"
+	default:
+		return q
+	}
+
+	hooks.mu.RLock()
+	defaults := make(map[string]func() interface{}, len(hooks.defaults[q.Model]))
+	names := make([]string, 0, len(hooks.defaults[q.Model]))
+	for field, fn := range hooks.defaults[q.Model] {
+		defaults[field] = fn
+		names = append(names, field)
+	}
+	hooks.mu.RUnlock()
+	if len(names) == 0 {
+		return q
+	}
+	sort.Strings(names)
+
+	result := append([]Input{}, q.Inputs...)
+	for i, input := range result {
+		if input.Name != name {
+			continue
+		}
+
+		fields := append([]Field{}, input.Fields...)
+		for _, field := range names {
+			if hasField(fields, field) {
+				continue
+			}
+			fields = append(fields, Field{Name: field, Value: defaults[field]()})
+		}
+		result[i].Fields = fields
+	}
+
+	q.Inputs = result
+	return q
+}
+
+// HookEvent is the point of a write at which a lifecycle hook is called
+type HookEvent string
+
+const (
+	BeforeCreate HookEvent = "beforeCreate"
+	AfterCreate  HookEvent = "afterCreate"
+	BeforeUpdate HookEvent = "beforeUpdate"
+	AfterUpdate  HookEvent = "afterUpdate"
+	BeforeDelete HookEvent = "beforeDelete"
+	AfterDelete  HookEvent = "afterDelete"
+)
+
+// Hook is a lifecycle hook of a model. It is called with the query of the write, whose engine is the transaction
+// engine if the write runs within a transaction. The result is nil in before hooks and the decoded result of the write
+// in after hooks, or its raw JSON for writes of batch transactions.
+type Hook func(ctx context.Context, q Query, result interface{}) error
+
+// lifecycleHooks holds the lifecycle hooks of models, keyed by model and event
+var lifecycleHooks = struct {
+	mu    sync.RWMutex
+	hooks map[string]map[HookEvent][]Hook
+}{
+	hooks: map[string]map[HookEvent][]Hook{},
+}
+
+// RegisterHook registers fn to be called at an event of writes of a model. Hooks of the same event are called in the
+// order they are registered, and the first error aborts the write; errors of after hooks are returned after the write
+// took place, so run the write in a transaction to roll it back. Hooks are global, so register them once at startup.
+func RegisterHook(model string, event HookEvent, fn Hook) {
+	lifecycleHooks.mu.Lock()
+	defer lifecycleHooks.mu.Unlock()
+	if lifecycleHooks.hooks[model] == nil {
+		lifecycleHooks.hooks[model] = map[HookEvent][]Hook{}
+	}
+	lifecycleHooks.hooks[model][event] = append(lifecycleHooks.hooks[model][event], fn)
+}
+
+// RunBeforeHooks calls the before hooks of a create, update or delete. Nested writes of related records don't call
+// hooks. The records of createMany are passed to BeforeCreate one by one, so that each can be checked. Upserts are
+// passed to BeforeCreate with their create data and to BeforeUpdate with their update data, as either may be written.
+func (q Query) RunBeforeHooks(ctx context.Context) error {
+	if q.Operation != "mutation" {
+		return nil
+	}
+	switch q.Method {
+	case "createOne":
+		return q.runHooks(ctx, BeforeCreate, nil)
+	case "createMany":
+		for _, input := range q.Inputs {
+			if input.Name != "data" {
+				continue
+			}
+			for _, record := range input.Fields {
+				r := q
+				r.Inputs = []Input{{Name: "data", Fields: record.Fields}}
+				if err := r.runHooks(ctx, BeforeCreate, nil); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	case "updateOne", "updateMany", "updateManyAndReturn":
+		return q.runHooks(ctx, BeforeUpdate, nil)
+	case "upsertOne":
+		if err := q.withData("create").runHooks(ctx, BeforeCreate, nil); err != nil {
+			return err
+		}
+		return q.withData("update").runHooks(ctx, BeforeUpdate, nil)
+	case "deleteOne", "deleteMany":
+		return q.runHooks(ctx, BeforeDelete, nil)
+	}
+	return nil
+}
+
+// RunAfterHooks calls the after hooks of a create, update or delete with its result. Upserts don't call after hooks,
+// as it's unknown whether they created or updated the record.
+func (q Query) RunAfterHooks(ctx context.Context, result interface{}) error {
+	if q.Operation != "mutation" {
+		return nil
+	}
+	switch q.Method {
+	case "createOne", "createMany":
+		return q.runHooks(ctx, AfterCreate, result)
+	case "updateOne", "updateMany", "updateManyAndReturn":
+		return q.runHooks(ctx, AfterUpdate, result)
+	case "deleteOne", "deleteMany":
+		return q.runHooks(ctx, AfterDelete, result)
+	}
+	return nil
+}
+
+// withData returns the query with the input of the given name as its data, e.g. the create input of an upsert
+func (q Query) withData(name string) Query {
+	inputs := make([]Input, 0, len(q.Inputs))
+	for _, input := range q.Inputs {
+		switch input.Name {
+		case name:
+			input.Name = "data"
+		case "create", "update":
+			continue
+		}
+		inputs = append(inputs, input)
+	}
+	q.Inputs = inputs
+	return q
+}
+
+// runHooks calls the hooks of an event of the query's model in order and stops at the first error
+func (q Query) runHooks(ctx context.Context, event HookEvent, result interface{}) error {
+	lifecycleHooks.mu.RLock()
+	fns := lifecycleHooks.hooks[q.Model][event]
+	lifecycleHooks.mu.RUnlock()
+	for _, fn := range fns {
+		if err := fn(ctx, q, result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
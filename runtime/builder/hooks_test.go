@@ -0,0 +1,186 @@
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/steebchen/prisma-client-go/engine/protocol"
+)
+
+func TestNormalize(t *testing.T) {
+	RegisterNormalizer("HookUser", "email", func(v string) string {
+		return strings.ToLower(strings.TrimSpace(v))
+	})
+
+	assert.Equal(t, "a@example.com", Normalize("HookUser", "email", " A@example.com "))
+	// other fields and mismatching types are left unchanged
+	assert.Equal(t, " A ", Normalize("HookUser", "name", " A "))
+	assert.Equal(t, 1, Normalize("HookUser", "email", 1))
+}
+
+func TestWithDefaults(t *testing.T) {
+	RegisterDefault("HookPost", "locale", func() interface{} { return "en" })
+	RegisterDefault("HookPost", "status", func() interface{} { return "draft" })
+
+	q := NewQuery()
+	q.Operation = "mutation"
+	q.Method = "createOne"
+	q.Model = "HookPost"
+	q.Inputs = []Input{{
+		Name:   "data",
+		Fields: []Field{{Name: "title", Value: "a"}, {Name: "status", Value: "published"}},
+	}}
+	q.Outputs = []Output{{Name: "id"}}
+
+	str, err := q.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, `mutation {result: createOneHookPost(data:{title:"a",status:"published",locale:"en",}) {id }}`, str)
+
+	// updates don't get defaults
+	q.Method = "updateOne"
+	str, err = q.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, `mutation {result: updateOneHookPost(data:{title:"a",status:"published",}) {id }}`, str)
+}
+
+// hookEngine records the writes it executes in a shared log
+type hookEngine struct {
+	log *[]string
+}
+
+func (e hookEngine) Connect() error    { return nil }
+func (e hookEngine) Disconnect() error { return nil }
+func (e hookEngine) Name() string      { return "hook" }
+
+func (e hookEngine) Do(ctx context.Context, payload interface{}, into interface{}) error {
+	*e.log = append(*e.log, "write")
+	*into.(*string) = "result"
+	return nil
+}
+
+func (e hookEngine) Batch(ctx context.Context, payload interface{}, into interface{}) error {
+	result := into.(*protocol.GQLBatchResponse)
+	for range payload.(protocol.GQLBatchRequest).Batch {
+		*e.log = append(*e.log, "write")
+		result.Result = append(result.Result, protocol.GQLResponse{Data: protocol.Data{Result: json.RawMessage(`{}`)}})
+	}
+	return nil
+}
+
+func TestRegisterHook(t *testing.T) {
+	var log []string
+	RegisterHook("HookComment", BeforeCreate, func(ctx context.Context, q Query, result interface{}) error {
+		log = append(log, fmt.Sprintf("before %s %v", q.Method, q.Data()["content"]))
+		return nil
+	})
+	RegisterHook("HookComment", AfterCreate, func(ctx context.Context, q Query, result interface{}) error {
+		log = append(log, fmt.Sprintf("after %s %s", q.Method, *result.(*string)))
+		return nil
+	})
+	RegisterHook("HookComment", BeforeDelete, func(ctx context.Context, q Query, result interface{}) error {
+		return fmt.Errorf("comments can't be deleted")
+	})
+
+	q := NewQuery()
+	q.Engine = hookEngine{log: &log}
+	q.Operation = "mutation"
+	q.Method = "createOne"
+	q.Model = "HookComment"
+	q.Inputs = []Input{{Name: "data", Fields: []Field{{Name: "content", Value: "a"}}}}
+	q.Outputs = []Output{{Name: "id"}}
+
+	var result string
+	assert.NoError(t, q.Exec(context.Background(), &result))
+	assert.Equal(t, []string{"before createOne a", "write", "after createOne result"}, log)
+
+	// an error of a before hook aborts the write
+	log = nil
+	q.Method = "deleteOne"
+	assert.EqualError(t, q.Exec(context.Background(), &result), "comments can't be deleted")
+	assert.Empty(t, log)
+
+	// reads don't call hooks
+	q.Operation = "query"
+	q.Method = "findUnique"
+	assert.NoError(t, q.Exec(context.Background(), &result))
+	assert.Equal(t, []string{"write"}, log)
+}
+
+func TestRegisterHookBulkWrites(t *testing.T) {
+	var log []string
+	RegisterHook("HookTag", BeforeCreate, func(ctx context.Context, q Query, result interface{}) error {
+		if q.Data()["name"] == "forbidden" {
+			return fmt.Errorf("forbidden tag")
+		}
+		log = append(log, fmt.Sprintf("before create %s %v", q.Method, q.Data()["name"]))
+		return nil
+	})
+	RegisterHook("HookTag", BeforeUpdate, func(ctx context.Context, q Query, result interface{}) error {
+		log = append(log, fmt.Sprintf("before update %s %v", q.Method, q.Data()["name"]))
+		return nil
+	})
+	RegisterHook("HookTag", AfterCreate, func(ctx context.Context, q Query, result interface{}) error {
+		log = append(log, fmt.Sprintf("after create %s", q.Method))
+		return nil
+	})
+
+	record := func(name string) Field {
+		return Field{Fields: []Field{{Name: "id", Value: name}, {Name: "name", Value: name}}}
+	}
+
+	q := NewQuery()
+	q.Engine = hookEngine{log: &log}
+	q.Operation = "mutation"
+	q.Method = "createMany"
+	q.Model = "HookTag"
+	q.Inputs = []Input{{Name: "data", Fields: []Field{record("a"), record("b")}, WrapList: true}}
+	q.Outputs = []Output{{Name: "count"}}
+
+	// each record of createMany is passed to before hooks
+	var result string
+	assert.NoError(t, q.Exec(context.Background(), &result))
+	assert.Equal(t, []string{
+		"before create createMany a",
+		"before create createMany b",
+		"write",
+		"after create createMany",
+	}, log)
+
+	// an invalid record aborts the whole write
+	log = nil
+	q.Inputs = []Input{{Name: "data", Fields: []Field{record("a"), record("forbidden")}, WrapList: true}}
+	assert.EqualError(t, q.Exec(context.Background(), &result), "forbidden tag")
+	assert.Equal(t, []string{"before create createMany a"}, log)
+
+	// upserts are passed to the before hooks of both creates and updates
+	log = nil
+	u := q
+	u.Method = "upsertOne"
+	u.Inputs = []Input{
+		{Name: "where", Fields: []Field{{Name: "id", Value: "a"}}},
+		{Name: "create", Fields: []Field{{Name: "id", Value: "a"}, {Name: "name", Value: "a"}}},
+		{Name: "update", Fields: []Field{{Name: "name", Value: "b"}}},
+	}
+	assert.NoError(t, u.Exec(context.Background(), &result))
+	assert.Equal(t, []string{"before create upsertOne a", "before update upsertOne b", "write"}, log)
+
+	// createMany with UpdateDuplicates runs each record as an upsert
+	log = nil
+	q.Inputs = []Input{{Name: "data", Fields: []Field{record("a"), record("b")}, WrapList: true}}
+	count, err := q.ExecUpserts(context.Background(), []Constraint{{Name: "id", Fields: []string{"id"}}})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, []string{
+		"before create upsertOne a",
+		"before update upsertOne map[set:a]",
+		"before create upsertOne b",
+		"before update upsertOne map[set:b]",
+		"write",
+		"write",
+	}, log)
+}
@@ -0,0 +1,211 @@
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/steebchen/prisma-client-go/runtime/results"
+)
+
+// Iterator iterates over the records of a findMany query, which are fetched in batches using a cursor on the primary
+// key of the model, so that large result sets don't have to be loaded at once. The query is ordered by the primary key
+// after any other order, which keeps the batches stable.
+//
+//	it := client.Event.FindMany().Iter(ctx, 500)
+//	for it.Next() {
+//		handle(it.Value())
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+type Iterator[T any] struct {
+	ctx   context.Context
+	query Query
+	size  int
+	// key is the name of the unique input of the primary key, which equals the field name for single-field keys
+	key    string
+	fields []string
+
+	batch  []T
+	index  int
+	cursor []Field
+	done   bool
+	err    error
+}
+
+// NewIterator returns an iterator over a findMany query, which fetches batchSize records per query and pages using the
+// primary key made up of the given fields. key is the name of the compound unique input of multi-field primary keys.
+func NewIterator[T any](ctx context.Context, q Query, batchSize int, key string, fields []string) *Iterator[T] {
+	it := &Iterator[T]{
+		ctx:    ctx,
+		size:   batchSize,
+		key:    key,
+		fields: fields,
+		index:  -1,
+	}
+	it.query, it.err = q.iterQuery(batchSize, fields)
+	if it.err != nil {
+		it.done = true
+	}
+	return it
+}
+
+// Next advances to the next record, fetching the next batch when needed, and returns false when all records were
+// read or an error occurred
+func (it *Iterator[T]) Next() bool {
+	if it.index+1 < len(it.batch) {
+		it.index++
+		return true
+	}
+	if it.done {
+		return false
+	}
+	if err := it.fetch(); err != nil {
+		it.err = err
+		it.done = true
+		return false
+	}
+	if len(it.batch) == 0 {
+		return false
+	}
+	it.index = 0
+	return true
+}
+
+// Value returns the current record
+func (it *Iterator[T]) Value() T {
+	return it.batch[it.index]
+}
+
+// Err returns the error which stopped the iteration, if any
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// All returns the remaining records as a sequence, which can be used with the helpers of the results package or
+// ranged over with Go 1.23. Check Err after the iteration, as errors end the sequence.
+func (it *Iterator[T]) All() results.Seq[T] {
+	return func(yield func(T) bool) {
+		for it.Next() {
+			if !yield(it.Value()) {
+				return
+			}
+		}
+	}
+}
+
+// fetch fetches the batch after the last record of the current one
+func (it *Iterator[T]) fetch() error {
+	q := it.query
+	if it.cursor != nil {
+		q.Inputs = append(append([]Input{}, q.Inputs...),
+			Input{Name: "cursor", Fields: it.cursor},
+			Input{Name: "skip", Value: 1},
+		)
+	}
+
+	var records []json.RawMessage
+	if err := q.Exec(it.ctx, &records); err != nil {
+		return err
+	}
+
+	batch := make([]T, len(records))
+	for i, record := range records {
+		if err := it.query.unmarshal(record, &batch[i]); err != nil {
+			return fmt.Errorf("json record unmarshal: %w", err)
+		}
+	}
+	it.batch = batch
+	it.index = -1
+
+	if len(records) < it.size {
+		it.done = true
+		return nil
+	}
+
+	cursor, err := it.cursorOf(records[len(records)-1])
+	if err != nil {
+		return err
+	}
+	it.cursor = cursor
+	return nil
+}
+
+// cursorOf returns the cursor pointing at the given record
+func (it *Iterator[T]) cursorOf(record json.RawMessage) ([]Field, error) {
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(record, &values); err != nil {
+		return nil, fmt.Errorf("json cursor unmarshal: %w", err)
+	}
+
+	var fields []Field
+	for _, name := range it.fields {
+		value, ok := values[name]
+		if !ok {
+			return nil, fmt.Errorf("record is missing the primary key field %s", name)
+		}
+		fields = append(fields, Field{Name: name, Value: value})
+	}
+
+	if len(fields) == 1 {
+		return fields, nil
+	}
+	return []Field{{Name: it.key, Fields: fields}}, nil
+}
+
+// iterQuery returns the query which fetches a batch of records, ordered by the primary key and including its fields
+func (q Query) iterQuery(batchSize int, fields []string) (Query, error) {
+	if q.Method != "findMany" {
+		return Query{}, fmt.Errorf("iteration requires a findMany query, got %s", q.Method)
+	}
+	if batchSize < 1 {
+		return Query{}, fmt.Errorf("invalid batch size %d, must be at least 1", batchSize)
+	}
+	if len(fields) == 0 {
+		return Query{}, fmt.Errorf("iteration requires a primary key")
+	}
+
+	var inputs []Input
+	var orderBy []Field
+	for _, input := range q.Inputs {
+		switch input.Name {
+		case "skip", "take", "cursor":
+			return Query{}, fmt.Errorf("iteration can't be combined with %s", input.Name)
+		case "orderBy":
+			orderBy = append(orderBy, input.Fields...)
+			continue
+		}
+		inputs = append(inputs, input)
+	}
+
+	for _, name := range fields {
+		if !hasField(orderBy, name) {
+			orderBy = append(orderBy, Field{Name: name, Value: "asc"})
+		}
+	}
+	inputs = append(inputs,
+		Input{Name: "orderBy", Fields: orderBy, WrapList: true},
+		Input{Name: "take", Value: batchSize},
+	)
+
+	outputs := append([]Output{}, q.Outputs...)
+	for _, name := range fields {
+		if !hasOutput(outputs, name) {
+			outputs = append(outputs, Output{Name: name})
+		}
+	}
+
+	q.Inputs = inputs
+	q.Outputs = outputs
+	return q, nil
+}
+
+func hasOutput(outputs []Output, name string) bool {
+	for _, o := range outputs {
+		if o.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/steebchen/prisma-client-go/runtime/results"
+)
+
+// iterEngine answers findMany queries with the records after the cursor, and records the queries it receives
+type iterEngine struct {
+	total   int
+	queries []string
+}
+
+func (e *iterEngine) Connect() error    { return nil }
+func (e *iterEngine) Disconnect() error { return nil }
+func (e *iterEngine) Name() string      { return "iter" }
+
+func (e *iterEngine) Batch(ctx context.Context, payload interface{}, into interface{}) error {
+	return fmt.Errorf("not supported")
+}
+
+func (e *iterEngine) Do(ctx context.Context, payload interface{}, into interface{}) error {
+	q, _ := QueryFromContext(ctx)
+	query, err := q.Build()
+	if err != nil {
+		return err
+	}
+	e.queries = append(e.queries, query)
+
+	start, take := 1, 0
+	for _, input := range q.Inputs {
+		switch input.Name {
+		case "cursor":
+			var id int
+			if err := json.Unmarshal(input.Fields[0].Value.(json.RawMessage), &id); err != nil {
+				return err
+			}
+			start = id
+		case "skip":
+			start += input.Value.(int)
+		case "take":
+			take = input.Value.(int)
+		}
+	}
+
+	records := []record{}
+	for id := start; id <= e.total && len(records) < take; id++ {
+		records = append(records, record{ID: id, Title: fmt.Sprintf("%d", id)})
+	}
+
+	data, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, into)
+}
+
+func newIterQuery(e *iterEngine, inputs ...Input) Query {
+	q := NewQuery()
+	q.Engine = e
+	q.Operation = "query"
+	q.Name = "findManyPost"
+	q.Method = "findMany"
+	q.Model = "Post"
+	q.Inputs = inputs
+	q.Outputs = []Output{{Name: "title"}}
+	return q
+}
+
+func TestIterator(t *testing.T) {
+	e := &iterEngine{total: 5}
+	it := NewIterator[record](context.Background(), newIterQuery(e), 2, "id", []string{"id"})
+
+	var ids []int
+	for it.Next() {
+		ids = append(ids, it.Value().ID)
+	}
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, ids)
+	assert.Equal(t, []string{
+		`query findManyPost{result: findManyPost(orderBy:[{id:"asc"},],take:2) {title id }}`,
+		`query findManyPost{result: findManyPost(orderBy:[{id:"asc"},],take:2,cursor:{id:2,},skip:1) {title id }}`,
+		`query findManyPost{result: findManyPost(orderBy:[{id:"asc"},],take:2,cursor:{id:4,},skip:1) {title id }}`,
+	}, e.queries)
+}
+
+func TestIteratorExactBatches(t *testing.T) {
+	e := &iterEngine{total: 4}
+	it := NewIterator[record](context.Background(), newIterQuery(e), 2, "id", []string{"id"})
+
+	titles := results.Collect(results.MapSeq(it.All(), func(r record) string {
+		return r.Title
+	}))
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []string{"1", "2", "3", "4"}, titles)
+	assert.Equal(t, 3, len(e.queries))
+}
+
+func TestIteratorOrderBy(t *testing.T) {
+	q := newIterQuery(&iterEngine{}, Input{
+		Name:     "orderBy",
+		Fields:   []Field{{Name: "title", Value: "desc"}},
+		WrapList: true,
+	})
+
+	iter, err := q.iterQuery(10, []string{"id"})
+	assert.NoError(t, err)
+	query, err := iter.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, `query findManyPost{result: findManyPost(orderBy:[{title:"desc"},{id:"asc"},],take:10) {title id }}`, query)
+}
+
+func TestIteratorInvalid(t *testing.T) {
+	e := &iterEngine{}
+
+	it := NewIterator[record](context.Background(), newIterQuery(e, Input{Name: "take", Value: 3}), 2, "id", []string{"id"})
+	assert.False(t, it.Next())
+	assert.EqualError(t, it.Err(), "iteration can't be combined with take")
+
+	it = NewIterator[record](context.Background(), newIterQuery(e), 0, "id", []string{"id"})
+	assert.False(t, it.Next())
+	assert.EqualError(t, it.Err(), "invalid batch size 0, must be at least 1")
+
+	assert.Empty(t, e.queries)
+}
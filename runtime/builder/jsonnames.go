@@ -0,0 +1,106 @@
+package builder
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+
+	"github.com/steebchen/prisma-client-go/engine"
+	"github.com/steebchen/prisma-client-go/runtime/metadata"
+)
+
+// renamesJSON returns whether results of the query are renamed to the JSON names of the generated models before
+// they're decoded
+func (q Query) renamesJSON() bool {
+	_, ok := engine.JSONNamesOf(q.Engine)
+	return ok && q.Model != ""
+}
+
+// decodesStruct returns whether a value decodes JSON objects into structs, e.g. a model or a slice of models
+func decodesStruct(t reflect.Type) bool {
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice) {
+		t = t.Elem()
+	}
+	return t != nil && t.Kind() == reflect.Struct
+}
+
+// renameResult replaces the field names of a result of a model with the JSON names of the generated models, including
+// the fields of the relations it fetched
+func renameResult(schema metadata.Schema, model string, outputs []Output, data json.RawMessage) (json.RawMessage, error) {
+	m, ok := schema.Model(model)
+	if !ok {
+		return data, nil
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case len(trimmed) > 0 && trimmed[0] == '[':
+		var items []json.RawMessage
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return nil, err
+		}
+		for i, item := range items {
+			renamed, err := renameResult(schema, model, outputs, item)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = renamed
+		}
+		return json.Marshal(items)
+	case len(trimmed) > 0 && trimmed[0] == '{':
+	default:
+		return data, nil
+	}
+
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(trimmed, &values); err != nil {
+		return nil, err
+	}
+
+	for _, o := range outputs {
+		value, ok := values[o.Name]
+		if !ok {
+			continue
+		}
+		f, ok := m.Field(o.Name)
+		if !ok {
+			continue
+		}
+		if f.IsRelation() && len(o.Outputs) > 0 {
+			renamed, err := renameResult(schema, f.Type, o.Outputs, value)
+			if err != nil {
+				return nil, err
+			}
+			value = renamed
+		}
+		if f.JSON != "" {
+			delete(values, o.Name)
+		}
+		values[f.JSONName()] = value
+	}
+
+	return json.Marshal(values)
+}
+
+// renameOutputs returns the outputs of a model with the JSON names of the generated models, as the results are named
+// after renameResult
+func renameOutputs(schema metadata.Schema, model string, outputs []Output) []Output {
+	m, ok := schema.Model(model)
+	if !ok {
+		return outputs
+	}
+
+	renamed := make([]Output, len(outputs))
+	for i, o := range outputs {
+		renamed[i] = o
+		f, ok := m.Field(o.Name)
+		if !ok {
+			continue
+		}
+		renamed[i].Name = f.JSONName()
+		if f.IsRelation() {
+			renamed[i].Outputs = renameOutputs(schema, f.Type, o.Outputs)
+		}
+	}
+	return renamed
+}
@@ -0,0 +1,83 @@
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/steebchen/prisma-client-go/engine"
+	"github.com/steebchen/prisma-client-go/runtime/metadata"
+)
+
+var jsonNamesSchema = metadata.Schema{
+	Models: []metadata.Model{{
+		Name: "Post",
+		Fields: []metadata.Field{
+			{Name: "id", Kind: "scalar"},
+			{Name: "postTitle", Kind: "scalar", JSON: "post_title"},
+			{Name: "author", Kind: "object", Type: "User"},
+		},
+	}, {
+		Name: "User",
+		Fields: []metadata.Field{
+			{Name: "firstName", Kind: "scalar", JSON: "first_name"},
+		},
+	}},
+}
+
+type jsonNamesPost struct {
+	ID        int            `json:"id"`
+	PostTitle string         `json:"post_title"`
+	Author    *jsonNamesUser `json:"author,omitempty"`
+}
+
+type jsonNamesUser struct {
+	FirstName string `json:"first_name"`
+}
+
+func newJSONNamesQuery(e engine.Engine) Query {
+	q := NewQuery()
+	q.Engine = e
+	q.Operation = "query"
+	q.Method = "findMany"
+	q.Model = "Post"
+	q.Outputs = []Output{
+		{Name: "id"},
+		{Name: "postTitle"},
+		{Name: "author", Outputs: []Output{{Name: "firstName"}}},
+	}
+	return q
+}
+
+func TestJSONNames(t *testing.T) {
+	result := `[{"id":1,"postTitle":"a","author":{"firstName":"x"}},{"id":2,"postTitle":"b","author":null}]`
+	e := engine.NewJSONNamesEngine(&resultEngine{result: result}, jsonNamesSchema)
+
+	var records []jsonNamesPost
+	assert.NoError(t, newJSONNamesQuery(e).Exec(context.Background(), &records))
+	assert.Equal(t, []jsonNamesPost{
+		{ID: 1, PostTitle: "a", Author: &jsonNamesUser{FirstName: "x"}},
+		{ID: 2, PostTitle: "b"},
+	}, records)
+}
+
+func TestJSONNamesStrict(t *testing.T) {
+	result := `[{"id":1,"postTitle":"a","author":{"firstName":"x"}}]`
+	e := engine.NewStrictEngine(engine.NewJSONNamesEngine(&resultEngine{result: result}, jsonNamesSchema))
+
+	var records []jsonNamesPost
+	assert.NoError(t, newJSONNamesQuery(e).Exec(context.Background(), &records))
+	assert.Equal(t, "x", records[0].Author.FirstName)
+}
+
+func TestJSONNamesRaw(t *testing.T) {
+	result := `[{"id":1,"postTitle":"a","author":null}]`
+	e := engine.NewJSONNamesEngine(&resultEngine{result: result}, jsonNamesSchema)
+
+	// results which aren't decoded into structs keep the names of the schema
+	var records []json.RawMessage
+	assert.NoError(t, newJSONNamesQuery(e).Exec(context.Background(), &records))
+	assert.JSONEq(t, `{"id":1,"postTitle":"a","author":null}`, string(records[0]))
+}
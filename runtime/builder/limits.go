@@ -0,0 +1,102 @@
+package builder
+
+import (
+	"reflect"
+
+	"github.com/steebchen/prisma-client-go/engine"
+	"github.com/steebchen/prisma-client-go/runtime/types"
+)
+
+// checkLimits returns a QueryLimitError if the query exceeds the limits attached to its engine
+func (q Query) checkLimits(limits engine.QueryLimits) error {
+	if limits.MaxDepth > 0 {
+		if depth := q.depth(); depth > limits.MaxDepth {
+			return &types.QueryLimitError{Limit: "depth", Max: limits.MaxDepth, Actual: depth}
+		}
+	}
+
+	if limits.MaxListLength > 0 {
+		if length := maxInputListLength(q.Inputs); length > limits.MaxListLength {
+			return &types.QueryLimitError{Limit: "list length", Max: limits.MaxListLength, Actual: length}
+		}
+	}
+
+	if limits.MaxIncludes > 0 {
+		if includes := countIncludes(q.Outputs); includes > limits.MaxIncludes {
+			return &types.QueryLimitError{Limit: "includes", Max: limits.MaxIncludes, Actual: includes}
+		}
+	}
+
+	return nil
+}
+
+// depth returns the maximum nesting of the inputs and outputs of the query
+func (q Query) depth() int {
+	depth := 0
+	for _, input := range q.Inputs {
+		depth = max(depth, 1+fieldsDepth(input.Fields))
+	}
+	return max(depth, outputsDepth(q.Outputs))
+}
+
+func fieldsDepth(fields []Field) int {
+	depth := 0
+	for _, f := range fields {
+		depth = max(depth, 1+fieldsDepth(f.Fields))
+	}
+	return depth
+}
+
+func outputsDepth(outputs []Output) int {
+	depth := 0
+	for _, o := range outputs {
+		d := 1 + outputsDepth(o.Outputs)
+		for _, input := range o.Inputs {
+			d = max(d, 1+fieldsDepth(input.Fields))
+		}
+		depth = max(depth, d)
+	}
+	return depth
+}
+
+// maxInputListLength returns the length of the longest list value or list of fields
+func maxInputListLength(inputs []Input) int {
+	length := 0
+	for _, input := range inputs {
+		length = max(length, listLength(input.Value), maxFieldListLength(input.Fields))
+	}
+	return length
+}
+
+func maxFieldListLength(fields []Field) int {
+	length := 0
+	for _, f := range fields {
+		length = max(length, listLength(f.Value), maxFieldListLength(f.Fields))
+		if f.List {
+			length = max(length, len(f.Fields))
+		}
+	}
+	return length
+}
+
+func listLength(value interface{}) int {
+	if value == nil {
+		return 0
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice || v.Type().Elem().Kind() == reflect.Uint8 {
+		return 0
+	}
+	return v.Len()
+}
+
+// countIncludes counts the outputs which fetch a relation, i.e. which select nested outputs
+func countIncludes(outputs []Output) int {
+	count := 0
+	for _, o := range outputs {
+		if len(o.Outputs) > 0 {
+			count += 1 + countIncludes(o.Outputs)
+		}
+	}
+	return count
+}
@@ -0,0 +1,136 @@
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/steebchen/prisma-client-go/engine"
+	"github.com/steebchen/prisma-client-go/engine/protocol"
+)
+
+// ExecPage executes a findMany query for the given page of perPage records, starting at 1, and counts all records
+// matching its filter in the same batched request. Both queries run in one transaction, so the total is consistent
+// with the returned records. Skip and Take of the query are replaced by the page.
+func (q Query) ExecPage(ctx context.Context, page, perPage int, into interface{}) (int, error) {
+	if q.Engine == nil {
+		return 0, fmt.Errorf("client.Prisma.Connect() needs to be called before sending queries")
+	}
+
+	q, err := q.resolveExists(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	find, count, err := q.pageQueries(page, perPage)
+	if err != nil {
+		return 0, err
+	}
+
+	findPayload, err := find.payload()
+	if err != nil {
+		return 0, err
+	}
+	countPayload, err := count.payload()
+	if err != nil {
+		return 0, err
+	}
+
+	l := engine.LoggerOf(q.Engine)
+	l.DebugContext(ctx, "query built", "model", q.Model, "action", q.Method, "duration", time.Since(q.Start))
+
+	var result protocol.GQLBatchResponse
+	payload := protocol.GQLBatchRequest{
+		Batch:       []protocol.GQLRequest{findPayload, countPayload},
+		Transaction: true,
+	}
+	err = q.Engine.Batch(ctx, payload, &result)
+	if err == nil {
+		err = batchError(result, 2)
+	}
+	q.log(ctx, l, err)
+	if err != nil {
+		return 0, err
+	}
+
+	var total struct {
+		Count struct {
+			All int `json:"_all"`
+		} `json:"_count"`
+	}
+	if err := find.unmarshal(result.Result[0].Data.Result, into); err != nil {
+		return 0, fmt.Errorf("json data result unmarshal: %w", err)
+	}
+	if err := json.Unmarshal(result.Result[1].Data.Result, &total); err != nil {
+		return 0, fmt.Errorf("json count result unmarshal: %w", err)
+	}
+
+	return total.Count.All, nil
+}
+
+// pageQueries returns the findMany query for a page and the aggregate query counting all records matching its filter
+func (q Query) pageQueries(page, perPage int) (Query, Query, error) {
+	if q.Method != "findMany" {
+		return Query{}, Query{}, fmt.Errorf("pagination requires a findMany query, got %s", q.Method)
+	}
+	if page < 1 {
+		return Query{}, Query{}, fmt.Errorf("invalid page %d, pages start at 1", page)
+	}
+	if perPage < 1 {
+		return Query{}, Query{}, fmt.Errorf("invalid page size %d, must be at least 1", perPage)
+	}
+
+	find := q
+	find.Inputs = nil
+	count := q
+	count.Name = "pageCount"
+	count.Method = "aggregate"
+	count.Inputs = nil
+	count.Outputs = []Output{{
+		Name:    "_count",
+		Outputs: []Output{{Name: "_all"}},
+	}}
+
+	for _, input := range q.Inputs {
+		switch input.Name {
+		case "skip", "take":
+			continue
+		case "cursor", "distinct":
+			return Query{}, Query{}, fmt.Errorf("pagination can't be combined with %s", input.Name)
+		case "where":
+			count.Inputs = append(count.Inputs, input)
+		}
+		find.Inputs = append(find.Inputs, input)
+	}
+
+	find.Inputs = append(find.Inputs,
+		Input{Name: "skip", Value: (page - 1) * perPage},
+		Input{Name: "take", Value: perPage},
+	)
+
+	return find, count, nil
+}
+
+// batchError returns the first error of a batch response, or an error if it doesn't contain n results
+func batchError(result protocol.GQLBatchResponse, n int) error {
+	errs := result.Errors
+	for _, inner := range result.Result {
+		errs = append(errs, inner.Errors...)
+	}
+	if len(errs) > 0 {
+		return gqlError(errs[0])
+	}
+	if len(result.Result) != n {
+		return fmt.Errorf("expected %d batch results, got %d", n, len(result.Result))
+	}
+	return nil
+}
+
+// gqlError returns the error of a query as reported by the engine
+func gqlError(e protocol.GQLError) error {
+	if e.UserFacingError != nil {
+		return fmt.Errorf("user facing error: %w", e.UserFacingError)
+	}
+	return fmt.Errorf("pql error: %s", e.RawMessage())
+}
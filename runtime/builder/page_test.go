@@ -0,0 +1,78 @@
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/steebchen/prisma-client-go/engine/protocol"
+)
+
+// pageEngine records the batch request and answers with a page of records and a total count
+type pageEngine struct {
+	payload protocol.GQLBatchRequest
+}
+
+func (e *pageEngine) Connect() error    { return nil }
+func (e *pageEngine) Disconnect() error { return nil }
+func (e *pageEngine) Name() string      { return "page" }
+
+func (e *pageEngine) Do(ctx context.Context, payload interface{}, into interface{}) error {
+	return fmt.Errorf("not supported")
+}
+
+func (e *pageEngine) Batch(ctx context.Context, payload interface{}, into interface{}) error {
+	e.payload = payload.(protocol.GQLBatchRequest)
+	return json.Unmarshal([]byte(`{"batchResult":[
+		{"data":{"result":[{"id":3,"title":"c"},{"id":4,"title":"d"}]}},
+		{"data":{"result":{"_count":{"_all":5}}}}
+	]}`), into)
+}
+
+func newPageQuery(e *pageEngine, inputs ...Input) Query {
+	q := NewQuery()
+	q.Engine = e
+	q.Operation = "query"
+	q.Name = "findManyPost"
+	q.Method = "findMany"
+	q.Model = "Post"
+	q.Inputs = append([]Input{{
+		Name:   "where",
+		Fields: []Field{{Name: "published", Value: true}},
+	}}, inputs...)
+	q.Outputs = []Output{{Name: "id"}, {Name: "title"}}
+	return q
+}
+
+func TestExecPage(t *testing.T) {
+	e := &pageEngine{}
+	q := newPageQuery(e, Input{Name: "skip", Value: 10}, Input{Name: "take", Value: 1})
+
+	var records []record
+	total, err := q.ExecPage(context.Background(), 2, 2, &records)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, total)
+	assert.Equal(t, []record{{ID: 3, Title: "c"}, {ID: 4, Title: "d"}}, records)
+
+	assert.True(t, e.payload.Transaction)
+	assert.Equal(t, 2, len(e.payload.Batch))
+	assert.Equal(t, `query findManyPost{result: findManyPost(where:{published:true,},skip:2,take:2) {id title }}`, e.payload.Batch[0].Query)
+	assert.Equal(t, `query pageCount{result: aggregatePost(where:{published:true,}) {_count {_all }}}`, e.payload.Batch[1].Query)
+}
+
+func TestExecPageInvalid(t *testing.T) {
+	e := &pageEngine{}
+
+	var records []record
+	_, err := newPageQuery(e).ExecPage(context.Background(), 0, 10, &records)
+	assert.EqualError(t, err, "invalid page 0, pages start at 1")
+
+	_, err = newPageQuery(e).ExecPage(context.Background(), 1, 0, &records)
+	assert.EqualError(t, err, "invalid page size 0, must be at least 1")
+
+	_, err = newPageQuery(e, Input{Name: "cursor", Fields: []Field{{Name: "id", Value: 1}}}).ExecPage(context.Background(), 1, 10, &records)
+	assert.EqualError(t, err, "pagination can't be combined with cursor")
+}
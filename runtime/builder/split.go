@@ -0,0 +1,345 @@
+package builder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/steebchen/prisma-client-go/engine"
+	"github.com/steebchen/prisma-client-go/runtime/types"
+)
+
+// splitIn splits a query whose top-level In filter contains more than limit values into multiple queries, each
+// filtering by a chunk of the values. It returns false if the query can't be split without changing its result.
+func (q Query) splitIn(limit int) ([]Query, bool) {
+	switch q.Method {
+	case "findMany", "updateMany", "deleteMany":
+	default:
+		return nil, false
+	}
+
+	where := -1
+	for i, input := range q.Inputs {
+		switch input.Name {
+		case "where":
+			where = i
+		case "cursor", "distinct":
+			return nil, false
+		case "take":
+			if n, ok := input.Value.(int); !ok || n < 0 {
+				return nil, false
+			}
+		case "orderBy":
+			if !q.canSort() {
+				return nil, false
+			}
+		}
+	}
+	if where < 0 {
+		return nil, false
+	}
+
+	field, in, values, ok := findLongestIn(q.Inputs[where].Fields)
+	if !ok {
+		return nil, false
+	}
+
+	values = unique(values)
+	if values.Len() <= limit {
+		return nil, false
+	}
+
+	var chunks []Query
+	for start := 0; start < values.Len(); start += limit {
+		end := min(start+limit, values.Len())
+
+		chunk := q
+		chunk.Inputs = append([]Input{}, q.Inputs...)
+		chunk.Inputs[where].Fields = append([]Field{}, q.Inputs[where].Fields...)
+
+		f := chunk.Inputs[where].Fields[field]
+		f.Fields = append([]Field{}, f.Fields...)
+		f.Fields[in].Value = values.Slice(start, end).Interface()
+		chunk.Inputs[where].Fields[field] = f
+
+		chunks = append(chunks, chunk.paginateChunk())
+	}
+
+	return chunks, true
+}
+
+// findLongestIn returns the positions and values of the longest In filter of the given where fields
+func findLongestIn(fields []Field) (int, int, reflect.Value, bool) {
+	field, in, length := -1, -1, 0
+	var values reflect.Value
+	for i, f := range fields {
+		for j, action := range f.Fields {
+			if action.Name != "in" {
+				continue
+			}
+			if l := listLength(action.Value); l > length {
+				field, in, length, values = i, j, l, reflect.ValueOf(action.Value)
+			}
+		}
+	}
+	return field, in, values, field >= 0
+}
+
+// unique removes duplicate values, so that each record matches exactly one chunk
+func unique(values reflect.Value) reflect.Value {
+	if !values.Type().Elem().Comparable() {
+		return values
+	}
+
+	seen := make(map[interface{}]bool, values.Len())
+	result := reflect.MakeSlice(values.Type(), 0, values.Len())
+	for i := 0; i < values.Len(); i++ {
+		v := values.Index(i)
+		if seen[v.Interface()] {
+			continue
+		}
+		seen[v.Interface()] = true
+		result = reflect.Append(result, v)
+	}
+	return result
+}
+
+// paginateChunk replaces skip and take of a chunk, so that it returns all records which may be part of the merged page
+func (q Query) paginateChunk() Query {
+	skip, take, hasTake := q.pagination()
+
+	var inputs []Input
+	for _, input := range q.Inputs {
+		if input.Name == "skip" || input.Name == "take" {
+			continue
+		}
+		inputs = append(inputs, input)
+	}
+	if hasTake {
+		inputs = append(inputs, Input{Name: "take", Value: skip + take})
+	}
+
+	q.Inputs = inputs
+	return q
+}
+
+func (q Query) pagination() (skip int, take int, hasTake bool) {
+	for _, input := range q.Inputs {
+		switch input.Name {
+		case "skip":
+			skip, _ = input.Value.(int)
+		case "take":
+			take, hasTake = input.Value.(int)
+		}
+	}
+	return skip, take, hasTake
+}
+
+type sortField struct {
+	name string
+	desc bool
+}
+
+// orderBy returns the fields the query is ordered by, and false if it's ordered by anything else than scalar fields
+func (q Query) orderBy() ([]sortField, bool) {
+	var fields []sortField
+	for _, input := range q.Inputs {
+		if input.Name != "orderBy" {
+			continue
+		}
+		for _, f := range input.Fields {
+			if f.Fields != nil || f.Value == nil {
+				return nil, false
+			}
+			switch fmt.Sprint(f.Value) {
+			case "asc":
+				fields = append(fields, sortField{name: f.Name})
+			case "desc":
+				fields = append(fields, sortField{name: f.Name, desc: true})
+			default:
+				return nil, false
+			}
+		}
+	}
+	return fields, true
+}
+
+// canSort returns whether the merged records of a split query can be sorted, which requires the query to be ordered
+// by scalar fields which are part of the result
+func (q Query) canSort() bool {
+	fields, ok := q.orderBy()
+	if !ok {
+		return false
+	}
+
+	outputs := make(map[string]bool, len(q.Outputs))
+	for _, o := range q.Outputs {
+		outputs[o.Name] = true
+	}
+	for _, f := range fields {
+		if !outputs[f.name] {
+			return false
+		}
+	}
+	return true
+}
+
+// execSplit sends the chunks of a split query and merges their results. Writes are sent within an interactive
+// transaction, so that either all or none of the chunks are applied.
+func (q Query) execSplit(ctx context.Context, chunks []Query, into interface{}) error {
+	if q.Operation == "mutation" {
+		exec := func(ctx context.Context, e engine.Engine) error {
+			var total types.BatchResult
+			for _, chunk := range chunks {
+				chunk.Engine = e
+				var result types.BatchResult
+				if err := chunk.Exec(ctx, &result); err != nil {
+					return err
+				}
+				total.Count += result.Count
+			}
+			return q.assign(total, into)
+		}
+
+		if _, ok := engine.AsTransactor(q.Engine); !ok && !engine.InTx(q.Engine) {
+			return exec(ctx, q.Engine)
+		}
+		return engine.RunInTx(ctx, q.Engine, engine.TxOptions{}, exec)
+	}
+
+	var records []json.RawMessage
+	for _, chunk := range chunks {
+		var result []json.RawMessage
+		if err := chunk.Exec(ctx, &result); err != nil {
+			return err
+		}
+		records = append(records, result...)
+	}
+
+	if fields, _ := q.orderBy(); len(fields) > 0 {
+		if err := sortRecords(records, fields); err != nil {
+			return err
+		}
+	}
+
+	skip, take, hasTake := q.pagination()
+	records = records[min(skip, len(records)):]
+	if hasTake {
+		records = records[:min(take, len(records))]
+	}
+
+	return q.assign(records, into)
+}
+
+// sortRecords sorts the merged records of a split query. Values are compared in Go, which may differ from the
+// collation of the database for strings, and null values are sorted last in ascending order.
+func sortRecords(records []json.RawMessage, fields []sortField) error {
+	values := make([]map[string]interface{}, len(records))
+	for i, record := range records {
+		d := json.NewDecoder(bytes.NewReader(record))
+		d.UseNumber()
+		if err := d.Decode(&values[i]); err != nil {
+			return fmt.Errorf("decode record: %w", err)
+		}
+	}
+
+	index := make([]int, len(records))
+	for i := range index {
+		index[i] = i
+	}
+
+	sort.SliceStable(index, func(a, b int) bool {
+		for _, f := range fields {
+			c := compare(values[index[a]][f.name], values[index[b]][f.name])
+			if f.desc {
+				c = -c
+			}
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+
+	sorted := make([]json.RawMessage, len(records))
+	for i, j := range index {
+		sorted[i] = records[j]
+	}
+	copy(records, sorted)
+	return nil
+}
+
+// compare compares two JSON values, treating numeric strings such as decimals as numbers
+func compare(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+
+	if x, ok := number(a); ok {
+		if y, ok := number(b); ok {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	}
+
+	if x, ok := a.(bool); ok {
+		if y, ok := b.(bool); ok {
+			switch {
+			case x == y:
+				return 0
+			case !x:
+				return -1
+			}
+			return 1
+		}
+	}
+
+	x, y := fmt.Sprint(a), fmt.Sprint(b)
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+func number(v interface{}) (float64, bool) {
+	var s string
+	switch v := v.(type) {
+	case json.Number:
+		s = v.String()
+	case string:
+		s = v
+	default:
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	return f, err == nil
+}
+
+// assign stores the merged result of a split query in into
+func (q Query) assign(result interface{}, into interface{}) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("encode merged result: %w", err)
+	}
+	if err := q.unmarshal(data, into); err != nil {
+		return fmt.Errorf("decode merged result: %w", err)
+	}
+	return nil
+}
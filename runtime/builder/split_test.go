@@ -0,0 +1,149 @@
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/steebchen/prisma-client-go/engine"
+	"github.com/steebchen/prisma-client-go/runtime/types"
+)
+
+type record struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+}
+
+// splitEngine answers queries filtering by id with one record per id, and records the length of each In filter
+type splitEngine struct {
+	lengths []int
+}
+
+func (e *splitEngine) Connect() error    { return nil }
+func (e *splitEngine) Disconnect() error { return nil }
+func (e *splitEngine) Name() string      { return "split" }
+
+func (e *splitEngine) Batch(ctx context.Context, payload interface{}, into interface{}) error {
+	return fmt.Errorf("not supported")
+}
+
+func (e *splitEngine) Do(ctx context.Context, payload interface{}, into interface{}) error {
+	q, _ := QueryFromContext(ctx)
+	ids := q.Where()["id"].(map[string]interface{})["in"].([]int)
+	e.lengths = append(e.lengths, len(ids))
+
+	var result interface{} = types.BatchResult{Count: len(ids)}
+	if q.Method == "findMany" {
+		var records []record
+		for _, id := range ids {
+			records = append(records, record{ID: id, Title: fmt.Sprintf("%03d", 100-id)})
+		}
+		result = records
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, into)
+}
+
+func newSplitQuery(e engine.Engine, method string, ids []int, inputs ...Input) Query {
+	q := NewQuery()
+	q.Engine = engine.NewSplitEngine(e, 3)
+	q.Operation = "query"
+	q.Method = method
+	q.Model = "Post"
+	q.Inputs = append([]Input{{
+		Name:   "where",
+		Fields: []Field{Action("id", "in", ids)},
+	}}, inputs...)
+	q.Outputs = []Output{{Name: "id"}, {Name: "title"}}
+	return q
+}
+
+func TestQuery_ExecSplit(t *testing.T) {
+	tests := []struct {
+		name    string
+		ids     []int
+		inputs  []Input
+		want    []int
+		lengths []int
+	}{{
+		name:    "within limit",
+		ids:     []int{1, 2, 3},
+		want:    []int{1, 2, 3},
+		lengths: []int{3},
+	}, {
+		name:    "split with duplicates",
+		ids:     []int{1, 2, 3, 4, 2, 5, 6, 7},
+		want:    []int{1, 2, 3, 4, 5, 6, 7},
+		lengths: []int{3, 3, 1},
+	}, {
+		name: "order by",
+		ids:  []int{1, 2, 3, 4, 5, 6, 7},
+		inputs: []Input{{
+			Name:     "orderBy",
+			Fields:   []Field{{Name: "title", Value: "asc"}},
+			WrapList: true,
+		}},
+		want:    []int{7, 6, 5, 4, 3, 2, 1},
+		lengths: []int{3, 3, 1},
+	}, {
+		name: "order by with pagination",
+		ids:  []int{1, 2, 3, 4, 5, 6, 7},
+		inputs: []Input{{
+			Name:     "orderBy",
+			Fields:   []Field{{Name: "id", Value: "desc"}},
+			WrapList: true,
+		}, {
+			Name:  "skip",
+			Value: 1,
+		}, {
+			Name:  "take",
+			Value: 2,
+		}},
+		want:    []int{6, 5},
+		lengths: []int{3, 3, 1},
+	}, {
+		name: "order by relation",
+		ids:  []int{1, 2, 3, 4},
+		inputs: []Input{{
+			Name:     "orderBy",
+			Fields:   []Field{{Name: "author", Fields: []Field{{Name: "name", Value: "asc"}}}},
+			WrapList: true,
+		}},
+		want:    []int{1, 2, 3, 4},
+		lengths: []int{4},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &splitEngine{}
+			var records []record
+			err := newSplitQuery(e, "findMany", tt.ids, tt.inputs...).Exec(context.Background(), &records)
+			assert.NoError(t, err)
+
+			var ids []int
+			for _, r := range records {
+				ids = append(ids, r.ID)
+			}
+			assert.Equal(t, tt.want, ids)
+			assert.Equal(t, tt.lengths, e.lengths)
+		})
+	}
+}
+
+func TestQuery_ExecSplitMany(t *testing.T) {
+	e := &splitEngine{}
+	q := newSplitQuery(e, "deleteMany", []int{1, 2, 3, 4, 5})
+	q.Operation = "mutation"
+
+	var result types.BatchResult
+	err := q.Exec(context.Background(), &result)
+	assert.NoError(t, err)
+	assert.Equal(t, types.BatchResult{Count: 5}, result)
+	assert.Equal(t, []int{3, 2}, e.lengths)
+}
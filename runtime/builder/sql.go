@@ -0,0 +1,109 @@
+package builder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/steebchen/prisma-client-go/engine"
+	"github.com/steebchen/prisma-client-go/engine/protocol"
+)
+
+// errDryRun rolls back the transaction of a dry run
+var errDryRun = errors.New("dry run")
+
+// dryRunMarker is the statement executed after the query of a dry run, which marks the end of its statements
+const dryRunMarker = "SELECT 1 AS prisma_dry_run_end"
+
+type dryRunKey struct{}
+
+// isDryRun returns whether a query is executed by ToSQL, which skips lifecycle hooks and cache invalidation
+func isDryRun(ctx context.Context) bool {
+	v, _ := ctx.Value(dryRunKey{}).(bool)
+	return v
+}
+
+// ToSQL returns the statements the engine sends to the database for the query, e.g. for query review or to EXPLAIN
+// them. The engine can't render statements without executing them, so the query is executed within an interactive
+// transaction which is always rolled back: writes are not applied, but they still take row locks until the rollback,
+// fire triggers and advance sequences. Use Debug to get the request sent to the engine without executing the query.
+// Statements are reported by the engine, so a query listener must be registered before connecting. Only the
+// statements of the transaction are returned, so concurrent queries of the same client are not included.
+func (q Query) ToSQL(ctx context.Context) ([]engine.QueryEvent, error) {
+	if q.Engine == nil {
+		return nil, errors.New("client.Prisma.Connect() needs to be called before sending queries")
+	}
+	if engine.InTx(q.Engine) {
+		return nil, errors.New("ToSQL can't be used within an interactive transaction")
+	}
+	if provider := engine.ProviderOf(q.Engine); provider == "mongodb" {
+		return nil, fmt.Errorf("ToSQL is not supported for provider %s", provider)
+	}
+	capturer, ok := engine.AsQueryCapturer(q.Engine)
+	if !ok {
+		return nil, engine.ErrQueryLogDisabled
+	}
+
+	var events []engine.QueryEvent
+	err := engine.RunInTx(context.WithValue(ctx, dryRunKey{}, true), q.Engine, engine.TxOptions{}, func(ctx context.Context, tx engine.Engine) error {
+		id, _ := engine.TxIDOf(tx)
+
+		// statements are logged after their response, so wait for them a limited time only
+		wait, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		var err error
+		events, err = capturer.CaptureTx(wait, id, func() error {
+			q.Engine = tx
+			var result interface{}
+			if err := q.Exec(ctx, &result); err != nil {
+				return err
+			}
+			return execMarker(ctx, tx)
+		}, func(e engine.QueryEvent) bool {
+			return strings.Contains(e.Query, dryRunMarker)
+		})
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			return errors.New("the engine did not report the statements of the transaction")
+		}
+		if err != nil {
+			return err
+		}
+		return errDryRun
+	})
+	if !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+
+	statements := make([]engine.QueryEvent, 0, len(events))
+	for _, e := range events {
+		if strings.Contains(e.Query, dryRunMarker) || statementIs(e.Query, "BEGIN", "START TRANSACTION", "SET TRANSACTION", "ROLLBACK", "COMMIT") {
+			continue
+		}
+		statements = append(statements, e)
+	}
+	return statements, nil
+}
+
+// execMarker executes the statement which marks the end of the statements of a dry run
+func execMarker(ctx context.Context, tx engine.Engine) error {
+	payload := protocol.GQLRequest{
+		Query:     fmt.Sprintf(`mutation {result: executeRaw(query:%q,parameters:"[]")}`, dryRunMarker),
+		Variables: map[string]interface{}{},
+	}
+	var count int
+	return tx.Do(ctx, payload, &count)
+}
+
+// statementIs returns whether a statement starts with one of the given keywords
+func statementIs(query string, keywords ...string) bool {
+	query = strings.ToUpper(strings.TrimSpace(query))
+	for _, k := range keywords {
+		if strings.HasPrefix(query, k) {
+			return true
+		}
+	}
+	return false
+}
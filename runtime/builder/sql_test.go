@@ -0,0 +1,106 @@
+package builder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/steebchen/prisma-client-go/engine"
+	"github.com/steebchen/prisma-client-go/engine/protocol"
+)
+
+// sqlEngine logs a statement for every request and transaction, and captures them synchronously
+type sqlEngine struct {
+	events     []engine.QueryEvent
+	rolledBack bool
+	provider   string
+}
+
+func (e *sqlEngine) Connect() error    { return nil }
+func (e *sqlEngine) Disconnect() error { return nil }
+func (e *sqlEngine) Name() string      { return "sql" }
+func (e *sqlEngine) Provider() string  { return e.provider }
+
+func (e *sqlEngine) Do(ctx context.Context, payload interface{}, into interface{}) error {
+	if strings.Contains(payload.(protocol.GQLRequest).Query, dryRunMarker) {
+		e.events = append(e.events, engine.QueryEvent{Query: dryRunMarker, TxID: "tx"})
+		return nil
+	}
+	e.events = append(e.events, engine.QueryEvent{Query: `INSERT INTO "User" ("email") VALUES ($1)`, Params: `["a"]`, TxID: "tx"})
+	// a concurrent query outside of the transaction
+	e.events = append(e.events, engine.QueryEvent{Query: `SELECT 1`})
+	return nil
+}
+
+func (e *sqlEngine) Batch(ctx context.Context, payload interface{}, into interface{}) error {
+	return fmt.Errorf("not supported")
+}
+
+func (e *sqlEngine) StartTx(ctx context.Context, options engine.TxOptions) (string, error) {
+	e.events = append(e.events, engine.QueryEvent{Query: "BEGIN", TxID: "tx"})
+	return "tx", nil
+}
+
+func (e *sqlEngine) CommitTx(ctx context.Context, id string) error {
+	return fmt.Errorf("dry runs must not be committed")
+}
+
+func (e *sqlEngine) RollbackTx(ctx context.Context, id string) error {
+	e.rolledBack = true
+	e.events = append(e.events, engine.QueryEvent{Query: "ROLLBACK", TxID: "tx"})
+	return nil
+}
+
+func (e *sqlEngine) CaptureTx(ctx context.Context, id string, fn func() error, done func(engine.QueryEvent) bool) ([]engine.QueryEvent, error) {
+	e.events = nil
+	if err := fn(); err != nil {
+		return nil, err
+	}
+	var events []engine.QueryEvent
+	for _, event := range e.events {
+		if event.TxID == id {
+			events = append(events, event)
+		}
+	}
+	if !done(events[len(events)-1]) {
+		return nil, fmt.Errorf("the last statement should end the capture")
+	}
+	return events, nil
+}
+
+func TestToSQL(t *testing.T) {
+	RegisterHook("DryRunUser", BeforeCreate, func(ctx context.Context, q Query, result interface{}) error {
+		return errors.New("hooks must not be called")
+	})
+
+	e := &sqlEngine{}
+	q := NewQuery()
+	q.Engine = e
+	q.Operation = "mutation"
+	q.Method = "createOne"
+	q.Model = "DryRunUser"
+	q.Inputs = []Input{{Name: "data", Fields: []Field{{Name: "email", Value: "a"}}}}
+	q.Outputs = []Output{{Name: "id"}}
+
+	statements, err := q.ToSQL(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, e.rolledBack)
+	assert.Equal(t, []engine.QueryEvent{{Query: `INSERT INTO "User" ("email") VALUES ($1)`, Params: `["a"]`, TxID: "tx"}}, statements)
+
+	// queries within an interactive transaction would be rolled back with it
+	q.Engine = engine.NewTxEngine(e, "tx")
+	_, err = q.ToSQL(context.Background())
+	assert.EqualError(t, err, "ToSQL can't be used within an interactive transaction")
+
+	q.Engine = hookEngine{log: new([]string)}
+	_, err = q.ToSQL(context.Background())
+	assert.ErrorIs(t, err, engine.ErrQueryLogDisabled)
+
+	q.Engine = &sqlEngine{provider: "mongodb"}
+	_, err = q.ToSQL(context.Background())
+	assert.EqualError(t, err, "ToSQL is not supported for provider mongodb")
+}
@@ -0,0 +1,123 @@
+package builder
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/steebchen/prisma-client-go/engine"
+)
+
+// Shape returns the shape of a query, which identifies its model, method, filtered fields and included relations
+// regardless of the filtered values, e.g. Post.findMany(where:published) with:comments,comments.author
+func (q Query) Shape() string {
+	var where []string
+	for field := range q.Where() {
+		where = append(where, field)
+	}
+	sort.Strings(where)
+
+	shape := q.Model + "." + q.Method + "(where:" + strings.Join(where, ",") + ")"
+	if relations := relationPaths("", q.Outputs); len(relations) > 0 {
+		shape += " with:" + strings.Join(relations, ",")
+	}
+	return shape
+}
+
+// relationPaths returns the paths of the outputs which select nested fields, i.e. included relations
+func relationPaths(prefix string, outputs []Output) []string {
+	var paths []string
+	for _, o := range outputs {
+		if len(o.Outputs) == 0 {
+			continue
+		}
+		paths = append(paths, prefix+o.Name)
+		paths = append(paths, relationPaths(prefix+o.Name+".", o.Outputs)...)
+	}
+	return paths
+}
+
+// isRead returns whether a query reads records, whose result sizes are recorded by query statistics
+func (q Query) isRead() bool {
+	switch q.Method {
+	case "findMany", "findFirst", "findFirstOrThrow", "findUnique", "findUniqueOrThrow":
+		return q.Operation == "query"
+	}
+	return false
+}
+
+// withRelationStrategy sets the relation load strategy chosen by the query statistics of the engine, if the query
+// includes relations and doesn't set a strategy itself
+func (q Query) withRelationStrategy() Query {
+	stats, ok := engine.StatsOf(q.Engine)
+	if !ok || !q.isRead() || len(relationPaths("", q.Outputs)) == 0 {
+		return q
+	}
+	for _, input := range q.Inputs {
+		if input.Name == "relationLoadStrategy" {
+			return q
+		}
+	}
+	strategy, ok := stats.Strategy(q.Shape())
+	if !ok {
+		return q
+	}
+	q.Inputs = append(append([]Input{}, q.Inputs...), Input{
+		Name:  "relationLoadStrategy",
+		Value: strategy,
+	})
+	return q
+}
+
+// sampleStats returns the query statistics of the engine if the result of the query should be recorded
+func (q Query) sampleStats() (*engine.QueryStats, bool) {
+	stats, ok := engine.StatsOf(q.Engine)
+	if !ok || !q.isRead() || !stats.Sample() {
+		return nil, false
+	}
+	return stats, true
+}
+
+// recordStats records the number of records of a result and the number of related records per record by relation
+func (q Query) recordStats(stats *engine.QueryStats, data json.RawMessage) {
+	var result interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return
+	}
+	records := items(result)
+	fanout := map[string][]int{}
+	countRelations(fanout, "", records, q.Outputs)
+	stats.Record(q.Shape(), len(records), fanout)
+}
+
+// items returns the records of a result, which is a list, a single record or null
+func items(v interface{}) []interface{} {
+	switch v := v.(type) {
+	case []interface{}:
+		return v
+	case nil:
+		return nil
+	}
+	return []interface{}{v}
+}
+
+// countRelations adds the number of related records of each record to fanout by relation path
+func countRelations(fanout map[string][]int, prefix string, records []interface{}, outputs []Output) {
+	for _, o := range outputs {
+		if len(o.Outputs) == 0 {
+			continue
+		}
+		path := prefix + o.Name
+		var related []interface{}
+		for _, record := range records {
+			m, ok := record.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			r := items(m[o.Name])
+			fanout[path] = append(fanout[path], len(r))
+			related = append(related, r...)
+		}
+		countRelations(fanout, path+".", related, o.Outputs)
+	}
+}
@@ -0,0 +1,67 @@
+package builder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/steebchen/prisma-client-go/engine"
+)
+
+func TestShape(t *testing.T) {
+	q := newStrictQuery(nil)
+	q.Inputs = []Input{{
+		Name: "where",
+		Fields: []Field{
+			{Name: "title", Value: "a"},
+			{Name: "id", Value: 1},
+		},
+	}}
+	q.Outputs = append(q.Outputs, Output{
+		Name:    "comments",
+		Outputs: []Output{{Name: "id"}, {Name: "author", Outputs: []Output{{Name: "name"}}}},
+	})
+
+	assert.Equal(t, "Post.findMany(where:id,title) with:author,comments,comments.author", q.Shape())
+}
+
+func TestQueryStats(t *testing.T) {
+	e := engine.NewStatsEngine(&resultEngine{
+		result: `[{"id":1,"title":"a","author":{"name":"x"}},{"id":2,"title":"b","author":null}]`,
+	}, engine.StatsOptions{SampleRate: 1, Adaptive: true, MinSamples: 2})
+	q := newStrictQuery(e)
+
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, q.Inputs, q.withRelationStrategy().Inputs)
+
+		var records []strictModel
+		assert.NoError(t, q.Exec(context.Background(), &records))
+		assert.Equal(t, 2, len(records))
+	}
+
+	shapes := e.Stats.Shapes()
+	assert.Equal(t, 1, len(shapes))
+	assert.Equal(t, "Post.findMany(where:) with:author", shapes[0].Shape)
+	assert.Equal(t, 2, shapes[0].Records.Count)
+	assert.Equal(t, 4, shapes[0].Records.Sum)
+	assert.Equal(t, 4, shapes[0].Fanout["author"].Count)
+	assert.Equal(t, 0.5, shapes[0].Fanout["author"].Mean())
+	assert.Equal(t, engine.StrategyJoin, shapes[0].Strategy)
+
+	assert.Equal(t, Input{Name: "relationLoadStrategy", Value: engine.StrategyJoin}, last(q.withRelationStrategy().Inputs))
+
+	e.Stats.Pin(q.Shape(), engine.StrategyQuery)
+	assert.Equal(t, Input{Name: "relationLoadStrategy", Value: engine.StrategyQuery}, last(q.withRelationStrategy().Inputs))
+
+	explicit := q
+	explicit.Inputs = []Input{{Name: "relationLoadStrategy", Value: "join"}}
+	assert.Equal(t, explicit.Inputs, explicit.withRelationStrategy().Inputs)
+}
+
+func last(inputs []Input) Input {
+	if len(inputs) == 0 {
+		return Input{}
+	}
+	return inputs[len(inputs)-1]
+}
@@ -0,0 +1,120 @@
+package builder
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/steebchen/prisma-client-go/engine"
+	"github.com/steebchen/prisma-client-go/runtime/types"
+)
+
+// unmarshal decodes a result of the query into v. If the engine decodes strictly, the result must contain exactly
+// the requested fields which the Go type knows, and a DecodeError is returned otherwise. If the generated models use
+// different JSON names than the schema, the fields of results decoded into structs are renamed first.
+func (q Query) unmarshal(data []byte, v interface{}) error {
+	outputs := q.Outputs
+	if schema, ok := engine.JSONNamesOf(q.Engine); ok && q.Model != "" && len(outputs) > 0 && decodesStruct(reflect.TypeOf(v)) {
+		renamed, err := renameResult(schema, q.Model, outputs, data)
+		if err != nil {
+			return err
+		}
+		data = renamed
+		outputs = renameOutputs(schema, q.Model, outputs)
+	}
+
+	if engine.IsStrict(q.Engine) && len(outputs) > 0 {
+		if err := checkStrict(data, reflect.TypeOf(v), outputs); err != nil {
+			return err
+		}
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Unmarshal decodes a result of the query into v like Exec does, e.g. the result of a transaction
+func (q Query) Unmarshal(data []byte, v interface{}) error {
+	return q.unmarshal(data, v)
+}
+
+// checkStrict compares a JSON result with the requested outputs and the JSON fields of the Go type it's decoded into.
+// Only structs are checked; other types such as maps or raw messages accept any result.
+func checkStrict(data []byte, t reflect.Type, outputs []Output) error {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	switch {
+	case t.Kind() == reflect.Slice && data[0] == '[':
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err := checkStrict(item, t.Elem(), outputs); err != nil {
+				return err
+			}
+		}
+		return nil
+	case t.Kind() != reflect.Struct || data[0] != '{':
+		return nil
+	}
+
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+
+	fields := jsonFields(t)
+	for name := range values {
+		if _, ok := fields[name]; !ok {
+			return &types.DecodeError{Type: t.String(), Field: name}
+		}
+	}
+
+	for _, o := range outputs {
+		value, ok := values[o.Name]
+		if !ok {
+			return &types.DecodeError{Type: t.String(), Field: o.Name, Missing: true}
+		}
+		if len(o.Outputs) > 0 {
+			if err := checkStrict(value, fields[o.Name], o.Outputs); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// jsonFields returns the types of the JSON fields of a struct by name, including the fields of embedded structs
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			for n, ft := range jsonFields(f.Type) {
+				fields[n] = ft
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
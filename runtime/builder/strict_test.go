@@ -0,0 +1,115 @@
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/steebchen/prisma-client-go/engine"
+	"github.com/steebchen/prisma-client-go/runtime/types"
+)
+
+// resultEngine answers every query with a fixed result
+type resultEngine struct {
+	result string
+}
+
+func (e *resultEngine) Connect() error    { return nil }
+func (e *resultEngine) Disconnect() error { return nil }
+func (e *resultEngine) Name() string      { return "result" }
+
+func (e *resultEngine) Batch(ctx context.Context, payload interface{}, into interface{}) error {
+	return fmt.Errorf("not supported")
+}
+
+func (e *resultEngine) Do(ctx context.Context, payload interface{}, into interface{}) error {
+	return json.Unmarshal([]byte(e.result), into)
+}
+
+type strictInner struct {
+	ID    int     `json:"id"`
+	Title string  `json:"title"`
+	Body  *string `json:"body,omitempty"`
+}
+
+type strictRelations struct {
+	Author *strictAuthor `json:"author,omitempty"`
+}
+
+type strictAuthor struct {
+	Name string `json:"name"`
+}
+
+type strictModel struct {
+	strictInner
+	strictRelations
+}
+
+func newStrictQuery(e engine.Engine) Query {
+	q := NewQuery()
+	q.Engine = e
+	q.Operation = "query"
+	q.Method = "findMany"
+	q.Model = "Post"
+	q.Outputs = []Output{
+		{Name: "id"},
+		{Name: "title"},
+		{Name: "body"},
+		{Name: "author", Outputs: []Output{{Name: "name"}}},
+	}
+	return q
+}
+
+func TestStrict(t *testing.T) {
+	tests := []struct {
+		name   string
+		result string
+		err    *types.DecodeError
+	}{{
+		name:   "valid",
+		result: `[{"id":1,"title":"a","body":null,"author":{"name":"x"}},{"id":2,"title":"b","body":"c","author":null}]`,
+	}, {
+		name:   "unknown field",
+		result: `[{"id":1,"title":"a","body":null,"author":null,"views":3}]`,
+		err:    &types.DecodeError{Type: "builder.strictModel", Field: "views"},
+	}, {
+		name:   "missing field",
+		result: `[{"id":1,"body":null,"author":null}]`,
+		err:    &types.DecodeError{Type: "builder.strictModel", Field: "title", Missing: true},
+	}, {
+		name:   "unknown nested field",
+		result: `[{"id":1,"title":"a","body":null,"author":{"name":"x","email":"y"}}]`,
+		err:    &types.DecodeError{Type: "builder.strictAuthor", Field: "email"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newStrictQuery(engine.NewStrictEngine(&resultEngine{result: tt.result}))
+
+			var records []strictModel
+			err := q.Exec(context.Background(), &records)
+			if tt.err == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, 2, len(records))
+				assert.Equal(t, "x", records[0].Author.Name)
+				return
+			}
+
+			var decodeErr *types.DecodeError
+			assert.True(t, errors.As(err, &decodeErr))
+			assert.Equal(t, tt.err, decodeErr)
+			assert.ErrorIs(t, err, types.ErrDecode)
+		})
+	}
+}
+
+func TestStrictDisabled(t *testing.T) {
+	q := newStrictQuery(&resultEngine{result: `[{"id":1,"views":3}]`})
+
+	var records []strictModel
+	assert.NoError(t, q.Exec(context.Background(), &records))
+	assert.Equal(t, []strictModel{{strictInner: strictInner{ID: 1}}}, records)
+}
@@ -0,0 +1,94 @@
+package builder
+
+import (
+	"sort"
+	"time"
+
+	"github.com/steebchen/prisma-client-go/engine"
+)
+
+// withTimestamps sets the timestamp fields of a write which are not set explicitly, if a clock is attached to the
+// engine. Only the written model is handled; nested writes of related records are left to the query engine.
+func (q Query) withTimestamps() Query {
+	if q.Operation != "mutation" || q.Engine == nil {
+		return q
+	}
+
+	var inputs map[string]bool
+	switch q.Method {
+	case "createOne", "createMany":
+		inputs = map[string]bool{"data": true}
+	case "updateOne", "updateMany", "updateManyAndReturn":
+		inputs = map[string]bool{"data": false}
+	case "upsertOne":
+		inputs = map[string]bool{"create": true, "update": false}
+	default:
+		return q
+	}
+
+	result := append([]Input{}, q.Inputs...)
+	changed := false
+	for i, input := range result {
+		create, ok := inputs[input.Name]
+		if !ok {
+			continue
+		}
+
+		timestamps := engine.Timestamps(q.Engine, q.Model, create)
+		if len(timestamps) == 0 {
+			continue
+		}
+
+		names := make([]string, 0, len(timestamps))
+		for name := range timestamps {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		// the data of createMany is a list of records
+		if input.WrapList {
+			records := append([]Field{}, input.Fields...)
+			for j, record := range records {
+				fields, ok := addTimestamps(record.Fields, names, timestamps)
+				if ok {
+					records[j].Fields = fields
+					changed = true
+				}
+			}
+			result[i].Fields = records
+			continue
+		}
+
+		if fields, ok := addTimestamps(input.Fields, names, timestamps); ok {
+			result[i].Fields = fields
+			changed = true
+		}
+	}
+
+	if changed {
+		q.Inputs = result
+	}
+	return q
+}
+
+// addTimestamps appends the timestamps in the order of names which are not set in fields, and returns false if all
+// of them are set
+func addTimestamps(fields []Field, names []string, timestamps map[string]time.Time) ([]Field, bool) {
+	result := append([]Field{}, fields...)
+	for _, name := range names {
+		if hasField(result, name) {
+			continue
+		}
+		result = append(result, Field{Name: name, Value: timestamps[name]})
+	}
+	return result, len(result) > len(fields)
+}
+
+func hasField(fields []Field, name string) bool {
+	for _, f := range fields {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
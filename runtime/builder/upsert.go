@@ -0,0 +1,137 @@
+package builder
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/steebchen/prisma-client-go/engine"
+	"github.com/steebchen/prisma-client-go/engine/protocol"
+)
+
+// Constraint is a unique constraint by which records of a model can be looked up
+type Constraint struct {
+	// Name is the name of the unique input, which is the field name of single fields and the name of the compound key
+	// otherwise, e.g. firstName_lastName
+	Name   string
+	Fields []string
+}
+
+// ExecUpserts executes the records of a createMany query as upserts in a single transaction, so that records which
+// already exist are updated instead of failing the query. Each record is looked up by the first constraint whose fields
+// it sets, and existing records are updated with its remaining fields. It returns the number of written records.
+func (q Query) ExecUpserts(ctx context.Context, constraints []Constraint) (int, error) {
+	if q.Engine == nil {
+		return 0, fmt.Errorf("client.Prisma.Connect() needs to be called before sending queries")
+	}
+
+	queries, err := q.upserts(constraints)
+	if err != nil {
+		return 0, err
+	}
+	if len(queries) == 0 {
+		return 0, nil
+	}
+
+	requests := make([]protocol.GQLRequest, len(queries))
+	for i, query := range queries {
+		if err := query.RunBeforeHooks(ctx); err != nil {
+			return 0, err
+		}
+		if requests[i], err = query.payload(); err != nil {
+			return 0, err
+		}
+	}
+
+	l := engine.LoggerOf(q.Engine)
+	l.DebugContext(ctx, "query built", "model", q.Model, "action", q.Method, "duration", time.Since(q.Start))
+
+	defer ClearDedup(ctx)
+
+	var result protocol.GQLBatchResponse
+	payload := protocol.GQLBatchRequest{
+		Batch:       requests,
+		Transaction: true,
+	}
+	err = q.Engine.Batch(ctx, payload, &result)
+	if err == nil {
+		err = batchError(result, len(requests))
+	}
+	q.log(ctx, l, err)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, inner := range result.Result {
+		engine.Invalidate(ctx, q.Engine, q.Model, queries[i].Method, queries[i].Where(), inner.Data.Result)
+	}
+	return len(queries), nil
+}
+
+// upserts returns an upsertOne query for each record of a createMany query
+func (q Query) upserts(constraints []Constraint) ([]Query, error) {
+	if len(constraints) == 0 {
+		return nil, fmt.Errorf("no unique constraints given for %s", q.Model)
+	}
+
+	var records []Field
+	for _, input := range q.Inputs {
+		if input.Name == "data" {
+			records = input.Fields
+		}
+	}
+
+	// only select the primary key, as the records are not returned
+	var outputs []Output
+	for _, name := range constraints[0].Fields {
+		outputs = append(outputs, Output{Name: name})
+	}
+
+	queries := make([]Query, len(records))
+	for i, record := range records {
+		set := make(map[string]Field, len(record.Fields))
+		for _, f := range record.Fields {
+			set[f.Name] = f
+		}
+
+		index := slices.IndexFunc(constraints, func(c Constraint) bool {
+			for _, name := range c.Fields {
+				if _, ok := set[name]; !ok {
+					return false
+				}
+			}
+			return len(c.Fields) > 0
+		})
+		if index < 0 {
+			return nil, fmt.Errorf("record %d of %s sets no unique fields to look it up by", i, q.Model)
+		}
+		c := constraints[index]
+
+		where := set[c.Fields[0]]
+		if len(c.Fields) > 1 {
+			where = Field{Name: c.Name}
+			for _, name := range c.Fields {
+				where.Fields = append(where.Fields, set[name])
+			}
+		}
+
+		var update []Field
+		for _, f := range record.Fields {
+			if !slices.Contains(c.Fields, f.Name) {
+				update = append(update, WrapSet(f))
+			}
+		}
+
+		u := q
+		u.Method = "upsertOne"
+		u.Outputs = outputs
+		u.Inputs = []Input{
+			{Name: "where", Fields: []Field{where}},
+			{Name: "create", Fields: record.Fields},
+			{Name: "update", Fields: update},
+		}
+		queries[i] = u
+	}
+	return queries, nil
+}
@@ -0,0 +1,49 @@
+package builder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuery_Upserts(t *testing.T) {
+	q := NewQuery()
+	q.Operation = "mutation"
+	q.Method = "createMany"
+	q.Model = "User"
+	q.Inputs = []Input{{
+		Name:     "data",
+		WrapList: true,
+		Fields: []Field{
+			{Fields: []Field{{Name: "email", Value: "a"}, {Name: "name", Value: "A"}}},
+			{Fields: []Field{{Name: "first", Value: "b"}, {Name: "last", Value: "c"}}},
+		},
+	}}
+
+	actual, err := q.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, `mutation {result: createManyUser(data:[{email:"a",name:"A",},{first:"b",last:"c",},]) }`, actual)
+
+	constraints := []Constraint{
+		{Name: "id", Fields: []string{"id"}},
+		{Name: "email", Fields: []string{"email"}},
+		{Name: "first_last", Fields: []string{"first", "last"}},
+	}
+	upserts, err := q.upserts(constraints)
+	assert.NoError(t, err)
+	if !assert.Len(t, upserts, 2) {
+		return
+	}
+
+	actual, err = upserts[0].Build()
+	assert.NoError(t, err)
+	assert.Equal(t, `mutation {result: upsertOneUser(where:{email:"a",},create:{email:"a",name:"A",},update:{name:{set:"A",},}) {id }}`, actual)
+
+	actual, err = upserts[1].Build()
+	assert.NoError(t, err)
+	assert.Equal(t, `mutation {result: upsertOneUser(where:{first_last:{first:"b",last:"c",},},create:{first:"b",last:"c",},update:{}) {id }}`, actual)
+
+	q.Inputs[0].Fields = append(q.Inputs[0].Fields, Field{Fields: []Field{{Name: "name", Value: "D"}}})
+	_, err = q.upserts(constraints)
+	assert.EqualError(t, err, "record 2 of User sets no unique fields to look it up by")
+}
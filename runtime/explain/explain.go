@@ -0,0 +1,185 @@
+// Package explain profiles the statements of generated queries with EXPLAIN, e.g. to find missing indexes of queries
+// in a staging environment.
+package explain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/steebchen/prisma-client-go/engine"
+	"github.com/steebchen/prisma-client-go/runtime/builder"
+	"github.com/steebchen/prisma-client-go/runtime/raw"
+)
+
+// errRollback rolls back the transaction in which statements are explained
+var errRollback = errors.New("rollback")
+
+// Plan is the query plan of a statement which a query executes
+type Plan struct {
+	// Statement is the explained statement including its parameters
+	Statement engine.QueryEvent
+	// Raw is the plan in the JSON format of the database
+	Raw json.RawMessage
+	// Root is the root node of the plan; it is only set for PostgreSQL
+	Root *Node
+	// PlanningTime is the time PostgreSQL took to plan the statement; it is only set by Analyze
+	PlanningTime time.Duration
+	// ExecutionTime is the time PostgreSQL took to execute the statement; it is only set by Analyze
+	ExecutionTime time.Duration
+}
+
+// Node is a node of a PostgreSQL query plan. Costs are in the arbitrary units of the planner, while actual times are
+// in milliseconds and per loop; the actual values are only set by Analyze.
+type Node struct {
+	NodeType          string  `json:"Node Type"`
+	RelationName      string  `json:"Relation Name"`
+	IndexName         string  `json:"Index Name"`
+	IndexCond         string  `json:"Index Cond"`
+	Filter            string  `json:"Filter"`
+	StartupCost       float64 `json:"Startup Cost"`
+	TotalCost         float64 `json:"Total Cost"`
+	PlanRows          float64 `json:"Plan Rows"`
+	ActualStartupTime float64 `json:"Actual Startup Time"`
+	ActualTotalTime   float64 `json:"Actual Total Time"`
+	ActualRows        float64 `json:"Actual Rows"`
+	ActualLoops       float64 `json:"Actual Loops"`
+	Plans             []Node  `json:"Plans"`
+}
+
+// Walk calls fn for the node and all of its descendants, depth-first
+func (n Node) Walk(fn func(Node)) {
+	fn(n)
+	for _, child := range n.Plans {
+		child.Walk(fn)
+	}
+}
+
+// prefix returns the statement prefix which returns the JSON plan of a statement. With analyze, PostgreSQL executes
+// the statement to report actual times and rows; MySQL only reports its estimates in JSON.
+func prefix(provider string, analyze bool) (string, error) {
+	switch provider {
+	case "postgresql", "postgres":
+		if analyze {
+			return "EXPLAIN (ANALYZE, FORMAT JSON) ", nil
+		}
+		return "EXPLAIN (FORMAT JSON) ", nil
+	case "mysql":
+		if analyze {
+			return "", fmt.Errorf("explain analyze is not supported for provider %q", provider)
+		}
+		return "EXPLAIN FORMAT=JSON ", nil
+	}
+	return "", fmt.Errorf("explain is not supported for provider %q", provider)
+}
+
+// Query returns the estimated plans of the statements a query executes. The statements are captured with ToSQL,
+// which requires a query listener and executes the query within an interactive transaction which is rolled back.
+// They are explained without being executed again.
+func Query(ctx context.Context, q builder.Query, provider string) ([]Plan, error) {
+	return plans(ctx, q, provider, false)
+}
+
+// Analyze returns the plans of the statements a query executes with their actual times and rows, which is only
+// supported for PostgreSQL. Unlike Query, every statement is executed once more by EXPLAIN ANALYZE, within an
+// interactive transaction which is rolled back, so writes are not applied but take locks and fire triggers again.
+func Analyze(ctx context.Context, q builder.Query, provider string) ([]Plan, error) {
+	return plans(ctx, q, provider, true)
+}
+
+func plans(ctx context.Context, q builder.Query, provider string, analyze bool) ([]Plan, error) {
+	p, err := prefix(provider, analyze)
+	if err != nil {
+		return nil, err
+	}
+
+	statements, err := q.ToSQL(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	plans := make([]Plan, 0, len(statements))
+	err = engine.RunInTx(ctx, q.Engine, engine.TxOptions{}, func(ctx context.Context, tx engine.Engine) error {
+		for _, statement := range statements {
+			plan, err := explain(ctx, tx, p, statement)
+			if err != nil {
+				return fmt.Errorf("explain %s: %w", statement.Query, err)
+			}
+			plans = append(plans, plan)
+		}
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		return nil, err
+	}
+	return plans, nil
+}
+
+// explain runs EXPLAIN for a statement with the parameters the engine logged for it
+func explain(ctx context.Context, tx engine.Engine, prefix string, statement engine.QueryEvent) (Plan, error) {
+	var params []interface{}
+	if statement.Params != "" {
+		if err := json.Unmarshal([]byte(statement.Params), &params); err != nil {
+			return Plan{}, fmt.Errorf("parse params: %w", err)
+		}
+	}
+
+	var rows []map[string]interface{}
+	r := raw.Raw{Engine: tx}
+	if err := r.QueryRaw(prefix+statement.Query, params...).Exec(ctx, &rows); err != nil {
+		return Plan{}, err
+	}
+	if len(rows) != 1 || len(rows[0]) != 1 {
+		return Plan{}, fmt.Errorf("unexpected plan %v", rows)
+	}
+
+	var data []byte
+	for _, v := range rows[0] {
+		// the plan is returned as text by MySQL and as a JSON column by PostgreSQL
+		if s, ok := v.(string); ok {
+			data = []byte(s)
+			continue
+		}
+		var err error
+		if data, err = json.Marshal(v); err != nil {
+			return Plan{}, err
+		}
+	}
+
+	return parse(statement, data)
+}
+
+// parse parses a JSON plan. PostgreSQL returns a list with a single entry, while MySQL returns an object which is
+// kept as it is.
+func parse(statement engine.QueryEvent, data []byte) (Plan, error) {
+	if !json.Valid(data) {
+		return Plan{}, fmt.Errorf("invalid plan %s", data)
+	}
+	plan := Plan{Statement: statement, Raw: data}
+
+	var entries []json.RawMessage
+	if err := json.Unmarshal(data, &entries); err != nil || len(entries) != 1 {
+		return plan, nil
+	}
+
+	var postgres struct {
+		Plan          *Node   `json:"Plan"`
+		PlanningTime  float64 `json:"Planning Time"`
+		ExecutionTime float64 `json:"Execution Time"`
+	}
+	if err := json.Unmarshal(entries[0], &postgres); err != nil {
+		return Plan{}, fmt.Errorf("parse plan: %w", err)
+	}
+
+	plan.Raw = entries[0]
+	plan.Root = postgres.Plan
+	plan.PlanningTime = milliseconds(postgres.PlanningTime)
+	plan.ExecutionTime = milliseconds(postgres.ExecutionTime)
+	return plan, nil
+}
+
+func milliseconds(ms float64) time.Duration {
+	return time.Duration(ms * float64(time.Millisecond))
+}
@@ -0,0 +1,140 @@
+package explain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/steebchen/prisma-client-go/engine"
+	"github.com/steebchen/prisma-client-go/engine/protocol"
+	"github.com/steebchen/prisma-client-go/runtime/builder"
+)
+
+// planEngine logs a statement for queries and answers raw queries with a PostgreSQL plan
+type planEngine struct {
+	events    []engine.QueryEvent
+	explained []string
+	commits   int
+}
+
+func (e *planEngine) Connect() error    { return nil }
+func (e *planEngine) Disconnect() error { return nil }
+func (e *planEngine) Name() string      { return "plan" }
+
+func (e *planEngine) Do(ctx context.Context, payload interface{}, into interface{}) error {
+	query := payload.(protocol.GQLRequest).Query
+	if strings.Contains(query, "queryRaw") {
+		e.explained = append(e.explained, query)
+		return json.Unmarshal([]byte(`[{"QUERY PLAN":[{
+			"Plan": {
+				"Node Type": "Seq Scan",
+				"Relation Name": "User",
+				"Filter": "(email = 'a'::text)",
+				"Total Cost": 25.88,
+				"Actual Total Time": 0.012,
+				"Actual Rows": 1,
+				"Actual Loops": 1
+			},
+			"Planning Time": 0.5,
+			"Execution Time": 1.5
+		}]}]`), into)
+	}
+	if strings.Contains(query, "prisma_dry_run_end") {
+		return nil
+	}
+	e.events = append(e.events, engine.QueryEvent{Query: `SELECT "id" FROM "User" WHERE "email" = $1`, Params: `["a"]`})
+	return nil
+}
+
+func (e *planEngine) Batch(ctx context.Context, payload interface{}, into interface{}) error {
+	return fmt.Errorf("not supported")
+}
+
+func (e *planEngine) StartTx(ctx context.Context, options engine.TxOptions) (string, error) {
+	e.events = append(e.events, engine.QueryEvent{Query: "BEGIN"})
+	return "tx", nil
+}
+
+func (e *planEngine) CommitTx(ctx context.Context, id string) error {
+	e.commits++
+	return nil
+}
+
+func (e *planEngine) RollbackTx(ctx context.Context, id string) error {
+	e.events = append(e.events, engine.QueryEvent{Query: "ROLLBACK"})
+	return nil
+}
+
+func (e *planEngine) CaptureTx(ctx context.Context, id string, fn func() error, done func(engine.QueryEvent) bool) ([]engine.QueryEvent, error) {
+	e.events = nil
+	if err := fn(); err != nil {
+		return nil, err
+	}
+	return e.events, nil
+}
+
+func TestQuery(t *testing.T) {
+	e := &planEngine{}
+	q := builder.NewQuery()
+	q.Engine = e
+	q.Operation = "query"
+	q.Method = "findMany"
+	q.Model = "User"
+	q.Inputs = []builder.Input{{Name: "where", Fields: []builder.Field{{Name: "email", Value: "a"}}}}
+	q.Outputs = []builder.Output{{Name: "id"}}
+
+	plans, err := Query(context.Background(), q, "postgresql")
+	assert.NoError(t, err)
+	assert.Len(t, plans, 1)
+	assert.Len(t, e.explained, 1)
+	assert.Contains(t, e.explained[0], `EXPLAIN (FORMAT JSON) SELECT \"id\" FROM \"User\" WHERE \"email\" = $1`)
+
+	e.explained = nil
+	plans, err = Analyze(context.Background(), q, "postgresql")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, e.commits)
+
+	assert.Len(t, e.explained, 1)
+	assert.Contains(t, e.explained[0], `EXPLAIN (ANALYZE, FORMAT JSON) SELECT \"id\" FROM \"User\" WHERE \"email\" = $1`)
+
+	assert.Len(t, plans, 1)
+	assert.Equal(t, `SELECT "id" FROM "User" WHERE "email" = $1`, plans[0].Statement.Query)
+	assert.Equal(t, 500*time.Microsecond, plans[0].PlanningTime)
+	assert.Equal(t, 1500*time.Microsecond, plans[0].ExecutionTime)
+	assert.Equal(t, &Node{
+		NodeType:        "Seq Scan",
+		RelationName:    "User",
+		Filter:          "(email = 'a'::text)",
+		TotalCost:       25.88,
+		ActualTotalTime: 0.012,
+		ActualRows:      1,
+		ActualLoops:     1,
+	}, plans[0].Root)
+
+	var scans []string
+	plans[0].Root.Walk(func(n Node) {
+		scans = append(scans, n.NodeType+" "+n.RelationName)
+	})
+	assert.Equal(t, []string{"Seq Scan User"}, scans)
+
+	_, err = Query(context.Background(), q, "sqlite")
+	assert.EqualError(t, err, `explain is not supported for provider "sqlite"`)
+
+	_, err = Analyze(context.Background(), q, "mysql")
+	assert.EqualError(t, err, `explain analyze is not supported for provider "mysql"`)
+}
+
+func TestParseMySQL(t *testing.T) {
+	plan, err := parse(engine.QueryEvent{Query: "SELECT 1"}, []byte(`{"query_block":{"select_id":1}}`))
+	assert.NoError(t, err)
+	assert.Nil(t, plan.Root)
+	assert.JSONEq(t, `{"query_block":{"select_id":1}}`, string(plan.Raw))
+
+	_, err = parse(engine.QueryEvent{Query: "SELECT 1"}, []byte(`not json`))
+	assert.EqualError(t, err, "invalid plan not json")
+}
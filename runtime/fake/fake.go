@@ -0,0 +1,76 @@
+// Package fake provides the fake values which generated factories fill required fields with.
+package fake
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/steebchen/prisma-client-go/runtime/types"
+)
+
+var seq atomic.Int64
+
+// Next returns the next number of a sequence which is shared by all fake values of a process, so that values of
+// unique fields don't collide across records and tests
+func Next() int {
+	return int(seq.Add(1))
+}
+
+// String returns a fake value of a String field, which is an email address, a URL, a UUID or a MongoDB ObjectId if
+// the field name or its native type suggests one, and the field name with a number otherwise
+func String(field string, nativeType string) string {
+	n := Next()
+	name := strings.ToLower(field)
+	switch {
+	case nativeType == "ObjectId":
+		return fmt.Sprintf("%024x", n)
+	case nativeType == "Uuid" || nativeType == "UniqueIdentifier" || name == "uuid":
+		return fmt.Sprintf("00000000-0000-4000-8000-%012d", n)
+	case strings.Contains(name, "email"):
+		return fmt.Sprintf("user%d@example.com", n)
+	case strings.HasSuffix(name, "url"):
+		return fmt.Sprintf("https://example.com/%d", n)
+	}
+	return fmt.Sprintf("%s-%d", field, n)
+}
+
+// Int returns a fake value of an Int or BigInt field
+func Int() int {
+	return Next()
+}
+
+// Float returns a fake value of a Float field
+func Float() float64 {
+	return float64(Next())
+}
+
+// Decimal returns a fake value of a Decimal field
+func Decimal() decimal.Decimal {
+	return decimal.NewFromInt(int64(Next()))
+}
+
+// BigInteger returns a fake value of a BigInt field which is generated as big.Int
+func BigInteger() types.BigInteger {
+	return types.NewBigInteger(big.NewInt(int64(Next())))
+}
+
+// Time returns a fake value of a DateTime field, which is the current time truncated to milliseconds as databases
+// store it
+func Time() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond)
+}
+
+// Bytes returns a fake value of a Bytes field
+func Bytes(field string) []byte {
+	return []byte(fmt.Sprintf("%s-%d", field, Next()))
+}
+
+// JSON returns a fake value of a Json field, which is an empty object
+func JSON() types.JSON {
+	return types.JSON("{}")
+}
@@ -0,0 +1,18 @@
+package fake
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestString(t *testing.T) {
+	n := Next()
+	assert.Equal(t, fmt.Sprintf("user%d@example.com", n+1), String("email", ""))
+	assert.Equal(t, fmt.Sprintf("user%d@example.com", n+2), String("contactEmail", ""))
+	assert.Equal(t, fmt.Sprintf("https://example.com/%d", n+3), String("avatarUrl", ""))
+	assert.Equal(t, fmt.Sprintf("00000000-0000-4000-8000-%012d", n+4), String("id", "Uuid"))
+	assert.Equal(t, fmt.Sprintf("%024x", n+5), String("id", "ObjectId"))
+	assert.Equal(t, fmt.Sprintf("title-%d", n+6), String("title", ""))
+}
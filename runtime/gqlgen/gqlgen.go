@@ -0,0 +1,45 @@
+// Package gqlgen helps to serve the generated client with gqlgen (https://gqlgen.com). With the gqlgen generator
+// option, the client generates gqlgen model bindings, a With helper per model which fetches the relations selected in
+// a GraphQL query, and a Loaders type holding the loaders of all models.
+//
+// The package doesn't depend on gqlgen. Selections are passed as Fields, which can be collected from the context of a
+// resolver:
+//
+//	func selection(ctx context.Context, set ast.SelectionSet) []gqlgen.Field {
+//		var fields []gqlgen.Field
+//		for _, f := range graphql.CollectFields(graphql.GetOperationContext(ctx), set, nil) {
+//			fields = append(fields, gqlgen.Field{Name: f.Name, Fields: selection(ctx, f.Selections)})
+//		}
+//		return fields
+//	}
+//
+//	// in a resolver
+//	users, err := client.User.FindMany().With(
+//		db.UserWith(selection(ctx, graphql.GetFieldContext(ctx).Field.Selections))...,
+//	).Exec(ctx)
+package gqlgen
+
+// Field is a field which is selected in a GraphQL query, together with the fields selected within it
+type Field struct {
+	Name   string
+	Fields []Field
+}
+
+// Find returns the selected field with the given name
+func Find(fields []Field, name string) (Field, bool) {
+	for _, f := range fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+// Names returns the names of the selected fields
+func Names(fields []Field) []string {
+	names := make([]string, 0, len(fields))
+	for _, f := range fields {
+		names = append(names, f.Name)
+	}
+	return names
+}
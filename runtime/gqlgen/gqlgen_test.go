@@ -0,0 +1,23 @@
+package gqlgen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFind(t *testing.T) {
+	fields := []Field{
+		{Name: "id"},
+		{Name: "author", Fields: []Field{{Name: "name"}}},
+	}
+
+	f, ok := Find(fields, "author")
+	assert.True(t, ok)
+	assert.Equal(t, []Field{{Name: "name"}}, f.Fields)
+
+	_, ok = Find(fields, "posts")
+	assert.False(t, ok)
+
+	assert.Equal(t, []string{"id", "author"}, Names(fields))
+}
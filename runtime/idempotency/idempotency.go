@@ -0,0 +1,154 @@
+// Package idempotency makes write operations idempotent by recording a key along with the result of the first
+// execution in the same transaction, so that retries with the same key return the original result instead of
+// writing again.
+//
+// Keys are stored in a model of the Prisma schema, which has to be added manually:
+//
+//	model IdempotencyKey {
+//	  key       String   @id
+//	  scope     String
+//	  result    String?
+//	  createdAt DateTime @default(now())
+//	}
+//
+// On MySQL, the result field needs a larger column type, e.g. @db.LongText. The model may be mapped to another
+// table, but the field names must not be mapped to other columns. Once the model exists, the generated client has an
+// Idempotent method on create queries.
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/steebchen/prisma-client-go/engine"
+	"github.com/steebchen/prisma-client-go/runtime/raw"
+	"github.com/steebchen/prisma-client-go/runtime/tools"
+)
+
+// Model is the name of the Prisma model which stores the idempotency keys
+const Model = "IdempotencyKey"
+
+// txTimeout is the maximum duration of the transaction recording a key and executing the operation
+const txTimeout = 15 * time.Second
+
+// ErrKeyReused is returned when a key is replayed for a different operation than it was first used for
+var ErrKeyReused = errors.New("idempotency key was already used for a different operation")
+
+// Exec runs fn at most once per key. On the first execution, fn runs within an interactive transaction which also
+// records the key and the JSON encoded result. Later executions with the same key return the recorded result without
+// calling fn. The scope identifies the operation, e.g. the model name, so that a key can't be reused across
+// operations.
+//
+// The engine must be a generated client; fn must send its queries to the engine it receives.
+func Exec[T any](ctx context.Context, e engine.Engine, key string, scope string, fn func(ctx context.Context, tx engine.Engine) (T, error)) (T, error) {
+	var zero T
+
+	client, ok := e.(tools.Client)
+	if !ok {
+		return zero, fmt.Errorf("idempotency keys require a generated client")
+	}
+
+	s, err := newStore(client.RelationGraph())
+	if err != nil {
+		return zero, err
+	}
+
+	// reuse a surrounding interactive transaction, as they can't be nested
+	if engine.InTx(e) {
+		v, created, err := run(ctx, s, e, key, scope, fn)
+		if err != nil || created {
+			return v, err
+		}
+		return load[T](ctx, s, e, key, scope)
+	}
+
+	transactor, ok := engine.AsTransactor(e)
+	if !ok {
+		return zero, fmt.Errorf("engine %s does not support interactive transactions", e.Name())
+	}
+
+	id, err := transactor.StartTx(ctx, engine.TxOptions{Timeout: txTimeout})
+	if err != nil {
+		return zero, err
+	}
+
+	tx := engine.NewTxEngine(e, id)
+	v, created, err := run(ctx, s, tx, key, scope, fn)
+	if err != nil || !created {
+		if rollbackErr := tx.Rollback(context.Background(), transactor); rollbackErr != nil && err == nil {
+			return zero, rollbackErr
+		}
+		if err != nil {
+			return zero, err
+		}
+		return load[T](ctx, s, e, key, scope)
+	}
+
+	if err := tx.Commit(ctx, transactor); err != nil {
+		return zero, err
+	}
+
+	return v, nil
+}
+
+// run records the key and calls fn if the key is new, returning whether fn was called
+func run[T any](ctx context.Context, s store, tx engine.Engine, key string, scope string, fn func(ctx context.Context, tx engine.Engine) (T, error)) (T, bool, error) {
+	var zero T
+
+	r := raw.Raw{Engine: tx}
+	result, err := r.ExecuteRaw(s.insert(), key, scope, engine.Now(tx)).Exec(ctx)
+	if err != nil {
+		return zero, false, fmt.Errorf("record idempotency key: %w", err)
+	}
+	if result.Count == 0 {
+		return zero, false, nil
+	}
+
+	v, err := fn(ctx, tx)
+	if err != nil {
+		return zero, false, err
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return zero, false, fmt.Errorf("encode result: %w", err)
+	}
+
+	if _, err := r.ExecuteRaw(s.update(), string(data), key).Exec(ctx); err != nil {
+		return zero, false, fmt.Errorf("record idempotency result: %w", err)
+	}
+
+	return v, true, nil
+}
+
+type record struct {
+	Scope  string  `json:"scope"`
+	Result *string `json:"result"`
+}
+
+// load returns the recorded result of a key
+func load[T any](ctx context.Context, s store, e engine.Engine, key string, scope string) (T, error) {
+	var v T
+
+	var records []record
+	r := raw.Raw{Engine: e}
+	if err := r.QueryRaw(s.selectResult(), key).Exec(ctx, &records); err != nil {
+		return v, fmt.Errorf("load idempotency key: %w", err)
+	}
+
+	if len(records) == 0 || records[0].Result == nil {
+		return v, fmt.Errorf("idempotency key %s is still in use by another operation", key)
+	}
+	if records[0].Scope != scope {
+		return v, ErrKeyReused
+	}
+
+	if err := json.Unmarshal([]byte(*records[0].Result), &v); err != nil {
+		return v, fmt.Errorf("decode result: %w", err)
+	}
+
+	return v, nil
+}
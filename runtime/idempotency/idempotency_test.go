@@ -0,0 +1,102 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/steebchen/prisma-client-go/engine"
+	"github.com/steebchen/prisma-client-go/engine/protocol"
+	"github.com/steebchen/prisma-client-go/runtime/tools"
+)
+
+// fakeClient stores a single idempotency key in memory
+type fakeClient struct {
+	engine.Engine
+	calls  []string
+	scope  string
+	result *string
+}
+
+func (c *fakeClient) RelationGraph() tools.RelationGraph {
+	return tools.RelationGraph{
+		Provider: "postgresql",
+		Tables:   map[string]string{"IdempotencyKey": "IdempotencyKey"},
+	}
+}
+
+func (c *fakeClient) Name() string {
+	return "fake"
+}
+
+func (c *fakeClient) Do(ctx context.Context, payload interface{}, into interface{}) error {
+	query := payload.(protocol.GQLRequest).Query
+
+	var response string
+	switch {
+	case strings.Contains(query, "INSERT"):
+		c.calls = append(c.calls, "insert")
+		response = "0"
+		if c.scope == "" {
+			c.scope = "User.createOne"
+			response = "1"
+		}
+	case strings.Contains(query, "UPDATE"):
+		c.calls = append(c.calls, "update")
+		result := `{"id":"1"}`
+		c.result = &result
+		response = "1"
+	case strings.Contains(query, "SELECT"):
+		c.calls = append(c.calls, "select")
+		data, _ := json.Marshal([]record{{Scope: c.scope, Result: c.result}})
+		response = string(data)
+	}
+	return json.Unmarshal([]byte(response), into)
+}
+
+func (c *fakeClient) StartTx(ctx context.Context, options engine.TxOptions) (string, error) {
+	c.calls = append(c.calls, "start")
+	return "tx1", nil
+}
+
+func (c *fakeClient) CommitTx(ctx context.Context, id string) error {
+	c.calls = append(c.calls, "commit")
+	return nil
+}
+
+func (c *fakeClient) RollbackTx(ctx context.Context, id string) error {
+	c.calls = append(c.calls, "rollback")
+	return nil
+}
+
+type user struct {
+	ID string `json:"id"`
+}
+
+func TestExec(t *testing.T) {
+	client := &fakeClient{}
+	ctx := context.Background()
+
+	create := func(ctx context.Context, tx engine.Engine) (*user, error) {
+		client.calls = append(client.calls, "create")
+		assert.True(t, engine.InTx(tx))
+		return &user{ID: "1"}, nil
+	}
+
+	v, err := Exec(ctx, client, "key1", "User.createOne", create)
+	assert.NoError(t, err)
+	assert.Equal(t, &user{ID: "1"}, v)
+	assert.Equal(t, []string{"start", "insert", "create", "update", "commit"}, client.calls)
+
+	client.calls = nil
+	v, err = Exec(ctx, client, "key1", "User.createOne", create)
+	assert.NoError(t, err)
+	assert.Equal(t, &user{ID: "1"}, v)
+	assert.Equal(t, []string{"start", "insert", "rollback", "select"}, client.calls)
+
+	_, err = Exec(ctx, client, "key1", "Post.createOne", create)
+	assert.ErrorIs(t, err, ErrKeyReused)
+}
@@ -0,0 +1,66 @@
+package idempotency
+
+import (
+	"fmt"
+
+	"github.com/steebchen/prisma-client-go/runtime/raw"
+	"github.com/steebchen/prisma-client-go/runtime/tools"
+)
+
+// store builds the statements for the idempotency key table of a provider
+type store struct {
+	provider string
+	table    string
+}
+
+func newStore(graph tools.RelationGraph) (store, error) {
+	switch graph.Provider {
+	case "postgresql", "postgres", "cockroachdb", "mysql", "sqlite":
+	default:
+		return store{}, fmt.Errorf("idempotency keys are not supported for provider %q", graph.Provider)
+	}
+
+	table, ok := graph.Table(Model)
+	if !ok {
+		return store{}, fmt.Errorf("model %q does not exist in the schema", Model)
+	}
+
+	return store{
+		provider: graph.Provider,
+		table:    table,
+	}, nil
+}
+
+func (s store) ident(name string) string {
+	return raw.Ident(s.provider, name)
+}
+
+// param returns the placeholder of the n-th parameter, starting at 1
+func (s store) param(n int) string {
+	switch s.provider {
+	case "postgresql", "postgres", "cockroachdb":
+		return fmt.Sprintf("$%d", n)
+	}
+	return "?"
+}
+
+// insert returns a statement which records a key, affecting no rows if the key already exists
+func (s store) insert() string {
+	columns := s.ident("key") + ", " + s.ident("scope") + ", " + s.ident("createdAt")
+	values := s.param(1) + ", " + s.param(2) + ", " + s.param(3)
+
+	if s.provider == "mysql" {
+		return "INSERT IGNORE INTO " + s.table + " (" + columns + ") VALUES (" + values + ")"
+	}
+	return "INSERT INTO " + s.table + " (" + columns + ") VALUES (" + values + ") ON CONFLICT DO NOTHING"
+}
+
+func (s store) update() string {
+	return "UPDATE " + s.table + " SET " + s.ident("result") + " = " + s.param(1) +
+		" WHERE " + s.ident("key") + " = " + s.param(2)
+}
+
+func (s store) selectResult() string {
+	return "SELECT " + s.ident("scope") + ", " + s.ident("result") + " FROM " + s.table +
+		" WHERE " + s.ident("key") + " = " + s.param(1)
+}
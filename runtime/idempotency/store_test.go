@@ -0,0 +1,29 @@
+package idempotency
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/steebchen/prisma-client-go/runtime/tools"
+)
+
+func TestStore(t *testing.T) {
+	tables := map[string]string{"IdempotencyKey": "idempotency_keys"}
+
+	s, err := newStore(tools.RelationGraph{Provider: "postgresql", Tables: tables})
+	assert.NoError(t, err)
+	assert.Equal(t, `INSERT INTO "idempotency_keys" ("key", "scope", "createdAt") VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`, s.insert())
+	assert.Equal(t, `UPDATE "idempotency_keys" SET "result" = $1 WHERE "key" = $2`, s.update())
+	assert.Equal(t, `SELECT "scope", "result" FROM "idempotency_keys" WHERE "key" = $1`, s.selectResult())
+
+	s, err = newStore(tools.RelationGraph{Provider: "mysql", Tables: tables})
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT IGNORE INTO `idempotency_keys` (`key`, `scope`, `createdAt`) VALUES (?, ?, ?)", s.insert())
+
+	_, err = newStore(tools.RelationGraph{Provider: "mongodb", Tables: tables})
+	assert.EqualError(t, err, `idempotency keys are not supported for provider "mongodb"`)
+
+	_, err = newStore(tools.RelationGraph{Provider: "sqlite"})
+	assert.EqualError(t, err, `model "IdempotencyKey" does not exist in the schema`)
+}
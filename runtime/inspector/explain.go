@@ -0,0 +1,84 @@
+package inspector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/steebchen/prisma-client-go/runtime/raw"
+)
+
+// explainPrefix returns the statement prefix which returns the query plan of a statement, and false if the provider
+// doesn't support it
+func explainPrefix(provider string) (string, bool) {
+	switch provider {
+	case "postgresql", "postgres", "cockroachdb", "mysql":
+		return "EXPLAIN ", true
+	case "sqlite":
+		return "EXPLAIN QUERY PLAN ", true
+	}
+	return "", false
+}
+
+// explainable reports whether the plan of a statement can be captured, which excludes transaction control statements
+// and plans themselves
+func explainable(query string) bool {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return false
+	}
+	switch strings.ToUpper(fields[0]) {
+	case "SELECT", "INSERT", "UPDATE", "DELETE", "WITH":
+		return true
+	}
+	return false
+}
+
+// explain captures the query plan of a statement with the parameters the engine logged for it. The plan is sent as a
+// separate raw query, so it runs on another connection of the pool than the slow statement and never within its
+// transaction.
+func (i *Inspector) explain(ctx context.Context, query string, params string) (string, error) {
+	prefix, ok := explainPrefix(i.provider)
+	if !ok {
+		return "", fmt.Errorf("query plans are not supported for provider %q", i.provider)
+	}
+
+	var args []interface{}
+	if params != "" {
+		if err := json.Unmarshal([]byte(params), &args); err != nil {
+			return "", fmt.Errorf("parse params: %w", err)
+		}
+	}
+
+	var rows []map[string]interface{}
+	r := raw.Raw{Engine: i.engine}
+	if err := r.QueryRaw(prefix+query, args...).Exec(ctx, &rows); err != nil {
+		return "", err
+	}
+	return formatPlan(rows)
+}
+
+// formatPlan renders the rows of a query plan as one line per row, using the value of single-column rows such as the
+// QUERY PLAN of PostgreSQL, the detail of SQLite and the JSON object of other rows, e.g. of MySQL
+func formatPlan(rows []map[string]interface{}) (string, error) {
+	lines := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if detail, ok := row["detail"]; ok {
+			lines = append(lines, fmt.Sprint(detail))
+			continue
+		}
+		if len(row) == 1 {
+			for _, v := range row {
+				lines = append(lines, fmt.Sprint(v))
+			}
+			continue
+		}
+		v, err := json.Marshal(row)
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, string(v))
+	}
+	return strings.Join(lines, "\n"), nil
+}
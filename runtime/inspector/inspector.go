@@ -0,0 +1,308 @@
+// Package inspector provides an HTTP handler which exposes the state of the data layer of a running service, such as
+// the engine status, connection pool stats and recent slow queries.
+package inspector
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steebchen/prisma-client-go/engine"
+	"github.com/steebchen/prisma-client-go/runtime/tools"
+)
+
+// Options configures an Inspector
+type Options struct {
+	// Authorize decides whether a request may access the inspector. If nil, all requests are denied.
+	Authorize func(r *http.Request) bool
+	// SlowQueryThreshold is the minimum duration of a query to be recorded as slow, 200ms by default
+	SlowQueryThreshold time.Duration
+	// SlowQueryLimit is the maximum number of recent slow queries which are kept, 50 by default
+	SlowQueryLimit int
+	// ExplainThreshold is the minimum duration of a slow query to capture its query plan with EXPLAIN. The plan is
+	// captured after the query finished, as the engine reports statements only then. If 0, no plans are captured.
+	// Only PostgreSQL, CockroachDB, MySQL and SQLite are supported.
+	ExplainThreshold time.Duration
+	// ExplainTimeout is the maximum duration of capturing a query plan, 5s by default
+	ExplainTimeout time.Duration
+	// WatchdogThreshold is the minimum duration of a statement which is still running to capture its query plan, if
+	// Watch is running. Only PostgreSQL is supported.
+	WatchdogThreshold time.Duration
+	// OnSlowQuery is called for every slow query, after its query plan was captured, e.g. to log it. Statements found
+	// by the watchdog are passed once while running and again once they finished. It must return quickly, as it may be
+	// called while the engine output is read.
+	OnSlowQuery func(SlowQuery)
+}
+
+// SlowQuery is a statement which took at least the slow query threshold
+type SlowQuery struct {
+	engine.QueryEvent
+	// Plan is the query plan of the statement if it took at least the explain threshold
+	Plan string
+	// PlanError describes why the query plan could not be captured
+	PlanError string
+	// Running is true for statements found by the watchdog which didn't finish yet, or whose end wasn't reported by
+	// the engine, e.g. statements of other clients. Their duration is the time they ran until they were found.
+	Running bool
+}
+
+// Inspector is an http.Handler serving the following JSON endpoints relative to where it's mounted:
+//
+//	/health        whether the engine responds
+//	/engine        the engine name and status
+//	/pool          the engine metrics including connection pool stats
+//	/slow-queries  the most recent slow queries
+type Inspector struct {
+	engine   engine.Engine
+	options  Options
+	mux      *http.ServeMux
+	provider string
+
+	// explaining limits the captured query plans to one at a time, so that a burst of slow queries doesn't add to
+	// the load of the database
+	explaining chan struct{}
+
+	mu          sync.Mutex
+	slowQueries []*SlowQuery
+}
+
+// New creates an Inspector for a client. It must be called before the client connects, so that slow queries and
+// connection pool stats can be recorded.
+//
+// Example:
+//
+//	client := db.NewClient()
+//	i := inspector.New(client, inspector.Options{
+//	  Authorize: inspector.BearerToken(os.Getenv("INSPECTOR_TOKEN")),
+//	})
+//	http.Handle("/debug/db/", http.StripPrefix("/debug/db", i))
+func New(client engine.Engine, options Options) *Inspector {
+	if options.SlowQueryThreshold == 0 {
+		options.SlowQueryThreshold = 200 * time.Millisecond
+	}
+	if options.SlowQueryLimit == 0 {
+		options.SlowQueryLimit = 50
+	}
+	if options.ExplainTimeout == 0 {
+		options.ExplainTimeout = 5 * time.Second
+	}
+
+	i := &Inspector{
+		engine:     client,
+		options:    options,
+		mux:        http.NewServeMux(),
+		explaining: make(chan struct{}, 1),
+	}
+	if c, ok := client.(tools.Client); ok {
+		i.provider = c.RelationGraph().Provider
+	}
+
+	if emitter, ok := engine.AsQueryEmitter(client); ok {
+		emitter.OnQuery(i.record)
+	}
+	engine.EnableMetrics(client)
+
+	i.mux.HandleFunc("/health", i.health)
+	i.mux.HandleFunc("/engine", i.status)
+	i.mux.HandleFunc("/pool", i.pool)
+	i.mux.HandleFunc("/slow-queries", i.slow)
+
+	return i
+}
+
+// BearerToken returns an Authorize function which requires the given token in the Authorization header.
+// An empty token denies all requests.
+func BearerToken(token string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		return ok && token != "" && subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+	}
+}
+
+func (i *Inspector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if i.options.Authorize == nil || !i.options.Authorize(r) {
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": "forbidden"})
+		return
+	}
+	i.mux.ServeHTTP(w, r)
+}
+
+// SlowQueries returns the most recent slow queries, oldest first
+func (i *Inspector) SlowQueries() []SlowQuery {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	queries := make([]SlowQuery, len(i.slowQueries))
+	for n, q := range i.slowQueries {
+		queries[n] = *q
+	}
+	return queries
+}
+
+func (i *Inspector) record(e engine.QueryEvent) {
+	// a statement found by the watchdog while running already has its plan
+	if q, ok := i.finish(e); ok {
+		i.notify(q)
+		return
+	}
+
+	if e.Duration < i.options.SlowQueryThreshold {
+		return
+	}
+
+	q := &SlowQuery{QueryEvent: e}
+	i.add(q)
+
+	if i.options.ExplainThreshold == 0 || e.Duration < i.options.ExplainThreshold || !explainable(e.Query) {
+		i.notify(q)
+		return
+	}
+
+	select {
+	case i.explaining <- struct{}{}:
+	default:
+		i.mu.Lock()
+		q.PlanError = "skipped, as another query plan is being captured"
+		i.mu.Unlock()
+		i.notify(q)
+		return
+	}
+
+	// events are emitted while the engine output is read, so the plan is captured in the background
+	go func() {
+		defer func() { <-i.explaining }()
+
+		ctx, cancel := context.WithTimeout(context.Background(), i.options.ExplainTimeout)
+		defer cancel()
+		plan, err := i.explain(ctx, e.Query, e.Params)
+
+		i.mu.Lock()
+		q.Plan = plan
+		if err != nil {
+			q.PlanError = err.Error()
+		}
+		i.mu.Unlock()
+		i.notify(q)
+	}()
+}
+
+// add records a slow query, dropping the oldest ones over the limit
+func (i *Inspector) add(q *SlowQuery) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.slowQueries = append(i.slowQueries, q)
+	if over := len(i.slowQueries) - i.options.SlowQueryLimit; over > 0 {
+		i.slowQueries = i.slowQueries[over:]
+	}
+}
+
+// finish updates the oldest running statement with the same query as a finished one, and returns false if there is
+// none
+func (i *Inspector) finish(e engine.QueryEvent) (*SlowQuery, bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, q := range i.slowQueries {
+		if q.Running && q.Query == e.Query {
+			q.QueryEvent, q.Running = e, false
+			return q, true
+		}
+	}
+	return nil, false
+}
+
+// notify passes a slow query to OnSlowQuery
+func (i *Inspector) notify(q *SlowQuery) {
+	if i.options.OnSlowQuery == nil {
+		return
+	}
+	i.mu.Lock()
+	v := *q
+	i.mu.Unlock()
+	i.options.OnSlowQuery(v)
+}
+
+func (i *Inspector) health(w http.ResponseWriter, r *http.Request) {
+	reporter, ok := engine.AsStatusReporter(i.engine)
+	if !ok {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "unknown"})
+		return
+	}
+
+	if _, err := reporter.Status(r.Context()); err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "unavailable", "error": err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (i *Inspector) status(w http.ResponseWriter, r *http.Request) {
+	response := map[string]interface{}{
+		"name": i.engine.Name(),
+	}
+
+	if reporter, ok := engine.AsStatusReporter(i.engine); ok {
+		status, err := reporter.Status(r.Context())
+		if err != nil {
+			response["error"] = err.Error()
+		} else {
+			response["status"] = status
+		}
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (i *Inspector) pool(w http.ResponseWriter, r *http.Request) {
+	reporter, ok := engine.AsStatusReporter(i.engine)
+	if !ok {
+		writeJSON(w, http.StatusNotImplemented, map[string]string{"error": "engine does not report metrics"})
+		return
+	}
+
+	metrics, err := reporter.Metrics(r.Context())
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, metrics)
+}
+
+type slowQuery struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Query      string    `json:"query"`
+	Params     string    `json:"params"`
+	DurationMS float64   `json:"durationMs"`
+	Target     string    `json:"target"`
+	Plan       string    `json:"plan,omitempty"`
+	PlanError  string    `json:"planError,omitempty"`
+	Running    bool      `json:"running,omitempty"`
+}
+
+func (i *Inspector) slow(w http.ResponseWriter, r *http.Request) {
+	queries := []slowQuery{}
+	for _, e := range i.SlowQueries() {
+		queries = append(queries, slowQuery{
+			Timestamp:  e.Timestamp,
+			Query:      e.Query,
+			Params:     e.Params,
+			DurationMS: float64(e.Duration) / float64(time.Millisecond),
+			Target:     e.Target,
+			Plan:       e.Plan,
+			PlanError:  e.PlanError,
+			Running:    e.Running,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, queries)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
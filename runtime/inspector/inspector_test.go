@@ -0,0 +1,223 @@
+package inspector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/steebchen/prisma-client-go/engine"
+	"github.com/steebchen/prisma-client-go/engine/protocol"
+	"github.com/steebchen/prisma-client-go/runtime/tools"
+)
+
+type fakeEngine struct {
+	engine.Engine
+	onQuery func(engine.QueryEvent)
+	metrics bool
+}
+
+func (e *fakeEngine) Name() string {
+	return "fake"
+}
+
+func (e *fakeEngine) OnQuery(fn func(engine.QueryEvent)) {
+	e.onQuery = fn
+}
+
+func (e *fakeEngine) EnableMetrics() {
+	e.metrics = true
+}
+
+func (e *fakeEngine) Status(ctx context.Context) (json.RawMessage, error) {
+	return json.RawMessage(`{"status":"ok"}`), nil
+}
+
+func (e *fakeEngine) Metrics(ctx context.Context) (json.RawMessage, error) {
+	return json.RawMessage(`{"gauges":[]}`), nil
+}
+
+func get(i *Inspector, path string, token string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest("GET", path, nil)
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	i.ServeHTTP(w, r)
+	return w
+}
+
+func TestInspector(t *testing.T) {
+	e := &fakeEngine{}
+	i := New(e, Options{
+		Authorize:      BearerToken("secret"),
+		SlowQueryLimit: 2,
+	})
+
+	assert.True(t, e.metrics)
+
+	assert.Equal(t, http.StatusForbidden, get(i, "/health", "").Code)
+	assert.Equal(t, http.StatusForbidden, get(i, "/health", "wrong").Code)
+
+	w := get(i, "/health", "secret")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+
+	assert.JSONEq(t, `{"name":"fake","status":{"status":"ok"}}`, get(i, "/engine", "secret").Body.String())
+	assert.JSONEq(t, `{"gauges":[]}`, get(i, "/pool", "secret").Body.String())
+
+	e.onQuery(engine.QueryEvent{Query: "fast", Duration: time.Millisecond})
+	e.onQuery(engine.QueryEvent{Query: "slow 1", Duration: time.Second})
+	e.onQuery(engine.QueryEvent{Query: "slow 2", Duration: time.Second})
+	e.onQuery(engine.QueryEvent{Query: "slow 3", Duration: 1500 * time.Millisecond})
+
+	var queries []slowQuery
+	if err := json.Unmarshal(get(i, "/slow-queries", "secret").Body.Bytes(), &queries); err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, queries, 2)
+	assert.Equal(t, "slow 2", queries[0].Query)
+	assert.Equal(t, "slow 3", queries[1].Query)
+	assert.Equal(t, 1500.0, queries[1].DurationMS)
+}
+
+func TestInspectorWithoutAuthorize(t *testing.T) {
+	i := New(&fakeEngine{}, Options{})
+	assert.Equal(t, http.StatusForbidden, get(i, "/health", "").Code)
+}
+
+// explainEngine answers raw queries with a query plan and records the statements it was sent
+type explainEngine struct {
+	fakeEngine
+	queries []string
+}
+
+func (e *explainEngine) RelationGraph() tools.RelationGraph {
+	return tools.RelationGraph{Provider: "postgresql"}
+}
+
+func (e *explainEngine) Do(ctx context.Context, payload interface{}, into interface{}) error {
+	e.queries = append(e.queries, payload.(protocol.GQLRequest).Query)
+	return json.Unmarshal([]byte(`[{"QUERY PLAN":"Seq Scan on users"},{"QUERY PLAN":"  Filter: (email = $1)"}]`), into)
+}
+
+func TestInspectorExplain(t *testing.T) {
+	e := &explainEngine{}
+	slow := make(chan SlowQuery, 3)
+	i := New(e, Options{
+		SlowQueryThreshold: 100 * time.Millisecond,
+		ExplainThreshold:   time.Second,
+		OnSlowQuery: func(q SlowQuery) {
+			slow <- q
+		},
+	})
+
+	// queries below the explain threshold and transaction statements are recorded without a plan
+	e.onQuery(engine.QueryEvent{Query: `SELECT 1`, Duration: 200 * time.Millisecond})
+	e.onQuery(engine.QueryEvent{Query: `COMMIT`, Duration: 2 * time.Second})
+	assert.Equal(t, SlowQuery{QueryEvent: engine.QueryEvent{Query: `SELECT 1`, Duration: 200 * time.Millisecond}}, <-slow)
+	assert.Equal(t, "", (<-slow).Plan)
+
+	e.onQuery(engine.QueryEvent{
+		Query:    `SELECT "id" FROM "users" WHERE "email" = $1`,
+		Params:   `["a@example.com"]`,
+		Duration: 2 * time.Second,
+	})
+	q := <-slow
+	assert.Equal(t, "Seq Scan on users\n  Filter: (email = $1)", q.Plan)
+	assert.Equal(t, "", q.PlanError)
+	assert.Len(t, e.queries, 1)
+	assert.Contains(t, e.queries[0], `EXPLAIN SELECT \"id\" FROM \"users\" WHERE \"email\" = $1`)
+	assert.Contains(t, e.queries[0], `a@example.com`)
+
+	queries := i.SlowQueries()
+	assert.Len(t, queries, 3)
+	assert.Equal(t, q, queries[2])
+}
+
+func TestFormatPlan(t *testing.T) {
+	plan, err := formatPlan([]map[string]interface{}{
+		{"id": 2, "parent": 0, "notused": 0, "detail": "SCAN users"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "SCAN users", plan)
+
+	plan, err = formatPlan([]map[string]interface{}{
+		{"id": 1, "select_type": "SIMPLE", "table": "users"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"id":1,"select_type":"SIMPLE","table":"users"}`, plan)
+}
+
+// watchdogEngine answers the query of running statements with a slow statement, and other raw queries with a plan
+type watchdogEngine struct {
+	explainEngine
+	mu sync.Mutex
+}
+
+func (e *watchdogEngine) Do(ctx context.Context, payload interface{}, into interface{}) error {
+	query := payload.(protocol.GQLRequest).Query
+	if strings.Contains(query, "pg_stat_activity") {
+		return json.Unmarshal([]byte(`[{"pid":42,"query":"SELECT \"id\" FROM \"users\" WHERE \"email\" = $1","queryStart":"2024-01-01T00:00:00Z"}]`), into)
+	}
+	e.mu.Lock()
+	e.queries = append(e.queries, query)
+	e.mu.Unlock()
+	return json.Unmarshal([]byte(`[{"QUERY PLAN":"Seq Scan on users"}]`), into)
+}
+
+func TestInspectorWatch(t *testing.T) {
+	e := &watchdogEngine{}
+	slow := make(chan SlowQuery, 3)
+	i := New(e, Options{
+		WatchdogThreshold: 10 * time.Millisecond,
+		OnSlowQuery: func(q SlowQuery) {
+			slow <- q
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- i.Watch(ctx)
+	}()
+
+	q := <-slow
+	assert.True(t, q.Running)
+	assert.Equal(t, `SELECT "id" FROM "users" WHERE "email" = $1`, q.Query)
+	assert.Equal(t, "Seq Scan on users", q.Plan)
+	assert.Equal(t, "", q.PlanError)
+
+	// the statement is explained only once while it's running
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.Len(t, slow, 0)
+	e.mu.Lock()
+	assert.Len(t, e.queries, 1)
+	assert.Contains(t, e.queries[0], `EXPLAIN (GENERIC_PLAN) SELECT \"id\" FROM \"users\" WHERE \"email\" = $1`)
+	assert.Contains(t, e.queries[0], `parameters:"[null]"`)
+	e.mu.Unlock()
+
+	// once the statement finished, the slow query is updated and keeps its plan
+	e.onQuery(engine.QueryEvent{Query: q.Query, Params: `["a@example.com"]`, Duration: 3 * time.Second})
+	q = <-slow
+	assert.False(t, q.Running)
+	assert.Equal(t, 3*time.Second, q.Duration)
+	assert.Equal(t, "Seq Scan on users", q.Plan)
+	assert.Equal(t, []SlowQuery{q}, i.SlowQueries())
+}
+
+func TestInspectorWatchUnsupported(t *testing.T) {
+	i := New(&fakeEngine{}, Options{WatchdogThreshold: time.Second})
+	assert.EqualError(t, i.Watch(context.Background()), `the watchdog is not supported for provider ""`)
+
+	i = New(&explainEngine{}, Options{})
+	assert.EqualError(t, i.Watch(context.Background()), "the watchdog requires a WatchdogThreshold")
+}
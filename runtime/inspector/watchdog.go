@@ -0,0 +1,145 @@
+package inspector
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/steebchen/prisma-client-go/logger"
+	"github.com/steebchen/prisma-client-go/runtime/raw"
+)
+
+// activityQuery lists the statements of other sessions of the database which have been running for at least the
+// given number of seconds
+const activityQuery = `SELECT pid, query, query_start AS "queryStart" FROM pg_stat_activity ` +
+	`WHERE datname = current_database() AND pid <> pg_backend_pid() AND state = 'active' ` +
+	`AND now() - query_start >= $1 * interval '1 second'`
+
+// truncatedQueryLength is the default track_activity_query_size of PostgreSQL, up to which pg_stat_activity reports
+// the text of a statement
+const truncatedQueryLength = 1023
+
+// placeholder matches the parameter placeholders of a statement, e.g. $1
+var placeholder = regexp.MustCompile(`\$(\d+)`)
+
+// activity is a statement which is running in a session of the database
+type activity struct {
+	PID        int       `json:"pid"`
+	Query      string    `json:"query"`
+	QueryStart time.Time `json:"queryStart"`
+}
+
+// activityKey identifies a running statement
+type activityKey struct {
+	pid   int
+	start time.Time
+}
+
+// Watch captures the query plans of statements while they are still running, once they ran for WatchdogThreshold,
+// until ctx is done. Running statements are looked up in pg_stat_activity every WatchdogThreshold, so they are found
+// after running for up to twice as long. Only PostgreSQL is supported.
+//
+// Example:
+//
+//	go i.Watch(ctx)
+func (i *Inspector) Watch(ctx context.Context) error {
+	if i.options.WatchdogThreshold <= 0 {
+		return fmt.Errorf("the watchdog requires a WatchdogThreshold")
+	}
+	if i.provider != "postgresql" && i.provider != "postgres" {
+		return fmt.Errorf("the watchdog is not supported for provider %q", i.provider)
+	}
+
+	ticker := time.NewTicker(i.options.WatchdogThreshold)
+	defer ticker.Stop()
+
+	seen := map[activityKey]bool{}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		next, err := i.watch(ctx, seen)
+		if err != nil {
+			logger.Debug.Printf("watchdog: %s", err)
+			continue
+		}
+		seen = next
+	}
+}
+
+// watch records the running statements which exceeded the watchdog threshold and were not seen before, and returns
+// the keys of all statements which are still running
+func (i *Inspector) watch(ctx context.Context, seen map[activityKey]bool) (map[activityKey]bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, i.options.ExplainTimeout)
+	defer cancel()
+
+	var running []activity
+	r := raw.Raw{Engine: i.engine}
+	if err := r.QueryRaw(activityQuery, i.options.WatchdogThreshold.Seconds()).Exec(ctx, &running); err != nil {
+		return nil, fmt.Errorf("list running statements: %w", err)
+	}
+
+	next := make(map[activityKey]bool, len(running))
+	for _, a := range running {
+		key := activityKey{pid: a.PID, start: a.QueryStart}
+		next[key] = true
+		if seen[key] || !explainable(a.Query) {
+			continue
+		}
+
+		q := &SlowQuery{Running: true}
+		q.Timestamp, q.Query, q.Duration = a.QueryStart, a.Query, time.Since(a.QueryStart)
+
+		// plans of running statements are captured one at a time as well, but waiting for the others, as the
+		// statement is only found once
+		select {
+		case i.explaining <- struct{}{}:
+		case <-ctx.Done():
+			return next, ctx.Err()
+		}
+		plan, err := i.explainRunning(ctx, a.Query)
+		<-i.explaining
+
+		q.Plan = plan
+		if err != nil {
+			q.PlanError = err.Error()
+		}
+		i.add(q)
+		i.notify(q)
+	}
+	return next, nil
+}
+
+// explainRunning captures the query plan of a running statement, whose parameters are unknown. Statements with
+// parameters are explained with a generic plan, which requires PostgreSQL 16.
+func (i *Inspector) explainRunning(ctx context.Context, query string) (string, error) {
+	if len(query) >= truncatedQueryLength {
+		return "", fmt.Errorf("the statement may be truncated by track_activity_query_size")
+	}
+
+	params := 0
+	for _, m := range placeholder.FindAllStringSubmatch(query, -1) {
+		if n, err := strconv.Atoi(m[1]); err == nil && n > params {
+			params = n
+		}
+	}
+
+	prefix := "EXPLAIN "
+	if params > 0 {
+		prefix = "EXPLAIN (GENERIC_PLAN) "
+	}
+
+	// a generic plan doesn't depend on the values of the parameters, but the engine binds one for each placeholder
+	args := make([]interface{}, params)
+	var rows []map[string]interface{}
+	r := raw.Raw{Engine: i.engine}
+	if err := r.QueryRaw(prefix+query, args...).Exec(ctx, &rows); err != nil {
+		return "", err
+	}
+	return formatPlan(rows)
+}
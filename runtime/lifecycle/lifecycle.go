@@ -1,11 +1,21 @@
 package lifecycle
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"github.com/steebchen/prisma-client-go/engine"
+	"github.com/steebchen/prisma-client-go/logger"
+	"github.com/steebchen/prisma-client-go/runtime/notify"
+	"github.com/steebchen/prisma-client-go/runtime/raw"
 )
 
 type Lifecycle struct {
 	Engine engine.Engine
+
+	// Provider is the active datasource provider, e.g. postgresql or mysql
+	Provider string
 }
 
 // Connect connects to the Prisma query engine. Required to call before accessing data.
@@ -42,3 +52,178 @@ func (c *Lifecycle) Connect() error {
 func (c *Lifecycle) Disconnect() error {
 	return c.Engine.Disconnect()
 }
+
+// OnQuery registers fn to be called for every statement the query engine executes, including its parameters and
+// duration. Listeners must be registered before calling Connect.
+//
+// Example:
+//
+//	client.Prisma.OnQuery(func(e db.QueryEvent) {
+//	  log.Printf("%s %s took %s", e.Query, e.Params, e.Duration)
+//	})
+func (c *Lifecycle) OnQuery(fn func(e engine.QueryEvent)) {
+	emitter, ok := engine.AsQueryEmitter(c.Engine)
+	if !ok {
+		logger.Info.Printf("query events are not supported by engine %s", c.Engine.Name())
+		return
+	}
+	emitter.OnQuery(fn)
+}
+
+// OnEngineEvent registers fn to be called when the query engine process crashes and is restarted. After a crash,
+// the engine is restarted automatically with an exponential backoff; queries fail until the restart succeeded.
+//
+// Example:
+//
+//	client.Prisma.OnEngineEvent(func(e db.EngineEvent) {
+//	  log.Printf("query engine %s (attempt %d): %v", e.Type, e.Attempt, e.Err)
+//	})
+func (c *Lifecycle) OnEngineEvent(fn func(e engine.EngineEvent)) {
+	emitter, ok := engine.AsEngineEventEmitter(c.Engine)
+	if !ok {
+		logger.Info.Printf("engine events are not supported by engine %s", c.Engine.Name())
+		return
+	}
+	emitter.OnEngineEvent(fn)
+}
+
+// OnCrash registers fn to be called with a crash report when the query engine process exits unexpectedly. The report
+// contains the exit code, the last lines the engine wrote to stderr and the last queries sent to it.
+//
+// Example:
+//
+//	client.Prisma.OnCrash(func(r *db.CrashReport) {
+//	  log.Printf("query engine crashed with exit code %d: %s", r.ExitCode, strings.Join(r.Stderr, "\n"))
+//	})
+func (c *Lifecycle) OnCrash(fn func(report *engine.CrashReport)) {
+	c.OnEngineEvent(func(e engine.EngineEvent) {
+		if e.Type == engine.EngineCrashed && e.Report != nil {
+			fn(e.Report)
+		}
+	})
+}
+
+// SetRestartPolicy configures how the query engine is restarted after it crashed, e.g. to limit the attempts or to
+// disable restarts altogether.
+//
+// Example:
+//
+//	client.Prisma.SetRestartPolicy(db.RestartPolicy{
+//	  MaxBackoff:  5 * time.Second,
+//	  MaxAttempts: 10,
+//	})
+func (c *Lifecycle) SetRestartPolicy(policy engine.RestartPolicy) {
+	if !engine.SetRestartPolicy(c.Engine, policy) {
+		logger.Info.Printf("restart policies are not supported by engine %s", c.Engine.Name())
+	}
+}
+
+// WatchSchema restarts the query engine whenever the schema file at path changes, until ctx is done, so that
+// hot-reload development loops don't require restarting the process. It is meant for development only: the generated
+// Go types are not updated, so each change logs a warning to regenerate the client. An interval of 0 checks the file
+// every 500ms.
+//
+// Example:
+//
+//	if os.Getenv("APP_ENV") == "development" {
+//	  if err := client.Prisma.WatchSchema(ctx, "prisma/schema.prisma", 0); err != nil {
+//	    handle(err)
+//	  }
+//	}
+func (c *Lifecycle) WatchSchema(ctx context.Context, path string, interval time.Duration) error {
+	return engine.WatchSchema(ctx, c.Engine, path, interval)
+}
+
+// Redeploy replaces the query engine process with one using a new schema or binary without dropping queries, e.g. to
+// roll out a regenerated client in a long-lived worker. A standby engine is started next to the current one, and once
+// it is ready, new queries switch to it at once, while the old engine finishes its in-flight queries and interactive
+// transactions before it is stopped. If the standby engine can't be started, the current engine keeps running.
+//
+// Example:
+//
+//	err := client.Prisma.Redeploy(ctx, db.RedeployOptions{
+//	  Schema:       newSchema,
+//	  BinaryPath:   "/opt/prisma/query-engine-5.x",
+//	  DrainTimeout: time.Minute,
+//	})
+func (c *Lifecycle) Redeploy(ctx context.Context, options engine.RedeployOptions) error {
+	r, ok := engine.AsRedeployer(c.Engine)
+	if !ok {
+		return fmt.Errorf("redeploys are not supported by engine %s", c.Engine.Name())
+	}
+	return r.Redeploy(ctx, options)
+}
+
+// Listen subscribes to PostgreSQL notifications on the given channels until ctx is done, when the returned channel is
+// closed. Notifications are received on a dedicated connection to the database, which is re-established if it's lost.
+// Only PostgreSQL is supported.
+//
+// Example:
+//
+//	notifications, err := client.Prisma.Listen(ctx, "jobs")
+//	if err != nil {
+//	  return err
+//	}
+//	for n := range notifications {
+//	  log.Printf("new job: %s", n.Payload)
+//	}
+func (c *Lifecycle) Listen(ctx context.Context, channels ...string) (<-chan notify.Notification, error) {
+	if c.Provider != "postgresql" && c.Provider != "postgres" {
+		return nil, fmt.Errorf("notifications are not supported for provider %s", c.Provider)
+	}
+	url, ok := engine.DatasourceURLOf(c.Engine)
+	if !ok {
+		return nil, fmt.Errorf("notifications are not supported by engine %s", c.Engine.Name())
+	}
+	return notify.Listen(ctx, url, channels...)
+}
+
+// Notify sends a notification with the given payload on a channel, which listeners receive once the transaction of
+// the query is committed.
+//
+// Example:
+//
+//	if err := client.Prisma.Notify(ctx, "jobs", job.ID); err != nil {
+//	  return err
+//	}
+func (c *Lifecycle) Notify(ctx context.Context, channel string, payload string) error {
+	r := raw.Raw{Engine: c.Engine}
+	if _, err := r.ExecuteRaw("SELECT pg_notify($1, $2)", channel, payload).Exec(ctx); err != nil {
+		return fmt.Errorf("notify: %w", err)
+	}
+	return nil
+}
+
+// Ping verifies that the query engine is running and that it can reach the database by sending a cheap query,
+// e.g. for readiness probes.
+//
+// Example:
+//
+//	http.HandleFunc("/ready", func(w http.ResponseWriter, r *http.Request) {
+//	  if err := client.Prisma.Ping(r.Context()); err != nil {
+//	    http.Error(w, err.Error(), http.StatusServiceUnavailable)
+//	  }
+//	})
+func (c *Lifecycle) Ping(ctx context.Context) error {
+	if reporter, ok := engine.AsStatusReporter(c.Engine); ok {
+		if _, err := reporter.Status(ctx); err != nil {
+			return fmt.Errorf("ping: %w", err)
+		}
+	}
+
+	r := raw.Raw{Engine: c.Engine}
+
+	var err error
+	if c.Provider == "mongodb" {
+		var result interface{}
+		err = r.RunCommandRaw(`{"ping": 1}`).Exec(ctx, &result)
+	} else {
+		var result []map[string]interface{}
+		err = r.QueryRaw("SELECT 1").Exec(ctx, &result)
+	}
+	if err != nil {
+		return fmt.Errorf("ping database: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/steebchen/prisma-client-go/engine"
+	"github.com/steebchen/prisma-client-go/engine/protocol"
+)
+
+type fakeEngine struct {
+	engine.Engine
+	queries []string
+	err     error
+}
+
+func (e *fakeEngine) Do(ctx context.Context, payload interface{}, into interface{}) error {
+	e.queries = append(e.queries, payload.(protocol.GQLRequest).Query)
+	return e.err
+}
+
+func TestPing(t *testing.T) {
+	e := &fakeEngine{}
+	c := Lifecycle{Engine: e, Provider: "postgresql"}
+
+	if err := c.Ping(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{`mutation {result: queryRaw(query:"SELECT 1",parameters:"[]") }`}, e.queries)
+
+	e.err = fmt.Errorf("connection refused")
+	assert.ErrorContains(t, c.Ping(context.Background()), "connection refused")
+}
+
+func TestPingMongoDB(t *testing.T) {
+	e := &fakeEngine{}
+	c := Lifecycle{Engine: e, Provider: "mongodb"}
+
+	if err := c.Ping(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, e.queries, 1)
+	assert.Contains(t, e.queries[0], "runCommandRaw")
+}
@@ -0,0 +1,134 @@
+// Package loader coalesces lookups of single records by key, which are requested concurrently, into one query for all
+// keys, e.g. to resolve the N+1 problem of GraphQL resolvers which each load a related record.
+//
+// The generated client provides a typed loader per model with a single-field primary key:
+//
+//	users := client.User.Loader(loader.Options{})
+//
+//	// in a resolver, which runs concurrently for many posts
+//	author, err := users.Load(ctx, post.AuthorID)
+package loader
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Options configures a Loader
+type Options struct {
+	// Wait is how long a batch collects keys before it's fetched, 1ms by default
+	Wait time.Duration
+	// MaxBatch is the maximum number of keys of a batch, which is fetched right away when it's full, 1000 by default
+	MaxBatch int
+}
+
+// Fetch fetches the records of the given keys, keyed by their key. Missing keys are reported as not found.
+type Fetch[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Loader collects the keys requested by concurrent Load calls within a short window and fetches them with a single
+// call of its fetch function. A Loader is safe for concurrent use and doesn't cache records, so every Load is answered
+// by a fetch.
+type Loader[K comparable, V any] struct {
+	fetch    Fetch[K, V]
+	wait     time.Duration
+	maxBatch int
+
+	mu    sync.Mutex
+	batch *batch[K, V]
+}
+
+// batch holds the keys which are fetched together and their results, which are available when done is closed
+type batch[K comparable, V any] struct {
+	ctx   context.Context
+	keys  []K
+	seen  map[K]bool
+	timer *time.Timer
+	once  sync.Once
+	done  chan struct{}
+
+	values map[K]V
+	err    error
+}
+
+// New returns a loader which fetches batches of keys with fetch
+func New[K comparable, V any](options Options, fetch Fetch[K, V]) *Loader[K, V] {
+	if options.Wait <= 0 {
+		options.Wait = time.Millisecond
+	}
+	if options.MaxBatch <= 0 {
+		options.MaxBatch = 1000
+	}
+	return &Loader[K, V]{
+		fetch:    fetch,
+		wait:     options.Wait,
+		maxBatch: options.MaxBatch,
+	}
+}
+
+// Load returns the record of a key, which is fetched together with the keys of other Load calls in the same window.
+// It returns false if no record exists for the key. The batch is fetched with the context of the first Load call
+// of the batch, without its cancellation, and ctx only cancels waiting for the result.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, bool, error) {
+	b := l.add(ctx, key)
+
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		var zero V
+		return zero, false, ctx.Err()
+	}
+
+	if b.err != nil {
+		var zero V
+		return zero, false, b.err
+	}
+	v, ok := b.values[key]
+	return v, ok, nil
+}
+
+// add adds a key to the current batch, starting a new batch if there is none
+func (l *Loader[K, V]) add(ctx context.Context, key K) *batch[K, V] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.batch
+	if b == nil {
+		b = &batch[K, V]{
+			ctx:  context.WithoutCancel(ctx),
+			seen: map[K]bool{},
+			done: make(chan struct{}),
+		}
+		b.timer = time.AfterFunc(l.wait, func() {
+			l.dispatch(b)
+		})
+		l.batch = b
+	}
+
+	if !b.seen[key] {
+		b.seen[key] = true
+		b.keys = append(b.keys, key)
+	}
+
+	if len(b.keys) >= l.maxBatch {
+		l.batch = nil
+		b.timer.Stop()
+		go l.dispatch(b)
+	}
+
+	return b
+}
+
+// dispatch fetches a batch once, either when its window ends or when it's full
+func (l *Loader[K, V]) dispatch(b *batch[K, V]) {
+	b.once.Do(func() {
+		l.mu.Lock()
+		if l.batch == b {
+			l.batch = nil
+		}
+		l.mu.Unlock()
+
+		b.values, b.err = l.fetch(b.ctx, b.keys)
+		close(b.done)
+	})
+}
@@ -0,0 +1,119 @@
+package loader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder is a fetch function which returns a record for even keys and records the batches it receives
+type recorder struct {
+	mu      sync.Mutex
+	batches [][]int
+	err     error
+}
+
+func (r *recorder) fetch(ctx context.Context, keys []int) (map[int]string, error) {
+	r.mu.Lock()
+	sorted := append([]int{}, keys...)
+	sort.Ints(sorted)
+	r.batches = append(r.batches, sorted)
+	r.mu.Unlock()
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	values := map[int]string{}
+	for _, key := range keys {
+		if key%2 == 0 {
+			values[key] = fmt.Sprintf("v%d", key)
+		}
+	}
+	return values, nil
+}
+
+// loadAll loads the given keys concurrently and returns the results by key
+func loadAll(l *Loader[int, string], keys ...int) map[int]string {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	found := map[int]string{}
+	for _, key := range keys {
+		wg.Add(1)
+		go func(key int) {
+			defer wg.Done()
+			v, ok, err := l.Load(context.Background(), key)
+			if err != nil || !ok {
+				return
+			}
+			mu.Lock()
+			found[key] = v
+			mu.Unlock()
+		}(key)
+	}
+	wg.Wait()
+	return found
+}
+
+func TestLoad(t *testing.T) {
+	r := &recorder{}
+	l := New(Options{Wait: 20 * time.Millisecond}, r.fetch)
+
+	found := loadAll(l, 1, 2, 3, 4, 2)
+
+	assert.Equal(t, map[int]string{2: "v2", 4: "v4"}, found)
+	assert.Equal(t, [][]int{{1, 2, 3, 4}}, r.batches)
+}
+
+func TestLoadMaxBatch(t *testing.T) {
+	r := &recorder{}
+	l := New(Options{Wait: time.Hour, MaxBatch: 2}, r.fetch)
+
+	found := loadAll(l, 2, 4, 6, 8)
+
+	assert.Equal(t, map[int]string{2: "v2", 4: "v4", 6: "v6", 8: "v8"}, found)
+	assert.Equal(t, 2, len(r.batches))
+	for _, batch := range r.batches {
+		assert.Equal(t, 2, len(batch))
+	}
+}
+
+func TestLoadSequential(t *testing.T) {
+	r := &recorder{}
+	l := New(Options{}, r.fetch)
+
+	v, ok, err := l.Load(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "v2", v)
+
+	_, ok, err = l.Load(context.Background(), 3)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.Equal(t, [][]int{{2}, {3}}, r.batches)
+}
+
+func TestLoadError(t *testing.T) {
+	r := &recorder{err: errors.New("boom")}
+	l := New(Options{}, r.fetch)
+
+	_, _, err := l.Load(context.Background(), 2)
+	assert.EqualError(t, err, "boom")
+}
+
+func TestLoadCanceled(t *testing.T) {
+	r := &recorder{}
+	l := New(Options{Wait: time.Hour}, r.fetch)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := l.Load(ctx, 2)
+	assert.ErrorIs(t, err, context.Canceled)
+}
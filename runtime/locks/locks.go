@@ -0,0 +1,267 @@
+// Package locks provides distributed locks stored in the database, e.g. to make sure a background worker only runs on
+// a single replica at a time.
+//
+// Locks are stored in a model of the Prisma schema, which has to be added manually:
+//
+//	model Lock {
+//	  name      String   @id
+//	  owner     String
+//	  expiresAt DateTime
+//	}
+//
+// The model may be mapped to another table, but the field names must not be mapped to other columns.
+// Only PostgreSQL and CockroachDB are supported.
+//
+// Session-level advisory locks are not used, because queries are sent through the connection pool of the query
+// engine and may run on different connections.
+package locks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/steebchen/prisma-client-go/logger"
+	"github.com/steebchen/prisma-client-go/runtime/raw"
+	"github.com/steebchen/prisma-client-go/runtime/tools"
+)
+
+// Model is the name of the Prisma model which stores the locks
+const Model = "Lock"
+
+// MinTTL is the shortest ttl of a lock, which is renewed three times within its ttl
+const MinTTL = 100 * time.Millisecond
+
+// ErrNotAcquired is returned by TryAcquire if the lock is held by someone else
+var ErrNotAcquired = errors.New("lock is held by another owner")
+
+// Lock is a held lock. It's kept alive by a heartbeat until it's released.
+type Lock struct {
+	client tools.Client
+	table  string
+	name   string
+	owner  string
+	ttl    time.Duration
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	stopped  chan struct{}
+	lost     chan struct{}
+	lostOnce sync.Once
+}
+
+// Acquire blocks until the lock with the given name is acquired or ctx is done. The lock expires after ttl, which must
+// be at least MinTTL, unless it's renewed, which happens automatically in the background until Release is called. If
+// the owner crashes, the lock can be acquired by others once ttl elapsed.
+//
+// Example:
+//
+//	lock, err := locks.Acquire(ctx, client, "billing-worker", 30*time.Second)
+//	if err != nil {
+//	  return err
+//	}
+//	defer lock.Release(context.Background())
+func Acquire(ctx context.Context, client tools.Client, name string, ttl time.Duration) (*Lock, error) {
+	for {
+		lock, err := TryAcquire(ctx, client, name, ttl)
+		if !errors.Is(err, ErrNotAcquired) {
+			return lock, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval(ttl)):
+		}
+	}
+}
+
+// TryAcquire acquires the lock with the given name if it's free or expired, and returns ErrNotAcquired otherwise.
+func TryAcquire(ctx context.Context, client tools.Client, name string, ttl time.Duration) (*Lock, error) {
+	if ttl < MinTTL {
+		return nil, fmt.Errorf("lock ttl %s is shorter than %s", ttl, MinTTL)
+	}
+
+	graph := client.RelationGraph()
+	switch graph.Provider {
+	case "postgresql", "postgres", "cockroachdb":
+	default:
+		return nil, fmt.Errorf("locks are not supported for provider %q", graph.Provider)
+	}
+
+	table, ok := graph.Table(Model)
+	if !ok {
+		return nil, fmt.Errorf("model %q does not exist in the schema", Model)
+	}
+
+	l := &Lock{
+		client:  client,
+		table:   table,
+		name:    name,
+		owner:   newOwner(),
+		ttl:     ttl,
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+		lost:    make(chan struct{}),
+	}
+
+	r := raw.Raw{Engine: client}
+	result, err := r.ExecuteRaw(
+		`INSERT INTO `+l.table+` AS l ("name", "owner", "expiresAt") `+
+			`VALUES ($1, $2, now() + $3::float8 * interval '1 millisecond') `+
+			`ON CONFLICT ("name") DO UPDATE SET "owner" = excluded."owner", "expiresAt" = excluded."expiresAt" `+
+			`WHERE l."expiresAt" < now()`,
+		l.name, l.owner, ttl.Milliseconds(),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("acquire lock %s: %w", name, err)
+	}
+	if result.Count == 0 {
+		return nil, ErrNotAcquired
+	}
+
+	go l.heartbeat()
+
+	return l, nil
+}
+
+// Lost returns a channel which is closed if the lock could not be renewed in time, e.g. because the database was
+// unreachable, so that another owner may have acquired it.
+func (l *Lock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// Release stops renewing the lock and frees it, so others can acquire it right away. It may be called again, e.g. if
+// freeing the lock failed.
+func (l *Lock) Release(ctx context.Context) error {
+	l.stopOnce.Do(func() {
+		close(l.stop)
+	})
+	<-l.stopped
+
+	r := raw.Raw{Engine: l.client}
+	if _, err := r.ExecuteRaw(
+		`DELETE FROM `+l.table+` WHERE "name" = $1 AND "owner" = $2`,
+		l.name, l.owner,
+	).Exec(ctx); err != nil {
+		return fmt.Errorf("release lock %s: %w", l.name, err)
+	}
+
+	return nil
+}
+
+// renew extends the expiry of the lock and returns false if the lock is held by someone else
+func (l *Lock) renew(ctx context.Context) (bool, error) {
+	r := raw.Raw{Engine: l.client}
+	result, err := r.ExecuteRaw(
+		`UPDATE `+l.table+` SET "expiresAt" = now() + $3::float8 * interval '1 millisecond' `+
+			`WHERE "name" = $1 AND "owner" = $2`,
+		l.name, l.owner, l.ttl.Milliseconds(),
+	).Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+	return result.Count > 0, nil
+}
+
+func (l *Lock) heartbeat() {
+	defer close(l.stopped)
+
+	renewed := time.Now()
+	ticker := time.NewTicker(interval(l.ttl))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), interval(l.ttl))
+		ok, err := l.renew(ctx)
+		cancel()
+
+		switch {
+		case err != nil:
+			logger.Default().Warn("could not renew lock", "lock", l.name, "error", err)
+			if time.Since(renewed) >= l.ttl {
+				l.markLost()
+			}
+		case !ok:
+			logger.Default().Warn("lock was acquired by another owner", "lock", l.name)
+			l.markLost()
+			return
+		default:
+			renewed = time.Now()
+		}
+	}
+}
+
+func (l *Lock) markLost() {
+	l.lostOnce.Do(func() {
+		close(l.lost)
+	})
+}
+
+// Run calls fn while holding the lock with the given name, e.g. to run a singleton background worker on exactly one
+// replica. The context passed to fn is cancelled when the lock is lost, after which Run waits for the lock again and
+// calls fn anew. Run returns once fn returns without having lost the lock, or when ctx is done.
+//
+// Example:
+//
+//	err := locks.Run(ctx, client, "billing-worker", 30*time.Second, func(ctx context.Context) error {
+//	  return billing.Work(ctx)
+//	})
+func Run(ctx context.Context, client tools.Client, name string, ttl time.Duration, fn func(ctx context.Context) error) error {
+	for {
+		lock, err := Acquire(ctx, client, name, ttl)
+		if err != nil {
+			return err
+		}
+
+		leaderCtx, cancel := context.WithCancel(ctx)
+		go func() {
+			select {
+			case <-lock.Lost():
+				cancel()
+			case <-leaderCtx.Done():
+			}
+		}()
+
+		err = fn(leaderCtx)
+		cancel()
+
+		lost := false
+		select {
+		case <-lock.Lost():
+			lost = true
+		default:
+		}
+
+		if err := lock.Release(context.Background()); err != nil {
+			logger.Default().Warn("could not release lock", "lock", name, "error", err)
+		}
+
+		if !lost || ctx.Err() != nil {
+			return err
+		}
+	}
+}
+
+// interval returns how often a lock is renewed or polled, so that it's renewed multiple times before it expires
+func interval(ttl time.Duration) time.Duration {
+	return ttl / 3
+}
+
+func newOwner() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Errorf("generate lock owner: %w", err))
+	}
+	return hex.EncodeToString(b)
+}
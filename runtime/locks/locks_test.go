@@ -0,0 +1,66 @@
+package locks
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/steebchen/prisma-client-go/engine"
+	"github.com/steebchen/prisma-client-go/runtime/tools"
+)
+
+type fakeClient struct {
+	engine.Engine
+	provider string
+	tables   map[string]string
+}
+
+func (c fakeClient) RelationGraph() tools.RelationGraph {
+	tables := c.tables
+	if tables == nil {
+		tables = map[string]string{"User": "users"}
+	}
+	return tools.RelationGraph{
+		Provider: c.provider,
+		Tables:   tables,
+	}
+}
+
+func TestTryAcquire(t *testing.T) {
+	_, err := TryAcquire(context.Background(), fakeClient{provider: "mysql"}, "worker", time.Second)
+	assert.EqualError(t, err, `locks are not supported for provider "mysql"`)
+
+	_, err = TryAcquire(context.Background(), fakeClient{provider: "postgresql"}, "worker", time.Second)
+	assert.EqualError(t, err, `model "Lock" does not exist in the schema`)
+
+	_, err = Acquire(context.Background(), fakeClient{provider: "postgresql"}, "worker", 2*time.Nanosecond)
+	assert.EqualError(t, err, "lock ttl 2ns is shorter than 100ms")
+}
+
+// countEngine answers every raw query with a count of 1
+type countEngine struct {
+	engine.Engine
+	queries atomic.Int64
+}
+
+func (e *countEngine) Do(ctx context.Context, payload interface{}, into interface{}) error {
+	e.queries.Add(1)
+	return json.Unmarshal([]byte(`1`), into)
+}
+
+func TestRelease(t *testing.T) {
+	e := &countEngine{}
+	client := fakeClient{Engine: e, provider: "postgresql"}
+	client.tables = map[string]string{"Lock": "locks"}
+
+	lock, err := TryAcquire(context.Background(), client, "worker", time.Second)
+	assert.NoError(t, err)
+
+	assert.NoError(t, lock.Release(context.Background()))
+	assert.NoError(t, lock.Release(context.Background()))
+	assert.EqualValues(t, 3, e.queries.Load())
+}
@@ -0,0 +1,101 @@
+// Package metadata describes the models of a Prisma schema at runtime, e.g. to build generic tooling.
+package metadata
+
+// Schema describes all models and enums of a Prisma schema
+type Schema struct {
+	// Provider is the active datasource provider, e.g. postgresql or mysql
+	Provider string
+	Models   []Model
+	Enums    []Enum
+}
+
+// Model returns the model with the given name as defined in the Prisma schema
+func (s Schema) Model(name string) (Model, bool) {
+	for _, m := range s.Models {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return Model{}, false
+}
+
+// Enum returns the enum with the given name as defined in the Prisma schema
+func (s Schema) Enum(name string) (Enum, bool) {
+	for _, e := range s.Enums {
+		if e.Name == name {
+			return e, true
+		}
+	}
+	return Enum{}, false
+}
+
+// Model describes a Prisma model
+type Model struct {
+	// Name is the model name as defined in the Prisma schema
+	Name string
+	// Table is the database name of the model, which differs from Name when using @@map
+	Table string
+	// Schema is the database schema of the model set with @@schema when using the multiSchema preview feature
+	Schema string
+	Fields []Field
+	// PrimaryKey contains the names of the fields which make up the primary key
+	PrimaryKey []string
+	// Uniques contains the field names of each unique constraint, including single unique fields
+	Uniques [][]string
+}
+
+// Field returns the field with the given name as defined in the Prisma schema
+func (m Model) Field(name string) (Field, bool) {
+	for _, f := range m.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+// Field describes a field of a Prisma model
+type Field struct {
+	// Name is the field name as defined in the Prisma schema
+	Name string
+	// Column is the database name of the field, which differs from Name when using @map. It is empty for relations.
+	Column string
+	// Type is the Prisma type, e.g. String, DateTime, the name of an enum or the name of a related model
+	Type string
+	// Kind is one of scalar, enum, object or composite, where object fields are relations and composite fields are
+	// embedded documents
+	Kind string
+
+	IsList      bool
+	IsRequired  bool
+	IsID        bool
+	IsUnique    bool
+	IsUpdatedAt bool
+	HasDefault  bool
+	// DefaultNow is whether the field defaults to the current time with @default(now())
+	DefaultNow bool
+
+	// RelationName (optional) is the name of the relation of an object field
+	RelationName string
+	// JSON (optional) is the name of the field when generated models are encoded to JSON, if it differs from Name
+	JSON string
+}
+
+// IsRelation returns whether the field is a relation
+func (f Field) IsRelation() bool {
+	return f.Kind == "object"
+}
+
+// JSONName returns the name of the field when generated models are encoded to JSON
+func (f Field) JSONName() string {
+	if f.JSON != "" {
+		return f.JSON
+	}
+	return f.Name
+}
+
+// Enum describes a Prisma enum
+type Enum struct {
+	Name   string
+	Values []string
+}
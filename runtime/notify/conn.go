@@ -0,0 +1,387 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"crypto/md5"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// config holds the connection parameters of a PostgreSQL connection URL
+type config struct {
+	network  string
+	address  string
+	host     string
+	user     string
+	password string
+	database string
+
+	// sslMode is disable, prefer or require, as supported by Prisma
+	sslMode string
+	// tls is used if the server supports TLS and sslMode isn't disable
+	tls *tls.Config
+}
+
+// parseURL parses a PostgreSQL connection URL as used by Prisma. Parameters which only concern the query engine, such
+// as connection_limit or schema, are ignored.
+func parseURL(s string) (*config, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("parse url: %w", err)
+	}
+	if u.Scheme != "postgresql" && u.Scheme != "postgres" {
+		return nil, fmt.Errorf("unsupported url scheme %q", u.Scheme)
+	}
+
+	query := u.Query()
+	c := &config{
+		network:  "tcp",
+		host:     u.Hostname(),
+		user:     u.User.Username(),
+		database: strings.TrimPrefix(u.Path, "/"),
+		sslMode:  query.Get("sslmode"),
+	}
+	c.password, _ = u.User.Password()
+	if c.database == "" {
+		c.database = c.user
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "5432"
+	}
+	c.address = net.JoinHostPort(c.host, port)
+	if host := query.Get("host"); strings.HasPrefix(host, "/") {
+		// a directory containing the unix socket of the server
+		c.network, c.address, c.host = "unix", host+"/.s.PGSQL."+port, "localhost"
+	}
+
+	switch c.sslMode {
+	case "":
+		c.sslMode = "prefer"
+	case "disable", "prefer", "require":
+	default:
+		return nil, fmt.Errorf("unsupported sslmode %q", c.sslMode)
+	}
+
+	c.tls = &tls.Config{
+		ServerName:         c.host,
+		InsecureSkipVerify: query.Get("sslaccept") == "accept_invalid_certs",
+	}
+	if path := query.Get("sslcert"); path != "" {
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read sslcert: %w", err)
+		}
+		c.tls.RootCAs = x509.NewCertPool()
+		if !c.tls.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("sslcert %s contains no certificates", path)
+		}
+	}
+
+	return c, nil
+}
+
+// Error is an error reported by the PostgreSQL server
+type Error struct {
+	Severity string
+	Code     string
+	Message  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s (SQLSTATE %s)", e.Severity, e.Message, e.Code)
+}
+
+// conn is a connection speaking the PostgreSQL frontend/backend protocol, which supports just enough of it to listen
+// for notifications
+type conn struct {
+	net.Conn
+	r *bufio.Reader
+
+	// secure is whether the connection is encrypted with TLS or uses a unix socket, so that passwords can be sent
+	secure bool
+
+	// pending holds notifications which arrived while waiting for a query to complete
+	pending []Notification
+}
+
+// dial opens a connection and authenticates
+func dial(ctx context.Context, c *config) (*conn, error) {
+	var d net.Dialer
+	nc, err := d.DialContext(ctx, c.network, c.address)
+	if err != nil {
+		return nil, err
+	}
+
+	// abort the handshake if ctx is done
+	stop := context.AfterFunc(ctx, func() {
+		_ = nc.Close()
+	})
+	defer stop()
+
+	cn, err := handshake(nc, c)
+	if err != nil {
+		_ = nc.Close()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+	return cn, nil
+}
+
+func handshake(nc net.Conn, c *config) (*conn, error) {
+	secure := c.network == "unix"
+	if c.network == "tcp" && c.sslMode != "disable" {
+		// SSLRequest
+		if _, err := nc.Write([]byte{0, 0, 0, 8, 4, 210, 22, 47}); err != nil {
+			return nil, err
+		}
+		answer := make([]byte, 1)
+		if _, err := io.ReadFull(nc, answer); err != nil {
+			return nil, err
+		}
+		switch {
+		case answer[0] == 'S':
+			tc := tls.Client(nc, c.tls)
+			if err := tc.Handshake(); err != nil {
+				return nil, fmt.Errorf("tls: %w", err)
+			}
+			nc, secure = tc, true
+		case c.sslMode == "require":
+			return nil, fmt.Errorf("server does not support TLS, but sslmode is require")
+		}
+	}
+
+	cn := &conn{Conn: nc, r: bufio.NewReader(nc), secure: secure}
+
+	startup := []byte{0, 3, 0, 0}
+	for _, param := range []string{"user", c.user, "database", c.database, "application_name", "prisma-client-go"} {
+		startup = append(append(startup, param...), 0)
+	}
+	startup = append(startup, 0)
+	if _, err := nc.Write(append(binary.BigEndian.AppendUint32(nil, uint32(len(startup)+4)), startup...)); err != nil {
+		return nil, err
+	}
+
+	if err := cn.authenticate(c); err != nil {
+		return nil, err
+	}
+	return cn, cn.ready()
+}
+
+// authenticate answers the authentication requests of the server until it accepts the connection
+func (cn *conn) authenticate(c *config) error {
+	var scram *scramClient
+	for {
+		typ, body, err := cn.receive()
+		if err != nil {
+			return err
+		}
+		if typ == 'E' {
+			return parseError(body)
+		}
+		if typ != 'R' {
+			return fmt.Errorf("unexpected message %q during authentication", typ)
+		}
+		if len(body) < 4 {
+			return fmt.Errorf("invalid authentication request")
+		}
+
+		switch code, data := binary.BigEndian.Uint32(body), body[4:]; code {
+		case 0:
+			return nil
+		case 3:
+			// e.g. a server which doesn't support TLS with sslmode=prefer must not learn the password in cleartext
+			if !cn.secure {
+				return fmt.Errorf("refusing to send the password in cleartext over an unencrypted connection; " +
+					"use sslmode=require or SCRAM-SHA-256 authentication")
+			}
+			err = cn.send('p', cstring(c.password))
+		case 5:
+			if len(data) < 4 {
+				return fmt.Errorf("invalid md5 salt")
+			}
+			err = cn.send('p', cstring("md5"+md5Hex(md5Hex(c.password+c.user)+string(data[:4]))))
+		case 10:
+			if !strings.Contains(string(data), "SCRAM-SHA-256\x00") {
+				return fmt.Errorf("unsupported SASL mechanisms %q", strings.Split(strings.TrimRight(string(data), "\x00"), "\x00"))
+			}
+			if scram, err = newScramClient(c.password); err != nil {
+				return err
+			}
+			first := scram.first()
+			msg := append(cstring("SCRAM-SHA-256"), binary.BigEndian.AppendUint32(nil, uint32(len(first)))...)
+			err = cn.send('p', append(msg, first...))
+		case 11:
+			if scram == nil {
+				return fmt.Errorf("unexpected SASL continuation")
+			}
+			var final []byte
+			if final, err = scram.final(data); err != nil {
+				return err
+			}
+			err = cn.send('p', final)
+		case 12:
+			if scram == nil {
+				return fmt.Errorf("unexpected SASL completion")
+			}
+			err = scram.verify(data)
+		default:
+			return fmt.Errorf("unsupported authentication method %d", code)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// exec runs a simple query and waits until the server is ready for the next one
+func (cn *conn) exec(query string) error {
+	if err := cn.send('Q', cstring(query)); err != nil {
+		return err
+	}
+	return cn.ready()
+}
+
+// ready reads messages until the server is ready for a query, returning the first error it reported
+func (cn *conn) ready() error {
+	var serverErr error
+	for {
+		typ, body, err := cn.receive()
+		if err != nil {
+			if serverErr != nil {
+				// fatal errors close the connection without ReadyForQuery
+				return serverErr
+			}
+			return err
+		}
+		switch typ {
+		case 'Z':
+			return serverErr
+		case 'E':
+			if serverErr == nil {
+				serverErr = parseError(body)
+			}
+		case 'A':
+			if n, err := parseNotification(body); err == nil {
+				cn.pending = append(cn.pending, n)
+			}
+		}
+	}
+}
+
+// next returns the next notification
+func (cn *conn) next() (Notification, error) {
+	if len(cn.pending) > 0 {
+		n := cn.pending[0]
+		cn.pending = cn.pending[1:]
+		return n, nil
+	}
+	for {
+		typ, body, err := cn.receive()
+		if err != nil {
+			return Notification{}, err
+		}
+		switch typ {
+		case 'A':
+			return parseNotification(body)
+		case 'E':
+			return Notification{}, parseError(body)
+		}
+		// parameter status changes and notices are ignored
+	}
+}
+
+// parseNotification parses a NotificationResponse
+func parseNotification(body []byte) (Notification, error) {
+	if len(body) < 4 {
+		return Notification{}, fmt.Errorf("invalid notification")
+	}
+	fields := strings.SplitN(string(body[4:]), "\x00", 3)
+	if len(fields) < 3 {
+		return Notification{}, fmt.Errorf("invalid notification")
+	}
+	return Notification{
+		Channel: fields[0],
+		Payload: fields[1],
+		PID:     int(binary.BigEndian.Uint32(body)),
+	}, nil
+}
+
+// receive reads the next message, returning its type and body
+func (cn *conn) receive() (byte, []byte, error) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(cn.r, header); err != nil {
+		return 0, nil, err
+	}
+	size := binary.BigEndian.Uint32(header[1:])
+	if size < 4 {
+		return 0, nil, fmt.Errorf("invalid message size %d", size)
+	}
+	body := make([]byte, size-4)
+	if _, err := io.ReadFull(cn.r, body); err != nil {
+		return 0, nil, err
+	}
+	return header[0], body, nil
+}
+
+// send writes a message
+func (cn *conn) send(typ byte, body []byte) error {
+	msg := binary.BigEndian.AppendUint32([]byte{typ}, uint32(len(body)+4))
+	_, err := cn.Write(append(msg, body...))
+	return err
+}
+
+// close terminates the session and closes the connection
+func (cn *conn) close() error {
+	_ = cn.send('X', nil)
+	return cn.Close()
+}
+
+// parseError parses the fields of an ErrorResponse
+func parseError(body []byte) *Error {
+	e := &Error{}
+	for len(body) > 1 {
+		field := body[0]
+		end := strings.IndexByte(string(body[1:]), 0)
+		if end < 0 {
+			break
+		}
+		value := string(body[1 : 1+end])
+		body = body[2+end:]
+		switch field {
+		case 'S':
+			e.Severity = value
+		case 'C':
+			e.Code = value
+		case 'M':
+			e.Message = value
+		}
+	}
+	return e
+}
+
+func cstring(s string) []byte {
+	return append([]byte(s), 0)
+}
+
+func md5Hex(s string) string {
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// quoteIdent quotes an identifier, such as the name of a channel
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,131 @@
+// Package notify subscribes to PostgreSQL notifications sent with NOTIFY or pg_notify, using a dedicated connection to
+// the database of the client. Queries are sent through the connection pool of the query engine, which can't deliver
+// notifications, so this package speaks the PostgreSQL protocol itself and no second database driver is needed.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/steebchen/prisma-client-go/logger"
+)
+
+// Notification is a notification received on a channel
+type Notification struct {
+	// Channel is the channel the notification was sent on
+	Channel string
+	// Payload is the payload of the notification, which is empty if none was given
+	Payload string
+	// PID is the process id of the database session which sent the notification
+	PID int
+}
+
+const (
+	initialBackoff = 100 * time.Millisecond
+	maxBackoff     = 30 * time.Second
+)
+
+// Listen connects to the PostgreSQL database at url and listens on the given channels until ctx is done, when the
+// returned channel is closed. If the connection is lost, it's re-established with an exponential backoff;
+// notifications sent while reconnecting are lost. Connection errors are only returned for the first connection.
+//
+// Example:
+//
+//	notifications, err := notify.Listen(ctx, url, "jobs")
+//	if err != nil {
+//	  return err
+//	}
+//	for n := range notifications {
+//	  log.Printf("%s: %s", n.Channel, n.Payload)
+//	}
+func Listen(ctx context.Context, url string, channels ...string) (<-chan Notification, error) {
+	if len(channels) == 0 {
+		return nil, fmt.Errorf("no channels given")
+	}
+
+	c, err := parseURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	cn, err := connect(ctx, c, channels)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan Notification)
+	go func() {
+		defer close(out)
+
+		backoff := initialBackoff
+		for {
+			err := receive(ctx, cn, out)
+			_ = cn.close()
+			if ctx.Err() != nil {
+				return
+			}
+			logger.Default().Warn("lost connection for notifications; reconnecting", "error", err)
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(backoff):
+				}
+				backoff = min(2*backoff, maxBackoff)
+
+				if cn, err = connect(ctx, c, channels); err == nil {
+					backoff = initialBackoff
+					break
+				}
+				if ctx.Err() != nil {
+					return
+				}
+				logger.Default().Warn("could not reconnect for notifications", "error", err)
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// connect opens a connection which listens on the channels
+func connect(ctx context.Context, c *config, channels []string) (*conn, error) {
+	cn, err := dial(ctx, c)
+	if err != nil {
+		return nil, fmt.Errorf("connect: %w", err)
+	}
+
+	statements := make([]string, len(channels))
+	for i, channel := range channels {
+		statements[i] = "LISTEN " + quoteIdent(channel)
+	}
+	if err := cn.exec(strings.Join(statements, "; ")); err != nil {
+		_ = cn.close()
+		return nil, fmt.Errorf("listen: %w", err)
+	}
+	return cn, nil
+}
+
+// receive forwards the notifications of a connection until it fails or ctx is done
+func receive(ctx context.Context, cn *conn, out chan<- Notification) error {
+	// unblock reads once ctx is done
+	stop := context.AfterFunc(ctx, func() {
+		_ = cn.SetReadDeadline(time.Now())
+	})
+	defer stop()
+
+	for {
+		n, err := cn.next()
+		if err != nil {
+			return err
+		}
+		select {
+		case out <- n:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
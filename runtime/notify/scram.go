@@ -0,0 +1,123 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// scramClient authenticates with SCRAM-SHA-256 as described in RFC 5802 and RFC 7677. PostgreSQL takes the user name
+// from the startup message, so it's left empty in the exchange.
+type scramClient struct {
+	password  string
+	nonce     string
+	firstBare string
+
+	authMessage    string
+	saltedPassword []byte
+}
+
+func newScramClient(password string) (*scramClient, error) {
+	raw := make([]byte, 18)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+	return newScramClientWithNonce(password, base64.StdEncoding.EncodeToString(raw)), nil
+}
+
+func newScramClientWithNonce(password string, nonce string) *scramClient {
+	return &scramClient{
+		password:  password,
+		nonce:     nonce,
+		firstBare: "n=,r=" + nonce,
+	}
+}
+
+// first returns the client-first-message
+func (s *scramClient) first() []byte {
+	return []byte("n,," + s.firstBare)
+}
+
+// final answers the server-first-message with the client-final-message, which contains the client proof
+func (s *scramClient) final(serverFirst []byte) ([]byte, error) {
+	var nonce, salt string
+	var iterations int
+	for _, attr := range strings.Split(string(serverFirst), ",") {
+		key, value, _ := strings.Cut(attr, "=")
+		switch key {
+		case "r":
+			nonce = value
+		case "s":
+			salt = value
+		case "i":
+			iterations, _ = strconv.Atoi(value)
+		}
+	}
+	if !strings.HasPrefix(nonce, s.nonce) || len(nonce) == len(s.nonce) {
+		return nil, fmt.Errorf("scram: invalid server nonce")
+	}
+	saltBytes, err := base64.StdEncoding.DecodeString(salt)
+	if err != nil || iterations < 1 {
+		return nil, fmt.Errorf("scram: invalid salt or iteration count")
+	}
+
+	finalWithoutProof := "c=biws,r=" + nonce
+	s.authMessage = s.firstBare + "," + string(serverFirst) + "," + finalWithoutProof
+	s.saltedPassword = pbkdf2SHA256([]byte(s.password), saltBytes, iterations)
+
+	clientKey := hmacSHA256(s.saltedPassword, "Client Key")
+	storedKey := sha256.Sum256(clientKey)
+	signature := hmacSHA256(storedKey[:], s.authMessage)
+	proof := make([]byte, len(clientKey))
+	for i := range clientKey {
+		proof[i] = clientKey[i] ^ signature[i]
+	}
+
+	return []byte(finalWithoutProof + ",p=" + base64.StdEncoding.EncodeToString(proof)), nil
+}
+
+// verify checks the server signature of the server-final-message
+func (s *scramClient) verify(serverFinal []byte) error {
+	value, ok := strings.CutPrefix(string(serverFinal), "v=")
+	if !ok {
+		return fmt.Errorf("scram: invalid server final message %q", serverFinal)
+	}
+	signature, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return fmt.Errorf("scram: invalid server signature")
+	}
+	serverKey := hmacSHA256(s.saltedPassword, "Server Key")
+	if !hmac.Equal(signature, hmacSHA256(serverKey, s.authMessage)) {
+		return fmt.Errorf("scram: server signature mismatch")
+	}
+	return nil
+}
+
+func hmacSHA256(key []byte, message string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(message))
+	return mac.Sum(nil)
+}
+
+// pbkdf2SHA256 derives a single block of 32 bytes, which is the Hi function of SCRAM-SHA-256
+func pbkdf2SHA256(password []byte, salt []byte, iterations int) []byte {
+	mac := hmac.New(sha256.New, password)
+	mac.Write(salt)
+	mac.Write([]byte{0, 0, 0, 1})
+	u := mac.Sum(nil)
+	result := bytes.Clone(u)
+	for i := 1; i < iterations; i++ {
+		mac.Reset()
+		mac.Write(u)
+		u = mac.Sum(u[:0])
+		for j := range result {
+			result[j] ^= u[j]
+		}
+	}
+	return result
+}
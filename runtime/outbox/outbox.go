@@ -0,0 +1,426 @@
+// Package outbox implements the transactional outbox pattern: messages are written to the database in the same
+// transaction as the changes they describe, and a consumer delivers them to a broker or another service afterwards,
+// at least once.
+//
+// Messages are stored in a model of the Prisma schema, which has to be added manually:
+//
+//	model OutboxMessage {
+//	  id          String    @id
+//	  topic       String
+//	  payload     Json
+//	  status      String    @default("pending")
+//	  attempts    Int       @default(0)
+//	  availableAt DateTime  @default(now())
+//	  leaseOwner  String?
+//	  leasedUntil DateTime?
+//	  lastError   String?
+//	  createdAt   DateTime  @default(now())
+//
+//	  @@index([status, availableAt])
+//	}
+//
+// The model may be renamed or mapped to another table, but the field names must not be mapped to other columns.
+// Consumers claim messages with SELECT ... FOR UPDATE SKIP LOCKED, so only PostgreSQL and CockroachDB are supported.
+package outbox
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/steebchen/prisma-client-go/logger"
+	"github.com/steebchen/prisma-client-go/runtime/raw"
+	"github.com/steebchen/prisma-client-go/runtime/tools"
+)
+
+// ErrLost is returned when a message can't be acknowledged or retried because its lease expired and it was claimed
+// by another consumer in the meantime
+var ErrLost = errors.New("message lost: lease expired")
+
+// Message statuses stored in the status column
+const (
+	StatusPending    = "pending"
+	StatusProcessing = "processing"
+	StatusDead       = "dead"
+)
+
+// Delivery outcomes reported to the MetricsRecorder
+const (
+	OutcomeDelivered = "delivered"
+	OutcomeRetried   = "retried"
+	OutcomeDead      = "dead"
+)
+
+// DeliveryMetric describes a processed message
+type DeliveryMetric struct {
+	// Topic is the topic the message was published to
+	Topic string
+	// Attempts is the number of times the message was claimed, including this attempt
+	Attempts int
+	// Duration is the time the handler took to process the message
+	Duration time.Duration
+	// Outcome is OutcomeDelivered, OutcomeRetried or OutcomeDead
+	Outcome string
+}
+
+// MetricsRecorder receives measurements of a consumer. It's implemented by adapters which export them as counters and
+// histograms of a metrics library, such as Prometheus or OpenTelemetry.
+// Its methods are called synchronously from the consumer and must not block.
+type MetricsRecorder interface {
+	// ObserveClaim is called after each claim with the number of claimed messages, which may be zero
+	ObserveClaim(ctx context.Context, claimed int)
+	// ObserveDelivery is called after a message was passed to the handler
+	ObserveDelivery(ctx context.Context, m DeliveryMetric)
+}
+
+// Options configures an Outbox
+type Options struct {
+	// Model is the name of the Prisma model which stores the messages, "OutboxMessage" by default
+	Model string
+	// Owner identifies the consumer in the leaseOwner column, a random id by default
+	Owner string
+	// BatchSize is the maximum number of messages claimed at once, 10 by default
+	BatchSize int
+	// LeaseDuration is how long claimed messages are hidden from other consumers before they are claimed again, 30s
+	// by default. It must cover processing a whole batch.
+	LeaseDuration time.Duration
+	// MaxAttempts is the number of attempts after which a failing message is marked as dead, 10 by default
+	MaxAttempts int
+	// RetryBackoff is the delay before a failed message is retried, doubled with each further attempt, 1s by default
+	RetryBackoff time.Duration
+	// MaxRetryBackoff caps the delay between retries, 5m by default
+	MaxRetryBackoff time.Duration
+	// PollInterval is how long Run waits before claiming again when no message is available, 1s by default
+	PollInterval time.Duration
+	// Metrics optionally records claims and deliveries
+	Metrics MetricsRecorder
+}
+
+// Message is a message claimed from the outbox
+type Message struct {
+	// ID is the unique id of the message
+	ID string
+	// Topic is the topic the message was published to
+	Topic string
+	// Payload is the JSON encoded payload which was published
+	Payload json.RawMessage
+	// Attempts is the number of times the message was claimed, including the current attempt
+	Attempts int
+	// CreatedAt is the time the message was published
+	CreatedAt time.Time
+}
+
+// Decode unmarshals the payload of the message into v
+func (m *Message) Decode(v interface{}) error {
+	return json.Unmarshal(m.Payload, v)
+}
+
+// Outbox publishes and consumes messages
+type Outbox struct {
+	client  tools.Client
+	table   string
+	options Options
+}
+
+// New creates an outbox stored in the model given in the options.
+//
+// Example:
+//
+//	box, err := outbox.New(client, outbox.Options{})
+func New(client tools.Client, options Options) (*Outbox, error) {
+	if options.Model == "" {
+		options.Model = "OutboxMessage"
+	}
+	if options.Owner == "" {
+		id, err := newID()
+		if err != nil {
+			return nil, err
+		}
+		options.Owner = id
+	}
+	if options.BatchSize == 0 {
+		options.BatchSize = 10
+	}
+	if options.LeaseDuration == 0 {
+		options.LeaseDuration = 30 * time.Second
+	}
+	if options.MaxAttempts == 0 {
+		options.MaxAttempts = 10
+	}
+	if options.RetryBackoff == 0 {
+		options.RetryBackoff = time.Second
+	}
+	if options.MaxRetryBackoff == 0 {
+		options.MaxRetryBackoff = 5 * time.Minute
+	}
+	if options.PollInterval == 0 {
+		options.PollInterval = time.Second
+	}
+
+	graph := client.RelationGraph()
+	switch graph.Provider {
+	case "postgresql", "postgres", "cockroachdb":
+	default:
+		return nil, fmt.Errorf("outboxes are not supported for provider %q", graph.Provider)
+	}
+
+	table, ok := graph.Table(options.Model)
+	if !ok {
+		return nil, fmt.Errorf("model %q does not exist in the schema", options.Model)
+	}
+
+	return &Outbox{
+		client:  client,
+		table:   table,
+		options: options,
+	}, nil
+}
+
+// Publish returns a query adding a message to the outbox. Pass it to a transaction together with the changes the
+// message describes, so that it's only delivered if the transaction succeeds. It returns an error if the payload
+// can't be encoded as JSON.
+//
+// Example:
+//
+//	msg, err := box.Publish("user.created", UserCreated{Email: email})
+//	if err != nil {
+//	  panic(err)
+//	}
+//	created := client.User.CreateOne(db.User.Email.Set(email)).Tx()
+//	if err := client.Prisma.Transaction(created, msg.Tx()).Exec(ctx); err != nil {
+//	  panic(err)
+//	}
+func (o *Outbox) Publish(topic string, payload interface{}) (raw.ExecuteExec, error) {
+	id, err := newID()
+	if err != nil {
+		return raw.ExecuteExec{}, err
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return raw.ExecuteExec{}, fmt.Errorf("encode payload: %w", err)
+	}
+	return o.raw().ExecuteRaw(
+		`INSERT INTO `+o.table+` ("id", "topic", "payload", "status", "attempts", "availableAt", "createdAt") `+
+			`VALUES ($1, $2, $3, '`+StatusPending+`', 0, now(), now())`,
+		id, topic, json.RawMessage(data),
+	), nil
+}
+
+type row struct {
+	ID        string          `json:"id"`
+	Topic     string          `json:"topic"`
+	Payload   json.RawMessage `json:"payload"`
+	Attempts  int             `json:"attempts"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// Claim leases up to BatchSize available messages to this consumer for the lease duration and returns them, oldest
+// first. Messages whose lease expired without being acknowledged are claimed again.
+// Each message must be passed to Ack or Retry once it was processed.
+func (o *Outbox) Claim(ctx context.Context) ([]*Message, error) {
+	var rows []row
+	if err := o.raw().QueryRaw(
+		`UPDATE `+o.table+` SET "status" = '`+StatusProcessing+`', "attempts" = "attempts" + 1, "leaseOwner" = $1, `+
+			`"leasedUntil" = now() + $2::float8 * interval '1 millisecond' `+
+			`WHERE "id" IN (SELECT "id" FROM `+o.table+` WHERE `+
+			`("status" = '`+StatusPending+`' AND "availableAt" <= now()) OR `+
+			`("status" = '`+StatusProcessing+`' AND "leasedUntil" < now()) `+
+			`ORDER BY "availableAt" LIMIT $3 FOR UPDATE SKIP LOCKED) `+
+			`RETURNING "id", "topic", "payload", "attempts", "createdAt"`,
+		o.options.Owner, o.options.LeaseDuration.Milliseconds(), o.options.BatchSize,
+	).Exec(ctx, &rows); err != nil {
+		return nil, fmt.Errorf("claim messages: %w", err)
+	}
+
+	messages := make([]*Message, len(rows))
+	for i, r := range rows {
+		payload, err := unquote(r.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("claim message %s: %w", r.ID, err)
+		}
+		messages[i] = &Message{
+			ID:        r.ID,
+			Topic:     r.Topic,
+			Payload:   payload,
+			Attempts:  r.Attempts,
+			CreatedAt: r.CreatedAt,
+		}
+	}
+	// UPDATE ... RETURNING doesn't keep the order of the subquery
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].CreatedAt.Before(messages[j].CreatedAt)
+	})
+
+	if o.options.Metrics != nil {
+		o.options.Metrics.ObserveClaim(ctx, len(messages))
+	}
+	return messages, nil
+}
+
+// Extend renews the lease of a message, e.g. as a heartbeat while processing a long-running message.
+func (o *Outbox) Extend(ctx context.Context, msg *Message) error {
+	return o.exec(ctx, "extend",
+		`UPDATE `+o.table+` SET "leasedUntil" = now() + $4::float8 * interval '1 millisecond' `+
+			`WHERE "id" = $1 AND "attempts" = $2 AND "leaseOwner" = $3 AND "status" = '`+StatusProcessing+`'`,
+		msg.ID, msg.Attempts, o.options.Owner, o.options.LeaseDuration.Milliseconds(),
+	)
+}
+
+// Ack removes a message after it was delivered.
+func (o *Outbox) Ack(ctx context.Context, msg *Message) error {
+	return o.exec(ctx, "ack",
+		`DELETE FROM `+o.table+` WHERE "id" = $1 AND "attempts" = $2 AND "leaseOwner" = $3 `+
+			`AND "status" = '`+StatusProcessing+`'`,
+		msg.ID, msg.Attempts, o.options.Owner,
+	)
+}
+
+// Retry records the error of a message and makes it available again after an exponential backoff. Once the message
+// reached the maximum number of attempts, it's marked as dead and kept for inspection instead. It reports whether the
+// message was marked as dead.
+func (o *Outbox) Retry(ctx context.Context, msg *Message, cause error) (bool, error) {
+	if msg.Attempts >= o.options.MaxAttempts {
+		return true, o.exec(ctx, "dead-letter",
+			`UPDATE `+o.table+` SET "status" = '`+StatusDead+`', "leaseOwner" = NULL, "leasedUntil" = NULL, `+
+				`"lastError" = $4 `+
+				`WHERE "id" = $1 AND "attempts" = $2 AND "leaseOwner" = $3 AND "status" = '`+StatusProcessing+`'`,
+			msg.ID, msg.Attempts, o.options.Owner, cause.Error(),
+		)
+	}
+
+	return false, o.exec(ctx, "retry",
+		`UPDATE `+o.table+` SET "status" = '`+StatusPending+`', "leaseOwner" = NULL, "leasedUntil" = NULL, `+
+			`"lastError" = $4, "availableAt" = now() + $5::float8 * interval '1 millisecond' `+
+			`WHERE "id" = $1 AND "attempts" = $2 AND "leaseOwner" = $3 AND "status" = '`+StatusProcessing+`'`,
+		msg.ID, msg.Attempts, o.options.Owner, cause.Error(), o.backoff(msg.Attempts).Milliseconds(),
+	)
+}
+
+// Redrive makes a dead message available again, with its attempts reset, e.g. after the cause of its failures was
+// fixed. It returns ErrLost if no dead message with the given id exists.
+func (o *Outbox) Redrive(ctx context.Context, id string) error {
+	return o.exec(ctx, "redrive",
+		`UPDATE `+o.table+` SET "status" = '`+StatusPending+`', "attempts" = 0, "availableAt" = now() `+
+			`WHERE "id" = $1 AND "status" = '`+StatusDead+`'`,
+		id,
+	)
+}
+
+// Run claims messages and passes them to fn until ctx is cancelled. Messages are acknowledged if fn returns nil and
+// retried otherwise, so fn may be called more than once for the same message and must be idempotent. Errors while
+// claiming are logged and retried after the poll interval.
+//
+// Example:
+//
+//	err := box.Run(ctx, func(ctx context.Context, msg *outbox.Message) error {
+//	  return broker.Publish(ctx, msg.Topic, msg.Payload)
+//	})
+func (o *Outbox) Run(ctx context.Context, fn func(ctx context.Context, msg *Message) error) error {
+	for {
+		messages, err := o.Claim(ctx)
+		if err != nil {
+			logger.Default().Warn("could not claim messages", "error", err)
+		}
+
+		if len(messages) == 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(o.options.PollInterval):
+			}
+			continue
+		}
+
+		for _, msg := range messages {
+			if ctx.Err() != nil {
+				// the remaining messages are claimed again once their lease expired
+				return ctx.Err()
+			}
+			o.deliver(ctx, msg, fn)
+		}
+	}
+}
+
+// deliver passes a message to fn and acknowledges or retries it depending on the result
+func (o *Outbox) deliver(ctx context.Context, msg *Message, fn func(ctx context.Context, msg *Message) error) {
+	start := time.Now()
+	err := fn(ctx, msg)
+	m := DeliveryMetric{
+		Topic:    msg.Topic,
+		Attempts: msg.Attempts,
+		Duration: time.Since(start),
+		Outcome:  OutcomeDelivered,
+	}
+
+	if err == nil {
+		if err := o.Ack(ctx, msg); err != nil {
+			logger.Default().Warn("could not ack message", "topic", msg.Topic, "message", msg.ID, "error", err)
+		}
+	} else {
+		dead, err := o.Retry(ctx, msg, err)
+		m.Outcome = OutcomeRetried
+		if dead {
+			m.Outcome = OutcomeDead
+		}
+		if err != nil {
+			logger.Default().Warn("could not retry message", "topic", msg.Topic, "message", msg.ID, "error", err)
+		}
+	}
+
+	if o.options.Metrics != nil {
+		o.options.Metrics.ObserveDelivery(ctx, m)
+	}
+}
+
+// backoff returns the delay before the next attempt of a message which failed the given number of times
+func (o *Outbox) backoff(attempts int) time.Duration {
+	d := o.options.RetryBackoff
+	for i := 1; i < attempts && d < o.options.MaxRetryBackoff; i++ {
+		d *= 2
+	}
+	if d > o.options.MaxRetryBackoff {
+		d = o.options.MaxRetryBackoff
+	}
+	return d
+}
+
+func (o *Outbox) raw() raw.Raw {
+	return raw.Raw{Engine: o.client}
+}
+
+func (o *Outbox) exec(ctx context.Context, action string, query string, params ...interface{}) error {
+	result, err := o.raw().ExecuteRaw(query, params...).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("%s message: %w", action, err)
+	}
+	if result.Count == 0 {
+		return ErrLost
+	}
+	return nil
+}
+
+// newID returns a new random message id
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// unquote returns a JSON payload, which some engine versions return as a JSON encoded string
+func unquote(data json.RawMessage) (json.RawMessage, error) {
+	if len(data) == 0 || data[0] != '"' {
+		return data, nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return json.RawMessage(s), nil
+}
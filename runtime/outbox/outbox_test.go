@@ -0,0 +1,166 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/steebchen/prisma-client-go/engine"
+	"github.com/steebchen/prisma-client-go/engine/protocol"
+	"github.com/steebchen/prisma-client-go/runtime/tools"
+)
+
+type fakeClient struct {
+	engine.Engine
+	provider string
+}
+
+func (c fakeClient) RelationGraph() tools.RelationGraph {
+	return tools.RelationGraph{
+		Provider: c.provider,
+		Tables:   map[string]string{"OutboxMessage": "outbox"},
+	}
+}
+
+func TestNew(t *testing.T) {
+	o, err := New(fakeClient{provider: "postgresql"}, Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, `"outbox"`, o.table)
+	assert.Equal(t, 10, o.options.BatchSize)
+	assert.Len(t, o.options.Owner, 32)
+
+	_, err = New(fakeClient{provider: "mysql"}, Options{})
+	assert.EqualError(t, err, `outboxes are not supported for provider "mysql"`)
+
+	_, err = New(fakeClient{provider: "postgresql"}, Options{Model: "Event"})
+	assert.EqualError(t, err, `model "Event" does not exist in the schema`)
+}
+
+func TestPublish(t *testing.T) {
+	o, err := New(fakeClient{provider: "postgresql"}, Options{})
+	assert.NoError(t, err)
+
+	msg, err := o.Publish("user.created", map[string]string{"email": "john@example.com"})
+	assert.NoError(t, err)
+	actual, err := msg.Debug()
+	assert.NoError(t, err)
+	assert.Contains(t, actual, "INSERT INTO")
+	assert.Contains(t, actual, "outbox")
+	assert.Contains(t, actual, "user.created")
+
+	_, err = o.Publish("user.created", make(chan int))
+	assert.EqualError(t, err, "encode payload: json: unsupported type: chan int")
+}
+
+// rawEngine answers the first raw query with the given rows and later ones with no rows, and records the queries
+type rawEngine struct {
+	engine.Engine
+	rows    string
+	queries []string
+}
+
+func (e *rawEngine) Do(ctx context.Context, payload interface{}, into interface{}) error {
+	query := payload.(protocol.GQLRequest).Query
+	e.queries = append(e.queries, query)
+	if strings.Contains(query, "queryRaw") {
+		rows := e.rows
+		e.rows = `[]`
+		return json.Unmarshal([]byte(rows), into)
+	}
+	return json.Unmarshal([]byte(`1`), into)
+}
+
+type recorder struct {
+	claims     []int
+	deliveries []DeliveryMetric
+}
+
+func (r *recorder) ObserveClaim(ctx context.Context, claimed int) {
+	r.claims = append(r.claims, claimed)
+}
+
+func (r *recorder) ObserveDelivery(ctx context.Context, m DeliveryMetric) {
+	r.deliveries = append(r.deliveries, m)
+}
+
+func TestClaim(t *testing.T) {
+	e := &rawEngine{rows: `[` +
+		`{"id":"2","topic":"b","payload":{"n":2},"attempts":1,"createdAt":"2020-01-02T00:00:00Z"},` +
+		`{"id":"1","topic":"a","payload":"{\"n\":1}","attempts":3,"createdAt":"2020-01-01T00:00:00Z"}]`}
+	r := &recorder{}
+	o, err := New(fakeClient{Engine: e, provider: "postgresql"}, Options{Owner: "worker-1", BatchSize: 5, Metrics: r})
+	assert.NoError(t, err)
+
+	messages, err := o.Claim(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, messages, 2)
+	assert.Equal(t, "1", messages[0].ID)
+	assert.Equal(t, 3, messages[0].Attempts)
+	assert.Equal(t, "2", messages[1].ID)
+
+	var payload struct {
+		N int `json:"n"`
+	}
+	assert.NoError(t, messages[0].Decode(&payload))
+	assert.Equal(t, 1, payload.N)
+
+	assert.Contains(t, e.queries[0], "FOR UPDATE SKIP LOCKED")
+	assert.Contains(t, e.queries[0], "worker-1")
+	assert.Equal(t, []int{2}, r.claims)
+}
+
+func TestRun(t *testing.T) {
+	e := &rawEngine{rows: `[` +
+		`{"id":"1","topic":"a","payload":{},"attempts":1,"createdAt":"2020-01-01T00:00:00Z"},` +
+		`{"id":"2","topic":"a","payload":{},"attempts":2,"createdAt":"2020-01-02T00:00:00Z"},` +
+		`{"id":"3","topic":"a","payload":{},"attempts":3,"createdAt":"2020-01-03T00:00:00Z"}]`}
+	r := &recorder{}
+	o, err := New(fakeClient{Engine: e, provider: "postgresql"}, Options{
+		MaxAttempts:  3,
+		PollInterval: time.Millisecond,
+		Metrics:      r,
+	})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err = o.Run(ctx, func(ctx context.Context, msg *Message) error {
+		if msg.ID == "3" {
+			cancel()
+		}
+		if msg.ID == "1" {
+			return nil
+		}
+		return errors.New("broker unavailable")
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	assert.Contains(t, e.queries[1], "DELETE FROM")
+	assert.Contains(t, e.queries[2], `SET \"status\" = 'pending'`)
+	assert.Contains(t, e.queries[2], "broker unavailable")
+	assert.Contains(t, e.queries[3], `SET \"status\" = 'dead'`)
+
+	var outcomes []string
+	for _, m := range r.deliveries {
+		outcomes = append(outcomes, m.Outcome)
+	}
+	assert.Equal(t, []string{OutcomeDelivered, OutcomeRetried, OutcomeDead}, outcomes)
+}
+
+func TestBackoff(t *testing.T) {
+	o, err := New(fakeClient{provider: "postgresql"}, Options{
+		RetryBackoff:    time.Second,
+		MaxRetryBackoff: 10 * time.Second,
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, time.Second, o.backoff(1))
+	assert.Equal(t, 2*time.Second, o.backoff(2))
+	assert.Equal(t, 8*time.Second, o.backoff(4))
+	assert.Equal(t, 10*time.Second, o.backoff(5))
+	assert.Equal(t, 10*time.Second, o.backoff(1000))
+}
@@ -0,0 +1,342 @@
+// Package queue provides a lightweight job queue which is stored in the database, as an alternative to running a
+// separate message broker for small deployments.
+//
+// Jobs are stored in a model of the Prisma schema, which has to be added manually:
+//
+//	model Job {
+//	  id          String    @id
+//	  queue       String
+//	  payload     Json
+//	  status      String    @default("pending")
+//	  attempts    Int       @default(0)
+//	  runAt       DateTime  @default(now())
+//	  lockedUntil DateTime?
+//	  lastError   String?
+//	  createdAt   DateTime  @default(now())
+//
+//	  @@index([queue, status, runAt])
+//	}
+//
+// The model may be renamed or mapped to another table, but the field names must not be mapped to other columns.
+// Workers use SELECT ... FOR UPDATE SKIP LOCKED, so only PostgreSQL and CockroachDB are supported.
+package queue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/steebchen/prisma-client-go/logger"
+	"github.com/steebchen/prisma-client-go/runtime/raw"
+	"github.com/steebchen/prisma-client-go/runtime/tools"
+)
+
+// ErrLost is returned when a job can't be completed or failed because its visibility timeout expired and it was
+// pulled by another worker in the meantime
+var ErrLost = errors.New("job lost: visibility timeout expired")
+
+// ErrInvalidPayload is returned by Pull if the payload of the pulled job can't be decoded. The job is marked as failed
+// right away, as retrying it would fail the same way.
+var ErrInvalidPayload = errors.New("invalid job payload")
+
+// Job statuses stored in the status column
+const (
+	StatusPending = "pending"
+	StatusRunning = "running"
+	StatusFailed  = "failed"
+)
+
+// Options configures a Queue
+type Options struct {
+	// Model is the name of the Prisma model which stores the jobs, "Job" by default
+	Model string
+	// VisibilityTimeout is how long a pulled job is hidden from other workers before it's pulled again, 30s by default
+	VisibilityTimeout time.Duration
+	// MaxAttempts is the number of attempts after which a failing job is marked as failed, 5 by default
+	MaxAttempts int
+	// RetryBackoff is the delay before a failed job is retried, multiplied by the number of attempts, 1s by default
+	RetryBackoff time.Duration
+	// PollInterval is how long Work waits before pulling again when the queue is empty, 1s by default
+	PollInterval time.Duration
+}
+
+// Job is a job pulled from a queue
+type Job[T any] struct {
+	// ID is the unique id of the job
+	ID string
+	// Queue is the name of the queue the job belongs to
+	Queue string
+	// Payload is the decoded payload which was enqueued
+	Payload T
+	// Attempts is the number of times the job was pulled, including the current attempt
+	Attempts int
+	// RunAt is the time the job was scheduled for
+	RunAt time.Time
+}
+
+// Queue enqueues and pulls jobs with payloads of type T
+type Queue[T any] struct {
+	client  tools.Client
+	name    string
+	table   string
+	options Options
+}
+
+// New creates a queue with the given name, which separates it from other queues stored in the same model.
+//
+// Example:
+//
+//	type Email struct {
+//	  To string `json:"to"`
+//	}
+//
+//	emails, err := queue.New[Email](client, "emails", queue.Options{})
+func New[T any](client tools.Client, name string, options Options) (*Queue[T], error) {
+	if options.Model == "" {
+		options.Model = "Job"
+	}
+	if options.VisibilityTimeout == 0 {
+		options.VisibilityTimeout = 30 * time.Second
+	}
+	if options.MaxAttempts == 0 {
+		options.MaxAttempts = 5
+	}
+	if options.RetryBackoff == 0 {
+		options.RetryBackoff = time.Second
+	}
+	if options.PollInterval == 0 {
+		options.PollInterval = time.Second
+	}
+
+	graph := client.RelationGraph()
+	switch graph.Provider {
+	case "postgresql", "postgres", "cockroachdb":
+	default:
+		return nil, fmt.Errorf("queues are not supported for provider %q", graph.Provider)
+	}
+
+	table, ok := graph.Table(options.Model)
+	if !ok {
+		return nil, fmt.Errorf("model %q does not exist in the schema", options.Model)
+	}
+
+	return &Queue[T]{
+		client:  client,
+		name:    name,
+		table:   table,
+		options: options,
+	}, nil
+}
+
+// Enqueue returns a query adding a job which is run as soon as possible. Use Exec to enqueue it directly, or Tx to
+// enqueue it in a transaction together with other queries, so that the job only exists if the transaction succeeds.
+// It returns an error if the payload can't be encoded as JSON.
+//
+// Example:
+//
+//	job, err := emails.Enqueue(Email{To: email})
+//	if err != nil {
+//	  panic(err)
+//	}
+//	created := client.User.CreateOne(db.User.Email.Set(email)).Tx()
+//	if err := client.Prisma.Transaction(created, job.Tx()).Exec(ctx); err != nil {
+//	  panic(err)
+//	}
+func (q *Queue[T]) Enqueue(payload T) (raw.ExecuteExec, error) {
+	id, data, err := newJob(payload)
+	if err != nil {
+		return raw.ExecuteExec{}, err
+	}
+	return q.raw().ExecuteRaw(
+		`INSERT INTO `+q.table+` ("id", "queue", "payload", "status", "attempts", "runAt", "createdAt") `+
+			`VALUES ($1, $2, $3, '`+StatusPending+`', 0, now(), now())`,
+		id, q.name, data,
+	), nil
+}
+
+// EnqueueAt returns a query adding a job which is run at the given time or later. It returns an error if the payload
+// can't be encoded as JSON.
+func (q *Queue[T]) EnqueueAt(payload T, runAt time.Time) (raw.ExecuteExec, error) {
+	id, data, err := newJob(payload)
+	if err != nil {
+		return raw.ExecuteExec{}, err
+	}
+	return q.raw().ExecuteRaw(
+		`INSERT INTO `+q.table+` ("id", "queue", "payload", "status", "attempts", "runAt", "createdAt") `+
+			`VALUES ($1, $2, $3, '`+StatusPending+`', 0, $4, now())`,
+		id, q.name, data, runAt,
+	), nil
+}
+
+type row struct {
+	ID       string          `json:"id"`
+	Payload  json.RawMessage `json:"payload"`
+	Attempts int             `json:"attempts"`
+	RunAt    time.Time       `json:"runAt"`
+}
+
+// Pull locks the next due job for the visibility timeout and returns it, or nil if no job is due. Jobs whose
+// visibility timeout expired without being completed are pulled again.
+// The job must be passed to Complete or Fail once it was processed.
+func (q *Queue[T]) Pull(ctx context.Context) (*Job[T], error) {
+	var rows []row
+	if err := q.raw().QueryRaw(
+		`UPDATE `+q.table+` SET "status" = '`+StatusRunning+`', "attempts" = "attempts" + 1, `+
+			`"lockedUntil" = now() + $2::float8 * interval '1 millisecond' `+
+			`WHERE "id" = (SELECT "id" FROM `+q.table+` WHERE "queue" = $1 AND `+
+			`(("status" = '`+StatusPending+`' AND "runAt" <= now()) OR ("status" = '`+StatusRunning+`' AND "lockedUntil" < now())) `+
+			`ORDER BY "runAt" LIMIT 1 FOR UPDATE SKIP LOCKED) `+
+			`RETURNING "id", "payload", "attempts", "runAt"`,
+		q.name, q.options.VisibilityTimeout.Milliseconds(),
+	).Exec(ctx, &rows); err != nil {
+		return nil, fmt.Errorf("pull job: %w", err)
+	}
+
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	r := rows[0]
+	job := &Job[T]{
+		ID:       r.ID,
+		Queue:    q.name,
+		Attempts: r.Attempts,
+		RunAt:    r.RunAt,
+	}
+	if err := decode(r.Payload, &job.Payload); err != nil {
+		cause := fmt.Errorf("%w: %s", ErrInvalidPayload, err)
+		if err := q.markFailed(ctx, job, cause); err != nil {
+			return nil, fmt.Errorf("pull job %s with invalid payload: %w", r.ID, err)
+		}
+		return nil, fmt.Errorf("pull job %s: %w", r.ID, cause)
+	}
+
+	return job, nil
+}
+
+// Extend resets the visibility timeout of a job, e.g. as a heartbeat while processing a long-running job.
+func (q *Queue[T]) Extend(ctx context.Context, job *Job[T]) error {
+	return q.exec(ctx, "extend",
+		`UPDATE `+q.table+` SET "lockedUntil" = now() + $3::float8 * interval '1 millisecond' `+
+			`WHERE "id" = $1 AND "attempts" = $2 AND "status" = '`+StatusRunning+`'`,
+		job.ID, job.Attempts, q.options.VisibilityTimeout.Milliseconds(),
+	)
+}
+
+// Complete removes a job after it was processed successfully.
+func (q *Queue[T]) Complete(ctx context.Context, job *Job[T]) error {
+	return q.exec(ctx, "complete",
+		`DELETE FROM `+q.table+` WHERE "id" = $1 AND "attempts" = $2 AND "status" = '`+StatusRunning+`'`,
+		job.ID, job.Attempts,
+	)
+}
+
+// Fail records the error of a job and schedules a retry. Once the job reached the maximum number of attempts, it's
+// marked as failed and kept for inspection instead.
+func (q *Queue[T]) Fail(ctx context.Context, job *Job[T], cause error) error {
+	if job.Attempts >= q.options.MaxAttempts {
+		return q.markFailed(ctx, job, cause)
+	}
+
+	backoff := q.options.RetryBackoff * time.Duration(job.Attempts)
+	return q.exec(ctx, "fail",
+		`UPDATE `+q.table+` SET "status" = '`+StatusPending+`', "lockedUntil" = NULL, "lastError" = $3, `+
+			`"runAt" = now() + $4::float8 * interval '1 millisecond' `+
+			`WHERE "id" = $1 AND "attempts" = $2 AND "status" = '`+StatusRunning+`'`,
+		job.ID, job.Attempts, cause.Error(), backoff.Milliseconds(),
+	)
+}
+
+// markFailed marks a job as failed without retrying it
+func (q *Queue[T]) markFailed(ctx context.Context, job *Job[T], cause error) error {
+	return q.exec(ctx, "fail",
+		`UPDATE `+q.table+` SET "status" = '`+StatusFailed+`', "lockedUntil" = NULL, "lastError" = $3 `+
+			`WHERE "id" = $1 AND "attempts" = $2 AND "status" = '`+StatusRunning+`'`,
+		job.ID, job.Attempts, cause.Error(),
+	)
+}
+
+// Work pulls jobs and passes them to fn until ctx is cancelled. Jobs are completed if fn returns nil and failed
+// otherwise. Errors while pulling are logged and retried after the poll interval, while jobs with an invalid payload
+// are marked as failed and skipped.
+//
+// Example:
+//
+//	err := emails.Work(ctx, func(ctx context.Context, job *queue.Job[Email]) error {
+//	  return send(ctx, job.Payload.To)
+//	})
+func (q *Queue[T]) Work(ctx context.Context, fn func(ctx context.Context, job *Job[T]) error) error {
+	for {
+		job, err := q.Pull(ctx)
+		if err != nil {
+			logger.Default().Warn("could not pull job", "queue", q.name, "error", err)
+			if errors.Is(err, ErrInvalidPayload) {
+				continue
+			}
+		}
+
+		if job == nil {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(q.options.PollInterval):
+			}
+			continue
+		}
+
+		if err := fn(ctx, job); err != nil {
+			if err := q.Fail(ctx, job, err); err != nil {
+				logger.Default().Warn("could not fail job", "queue", q.name, "job", job.ID, "error", err)
+			}
+			continue
+		}
+
+		if err := q.Complete(ctx, job); err != nil {
+			logger.Default().Warn("could not complete job", "queue", q.name, "job", job.ID, "error", err)
+		}
+	}
+}
+
+func (q *Queue[T]) raw() raw.Raw {
+	return raw.Raw{Engine: q.client}
+}
+
+func (q *Queue[T]) exec(ctx context.Context, action string, query string, params ...interface{}) error {
+	result, err := q.raw().ExecuteRaw(query, params...).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("%s job: %w", action, err)
+	}
+	if result.Count == 0 {
+		return ErrLost
+	}
+	return nil
+}
+
+// newJob returns a new job id and the encoded payload
+func newJob(payload interface{}) (string, json.RawMessage, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, fmt.Errorf("generate job id: %w", err)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", nil, fmt.Errorf("encode payload: %w", err)
+	}
+	return hex.EncodeToString(b), data, nil
+}
+
+// decode unmarshals a payload, which some engine versions return as a JSON encoded string
+func decode(data json.RawMessage, into interface{}) error {
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		data = json.RawMessage(s)
+	}
+	return json.Unmarshal(data, into)
+}
@@ -0,0 +1,102 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/steebchen/prisma-client-go/engine"
+	"github.com/steebchen/prisma-client-go/engine/protocol"
+	"github.com/steebchen/prisma-client-go/runtime/tools"
+)
+
+type fakeClient struct {
+	engine.Engine
+	provider string
+}
+
+func (c fakeClient) RelationGraph() tools.RelationGraph {
+	return tools.RelationGraph{
+		Provider: c.provider,
+		Tables:   map[string]string{"Job": "jobs"},
+	}
+}
+
+type email struct {
+	To string `json:"to"`
+}
+
+func TestNew(t *testing.T) {
+	q, err := New[email](fakeClient{provider: "postgresql"}, "emails", Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, `"jobs"`, q.table)
+	assert.Equal(t, 5, q.options.MaxAttempts)
+
+	_, err = New[email](fakeClient{provider: "mysql"}, "emails", Options{})
+	assert.EqualError(t, err, `queues are not supported for provider "mysql"`)
+
+	_, err = New[email](fakeClient{provider: "postgresql"}, "emails", Options{Model: "Task"})
+	assert.EqualError(t, err, `model "Task" does not exist in the schema`)
+}
+
+func TestEnqueue(t *testing.T) {
+	q, err := New[email](fakeClient{provider: "postgresql"}, "emails", Options{})
+	assert.NoError(t, err)
+
+	job, err := q.Enqueue(email{To: "john@example.com"})
+	assert.NoError(t, err)
+	actual, err := job.Debug()
+	assert.NoError(t, err)
+	assert.Contains(t, actual, "INSERT INTO")
+	assert.Contains(t, actual, "emails")
+	assert.Contains(t, actual, "prisma__type")
+}
+
+func TestEnqueueInvalidPayload(t *testing.T) {
+	q, err := New[chan int](fakeClient{provider: "postgresql"}, "channels", Options{})
+	assert.NoError(t, err)
+
+	_, err = q.Enqueue(make(chan int))
+	assert.EqualError(t, err, "encode payload: json: unsupported type: chan int")
+}
+
+// rawEngine answers raw queries with the given rows and counts, and records the queries
+type rawEngine struct {
+	engine.Engine
+	rows    string
+	queries []string
+}
+
+func (e *rawEngine) Do(ctx context.Context, payload interface{}, into interface{}) error {
+	query := payload.(protocol.GQLRequest).Query
+	e.queries = append(e.queries, query)
+	if strings.Contains(query, "queryRaw") {
+		return json.Unmarshal([]byte(e.rows), into)
+	}
+	return json.Unmarshal([]byte(`1`), into)
+}
+
+func TestPullInvalidPayload(t *testing.T) {
+	e := &rawEngine{rows: `[{"id":"1","payload":"{\"to\":1}","attempts":1,"runAt":"2020-01-01T00:00:00Z"}]`}
+	q, err := New[email](fakeClient{Engine: e, provider: "postgresql"}, "emails", Options{})
+	assert.NoError(t, err)
+
+	job, err := q.Pull(context.Background())
+	assert.Nil(t, job)
+	assert.ErrorIs(t, err, ErrInvalidPayload)
+	assert.Len(t, e.queries, 2)
+	assert.Contains(t, e.queries[1], `SET \"status\" = 'failed'`)
+	assert.Contains(t, e.queries[1], "invalid job payload")
+}
+
+func TestDecode(t *testing.T) {
+	var e email
+	assert.NoError(t, decode([]byte(`{"to":"a@example.com"}`), &e))
+	assert.Equal(t, "a@example.com", e.To)
+
+	assert.NoError(t, decode([]byte(`"{\"to\":\"b@example.com\"}"`), &e))
+	assert.Equal(t, "b@example.com", e.To)
+}
@@ -23,6 +23,11 @@ func (r ExecuteExec) ExtractQuery() builder.Query {
 	return r.query
 }
 
+// Debug returns the query which would be sent to the engine as indented JSON, without executing it
+func (r ExecuteExec) Debug() (string, error) {
+	return r.query.Debug()
+}
+
 func (r ExecuteExec) Tx() TxExecuteResult {
 	v := NewTxExecuteResult()
 	v.query = r.query
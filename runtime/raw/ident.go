@@ -0,0 +1,42 @@
+package raw
+
+import (
+	"strings"
+)
+
+// Ident quotes an identifier such as a table or column name for raw queries of the given provider, so that names
+// which are reserved words, e.g. `order` or `group`, can be used safely. Quotes within the name are escaped.
+// Identifiers of providers which don't use SQL, such as mongodb, are returned unchanged.
+//
+// Example:
+//
+//	query := fmt.Sprintf("SELECT %s FROM %s", raw.Ident("postgresql", "group"), raw.Ident("postgresql", "order"))
+func Ident(provider string, name string) string {
+	switch provider {
+	case "postgresql", "postgres", "cockroachdb", "sqlite":
+		return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+	case "mysql":
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	case "sqlserver":
+		return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+	}
+	return name
+}
+
+// Table quotes a table name for raw queries of the given provider and qualifies it with its schema, e.g. for models of
+// the multiSchema preview feature which set @@schema. If the schema is empty, only the table name is quoted.
+//
+// Example:
+//
+//	raw.Table("postgresql", "auth", "User") // "auth"."User"
+func Table(provider string, schema string, table string) string {
+	if schema == "" || !SupportsIdent(provider) {
+		return Ident(provider, table)
+	}
+	return Ident(provider, schema) + "." + Ident(provider, table)
+}
+
+// SupportsIdent returns whether identifiers of the given provider are quoted by Ident
+func SupportsIdent(provider string) bool {
+	return Ident(provider, "") != ""
+}
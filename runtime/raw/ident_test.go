@@ -0,0 +1,38 @@
+package raw
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdent(t *testing.T) {
+	tests := []struct {
+		provider string
+		name     string
+		expected string
+	}{
+		{"postgresql", "order", `"order"`},
+		{"sqlite", `we"ird`, `"we""ird"`},
+		{"mysql", "group", "`group`"},
+		{"mysql", "we`ird", "`we``ird`"},
+		{"sqlserver", "order", "[order]"},
+		{"sqlserver", "we]ird", "[we]]ird]"},
+		{"mongodb", "order", "order"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.provider+" "+tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Ident(tt.provider, tt.name))
+		})
+	}
+
+	assert.True(t, SupportsIdent("cockroachdb"))
+	assert.False(t, SupportsIdent("mongodb"))
+}
+
+func TestTable(t *testing.T) {
+	assert.Equal(t, `"auth"."User"`, Table("postgresql", "auth", "User"))
+	assert.Equal(t, `"User"`, Table("postgresql", "", "User"))
+	assert.Equal(t, "[sales].[order]", Table("sqlserver", "sales", "order"))
+	assert.Equal(t, "User", Table("mongodb", "auth", "User"))
+}
@@ -0,0 +1,25 @@
+package raw
+
+import (
+	"encoding/json"
+)
+
+// Document encodes a command, filter, pipeline stage or options document of the raw MongoDB operations. Strings,
+// byte slices and json.RawMessage values are expected to contain (extended) JSON already, while other values such as
+// maps, bson.M or structs are encoded with encoding/json.
+func Document(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case json.RawMessage:
+		return string(v)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return string(data)
+}
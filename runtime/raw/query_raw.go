@@ -23,6 +23,11 @@ func (r QueryExec) ExtractQuery() builder.Query {
 	return r.query
 }
 
+// Debug returns the query which would be sent to the engine as indented JSON, without executing it
+func (r QueryExec) Debug() (string, error) {
+	return r.query.Debug()
+}
+
 func (r QueryExec) Tx() TxQueryResult {
 	v := NewTxQueryResult()
 	v.query = r.query
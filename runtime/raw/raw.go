@@ -72,8 +72,20 @@ func convertType(input interface{}) string {
 	switch p := input.(type) {
 	case time.Time, *time.Time, raw.DateTime, *raw.DateTime:
 		return fmt.Sprintf(`{"prisma__type":"date","prisma__value":%s}`, string(data))
-	case decimal.Decimal, *decimal.Decimal, raw.Decimal, *raw.Decimal:
-		return fmt.Sprintf(`{"prisma__type":"decimal","prisma__value":%q}`, string(data))
+	case decimal.Decimal:
+		return decimalParam(p)
+	case *decimal.Decimal:
+		if p == nil {
+			return "null"
+		}
+		return decimalParam(*p)
+	case raw.Decimal:
+		return decimalParam(p.Decimal)
+	case *raw.Decimal:
+		if p == nil {
+			return "null"
+		}
+		return decimalParam(p.Decimal)
 	case json.RawMessage, *json.RawMessage, raw.JSON, *raw.JSON:
 		encoded := base64.URLEncoding.EncodeToString(data)
 		return fmt.Sprintf(`{"prisma__type":"json","prisma__value":%q}`, encoded)
@@ -84,3 +96,8 @@ func convertType(input interface{}) string {
 		return string(builder.Value(p))
 	}
 }
+
+// decimalParam encodes a decimal parameter as a string, so that it keeps its precision
+func decimalParam(d decimal.Decimal) string {
+	return fmt.Sprintf(`{"prisma__type":"decimal","prisma__value":%q}`, d.String())
+}
@@ -0,0 +1,31 @@
+package raw
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/steebchen/prisma-client-go/runtime/types/raw"
+)
+
+func TestConvertTypeDecimal(t *testing.T) {
+	d := decimal.RequireFromString("12345678901234567890.123456789")
+	want := `{"prisma__type":"decimal","prisma__value":"12345678901234567890.123456789"}`
+
+	assert.Equal(t, want, convertType(d))
+	assert.Equal(t, want, convertType(&d))
+	assert.Equal(t, want, convertType(raw.Decimal{Decimal: d}))
+	assert.Equal(t, `null`, convertType((*decimal.Decimal)(nil)))
+}
+
+func TestDocument(t *testing.T) {
+	assert.Equal(t, `{"ping": 1}`, Document(`{"ping": 1}`))
+	assert.Equal(t, `{"ping":1}`, Document([]byte(`{"ping":1}`)))
+	assert.Equal(t, `{"$match":{"age":{"$gt":21}}}`, Document(map[string]interface{}{
+		"$match": map[string]interface{}{"age": map[string]interface{}{"$gt": 21}},
+	}))
+	assert.Equal(t, `{"limit":10}`, Document(struct {
+		Limit int `json:"limit"`
+	}{Limit: 10}))
+}
@@ -2,14 +2,15 @@ package raw
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/steebchen/prisma-client-go/runtime/builder"
 )
 
+// RunCommandRaw runs a MongoDB database command, e.g. `{"ping": 1}`, whose result is returned as extended JSON.
+// The command is encoded with Document.
 func (r Raw) RunCommandRaw(cmd interface{}) RunCommandExec {
 	return RunCommandExec{
-		query: doCommandRaw(r.Engine, "runCommandRaw", fmt.Sprintf("%v", cmd)),
+		query: doCommandRaw(r.Engine, "runCommandRaw", Document(cmd)),
 	}
 }
 
@@ -21,6 +22,11 @@ func (r RunCommandExec) ExtractQuery() builder.Query {
 	return r.query
 }
 
+// Debug returns the query which would be sent to the engine as indented JSON, without executing it
+func (r RunCommandExec) Debug() (string, error) {
+	return r.query.Debug()
+}
+
 func (r RunCommandExec) Tx() TxQueryResult {
 	v := NewTxQueryResult()
 	v.query = r.query
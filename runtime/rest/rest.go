@@ -0,0 +1,194 @@
+// Package rest provides the helpers of the generated REST handlers, which expose list, get, create, update and delete
+// endpoints per model with net/http, e.g. for admin APIs:
+//
+//	http.Handle("/api/", http.StripPrefix("/api", client.RESTHandler()))
+//
+// Lists are filtered by query parameters named like fields, e.g. ?published=true, and paginated with take, skip and
+// orderBy, which is the name of a field prefixed with - for descending order.
+package rest
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/steebchen/prisma-client-go/logger"
+	"github.com/steebchen/prisma-client-go/runtime/types"
+)
+
+// DefaultTake is the number of records which lists return if the take parameter isn't set
+const DefaultTake = 100
+
+// MaxTake is the maximum number of records which lists return
+const MaxTake = 1000
+
+// Error is an error which is returned to the client with an HTTP status code
+type Error struct {
+	Status  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// BadRequest returns an Error with status 400
+func BadRequest(format string, args ...interface{}) error {
+	return &Error{Status: http.StatusBadRequest, Message: fmt.Sprintf(format, args...)}
+}
+
+// Page holds the pagination parameters of a list request
+type Page struct {
+	Take int
+	Skip int
+	// OrderBy is the name of the field to order by, or empty
+	OrderBy string
+	// Desc indicates that records are ordered descending
+	Desc bool
+}
+
+// ParsePage parses the take, skip and orderBy parameters of a list request
+func ParsePage(query url.Values) (Page, error) {
+	page := Page{Take: DefaultTake}
+	if s := query.Get("take"); s != "" {
+		take, err := strconv.Atoi(s)
+		if err != nil || take < 0 || take > MaxTake {
+			return Page{}, BadRequest("invalid take %q, expected a number between 0 and %d", s, MaxTake)
+		}
+		page.Take = take
+	}
+	if s := query.Get("skip"); s != "" {
+		skip, err := strconv.Atoi(s)
+		if err != nil || skip < 0 {
+			return Page{}, BadRequest("invalid skip %q, expected a positive number", s)
+		}
+		page.Skip = skip
+	}
+	page.OrderBy, page.Desc = strings.CutPrefix(query.Get("orderBy"), "-")
+	return page, nil
+}
+
+// Parse parses a query parameter or path segment into a value of type T, which is a string, number or boolean type
+// or implements encoding.TextUnmarshaler, e.g. time.Time
+func Parse[T any](name string, s string) (T, error) {
+	var v T
+	if u, ok := any(&v).(encoding.TextUnmarshaler); ok {
+		if err := u.UnmarshalText([]byte(s)); err != nil {
+			return v, BadRequest("invalid %s %q: %s", name, s, err)
+		}
+		return v, nil
+	}
+
+	rv := reflect.ValueOf(&v).Elem()
+	var err error
+	switch rv.Kind() {
+	case reflect.String:
+		rv.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		if i, err = strconv.ParseInt(s, 10, 64); err == nil {
+			rv.SetInt(i)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(s, 64); err == nil {
+			rv.SetFloat(f)
+		}
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(s); err == nil {
+			rv.SetBool(b)
+		}
+	default:
+		err = fmt.Errorf("unsupported type %T", v)
+	}
+	if err != nil {
+		return v, BadRequest("invalid %s %q", name, s)
+	}
+	return v, nil
+}
+
+// Body is the decoded JSON object of a create or update request by field name
+type Body map[string]json.RawMessage
+
+// DecodeBody decodes the JSON object of a request
+func DecodeBody(r *http.Request) (Body, error) {
+	var body Body
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return nil, BadRequest("invalid JSON body: %s", err)
+	}
+	return body, nil
+}
+
+// Check returns an error if the body contains a field which isn't one of the given fields
+func (b Body) Check(fields ...string) error {
+	for name := range b {
+		if !slices.Contains(fields, name) {
+			return BadRequest("unknown or read-only field %s", name)
+		}
+	}
+	return nil
+}
+
+// Value decodes the field of a body into a value of type T. It returns whether the field is present, and a nil value
+// if it's null.
+func Value[T any](body Body, name string) (*T, bool, error) {
+	data, ok := body[name]
+	if !ok {
+		return nil, false, nil
+	}
+	var v *T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, true, BadRequest("invalid value of field %s: %s", name, err)
+	}
+	return v, true, nil
+}
+
+// WriteJSON writes v as a JSON response with the given status code
+func WriteJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Default().Warn("could not write response", "error", err)
+	}
+}
+
+// WriteError writes an error response, whose status code depends on the error: 404 if no record was found, 409 on
+// unique constraint violations, the status of an Error, or 500 otherwise. The messages of unexpected errors aren't
+// exposed.
+func WriteError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	message := http.StatusText(status)
+
+	var restErr *Error
+	switch {
+	case errors.As(err, &restErr):
+		status, message = restErr.Status, restErr.Message
+	case errors.Is(err, types.ErrNotFound):
+		status, message = http.StatusNotFound, "record not found"
+	default:
+		if _, ok := types.CheckUniqueConstraint[string](err); ok {
+			status, message = http.StatusConflict, "unique constraint violated"
+		} else {
+			logger.Default().Error("request failed", "error", err)
+		}
+	}
+
+	WriteJSON(w, status, map[string]string{"error": message})
+}
+
+// Route returns the ID of the record of a request path, or an empty string for the collection. It returns false if the path has more segments.
+func Route(r *http.Request) (string, bool) {
+	id := strings.Trim(r.URL.Path, "/")
+	if strings.Contains(id, "/") {
+		return "", false
+	}
+	return id, true
+}
@@ -0,0 +1,125 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/steebchen/prisma-client-go/runtime/types"
+)
+
+func TestParsePage(t *testing.T) {
+	page, err := ParsePage(url.Values{"take": {"10"}, "skip": {"20"}, "orderBy": {"-createdAt"}})
+	assert.NoError(t, err)
+	assert.Equal(t, Page{Take: 10, Skip: 20, OrderBy: "createdAt", Desc: true}, page)
+
+	page, err = ParsePage(url.Values{})
+	assert.NoError(t, err)
+	assert.Equal(t, Page{Take: DefaultTake}, page)
+
+	_, err = ParsePage(url.Values{"take": {"5000"}})
+	assert.Error(t, err)
+
+	_, err = ParsePage(url.Values{"skip": {"-1"}})
+	assert.Error(t, err)
+}
+
+type role string
+
+func TestParse(t *testing.T) {
+	i, err := Parse[int]("age", "42")
+	assert.NoError(t, err)
+	assert.Equal(t, 42, i)
+
+	b, err := Parse[bool]("published", "true")
+	assert.NoError(t, err)
+	assert.Equal(t, true, b)
+
+	r, err := Parse[role]("role", "ADMIN")
+	assert.NoError(t, err)
+	assert.Equal(t, role("ADMIN"), r)
+
+	ts, err := Parse[time.Time]("createdAt", "2020-01-01T00:00:00Z")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), ts)
+
+	_, err = Parse[int]("age", "x")
+	var restErr *Error
+	assert.True(t, errors.As(err, &restErr))
+	assert.Equal(t, http.StatusBadRequest, restErr.Status)
+}
+
+func TestValue(t *testing.T) {
+	body := Body{"name": []byte(`"alice"`), "bio": []byte(`null`), "age": []byte(`"x"`)}
+
+	name, ok, err := Value[string](body, "name")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "alice", *name)
+
+	bio, ok, err := Value[string](body, "bio")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Nil(t, bio)
+
+	_, ok, err = Value[string](body, "email")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, _, err = Value[int](body, "age")
+	assert.Error(t, err)
+
+	assert.NoError(t, body.Check("name", "bio", "age", "email"))
+	assert.Error(t, body.Check("name", "bio"))
+}
+
+func TestWriteError(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		body   string
+	}{{
+		err:    BadRequest("invalid take"),
+		status: http.StatusBadRequest,
+		body:   `{"error":"invalid take"}`,
+	}, {
+		err:    fmt.Errorf("find: %w", types.ErrNotFound),
+		status: http.StatusNotFound,
+		body:   `{"error":"record not found"}`,
+	}, {
+		err:    fmt.Errorf("secret"),
+		status: http.StatusInternalServerError,
+		body:   `{"error":"Internal Server Error"}`,
+	}}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		WriteError(w, tt.err)
+		assert.Equal(t, tt.status, w.Code)
+		assert.JSONEq(t, tt.body, w.Body.String())
+	}
+}
+
+func TestRoute(t *testing.T) {
+	tests := []struct {
+		path string
+		id   string
+		ok   bool
+	}{
+		{path: "/", id: "", ok: true},
+		{path: "", id: "", ok: true},
+		{path: "/abc", id: "abc", ok: true},
+		{path: "/abc/", id: "abc", ok: true},
+		{path: "/abc/def", ok: false},
+	}
+	for _, tt := range tests {
+		id, ok := Route(httptest.NewRequest(http.MethodGet, "http://localhost"+tt.path, nil))
+		assert.Equal(t, tt.id, id, tt.path)
+		assert.Equal(t, tt.ok, ok, tt.path)
+	}
+}
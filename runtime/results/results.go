@@ -0,0 +1,99 @@
+// Package results provides generic helpers to transform query results, e.g. to map models to API responses.
+//
+// The helpers work on slices, as returned by Exec, and on sequences. A Seq has the same underlying type as iter.Seq,
+// so it can be used with range-over-func loops and the iter package on Go 1.23 and later.
+package results
+
+// Seq is a sequence of values, which yields values until yield returns false
+type Seq[T any] func(yield func(T) bool)
+
+// Values returns a sequence of the items of a slice
+func Values[T any](items []T) Seq[T] {
+	return func(yield func(T) bool) {
+		for _, item := range items {
+			if !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// Collect returns the values of a sequence as a slice
+func Collect[T any](seq Seq[T]) []T {
+	var items []T
+	seq(func(item T) bool {
+		items = append(items, item)
+		return true
+	})
+	return items
+}
+
+// Map returns the results of fn for each item. The result is allocated once with the length of items.
+func Map[T, R any](items []T, fn func(T) R) []R {
+	if items == nil {
+		return nil
+	}
+	result := make([]R, len(items))
+	for i, item := range items {
+		result[i] = fn(item)
+	}
+	return result
+}
+
+// Filter returns the items for which keep returns true. To avoid allocations, the items are filtered in place like
+// with slices.DeleteFunc, so the original slice must not be used afterwards.
+func Filter[T any](items []T, keep func(T) bool) []T {
+	n := 0
+	for _, item := range items {
+		if keep(item) {
+			items[n] = item
+			n++
+		}
+	}
+	// clear the remaining items, so that they can be garbage collected
+	var zero T
+	for i := n; i < len(items); i++ {
+		items[i] = zero
+	}
+	return items[:n]
+}
+
+// Reduce combines the items into a single value, starting with initial, e.g. to sum a field of all items
+func Reduce[T, A any](items []T, initial A, fn func(A, T) A) A {
+	acc := initial
+	for _, item := range items {
+		acc = fn(acc, item)
+	}
+	return acc
+}
+
+// MapSeq lazily applies fn to each value of a sequence
+func MapSeq[T, R any](seq Seq[T], fn func(T) R) Seq[R] {
+	return func(yield func(R) bool) {
+		seq(func(item T) bool {
+			return yield(fn(item))
+		})
+	}
+}
+
+// FilterSeq lazily skips the values of a sequence for which keep returns false
+func FilterSeq[T any](seq Seq[T], keep func(T) bool) Seq[T] {
+	return func(yield func(T) bool) {
+		seq(func(item T) bool {
+			if !keep(item) {
+				return true
+			}
+			return yield(item)
+		})
+	}
+}
+
+// ReduceSeq combines the values of a sequence into a single value, starting with initial
+func ReduceSeq[T, A any](seq Seq[T], initial A, fn func(A, T) A) A {
+	acc := initial
+	seq(func(item T) bool {
+		acc = fn(acc, item)
+		return true
+	})
+	return acc
+}
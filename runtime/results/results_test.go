@@ -0,0 +1,40 @@
+package results
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMap(t *testing.T) {
+	assert.Equal(t, []string{"1", "2", "3"}, Map([]int{1, 2, 3}, strconv.Itoa))
+	assert.Nil(t, Map([]int(nil), strconv.Itoa))
+}
+
+func TestFilter(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	even := Filter(items, func(i int) bool { return i%2 == 0 })
+	assert.Equal(t, []int{2, 4}, even)
+	// the backing array is reused and the remaining items are cleared
+	assert.Equal(t, []int{2, 4, 0, 0, 0}, items)
+}
+
+func TestReduce(t *testing.T) {
+	sum := Reduce([]int{1, 2, 3}, 0, func(acc, i int) int { return acc + i })
+	assert.Equal(t, 6, sum)
+}
+
+func TestSeq(t *testing.T) {
+	seq := MapSeq(FilterSeq(Values([]int{1, 2, 3, 4}), func(i int) bool { return i > 1 }), func(i int) int { return i * 10 })
+	assert.Equal(t, []int{20, 30, 40}, Collect(seq))
+	assert.Equal(t, 90, ReduceSeq(seq, 0, func(acc, i int) int { return acc + i }))
+
+	// stops when yield returns false
+	var first []int
+	seq(func(i int) bool {
+		first = append(first, i)
+		return false
+	})
+	assert.Equal(t, []int{20}, first)
+}
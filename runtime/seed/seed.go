@@ -0,0 +1,175 @@
+// Package seed applies seed functions written in Go, as an alternative to `prisma db seed` scripts. Like migrations,
+// each seed is applied once: its name is recorded in the same transaction in which it runs, and seeds whose name was
+// recorded are skipped.
+//
+// Applied seeds are stored in a model of the Prisma schema, which has to be added manually:
+//
+//	model AppliedSeed {
+//	  name      String   @id
+//	  appliedAt DateTime @default(now())
+//	}
+//
+// The model may be mapped to another table, but the field names must not be mapped to other columns. Once the model
+// exists, the generated client has a Seed function and a SeedMain entry point for seed commands.
+package seed
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/steebchen/prisma-client-go/engine"
+	"github.com/steebchen/prisma-client-go/runtime/raw"
+	"github.com/steebchen/prisma-client-go/runtime/tools"
+)
+
+// Model is the name of the Prisma model which stores the applied seeds
+const Model = "AppliedSeed"
+
+// txTimeout is the maximum duration of the transaction of a seed, which may insert many records
+const txTimeout = 5 * time.Minute
+
+// Func is a seed function with the name under which it's recorded once applied. Names must be unique and must not
+// change after a seed was applied, as it's applied again otherwise.
+type Func[C any] struct {
+	Name string
+	Run  func(ctx context.Context, client C) error
+}
+
+// Status describes whether a seed was applied
+type Status struct {
+	Name string
+	// AppliedAt is the time the seed was applied, or nil if it's pending
+	AppliedAt *time.Time
+}
+
+// Apply applies the seeds which weren't applied yet in the given order and returns their names. Each seed runs within
+// an interactive transaction which also records its name, so a failing seed leaves no records behind and is applied
+// again next time; seeds applied before it stay applied. wrap returns a client which sends its queries to the
+// transaction.
+//
+// The client must be a generated client.
+func Apply[C any](ctx context.Context, client engine.Engine, wrap func(tx engine.Engine) C, seeds []Func[C]) ([]string, error) {
+	s, applied, err := load(ctx, client, seeds)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, seed := range seeds {
+		if _, ok := applied[seed.Name]; ok {
+			continue
+		}
+
+		err := engine.RunInTx(ctx, client, engine.TxOptions{Timeout: txTimeout}, func(ctx context.Context, tx engine.Engine) error {
+			r := raw.Raw{Engine: tx}
+			if _, err := r.ExecuteRaw(s.insert(), seed.Name, engine.Now(tx)).Exec(ctx); err != nil {
+				return fmt.Errorf("record seed: %w", err)
+			}
+			return seed.Run(ctx, wrap(tx))
+		})
+		if err != nil {
+			return names, fmt.Errorf("seed %s: %w", seed.Name, err)
+		}
+		names = append(names, seed.Name)
+	}
+
+	return names, nil
+}
+
+// List returns whether each of the seeds was applied, in the given order
+func List[C any](ctx context.Context, client engine.Engine, seeds []Func[C]) ([]Status, error) {
+	_, applied, err := load(ctx, client, seeds)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]Status, len(seeds))
+	for i, seed := range seeds {
+		list[i] = Status{Name: seed.Name}
+		if at, ok := applied[seed.Name]; ok {
+			list[i].AppliedAt = &at
+		}
+	}
+	return list, nil
+}
+
+type record struct {
+	Name      string    `json:"name"`
+	AppliedAt time.Time `json:"appliedAt"`
+}
+
+// load validates the names of seeds and returns the times at which seeds were applied by name
+func load[C any](ctx context.Context, e engine.Engine, seeds []Func[C]) (store, map[string]time.Time, error) {
+	client, ok := e.(tools.Client)
+	if !ok {
+		return store{}, nil, fmt.Errorf("seeds require a generated client")
+	}
+
+	s, err := newStore(client.RelationGraph())
+	if err != nil {
+		return store{}, nil, err
+	}
+
+	seen := make(map[string]bool, len(seeds))
+	for i, seed := range seeds {
+		if seed.Name == "" {
+			return store{}, nil, fmt.Errorf("seed %d has no name", i)
+		}
+		if seen[seed.Name] {
+			return store{}, nil, fmt.Errorf("seed %s is defined twice", seed.Name)
+		}
+		seen[seed.Name] = true
+	}
+
+	var records []record
+	r := raw.Raw{Engine: e}
+	if err := r.QueryRaw(s.selectApplied()).Exec(ctx, &records); err != nil {
+		return store{}, nil, fmt.Errorf("load applied seeds: %w", err)
+	}
+
+	applied := make(map[string]time.Time, len(records))
+	for _, rec := range records {
+		applied[rec.Name] = rec.AppliedAt
+	}
+	return s, applied, nil
+}
+
+// Main runs a seed command with the given arguments, which applies the pending seeds without arguments or with
+// "apply", and lists all seeds with their status with "status". It writes its output to w.
+func Main(ctx context.Context, args []string, w io.Writer, apply func(ctx context.Context) ([]string, error), list func(ctx context.Context) ([]Status, error)) error {
+	command := "apply"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch command {
+	case "apply":
+		names, err := apply(ctx)
+		for _, name := range names {
+			fmt.Fprintf(w, "applied seed %s\n", name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			fmt.Fprintln(w, "no pending seeds")
+		}
+		return nil
+	case "status":
+		statuses, err := list(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			if s.AppliedAt == nil {
+				fmt.Fprintf(w, "pending  %s\n", s.Name)
+			} else {
+				fmt.Fprintf(w, "applied  %s (%s)\n", s.Name, s.AppliedAt.Format(time.RFC3339))
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown command %q, expected apply or status", command)
+}
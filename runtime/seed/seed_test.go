@@ -0,0 +1,129 @@
+package seed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/steebchen/prisma-client-go/engine"
+	"github.com/steebchen/prisma-client-go/engine/protocol"
+	"github.com/steebchen/prisma-client-go/runtime/tools"
+)
+
+// fakeClient returns a fixed list of applied seeds
+type fakeClient struct {
+	engine.Engine
+	calls   []string
+	applied []record
+}
+
+func (c *fakeClient) RelationGraph() tools.RelationGraph {
+	return tools.RelationGraph{
+		Provider: "postgresql",
+		Tables:   map[string]string{"AppliedSeed": "AppliedSeed"},
+	}
+}
+
+func (c *fakeClient) Name() string {
+	return "fake"
+}
+
+func (c *fakeClient) Do(ctx context.Context, payload interface{}, into interface{}) error {
+	query := payload.(protocol.GQLRequest).Query
+
+	var response string
+	switch {
+	case strings.Contains(query, "INSERT"):
+		c.calls = append(c.calls, "insert")
+		response = "1"
+	case strings.Contains(query, "SELECT"):
+		c.calls = append(c.calls, "select")
+		data, _ := json.Marshal(c.applied)
+		response = string(data)
+	}
+	return json.Unmarshal([]byte(response), into)
+}
+
+func (c *fakeClient) StartTx(ctx context.Context, options engine.TxOptions) (string, error) {
+	c.calls = append(c.calls, "start")
+	return "tx1", nil
+}
+
+func (c *fakeClient) CommitTx(ctx context.Context, id string) error {
+	c.calls = append(c.calls, "commit")
+	return nil
+}
+
+func (c *fakeClient) RollbackTx(ctx context.Context, id string) error {
+	c.calls = append(c.calls, "rollback")
+	return nil
+}
+
+func TestApply(t *testing.T) {
+	client := &fakeClient{
+		applied: []record{{Name: "users", AppliedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}},
+	}
+	ctx := context.Background()
+
+	wrap := func(tx engine.Engine) engine.Engine {
+		assert.True(t, engine.InTx(tx))
+		return tx
+	}
+	var ran []string
+	run := func(name string) func(ctx context.Context, client engine.Engine) error {
+		return func(ctx context.Context, client engine.Engine) error {
+			ran = append(ran, name)
+			return nil
+		}
+	}
+	failed := errors.New("failed")
+	seeds := []Func[engine.Engine]{
+		{Name: "users", Run: run("users")},
+		{Name: "posts", Run: run("posts")},
+		{Name: "comments", Run: func(ctx context.Context, client engine.Engine) error {
+			return failed
+		}},
+	}
+
+	names, err := Apply(ctx, client, wrap, seeds)
+	assert.ErrorIs(t, err, failed)
+	assert.EqualError(t, err, "seed comments: failed")
+	assert.Equal(t, []string{"posts"}, names)
+	assert.Equal(t, []string{"posts"}, ran)
+	assert.Equal(t, []string{"select", "start", "insert", "commit", "start", "insert", "rollback"}, client.calls)
+
+	statuses, err := List(ctx, client, seeds)
+	assert.NoError(t, err)
+	applied := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, []Status{{Name: "users", AppliedAt: &applied}, {Name: "posts"}, {Name: "comments"}}, statuses)
+
+	_, err = Apply(ctx, client, wrap, []Func[engine.Engine]{{Name: "users"}, {Name: "users"}})
+	assert.EqualError(t, err, "seed users is defined twice")
+}
+
+func TestMainCommand(t *testing.T) {
+	ctx := context.Background()
+	apply := func(ctx context.Context) ([]string, error) {
+		return []string{"users"}, nil
+	}
+	applied := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	list := func(ctx context.Context) ([]Status, error) {
+		return []Status{{Name: "users", AppliedAt: &applied}, {Name: "posts"}}, nil
+	}
+
+	var out bytes.Buffer
+	assert.NoError(t, Main(ctx, nil, &out, apply, list))
+	assert.Equal(t, "applied seed users\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, Main(ctx, []string{"status"}, &out, apply, list))
+	assert.Equal(t, "applied  users (2024-01-01T00:00:00Z)\npending  posts\n", out.String())
+
+	assert.EqualError(t, Main(ctx, []string{"reset"}, &out, apply, list), `unknown command "reset", expected apply or status`)
+}
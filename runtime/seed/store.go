@@ -0,0 +1,57 @@
+package seed
+
+import (
+	"fmt"
+
+	"github.com/steebchen/prisma-client-go/runtime/raw"
+	"github.com/steebchen/prisma-client-go/runtime/tools"
+)
+
+// store builds the statements for the applied seed table of a provider
+type store struct {
+	provider string
+	table    string
+}
+
+func newStore(graph tools.RelationGraph) (store, error) {
+	switch graph.Provider {
+	case "postgresql", "postgres", "cockroachdb", "mysql", "sqlite", "sqlserver":
+	default:
+		return store{}, fmt.Errorf("seeds are not supported for provider %q", graph.Provider)
+	}
+
+	table, ok := graph.Table(Model)
+	if !ok {
+		return store{}, fmt.Errorf("model %q does not exist in the schema", Model)
+	}
+
+	return store{
+		provider: graph.Provider,
+		table:    table,
+	}, nil
+}
+
+func (s store) ident(name string) string {
+	return raw.Ident(s.provider, name)
+}
+
+// param returns the placeholder of the n-th parameter, starting at 1
+func (s store) param(n int) string {
+	switch s.provider {
+	case "postgresql", "postgres", "cockroachdb":
+		return fmt.Sprintf("$%d", n)
+	case "sqlserver":
+		return fmt.Sprintf("@P%d", n)
+	}
+	return "?"
+}
+
+// insert returns a statement which records an applied seed
+func (s store) insert() string {
+	return "INSERT INTO " + s.table + " (" + s.ident("name") + ", " + s.ident("appliedAt") + ") VALUES (" +
+		s.param(1) + ", " + s.param(2) + ")"
+}
+
+func (s store) selectApplied() string {
+	return "SELECT " + s.ident("name") + ", " + s.ident("appliedAt") + " FROM " + s.table
+}
@@ -0,0 +1,32 @@
+package seed
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/steebchen/prisma-client-go/runtime/tools"
+)
+
+func TestStore(t *testing.T) {
+	tables := map[string]string{"AppliedSeed": "applied_seeds"}
+
+	s, err := newStore(tools.RelationGraph{Provider: "postgresql", Tables: tables})
+	assert.NoError(t, err)
+	assert.Equal(t, `INSERT INTO "applied_seeds" ("name", "appliedAt") VALUES ($1, $2)`, s.insert())
+	assert.Equal(t, `SELECT "name", "appliedAt" FROM "applied_seeds"`, s.selectApplied())
+
+	s, err = newStore(tools.RelationGraph{Provider: "mysql", Tables: tables})
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO `applied_seeds` (`name`, `appliedAt`) VALUES (?, ?)", s.insert())
+
+	s, err = newStore(tools.RelationGraph{Provider: "sqlserver", Tables: tables})
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO [applied_seeds] ([name], [appliedAt]) VALUES (@P1, @P2)", s.insert())
+
+	_, err = newStore(tools.RelationGraph{Provider: "mongodb", Tables: tables})
+	assert.EqualError(t, err, `seeds are not supported for provider "mongodb"`)
+
+	_, err = newStore(tools.RelationGraph{Provider: "sqlite"})
+	assert.EqualError(t, err, `model "AppliedSeed" does not exist in the schema`)
+}
@@ -0,0 +1,43 @@
+// Package testdb sets up throwaway databases for tests which should run against the real query engine.
+package testdb
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/steebchen/prisma-client-go/migrate"
+)
+
+// urlEnvName is the env variable the datasource url of the pushed schema is read from
+const urlEnvName = "PRISMA_TEST_DATABASE_URL"
+
+var datasourceURL = regexp.MustCompile(`(?m)^(\s*url\s*=\s*).*$`)
+
+// SQLite creates a SQLite database file in dir, pushes the schema to it and returns its connection url.
+// The database lives as long as dir, so tests usually pass t.TempDir().
+func SQLite(ctx context.Context, dir string, schema string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("test database dir: %w", err)
+	}
+
+	schemaPath := filepath.Join(dir, "schema.prisma")
+	if err := os.WriteFile(schemaPath, []byte(withURLFromEnv(schema)), 0o644); err != nil {
+		return "", fmt.Errorf("write schema: %w", err)
+	}
+
+	url := "file:" + filepath.Join(dir, "test.db")
+	if _, err := migrate.Push(ctx, schemaPath, url, true); err != nil {
+		return "", fmt.Errorf("push schema to test database: %w", err)
+	}
+
+	return url, nil
+}
+
+// withURLFromEnv makes the datasource read its url from an env variable, so it can be pointed to the test database
+func withURLFromEnv(schema string) string {
+	return datasourceURL.ReplaceAllString(schema, fmt.Sprintf(`${1}env("%s")`, urlEnvName))
+}
@@ -0,0 +1,27 @@
+package testdb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithURLFromEnv(t *testing.T) {
+	schema := `datasource db {
+  provider = "sqlite"
+  url      = "file:dev.db"
+}
+
+model User {
+  id String @id
+}`
+
+	assert.Equal(t, `datasource db {
+  provider = "sqlite"
+  url      = env("PRISMA_TEST_DATABASE_URL")
+}
+
+model User {
+  id String @id
+}`, withURLFromEnv(schema))
+}
@@ -0,0 +1,62 @@
+// Package testutil contains helpers for integration tests which run against a real database.
+package testutil
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/steebchen/prisma-client-go/engine"
+)
+
+// txTimeout is the maximum duration of a test wrapped in WithRollback
+const txTimeout = time.Minute
+
+// Client is implemented by generated clients
+type Client[C any] interface {
+	engine.Engine
+	WrapEngine(wrap func(e engine.Engine) engine.Engine) C
+}
+
+// WithRollback runs fn with a copy of the client which sends all queries within an interactive transaction.
+// The transaction is always rolled back afterwards, so tests can share a database without affecting each other.
+//
+// Example:
+//
+//	testutil.WithRollback(t, client, func(tx *db.PrismaClient) {
+//	  _, err := tx.User.CreateOne(db.User.Email.Set("john@example.com")).Exec(ctx)
+//	  // ...
+//	})
+func WithRollback[C Client[C]](t testing.TB, client C, fn func(tx C)) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	var transactor engine.Transactor
+	var id string
+	var err error
+	tx := client.WrapEngine(func(e engine.Engine) engine.Engine {
+		var ok bool
+		transactor, ok = engine.AsTransactor(e)
+		if !ok {
+			return e
+		}
+		id, err = transactor.StartTx(ctx, engine.TxOptions{Timeout: txTimeout})
+		return engine.NewTxEngine(e, id)
+	})
+
+	if transactor == nil {
+		t.Fatalf("engine %s does not support interactive transactions", client.Name())
+	}
+	if err != nil {
+		t.Fatalf("could not start transaction: %s", err)
+	}
+
+	defer func() {
+		if err := transactor.RollbackTx(ctx, id); err != nil {
+			t.Errorf("could not roll back transaction: %s", err)
+		}
+	}()
+
+	fn(tx)
+}
@@ -0,0 +1,53 @@
+package testutil
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/steebchen/prisma-client-go/engine"
+)
+
+type fakeEngine struct {
+	engine.Engine
+	calls []string
+}
+
+func (e *fakeEngine) StartTx(ctx context.Context, options engine.TxOptions) (string, error) {
+	e.calls = append(e.calls, "start")
+	return "tx1", nil
+}
+
+func (e *fakeEngine) CommitTx(ctx context.Context, id string) error {
+	e.calls = append(e.calls, "commit "+id)
+	return nil
+}
+
+func (e *fakeEngine) RollbackTx(ctx context.Context, id string) error {
+	e.calls = append(e.calls, "rollback "+id)
+	return nil
+}
+
+type fakeClient struct {
+	engine.Engine
+}
+
+func (c *fakeClient) WrapEngine(wrap func(e engine.Engine) engine.Engine) *fakeClient {
+	return &fakeClient{Engine: wrap(c.Engine)}
+}
+
+func TestWithRollback(t *testing.T) {
+	e := &fakeEngine{}
+
+	WithRollback(t, &fakeClient{Engine: e}, func(tx *fakeClient) {
+		txEngine, ok := tx.Engine.(*engine.TxEngine)
+		if !ok {
+			t.Fatalf("expected a transaction engine")
+		}
+		assert.Equal(t, "tx1", txEngine.ID)
+		e.calls = append(e.calls, "run")
+	})
+
+	assert.Equal(t, []string{"start", "run", "rollback tx1"}, e.calls)
+}
@@ -0,0 +1,130 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/steebchen/prisma-client-go/runtime/raw"
+	"github.com/steebchen/prisma-client-go/runtime/tools"
+)
+
+// TruncateAll deletes all rows of all models except the given ones, which may be model or table names.
+// The order is derived from the relations of the schema, so that rows holding a foreign key are deleted before the
+// rows they reference. On PostgreSQL and CockroachDB, all tables are truncated in a single statement, using CASCADE
+// if no tables are excluded.
+//
+// Example:
+//
+//	if err := testutil.TruncateAll(ctx, client, "Country"); err != nil {
+//	  t.Fatal(err)
+//	}
+func TruncateAll(ctx context.Context, client tools.Client, except ...string) error {
+	graph := client.RelationGraph()
+
+	if !raw.SupportsIdent(graph.Provider) {
+		return fmt.Errorf("truncating is not supported for provider %q", graph.Provider)
+	}
+
+	tables := truncateOrder(graph, except)
+	if len(tables) == 0 {
+		return nil
+	}
+
+	r := raw.Raw{Engine: client}
+	for _, statement := range truncateStatements(graph.Provider, tables, len(except) == 0) {
+		if _, err := r.ExecuteRaw(statement).Exec(ctx); err != nil {
+			return fmt.Errorf("truncate: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// truncateOrder returns the quoted tables of the graph which are not excluded, ordered so that tables holding a
+// foreign key come before the tables they reference. Tables in a reference cycle keep alphabetical order.
+func truncateOrder(graph tools.RelationGraph, except []string) []string {
+	excluded := make(map[string]bool)
+	for _, e := range except {
+		excluded[e] = true
+	}
+
+	var tables []string
+	for model, table := range graph.Tables {
+		if excluded[model] || excluded[table] {
+			continue
+		}
+		tables = append(tables, raw.Table(graph.Provider, graph.Schemas[model], table))
+	}
+	sort.Strings(tables)
+
+	// referencedBy counts the remaining tables which hold a foreign key to a table
+	referencedBy := make(map[string]map[string]bool)
+	for _, relation := range graph.Relations {
+		table := raw.Table(graph.Provider, relation.Schema, relation.Table)
+		references := raw.Table(graph.Provider, relation.ReferencesSchema, relation.ReferencesTable)
+		if table == references {
+			continue
+		}
+		if referencedBy[references] == nil {
+			referencedBy[references] = make(map[string]bool)
+		}
+		referencedBy[references][table] = true
+	}
+
+	var order []string
+	done := make(map[string]bool)
+	for len(order) < len(tables) {
+		progress := false
+		for _, table := range tables {
+			if done[table] || !allDone(referencedBy[table], done, tables) {
+				continue
+			}
+			order = append(order, table)
+			done[table] = true
+			progress = true
+		}
+
+		if !progress {
+			// break a reference cycle by deleting the first remaining table
+			for _, table := range tables {
+				if !done[table] {
+					order = append(order, table)
+					done[table] = true
+					break
+				}
+			}
+		}
+	}
+
+	return order
+}
+
+// allDone returns whether all referencing tables which are truncated at all were already truncated
+func allDone(referencing map[string]bool, done map[string]bool, tables []string) bool {
+	for _, table := range tables {
+		if referencing[table] && !done[table] {
+			return false
+		}
+	}
+	return true
+}
+
+// truncateStatements returns the statements which empty the given quoted tables
+func truncateStatements(provider string, quoted []string, cascade bool) []string {
+	switch provider {
+	case "postgresql", "postgres", "cockroachdb":
+		statement := "TRUNCATE TABLE " + strings.Join(quoted, ", ")
+		if cascade {
+			statement += " CASCADE"
+		}
+		return []string{statement}
+	}
+
+	statements := make([]string, len(quoted))
+	for i, table := range quoted {
+		statements[i] = "DELETE FROM " + table
+	}
+	return statements
+}
@@ -0,0 +1,64 @@
+package testutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/steebchen/prisma-client-go/runtime/tools"
+)
+
+var graph = tools.RelationGraph{
+	Provider: "mysql",
+	Relations: []tools.Relation{{
+		Model:           "Post",
+		Table:           "posts",
+		References:      "User",
+		ReferencesTable: "users",
+	}, {
+		Model:           "Comment",
+		Table:           "Comment",
+		References:      "Post",
+		ReferencesTable: "posts",
+	}, {
+		Model:           "User",
+		Table:           "users",
+		References:      "User",
+		ReferencesTable: "users",
+	}},
+	Tables: map[string]string{
+		"User":    "users",
+		"Post":    "posts",
+		"Comment": "Comment",
+		"Country": "Country",
+	},
+}
+
+func TestTruncateOrder(t *testing.T) {
+	assert.Equal(t, []string{"`Comment`", "`Country`", "`posts`", "`users`"}, truncateOrder(graph, nil))
+	assert.Equal(t, []string{"`posts`", "`users`"}, truncateOrder(graph, []string{"Comment", "Country"}))
+	assert.Equal(t, []string{"`Comment`", "`Country`", "`posts`"}, truncateOrder(graph, []string{"users"}))
+}
+
+func TestTruncateOrderSchemas(t *testing.T) {
+	graph := tools.RelationGraph{
+		Provider: "postgresql",
+		Relations: []tools.Relation{{
+			Model:            "Post",
+			Table:            "Post",
+			Schema:           "blog",
+			References:       "User",
+			ReferencesTable:  "User",
+			ReferencesSchema: "auth",
+		}},
+		Tables:  map[string]string{"User": "User", "Post": "Post", "Audit": "User"},
+		Schemas: map[string]string{"User": "auth", "Post": "blog"},
+	}
+	assert.Equal(t, []string{`"User"`, `"blog"."Post"`, `"auth"."User"`}, truncateOrder(graph, nil))
+}
+
+func TestTruncateStatements(t *testing.T) {
+	assert.Equal(t, []string{"DELETE FROM `posts`", "DELETE FROM `users`"}, truncateStatements("mysql", []string{"`posts`", "`users`"}, true))
+	assert.Equal(t, []string{`TRUNCATE TABLE "posts", "users" CASCADE`}, truncateStatements("postgresql", []string{`"posts"`, `"users"`}, true))
+	assert.Equal(t, []string{`TRUNCATE TABLE "posts", "users"`}, truncateStatements("postgresql", []string{`"posts"`, `"users"`}, false))
+}
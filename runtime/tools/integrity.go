@@ -0,0 +1,197 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/steebchen/prisma-client-go/engine"
+	"github.com/steebchen/prisma-client-go/runtime/raw"
+)
+
+// Relation describes a foreign key relation of the Prisma schema using database names
+type Relation struct {
+	// Name is the relation name as defined in the Prisma schema
+	Name string
+	// Model is the model which holds the foreign key
+	Model string
+	// Table is the database name of Model
+	Table string
+	// Schema is the database schema of Model, which is only set for models with @@schema of the multiSchema preview
+	// feature
+	Schema string
+	// IDColumns are the primary key columns of Model, used to identify orphaned rows
+	IDColumns []string
+	// Columns are the foreign key columns of Model
+	Columns []string
+	// References is the model the foreign key points to
+	References string
+	// ReferencesTable is the database name of References
+	ReferencesTable string
+	// ReferencesSchema is the database schema of References
+	ReferencesSchema string
+	// ReferencesColumns are the columns of ReferencesTable the foreign key points to
+	ReferencesColumns []string
+}
+
+// RelationGraph contains all foreign key relations of a Prisma schema
+type RelationGraph struct {
+	// Provider is the active datasource provider, e.g. postgresql or mysql
+	Provider string
+	// Relations contains all relations which hold a foreign key
+	Relations []Relation
+	// Tables contains the database name of every model, keyed by model name
+	Tables map[string]string
+	// Schemas contains the database schema of models with @@schema of the multiSchema preview feature, keyed by
+	// model name
+	Schemas map[string]string
+}
+
+// Table returns the quoted table name of a model for raw queries, qualified with its schema if it has one
+func (g RelationGraph) Table(model string) (string, bool) {
+	table, ok := g.Tables[model]
+	if !ok {
+		return "", false
+	}
+	return raw.Table(g.Provider, g.Schemas[model], table), true
+}
+
+// Client is implemented by generated Prisma clients
+type Client interface {
+	engine.Engine
+	RelationGraph() RelationGraph
+}
+
+// Orphan is a row which references a record that does not exist
+type Orphan struct {
+	// ID holds the primary key values of the orphaned row, keyed by column
+	ID map[string]interface{}
+	// Keys holds the dangling foreign key values, keyed by column
+	Keys map[string]interface{}
+}
+
+// Violation lists all orphaned rows of a single relation
+type Violation struct {
+	Relation Relation
+	Orphans  []Orphan
+}
+
+// IntegrityReport is the result of CheckIntegrity
+type IntegrityReport struct {
+	// Checked contains the number of relations which were checked
+	Checked int
+	// Violations contains relations with at least one orphaned row
+	Violations []Violation
+}
+
+// OK is true if no orphaned rows were found
+func (r *IntegrityReport) OK() bool {
+	return len(r.Violations) == 0
+}
+
+// CheckIntegrity uses the relation graph of the Prisma schema to find rows with foreign keys pointing to
+// records which do not exist. This is useful with relationMode = "prisma", where the database does not enforce
+// foreign keys, or after manually importing data.
+//
+// Example:
+//
+//	report, err := tools.CheckIntegrity(ctx, client)
+//	if err != nil {
+//	  handle(err)
+//	}
+//
+//	for _, v := range report.Violations {
+//	  log.Printf("%s has %d orphaned rows", v.Relation.Name, len(v.Orphans))
+//	}
+func CheckIntegrity(ctx context.Context, client Client) (*IntegrityReport, error) {
+	graph := client.RelationGraph()
+
+	quote, err := quoteFunc(graph.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	r := raw.Raw{Engine: client}
+
+	var report IntegrityReport
+	for _, relation := range graph.Relations {
+		var rows []map[string]interface{}
+		if err := r.QueryRaw(orphanQuery(relation, quote)).Exec(ctx, &rows); err != nil {
+			return nil, fmt.Errorf("check relation %s: %w", relation.Name, err)
+		}
+
+		report.Checked++
+
+		if len(rows) == 0 {
+			continue
+		}
+
+		violation := Violation{
+			Relation: relation,
+		}
+		for _, row := range rows {
+			orphan := Orphan{
+				ID:   make(map[string]interface{}),
+				Keys: make(map[string]interface{}),
+			}
+			for _, c := range relation.IDColumns {
+				orphan.ID[c] = row[c]
+			}
+			for _, c := range relation.Columns {
+				orphan.Keys[c] = row[c]
+			}
+			violation.Orphans = append(violation.Orphans, orphan)
+		}
+		report.Violations = append(report.Violations, violation)
+	}
+
+	return &report, nil
+}
+
+func quoteFunc(provider string) (func(string) string, error) {
+	if !raw.SupportsIdent(provider) {
+		return nil, fmt.Errorf("integrity checks are not supported for provider %q", provider)
+	}
+	return func(s string) string {
+		return raw.Ident(provider, s)
+	}, nil
+}
+
+// orphanQuery builds a query which selects all rows of the relation's table with a non-null foreign key
+// which has no matching row in the referenced table
+func orphanQuery(relation Relation, quote func(string) string) string {
+	var selects []string
+	seen := make(map[string]bool)
+	for _, c := range append(append([]string{}, relation.IDColumns...), relation.Columns...) {
+		if seen[c] {
+			continue
+		}
+		seen[c] = true
+		selects = append(selects, "c."+quote(c))
+	}
+
+	var on []string
+	var where []string
+	for i, c := range relation.Columns {
+		on = append(on, fmt.Sprintf("p.%s = c.%s", quote(relation.ReferencesColumns[i]), quote(c)))
+		where = append(where, fmt.Sprintf("c.%s IS NOT NULL", quote(c)))
+	}
+	where = append(where, fmt.Sprintf("p.%s IS NULL", quote(relation.ReferencesColumns[0])))
+
+	return fmt.Sprintf(
+		"SELECT %s FROM %s c LEFT JOIN %s p ON %s WHERE %s",
+		strings.Join(selects, ", "),
+		qualify(relation.Schema, relation.Table, quote),
+		qualify(relation.ReferencesSchema, relation.ReferencesTable, quote),
+		strings.Join(on, " AND "),
+		strings.Join(where, " AND "),
+	)
+}
+
+// qualify quotes a table name, qualified with its schema if it has one
+func qualify(schema string, table string, quote func(string) string) string {
+	if schema == "" {
+		return quote(table)
+	}
+	return quote(schema) + "." + quote(table)
+}
@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrphanQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		relation Relation
+		expected string
+	}{{
+		name:     "postgresql",
+		provider: "postgresql",
+		relation: Relation{
+			Table:             "Post",
+			IDColumns:         []string{"id"},
+			Columns:           []string{"authorId"},
+			ReferencesTable:   "User",
+			ReferencesColumns: []string{"id"},
+		},
+		expected: `SELECT c."id", c."authorId" FROM "Post" c LEFT JOIN "User" p ON p."id" = c."authorId" WHERE c."authorId" IS NOT NULL AND p."id" IS NULL`,
+	}, {
+		name:     "mysql composite",
+		provider: "mysql",
+		relation: Relation{
+			Table:             "line_item",
+			IDColumns:         []string{"order_id", "position"},
+			Columns:           []string{"order_id", "shop_id"},
+			ReferencesTable:   "order",
+			ReferencesColumns: []string{"id", "shop_id"},
+		},
+		expected: "SELECT c.`order_id`, c.`position`, c.`shop_id` FROM `line_item` c LEFT JOIN `order` p ON p.`id` = c.`order_id` AND p.`shop_id` = c.`shop_id` WHERE c.`order_id` IS NOT NULL AND c.`shop_id` IS NOT NULL AND p.`id` IS NULL",
+	}, {
+		name:     "postgresql multiple schemas",
+		provider: "postgresql",
+		relation: Relation{
+			Table:             "Post",
+			Schema:            "blog",
+			IDColumns:         []string{"id"},
+			Columns:           []string{"authorId"},
+			ReferencesTable:   "User",
+			ReferencesSchema:  "auth",
+			ReferencesColumns: []string{"id"},
+		},
+		expected: `SELECT c."id", c."authorId" FROM "blog"."Post" c LEFT JOIN "auth"."User" p ON p."id" = c."authorId" WHERE c."authorId" IS NOT NULL AND p."id" IS NULL`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote, err := quoteFunc(tt.provider)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.expected, orphanQuery(tt.relation, quote))
+		})
+	}
+}
+
+func TestQuoteFuncMongoDB(t *testing.T) {
+	if _, err := quoteFunc("mongodb"); err == nil {
+		t.Fatalf("expected an error for mongodb")
+	}
+}
+
+func TestRelationGraphTable(t *testing.T) {
+	graph := RelationGraph{
+		Provider: "postgresql",
+		Tables:   map[string]string{"User": "users", "Post": "Post"},
+		Schemas:  map[string]string{"User": "auth"},
+	}
+
+	table, ok := graph.Table("User")
+	assert.True(t, ok)
+	assert.Equal(t, `"auth"."users"`, table)
+
+	table, ok = graph.Table("Post")
+	assert.True(t, ok)
+	assert.Equal(t, `"Post"`, table)
+
+	_, ok = graph.Table("Comment")
+	assert.False(t, ok)
+}
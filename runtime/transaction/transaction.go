@@ -37,6 +37,9 @@ type Exec struct {
 func (r Exec) Exec(ctx context.Context) error {
 	r.requests = make([]protocol.GQLRequest, len(r.queries))
 	for i, query := range r.queries {
+		if err := query.ExtractQuery().RunBeforeHooks(ctx); err != nil {
+			return err
+		}
 		str, err := query.ExtractQuery().Build()
 		if err != nil {
 			return err
@@ -52,6 +55,8 @@ func (r Exec) Exec(ctx context.Context) error {
 		defer close(q.ExtractQuery().TxResult)
 	}
 
+	defer builder.ClearDedup(ctx)
+
 	var result protocol.GQLBatchResponse
 	payload := protocol.GQLBatchRequest{
 		Batch:       r.requests,
@@ -72,5 +77,17 @@ func (r Exec) Exec(ctx context.Context) error {
 
 		r.queries[i].ExtractQuery().TxResult <- inner.Data.Result
 	}
+
+	for i, inner := range result.Result {
+		q := r.queries[i].ExtractQuery()
+		engine.Invalidate(ctx, r.engine, q.Model, q.Method, q.Where(), inner.Data.Result)
+	}
+
+	// the transaction is already committed, so errors of after hooks can't roll it back
+	for i, inner := range result.Result {
+		if err := r.queries[i].ExtractQuery().RunAfterHooks(ctx, inner.Data.Result); err != nil {
+			return err
+		}
+	}
 	return nil
 }
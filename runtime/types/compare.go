@@ -0,0 +1,122 @@
+package types
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Collation describes how a database orders values by default, so that results which are sorted in memory, e.g. after
+// merging the results of multiple queries, are ordered like with ORDER BY.
+type Collation struct {
+	// NullsFirst sorts null values before other values in ascending order, and after them in descending order
+	NullsFirst bool
+	// CaseInsensitive compares strings regardless of their case, falling back to a case-sensitive comparison for ties
+	CaseInsensitive bool
+}
+
+// CollationOf returns the default collation of a database provider. PostgreSQL and CockroachDB sort nulls last in
+// ascending order, the other databases first. MySQL and SQL Server compare strings case-insensitively by default.
+// Locale-specific collation rules are not applied; strings are compared by their bytes.
+func CollationOf(provider string) Collation {
+	switch provider {
+	case "postgresql", "postgres", "cockroachdb":
+		return Collation{}
+	case "mysql", "sqlserver":
+		return Collation{NullsFirst: true, CaseInsensitive: true}
+	}
+	return Collation{NullsFirst: true}
+}
+
+// Compare compares two values of the same field and returns -1, 0 or 1. Nil pointers are null values, and desc
+// reverses the order. Values of unknown types are compared by their string representation.
+func (c Collation) Compare(a, b interface{}, desc bool) int {
+	result := c.compare(a, b)
+	if desc {
+		return -result
+	}
+	return result
+}
+
+func (c Collation) compare(a, b interface{}) int {
+	x, xNull := deref(a)
+	y, yNull := deref(b)
+	switch {
+	case xNull && yNull:
+		return 0
+	case xNull:
+		if c.NullsFirst {
+			return -1
+		}
+		return 1
+	case yNull:
+		if c.NullsFirst {
+			return 1
+		}
+		return -1
+	}
+
+	switch x := x.Interface().(type) {
+	case time.Time:
+		return x.Compare(y.Interface().(time.Time))
+	case Decimal:
+		return x.Cmp(y.Interface().(Decimal))
+	case BigInteger:
+		return x.Cmp(y.Interface().(BigInteger))
+	case JSON:
+		return bytes.Compare(x, y.Interface().(JSON))
+	case Bytes:
+		return bytes.Compare(x, y.Interface().(Bytes))
+	}
+
+	switch x.Kind() {
+	case reflect.String:
+		return c.compareStrings(x.String(), y.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return sign(x.Int() < y.Int(), x.Int() > y.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return sign(x.Uint() < y.Uint(), x.Uint() > y.Uint())
+	case reflect.Float32, reflect.Float64:
+		return sign(x.Float() < y.Float(), x.Float() > y.Float())
+	case reflect.Bool:
+		return sign(!x.Bool() && y.Bool(), x.Bool() && !y.Bool())
+	}
+
+	return strings.Compare(fmt.Sprint(x.Interface()), fmt.Sprint(y.Interface()))
+}
+
+func (c Collation) compareStrings(x, y string) int {
+	if c.CaseInsensitive {
+		if result := strings.Compare(strings.ToLower(x), strings.ToLower(y)); result != 0 {
+			return result
+		}
+	}
+	return strings.Compare(x, y)
+}
+
+// deref returns the value a pointer points to and whether the value is null
+func deref(v interface{}) (reflect.Value, bool) {
+	if v == nil {
+		return reflect.Value{}, true
+	}
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return reflect.Value{}, true
+		}
+		value = value.Elem()
+	}
+	return value, false
+}
+
+func sign(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+	return 0
+}
@@ -2,6 +2,7 @@ package types
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/steebchen/prisma-client-go/engine/protocol"
 )
@@ -15,6 +16,162 @@ func IsErrNotFound(err error) bool {
 	return errors.Is(err, ErrNotFound)
 }
 
+// NotFoundError is returned by OrFail queries when no record matches. It carries the model and the where-conditions
+// of the query, e.g. to map it to a 404 response generically, and matches ErrNotFound with errors.Is.
+type NotFoundError struct {
+	// Model is the name of the queried model
+	Model string
+	// Action is the query method, e.g. findUnique
+	Action string
+	// Where contains the where-conditions of the query by field name
+	Where map[string]interface{}
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s not found", e.Model)
+}
+
+// Is makes errors.Is(err, ErrNotFound) report true for a NotFoundError
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// AsNotFound returns the NotFoundError of an error, if any
+func AsNotFound(err error) (*NotFoundError, bool) {
+	var nf *NotFoundError
+	if errors.As(err, &nf) {
+		return nf, true
+	}
+	return nil, false
+}
+
+// ErrQueryLimit matches errors of queries which exceed a configured query limit
+var ErrQueryLimit = errors.New("query limit exceeded")
+
+// QueryLimitError is returned when a query exceeds a configured query limit. It matches ErrQueryLimit with errors.Is.
+type QueryLimitError struct {
+	// Limit is the exceeded limit: depth, list length, includes or size
+	Limit string
+	// Max is the configured maximum
+	Max int
+	// Actual is the value of the query
+	Actual int
+}
+
+func (e *QueryLimitError) Error() string {
+	return fmt.Sprintf("query exceeds the maximum %s of %d with %d", e.Limit, e.Max, e.Actual)
+}
+
+// Is makes errors.Is(err, ErrQueryLimit) report true for a QueryLimitError
+func (e *QueryLimitError) Is(target error) bool {
+	return target == ErrQueryLimit
+}
+
+// ErrNotLoaded matches errors of relation accessors whose relation wasn't fetched with With
+var ErrNotLoaded = errors.New("relation not loaded")
+
+// NotLoadedError is returned by relation accessors if the relation wasn't fetched with With, when the client is
+// generated with relationAccessors = "error". It matches ErrNotLoaded with errors.Is.
+type NotLoadedError struct {
+	// Model is the name of the model the relation belongs to
+	Model string
+	// Relation is the name of the relation field
+	Relation string
+}
+
+func (e *NotLoadedError) Error() string {
+	return fmt.Sprintf("relation %s.%s was not fetched using the .With() syntax", e.Model, e.Relation)
+}
+
+// Is makes errors.Is(err, ErrNotLoaded) report true for a NotLoadedError
+func (e *NotLoadedError) Is(target error) bool {
+	return target == ErrNotLoaded
+}
+
+// ErrDecode matches errors of results which don't match the Go types of the client when decoding strictly
+var ErrDecode = errors.New("strict decoding failed")
+
+// DecodeError is returned when decoding strictly and a result contains a field which the Go type doesn't know, e.g.
+// after a schema change the client wasn't regenerated for, or misses a field which the query requested. It matches
+// ErrDecode with errors.Is.
+type DecodeError struct {
+	// Type is the Go type which the result is decoded into
+	Type string
+	// Field is the name of the unknown or missing field
+	Field string
+	// Missing is true if the field was requested but not returned, and false if it's unknown
+	Missing bool
+}
+
+func (e *DecodeError) Error() string {
+	if e.Missing {
+		return fmt.Sprintf("strict decoding: result of %s is missing the field %s", e.Type, e.Field)
+	}
+	return fmt.Sprintf("strict decoding: result of %s contains the unknown field %s", e.Type, e.Field)
+}
+
+// Is makes errors.Is(err, ErrDecode) report true for a DecodeError
+func (e *DecodeError) Is(target error) bool {
+	return target == ErrDecode
+}
+
+// ErrInvalidFilter matches errors of untyped filters which don't match the model
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// InvalidFilterError is returned when building filters from untyped input, e.g. with FilterFromMap, and a key refers
+// to an unknown field or operation, or a value doesn't match the type of the field. It matches ErrInvalidFilter with
+// errors.Is.
+type InvalidFilterError struct {
+	// Model is the name of the model which is filtered
+	Model string
+	// Key is the key of the filter
+	Key string
+	// Reason describes why the filter is invalid
+	Reason string
+}
+
+func (e *InvalidFilterError) Error() string {
+	return fmt.Sprintf("invalid filter %s on %s: %s", e.Key, e.Model, e.Reason)
+}
+
+// Is makes errors.Is(err, ErrInvalidFilter) report true for an InvalidFilterError
+func (e *InvalidFilterError) Is(target error) bool {
+	return target == ErrInvalidFilter
+}
+
+// ErrStaleRecord matches errors of updates with UpdateIfVersion whose record no longer has the expected version
+var ErrStaleRecord = errors.New("stale record")
+
+// StaleRecordError is returned by UpdateIfVersion if the record was changed or deleted since it was read, so that no
+// record with the expected version exists anymore. It matches ErrStaleRecord with errors.Is.
+type StaleRecordError struct {
+	// Model is the name of the updated model
+	Model string
+	// Version is the expected version which didn't match
+	Version int
+}
+
+func (e *StaleRecordError) Error() string {
+	return fmt.Sprintf("%s with version %d was changed or deleted in the meantime", e.Model, e.Version)
+}
+
+// Is makes errors.Is(err, ErrStaleRecord) report true for a StaleRecordError
+func (e *StaleRecordError) Is(target error) bool {
+	return target == ErrStaleRecord
+}
+
+// ErrTxConflict matches errors of transactions which failed due to a write conflict or a deadlock (P2034)
+var ErrTxConflict = &protocol.ErrorClass{
+	Name:  "transaction conflict",
+	Codes: []string{"P2034"},
+}
+
+// ErrConnection matches errors of connections to the database or the query engine which failed or were reset
+var ErrConnection = &protocol.ErrorClass{
+	Name:  "connection error",
+	Codes: []string{"P1001", "P1002", "P1017"},
+}
+
 type F interface {
 	~string
 }
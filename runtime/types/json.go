@@ -0,0 +1,51 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// JSONField is a single field of a JSON object encoded by MarshalOrdered
+type JSONField struct {
+	Name  string
+	Value interface{}
+	// Omit skips the field entirely
+	Omit bool
+}
+
+// MarshalOrdered encodes fields as a JSON object, keeping the order in which they are given
+func MarshalOrdered(fields []JSONField) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+
+	first := true
+	for _, f := range fields {
+		if f.Omit {
+			continue
+		}
+
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+
+		key, err := json.Marshal(f.Name)
+		if err != nil {
+			return nil, fmt.Errorf("marshal key %s: %w", f.Name, err)
+		}
+
+		value, err := json.Marshal(f.Value)
+		if err != nil {
+			return nil, fmt.Errorf("marshal field %s: %w", f.Name, err)
+		}
+
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
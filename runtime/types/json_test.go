@@ -0,0 +1,21 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalOrdered(t *testing.T) {
+	var name *string
+	data, err := MarshalOrdered([]JSONField{
+		{Name: "z", Value: 1},
+		{Name: "a", Value: "b"},
+		{Name: "skipped", Value: 2, Omit: true},
+		{Name: "name", Value: name},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, `{"z":1,"a":"b","name":null}`, string(data))
+}
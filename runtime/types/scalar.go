@@ -0,0 +1,150 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// scalarCodec converts a custom scalar type from and to the wire format of the query engine
+type scalarCodec struct {
+	encode func(v interface{}) (interface{}, error)
+	decode func(data []byte) (interface{}, error)
+}
+
+var (
+	scalarCodecs   = map[reflect.Type]scalarCodec{}
+	scalarCodecsMu sync.RWMutex
+)
+
+// RegisterScalar registers how values of a custom scalar type T are converted from and to the wire format of the
+// query engine, for types which are used with the typeOverrides generator option but don't encode to the format the
+// engine expects, e.g. a date type which isn't encoded as an RFC 3339 string. encode returns a value which is encoded
+// as JSON, and decode receives the JSON value returned by the engine. Types without a registered codec are encoded and
+// decoded with encoding/json. Register codecs before sending queries, e.g. in an init function.
+func RegisterScalar[T any](encode func(v T) (interface{}, error), decode func(data []byte) (T, error)) {
+	scalarCodecsMu.Lock()
+	defer scalarCodecsMu.Unlock()
+
+	scalarCodecs[reflect.TypeOf((*T)(nil)).Elem()] = scalarCodec{
+		encode: func(v interface{}) (interface{}, error) {
+			return encode(v.(T))
+		},
+		decode: func(data []byte) (interface{}, error) {
+			return decode(data)
+		},
+	}
+}
+
+func lookupScalar(t reflect.Type) (scalarCodec, bool) {
+	scalarCodecsMu.RLock()
+	defer scalarCodecsMu.RUnlock()
+	c, ok := scalarCodecs[t]
+	return c, ok
+}
+
+func hasScalars() bool {
+	scalarCodecsMu.RLock()
+	defer scalarCodecsMu.RUnlock()
+	return len(scalarCodecs) > 0
+}
+
+// EncodeScalar converts values of registered scalar types, pointers and slices of them to their wire format. Other
+// values are returned unchanged.
+func EncodeScalar(value interface{}) (interface{}, error) {
+	if value == nil || !hasScalars() {
+		return value, nil
+	}
+
+	v := reflect.ValueOf(value)
+	if c, ok := lookupScalar(v.Type()); ok {
+		return c.encode(value)
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if _, ok := lookupScalar(v.Type().Elem()); !ok {
+			return value, nil
+		}
+		if v.IsNil() {
+			return nil, nil
+		}
+		return EncodeScalar(v.Elem().Interface())
+	case reflect.Slice:
+		if _, ok := lookupScalar(v.Type().Elem()); !ok {
+			return value, nil
+		}
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			item, err := EncodeScalar(v.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	}
+
+	return value, nil
+}
+
+// DecodeScalar decodes a value returned by the query engine into v, which points to a scalar type, a pointer or a
+// slice of one. Registered scalar types are decoded with their codec, other types with encoding/json. Missing values
+// leave v unchanged.
+func DecodeScalar(data json.RawMessage, v interface{}) error {
+	if len(data) == 0 {
+		return nil
+	}
+	return decodeScalar(data, reflect.ValueOf(v).Elem())
+}
+
+func decodeScalar(data []byte, target reflect.Value) error {
+	if c, ok := lookupScalar(target.Type()); ok {
+		value, err := c.decode(data)
+		if err != nil {
+			return fmt.Errorf("decode %s: %w", target.Type(), err)
+		}
+		target.Set(reflect.ValueOf(value))
+		return nil
+	}
+
+	switch target.Kind() {
+	case reflect.Ptr:
+		if _, ok := lookupScalar(target.Type().Elem()); !ok {
+			break
+		}
+		if string(data) == "null" {
+			target.Set(reflect.Zero(target.Type()))
+			return nil
+		}
+		p := reflect.New(target.Type().Elem())
+		if err := decodeScalar(data, p.Elem()); err != nil {
+			return err
+		}
+		target.Set(p)
+		return nil
+	case reflect.Slice:
+		if _, ok := lookupScalar(target.Type().Elem()); !ok {
+			break
+		}
+		if string(data) == "null" {
+			target.Set(reflect.Zero(target.Type()))
+			return nil
+		}
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+		s := reflect.MakeSlice(target.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := decodeScalar(item, s.Index(i)); err != nil {
+				return err
+			}
+		}
+		target.Set(s)
+		return nil
+	}
+
+	return json.Unmarshal(data, target.Addr().Interface())
+}
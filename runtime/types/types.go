@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
 	"strconv"
 	"time"
 
@@ -28,16 +29,20 @@ type Bytes = []byte
 // BigInt is a type alias for int64
 type BigInt int64
 
-// UnmarshalJSON converts the Prisma QE value of string to int64
+// UnmarshalJSON converts the Prisma QE value of string to int64. Plain JSON numbers are accepted as well, and values
+// which don't fit into an int64 return an error instead of being truncated.
 func (m *BigInt) UnmarshalJSON(data []byte) error {
 	if m == nil {
 		return errors.New("BigInt: UnmarshalJSON on nil pointer")
 	}
-	str, err := strconv.Unquote(string(data))
+	str, err := unquoteNumber(data)
 	if err != nil {
 		return fmt.Errorf("BigInt: unquote: %w", err)
 	}
 	i, err := strconv.ParseInt(str, 10, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		return fmt.Errorf("BigInt: value %s overflows int64; use bigIntType = \"big.Int\" in the generator config", str)
+	}
 	if err != nil {
 		return fmt.Errorf("BigInt: UnmarshalJSON error: %w", err)
 	}
@@ -53,6 +58,82 @@ func (m *BigInt) MarshalJSON() ([]byte, error) {
 	return []byte(fmt.Sprintf("\"%d\"", *m)), nil
 }
 
+// BigInteger is an arbitrary-precision integer based on math/big.Int. It's used for BigInt fields if the generator
+// config sets bigIntType = "big.Int". It's encoded as a JSON string, so that it keeps its precision in JSON clients
+// which decode numbers as floats. The zero value is 0. A BigInteger is immutable, so that copies of records and query
+// params never share their value.
+type BigInteger struct {
+	v *big.Int
+}
+
+// NewBigInteger returns a BigInteger with the value of i. Later changes to i don't affect the result.
+func NewBigInteger(i *big.Int) BigInteger {
+	if i == nil {
+		return BigInteger{}
+	}
+	return BigInteger{v: new(big.Int).Set(i)}
+}
+
+// Int returns a copy of b as a *big.Int, which can be changed without affecting b
+func (b BigInteger) Int() *big.Int {
+	if b.v == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(b.v)
+}
+
+// Cmp compares b and x and returns -1 if b < x, 0 if b == x and +1 if b > x
+func (b BigInteger) Cmp(x BigInteger) int {
+	return b.value().Cmp(x.value())
+}
+
+// String returns the decimal representation of b
+func (b BigInteger) String() string {
+	return b.value().String()
+}
+
+// MarshalJSON encodes b as a JSON string
+func (b BigInteger) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(b.String())), nil
+}
+
+// UnmarshalJSON decodes a JSON string or number into b
+func (b *BigInteger) UnmarshalJSON(data []byte) error {
+	if b == nil {
+		return errors.New("BigInteger: UnmarshalJSON on nil pointer")
+	}
+	if string(data) == "null" {
+		return nil
+	}
+	str, err := unquoteNumber(data)
+	if err != nil {
+		return fmt.Errorf("BigInteger: unquote: %w", err)
+	}
+	// decode into a new value, as the current one may be shared with copies of b
+	v, ok := new(big.Int).SetString(str, 10)
+	if !ok {
+		return fmt.Errorf("BigInteger: invalid integer %q", str)
+	}
+	b.v = v
+	return nil
+}
+
+// value returns the value of b without copying it, which must not be changed
+func (b BigInteger) value() *big.Int {
+	if b.v == nil {
+		return new(big.Int)
+	}
+	return b.v
+}
+
+// unquoteNumber returns the text of a JSON number, which may be encoded as a string
+func unquoteNumber(data []byte) (string, error) {
+	if len(data) > 0 && data[0] == '"' {
+		return strconv.Unquote(string(data))
+	}
+	return string(data), nil
+}
+
 // JSON is a new type which implements the correct internal prisma (un)marshaller
 type JSON json.RawMessage
 
@@ -76,3 +157,29 @@ func (m *JSON) UnmarshalJSON(data []byte) error {
 	*m = append((*m)[0:0], str...)
 	return nil
 }
+
+// MustJSON encodes v as the value of a Json field. It is used for Json fields which are mapped to Go types and panics
+// if v can't be encoded, just like query values which can't be encoded.
+func MustJSON(v interface{}) JSON {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Errorf("JSON: could not encode %T: %w", v, err))
+	}
+	return data
+}
+
+// DecodeJSON decodes the value of a Json field into v. The query engine returns Json values as JSON strings, but plain
+// JSON is accepted as well, e.g. when models were encoded by the client itself. Missing values leave v unchanged.
+func DecodeJSON(data json.RawMessage, v interface{}) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if data[0] == '"' {
+		str, err := strconv.Unquote(string(data))
+		if err != nil {
+			return fmt.Errorf("JSON: DecodeJSON error: %w", err)
+		}
+		data = []byte(str)
+	}
+	return json.Unmarshal(data, v)
+}
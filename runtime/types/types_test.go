@@ -0,0 +1,199 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBigInt_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  BigInt
+		err   string
+	}{{
+		name:  "string",
+		input: `"9007199254740993"`,
+		want:  9007199254740993,
+	}, {
+		name:  "number",
+		input: `9007199254740993`,
+		want:  9007199254740993,
+	}, {
+		name:  "overflow",
+		input: `"9223372036854775808"`,
+		err:   `BigInt: value 9223372036854775808 overflows int64; use bigIntType = "big.Int" in the generator config`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var actual BigInt
+			err := json.Unmarshal([]byte(tt.input), &actual)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, actual)
+		})
+	}
+}
+
+func TestBigInteger(t *testing.T) {
+	i, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	b := NewBigInteger(i)
+
+	data, err := json.Marshal(b)
+	assert.NoError(t, err)
+	assert.Equal(t, `"123456789012345678901234567890"`, string(data))
+
+	var actual struct {
+		A BigInteger  `json:"a"`
+		B BigInteger  `json:"b"`
+		C *BigInteger `json:"c"`
+	}
+	err = json.Unmarshal([]byte(`{"a":"123456789012345678901234567890","b":-5,"c":null}`), &actual)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, i.Cmp(actual.A.Int()))
+	assert.Equal(t, "-5", actual.B.String())
+	assert.Nil(t, actual.C)
+}
+
+func TestBigInteger_copies(t *testing.T) {
+	i := big.NewInt(5)
+	a := NewBigInteger(i)
+	i.SetInt64(6)
+	assert.Equal(t, "5", a.String())
+
+	b := a
+	b.Int().SetInt64(7)
+	assert.NoError(t, json.Unmarshal([]byte(`"8"`), &b))
+	assert.Equal(t, "5", a.String())
+	assert.Equal(t, "8", b.String())
+	assert.Equal(t, -1, a.Cmp(b))
+
+	var zero BigInteger
+	assert.Equal(t, "0", zero.String())
+	assert.Equal(t, 0, zero.Cmp(NewBigInteger(big.NewInt(0))))
+}
+
+func TestDecodeJSON(t *testing.T) {
+	type settings struct {
+		Theme string `json:"theme"`
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  *settings
+	}{
+		{name: "engine string", input: `"{\"theme\":\"dark\"}"`, want: &settings{Theme: "dark"}},
+		{name: "plain json", input: `{"theme":"dark"}`, want: &settings{Theme: "dark"}},
+		{name: "null", input: `null`, want: nil},
+		{name: "missing", input: ``, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *settings
+			assert.NoError(t, DecodeJSON(json.RawMessage(tt.input), &got))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMustJSON(t *testing.T) {
+	assert.Equal(t, JSON(`{"theme":"dark"}`), MustJSON(map[string]string{"theme": "dark"}))
+	assert.Panics(t, func() {
+		MustJSON(make(chan int))
+	})
+}
+
+type testCelsius float64
+
+func TestScalarCodec(t *testing.T) {
+	RegisterScalar(func(v testCelsius) (interface{}, error) {
+		return fmt.Sprintf("%.1fC", float64(v)), nil
+	}, func(data []byte) (testCelsius, error) {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return 0, err
+		}
+		var v float64
+		_, err := fmt.Sscanf(s, "%fC", &v)
+		return testCelsius(v), err
+	})
+
+	c := testCelsius(21.5)
+
+	encoded, err := EncodeScalar(c)
+	assert.NoError(t, err)
+	assert.Equal(t, "21.5C", encoded)
+
+	encoded, err = EncodeScalar(&c)
+	assert.NoError(t, err)
+	assert.Equal(t, "21.5C", encoded)
+
+	encoded, err = EncodeScalar((*testCelsius)(nil))
+	assert.NoError(t, err)
+	assert.Nil(t, encoded)
+
+	encoded, err = EncodeScalar([]testCelsius{1, 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"1.0C", "2.0C"}, encoded)
+
+	encoded, err = EncodeScalar("unchanged")
+	assert.NoError(t, err)
+	assert.Equal(t, "unchanged", encoded)
+
+	var value testCelsius
+	assert.NoError(t, DecodeScalar(json.RawMessage(`"21.5C"`), &value))
+	assert.Equal(t, c, value)
+
+	var ptr *testCelsius
+	assert.NoError(t, DecodeScalar(json.RawMessage(`"3.0C"`), &ptr))
+	assert.Equal(t, testCelsius(3), *ptr)
+	assert.NoError(t, DecodeScalar(json.RawMessage(`null`), &ptr))
+	assert.Nil(t, ptr)
+
+	var list []testCelsius
+	assert.NoError(t, DecodeScalar(json.RawMessage(`["1.0C","2.0C"]`), &list))
+	assert.Equal(t, []testCelsius{1, 2}, list)
+
+	var plain string
+	assert.NoError(t, DecodeScalar(json.RawMessage(`"plain"`), &plain))
+	assert.Equal(t, "plain", plain)
+}
+
+func TestCollation_Compare(t *testing.T) {
+	a, b := "a", "B"
+	tests := []struct {
+		name      string
+		collation Collation
+		x, y      interface{}
+		desc      bool
+		want      int
+	}{
+		{name: "ints", x: 1, y: 2, want: -1},
+		{name: "ints desc", x: 1, y: 2, desc: true, want: 1},
+		{name: "floats", x: 2.5, y: 2.5, want: 0},
+		{name: "bools", x: true, y: false, want: 1},
+		{name: "times", x: time.Unix(2, 0), y: time.Unix(1, 0), want: 1},
+		{name: "decimals", x: decimal.RequireFromString("10.5"), y: decimal.RequireFromString("9.5"), want: 1},
+		{name: "strings", x: &a, y: &b, want: 1},
+		{name: "case-insensitive strings", collation: CollationOf("mysql"), x: &a, y: &b, want: -1},
+		{name: "nulls last", collation: CollationOf("postgresql"), x: (*string)(nil), y: &a, want: 1},
+		{name: "nulls last desc", collation: CollationOf("postgresql"), x: (*string)(nil), y: &a, desc: true, want: -1},
+		{name: "nulls first", collation: CollationOf("sqlite"), x: (*string)(nil), y: &a, want: -1},
+		{name: "both null", x: (*int)(nil), y: nil, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.collation.Compare(tt.x, tt.y, tt.desc))
+		})
+	}
+}
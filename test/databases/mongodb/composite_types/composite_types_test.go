@@ -0,0 +1,137 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/steebchen/prisma-client-go/test"
+	"github.com/steebchen/prisma-client-go/test/helpers/massert"
+)
+
+type cx = context.Context
+type Func func(t *testing.T, client *PrismaClient, ctx cx)
+
+func TestCompositeTypes(t *testing.T) {
+	t.Parallel()
+
+	zip := "10115"
+
+	tests := []struct {
+		name   string
+		before []string
+		run    Func
+	}{{
+		name: "create and find",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			created, err := client.User.CreateOne(
+				User.Name.Set("alice"),
+				User.Address.Set(Address{
+					Street: "Main St",
+					City:   "Berlin",
+					Zip:    &zip,
+					Geo:    &Geo{Lat: 52.5, Lng: 13.4},
+				}),
+				User.Photos.Set([]Photo{{
+					URL:    "a.png",
+					Height: 100,
+					Tags:   []string{"a"},
+				}}),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			expected := &UserModel{
+				InnerUser: InnerUser{
+					ID:   created.ID,
+					Name: "alice",
+					Address: Address{
+						Street: "Main St",
+						City:   "Berlin",
+						Zip:    &zip,
+						Geo:    &Geo{Lat: 52.5, Lng: 13.4},
+					},
+					Photos: []Photo{{
+						URL:    "a.png",
+						Height: 100,
+						Tags:   []string{"a"},
+					}},
+				},
+			}
+
+			massert.Equal(t, expected, created)
+
+			actual, err := client.User.FindFirst(
+				User.Address.Is(
+					AddressWhere.City.Equals("Berlin"),
+					AddressWhere.Geo.Is(GeoWhere.Lat.Gt(50)),
+				),
+				User.Billing.IsSet(false),
+				User.Photos.Some(PhotoWhere.Height.Gte(100)),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			massert.Equal(t, expected, actual)
+		},
+	}, {
+		name: "set, push and unset",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			created, err := client.User.CreateOne(
+				User.Name.Set("bob"),
+				User.Address.Set(Address{Street: "Main St", City: "Berlin"}),
+				User.Billing.Set(Address{Street: "Side St", City: "Hamburg"}),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			updated, err := client.User.FindUnique(
+				User.ID.Equals(created.ID),
+			).Update(
+				User.Address.Set(Address{Street: "Other St", City: "Munich"}),
+				User.Billing.Unset(),
+				User.Photos.Push(Photo{URL: "a.png", Height: 1}, Photo{URL: "b.png", Height: 2}),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			expected := &UserModel{
+				InnerUser: InnerUser{
+					ID:      created.ID,
+					Name:    "bob",
+					Address: Address{Street: "Other St", City: "Munich"},
+					Photos: []Photo{
+						{URL: "a.png", Height: 1, Tags: []string{}},
+						{URL: "b.png", Height: 2, Tags: []string{}},
+					},
+				},
+			}
+
+			massert.Equal(t, expected, updated)
+
+			users, err := client.User.FindMany(
+				User.Photos.Every(PhotoWhere.Height.Lt(3)),
+				User.Photos.IsEmpty(false),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			massert.Equal(t, 1, len(users))
+		},
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient()
+
+			mockDB := test.Start(t, test.MongoDB, client.Engine, tt.before)
+			defer test.End(t, test.MongoDB, client.Engine, mockDB)
+
+			tt.run(t, client, context.Background())
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/steebchen/prisma-client-go/test"
+	"github.com/steebchen/prisma-client-go/test/helpers/massert"
+)
+
+type cx = context.Context
+type Func func(t *testing.T, client *PrismaClient, ctx cx)
+
+func TestRaw(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		before []string
+		run    Func
+	}{{
+		name: "find raw",
+		// language=GraphQL
+		before: []string{`
+			mutation {
+				result: createOneUser(data: {
+					name: "a",
+					age: 20,
+				}) {
+					id
+				}
+			}
+		`, `
+			mutation {
+				result: createOneUser(data: {
+					name: "b",
+					age: 30,
+				}) {
+					id
+				}
+			}
+		`},
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			var users []struct {
+				Name string `json:"name"`
+			}
+			err := client.User.FindRaw(
+				map[string]interface{}{"age": map[string]interface{}{"$gt": 25}},
+				`{"projection": {"name": 1}}`,
+			).ExecInto(ctx, &users)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			massert.Equal(t, 1, len(users))
+			massert.Equal(t, "b", users[0].Name)
+		},
+	}, {
+		name: "aggregate raw",
+		// language=GraphQL
+		before: []string{`
+			mutation {
+				result: createOneUser(data: {
+					name: "a",
+					age: 20,
+				}) {
+					id
+				}
+			}
+		`, `
+			mutation {
+				result: createOneUser(data: {
+					name: "b",
+					age: 30,
+				}) {
+					id
+				}
+			}
+		`},
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			var result []struct {
+				Total int `json:"total"`
+			}
+			err := client.User.AggregateRaw([]interface{}{
+				map[string]interface{}{"$group": map[string]interface{}{"_id": nil, "total": map[string]interface{}{"$sum": "$age"}}},
+			}).ExecInto(ctx, &result)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			massert.Equal(t, 1, len(result))
+			massert.Equal(t, 50, result[0].Total)
+		},
+	}, {
+		name: "run command raw",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			var result json.RawMessage
+			if err := client.Prisma.RunCommandRaw(map[string]interface{}{"ping": 1}).Exec(ctx, &result); err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			var ping struct {
+				OK float64 `json:"ok"`
+			}
+			if err := json.Unmarshal(result, &ping); err != nil {
+				t.Fatalf("fail %s", err)
+			}
+			massert.Equal(t, 1.0, ping.OK)
+		},
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient()
+
+			mockDB := test.Start(t, test.MongoDB, client.Engine, tt.before)
+			defer test.End(t, test.MongoDB, client.Engine, mockDB)
+
+			tt.run(t, client, context.Background())
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/steebchen/prisma-client-go/test"
+	"github.com/steebchen/prisma-client-go/test/helpers/massert"
+)
+
+type cx = context.Context
+type Func func(t *testing.T, client *PrismaClient, ctx cx)
+
+func TestRelationJoins(t *testing.T) {
+	t.Parallel()
+
+	// language=GraphQL
+	before := []string{`
+		mutation {
+			result: createOneUser(data: {
+				id: "a",
+				name: "john",
+				posts: {
+					create: [{
+						id: "a1",
+						title: "first",
+					}, {
+						id: "a2",
+						title: "second",
+					}],
+				},
+			}) {
+				id
+			}
+		}
+	`}
+
+	expected := []UserModel{{
+		InnerUser: InnerUser{
+			ID:   "a",
+			Name: "john",
+		},
+		RelationsUser: RelationsUser{
+			Posts: []PostModel{{
+				InnerPost: InnerPost{
+					ID:       "a1",
+					Title:    "first",
+					AuthorID: "a",
+				},
+			}, {
+				InnerPost: InnerPost{
+					ID:       "a2",
+					Title:    "second",
+					AuthorID: "a",
+				},
+			}},
+		},
+	}}
+
+	tests := []struct {
+		name string
+		run  Func
+	}{{
+		name: "join",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			users, err := client.User.FindMany().With(
+				User.Posts.Fetch().OrderBy(Post.ID.Order(SortOrderAsc)),
+			).WithStrategy(RelationJoin).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			massert.Equal(t, expected, users)
+		},
+	}, {
+		name: "query",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			users, err := client.User.FindMany().With(
+				User.Posts.Fetch().OrderBy(Post.ID.Order(SortOrderAsc)),
+			).WithStrategy(RelationQuery).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			massert.Equal(t, expected, users)
+		},
+	}, {
+		name: "find unique with join",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			user, err := client.User.FindUnique(
+				User.ID.Equals("a"),
+			).With(
+				User.Posts.Fetch().OrderBy(Post.ID.Order(SortOrderAsc)),
+			).WithStrategy(RelationJoin).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			massert.Equal(t, &expected[0], user)
+		},
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient()
+
+			mockDB := test.Start(t, test.PostgreSQL, client.Engine, before)
+			defer test.End(t, test.PostgreSQL, client.Engine, mockDB)
+
+			tt.run(t, client, context.Background())
+		})
+	}
+}
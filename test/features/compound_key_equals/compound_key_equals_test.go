@@ -0,0 +1,133 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/steebchen/prisma-client-go/test"
+	"github.com/steebchen/prisma-client-go/test/helpers/massert"
+)
+
+type cx = context.Context
+type Func func(t *testing.T, client *PrismaClient, ctx cx)
+
+// language=GraphQL
+var before = []string{`
+	mutation {
+		result: createOneOrganization(data: {
+			platformKind: "github",
+			platformId: "123",
+			name: "a",
+		}) {
+			platformId
+		}
+	}
+`, `
+	mutation {
+		result: createOneOrganization(data: {
+			platformKind: "gitlab",
+			platformId: "123",
+			name: "b",
+		}) {
+			platformId
+		}
+	}
+`, `
+	mutation {
+		result: createOneMembership(data: {
+			id: "m",
+			teamID: 1,
+			role: Admin,
+			email: "alice@example.com",
+		}) {
+			id
+		}
+	}
+`}
+
+func TestCompoundKeyEquals(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		before []string
+		run    Func
+	}{{
+		name:   "find unique",
+		before: before,
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			actual, err := client.Organization.FindUnique(
+				Organization.OrganizationIDEquals("gitlab", "123"),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			expected := &OrganizationModel{
+				InnerOrganization: InnerOrganization{
+					PlatformKind: "gitlab",
+					PlatformID:   "123",
+					Name:         "b",
+				},
+			}
+
+			massert.Equal(t, expected, actual)
+		},
+	}, {
+		name:   "find unique with enum and int fields",
+		before: before,
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			actual, err := client.Membership.FindUnique(
+				Membership.TeamIDRoleEmailEquals(1, RoleAdmin, "alice@example.com"),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+			massert.Equal(t, "m", actual.ID)
+
+			_, err = client.Membership.FindUnique(
+				Membership.TeamIDRoleEmailEquals(1, RoleMember, "alice@example.com"),
+			).Exec(ctx)
+			massert.Equal(t, true, errors.Is(err, ErrNotFound))
+		},
+	}, {
+		name:   "update and delete",
+		before: before,
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			updated, err := client.Organization.FindUnique(
+				Organization.OrganizationIDEquals("github", "123"),
+			).Update(
+				Organization.Name.Set("c"),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+			massert.Equal(t, "c", updated.Name)
+
+			if _, err := client.Organization.FindUnique(
+				Organization.OrganizationIDEquals("github", "123"),
+			).Delete().Exec(ctx); err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			remaining, err := client.Organization.FindMany().Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+			massert.Equal(t, 1, len(remaining))
+			massert.Equal(t, "gitlab", remaining[0].PlatformKind)
+		},
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test.RunSerial(t, []test.Database{test.MySQL, test.PostgreSQL}, func(t *testing.T, db test.Database, ctx context.Context) {
+				client := NewClient()
+				mockDBName := test.Start(t, db, client.Engine, tt.before)
+				defer test.End(t, db, client.Engine, mockDBName)
+				tt.run(t, client, context.Background())
+			})
+		})
+	}
+}
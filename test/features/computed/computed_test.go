@@ -0,0 +1,108 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/steebchen/prisma-client-go/test"
+	"github.com/steebchen/prisma-client-go/test/helpers/massert"
+)
+
+type cx = context.Context
+type Func func(t *testing.T, client *PrismaClient, ctx cx)
+
+// generatedColumn replaces the total column with a column generated by SQLite, which Prisma can't create
+var generatedColumn = []string{
+	`ALTER TABLE "Order" DROP COLUMN "total"`,
+	`ALTER TABLE "Order" ADD COLUMN "total" INTEGER GENERATED ALWAYS AS ("price" * "quantity") VIRTUAL`,
+}
+
+func TestComputed(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		before []string
+		run    Func
+	}{{
+		name: "create without computed field",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			// Order.Total has no setter
+			actual, err := client.Order.CreateOne(
+				Order.Price.Set(10),
+				Order.Quantity.Set(2),
+				Order.ID.Set("123"),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			total := 20
+			expected := &OrderModel{
+				InnerOrder: InnerOrder{
+					ID:       "123",
+					Price:    10,
+					Quantity: 2,
+					Total:    &total,
+				},
+			}
+
+			massert.Equal(t, expected, actual)
+		},
+	}, {
+		name: "filter by computed field",
+		// language=GraphQL
+		before: []string{`
+			mutation {
+				result: createOneOrder(data: {
+					id: "123",
+					price: 10,
+					quantity: 2,
+				}) {
+					id
+				}
+			}
+		`},
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			actual, err := client.Order.FindMany(
+				Order.Total.Equals(20),
+			).Update(
+				Order.Quantity.Increment(1),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			massert.Equal(t, 1, actual.Count)
+
+			// the database computes the value again after the update
+			order, err := client.Order.FindUnique(
+				Order.ID.Equals("123"),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			total, _ := order.Total()
+			massert.Equal(t, 30, total)
+		},
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test.RunSerial(t, []test.Database{test.SQLite}, func(t *testing.T, db test.Database, ctx context.Context) {
+				client := NewClient()
+				mockDBName := test.Start(t, db, client.Engine, tt.before)
+				defer test.End(t, db, client.Engine, mockDBName)
+
+				for _, q := range generatedColumn {
+					if _, err := client.Prisma.ExecuteRaw(q).Exec(ctx); err != nil {
+						t.Fatalf("could not create generated column: %s", err)
+					}
+				}
+
+				tt.run(t, client, context.Background())
+			})
+		})
+	}
+}
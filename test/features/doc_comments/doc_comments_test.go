@@ -0,0 +1,87 @@
+package db
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/steebchen/prisma-client-go/test/helpers/massert"
+)
+
+// docs returns the doc comments of the type declarations and struct fields in the generated code, keyed by the type
+// name and Type.Field for fields
+func docs(t *testing.T) map[string]string {
+	t.Helper()
+
+	file, err := parser.ParseFile(token.NewFileSet(), "db_gen.go", nil, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("fail %s", err)
+	}
+
+	result := map[string]string{}
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			typ := spec.(*ast.TypeSpec)
+			if gen.Doc != nil {
+				result[typ.Name.Name] = gen.Doc.Text()
+			}
+			s, ok := typ.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+			for _, field := range s.Fields.List {
+				if field.Doc == nil {
+					continue
+				}
+				for _, name := range field.Names {
+					result[typ.Name.Name+"."+name.Name] = field.Doc.Text()
+				}
+			}
+		}
+	}
+	return result
+}
+
+func TestDocComments(t *testing.T) {
+	t.Parallel()
+
+	d := docs(t)
+
+	massert.Equal(t, "PostModel represents the Post model and is a wrapper for accessing fields and methods\n\nA blog post, written by a user.\n\nPosts are listed by their title.\n", d["PostModel"])
+	massert.Equal(t, "The headline, shown in listings.\nIt must not be empty.\n", d["InnerPost.Title"])
+	massert.Equal(t, "Deprecated: use title instead\n", d["InnerPost.Headline"])
+	massert.Equal(t, "Glob of the paths it's shown on, e.g. /blog/*/ or /*/ ; a block comment would end at */\n", d["InnerPost.Path"])
+	massert.Equal(t, "The author of the post.\n", d["RelationsPost.Author"])
+	massert.Equal(t, "Role of a user, which determines their permissions.\n", d["Role"])
+
+	// fields without documentation have no doc comment
+	massert.Equal(t, "", d["InnerPost.AuthorID"])
+	massert.Equal(t, false, strings.Contains(d["InnerPost.Path"], "@gotag"))
+}
+
+func TestDeprecatedAccessor(t *testing.T) {
+	t.Parallel()
+
+	file, err := parser.ParseFile(token.NewFileSet(), "db_gen.go", nil, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("fail %s", err)
+	}
+
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Name.Name != "Headline" || fn.Recv == nil {
+			continue
+		}
+		if recv, ok := fn.Recv.List[0].Type.(*ast.Ident); ok && recv.Name == "PostModel" {
+			massert.Equal(t, "Deprecated: use title instead\n", fn.Doc.Text())
+			return
+		}
+	}
+	t.Fatalf("PostModel.Headline not found")
+}
@@ -0,0 +1,130 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/steebchen/prisma-client-go/test"
+	"github.com/steebchen/prisma-client-go/test/helpers/massert"
+)
+
+type cx = context.Context
+type Func func(t *testing.T, client *PrismaClient, ctx cx)
+
+func TestExists(t *testing.T) {
+	t.Parallel()
+
+	// language=GraphQL
+	before := []string{`
+		mutation {
+			a: createOneUser(data: { id: "a", email: "a@example.com", team: "red" }) { id }
+			b: createOneUser(data: { id: "b", email: "b@example.com", team: "blue" }) { id }
+			c: createOneUser(data: { id: "c", email: "c@example.com" }) { id }
+			o1: createOneOrder(data: { id: "o1", userId: "a", total: 50 }) { id }
+			o2: createOneOrder(data: { id: "o2", userId: "a", total: 200 }) { id }
+			o3: createOneOrder(data: { id: "o3", userId: "b", total: 20 }) { id }
+			i1: createOneInvite(data: { id: "i1", team: "red" }) { id }
+			i2: createOneInvite(data: { id: "i2" }) { id }
+		}
+	`}
+
+	ids := func(users []UserModel) []string {
+		var ids []string
+		for _, u := range users {
+			ids = append(ids, u.ID)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name string
+		run  Func
+	}{{
+		name: "exists",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			users, err := client.User.FindMany(
+				Exists(Order.FindMany(
+					Order.UserID.EqualsRef(User.ID),
+				)),
+			).OrderBy(User.ID.Order(SortOrderAsc)).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			massert.Equal(t, []string{"a", "b"}, ids(users))
+		},
+	}, {
+		name: "exists with conditions",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			users, err := client.User.FindMany(
+				User.Email.Contains("@example.com"),
+				Exists(Order.FindMany(
+					Order.UserID.EqualsRef(User.ID),
+					Order.Total.Gt(100),
+				)),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			massert.Equal(t, []string{"a"}, ids(users))
+		},
+	}, {
+		name: "not exists",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			users, err := client.User.FindMany(
+				NotExists(Order.FindMany(
+					Order.UserID.EqualsRef(User.ID),
+				)),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			massert.Equal(t, []string{"c"}, ids(users))
+		},
+	}, {
+		name: "not exists on optional field",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			users, err := client.User.FindMany(
+				NotExists(Invite.FindMany(
+					Invite.Team.EqualsRef(User.Team),
+				)),
+			).OrderBy(User.ID.Order(SortOrderAsc)).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			massert.Equal(t, []string{"b", "c"}, ids(users))
+		},
+	}, {
+		name: "or",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			users, err := client.User.FindMany(
+				User.Or(
+					User.ID.Equals("c"),
+					Exists(Order.FindMany(
+						Order.UserID.EqualsRef(User.ID),
+						Order.Total.Lt(30),
+					)),
+				),
+			).OrderBy(User.ID.Order(SortOrderAsc)).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			massert.Equal(t, []string{"b", "c"}, ids(users))
+		},
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test.RunSerial(t, test.Databases, func(t *testing.T, db test.Database, ctx context.Context) {
+				client := NewClient()
+				mockDBName := test.Start(t, db, client.Engine, before)
+				defer test.End(t, db, client.Engine, mockDBName)
+				tt.run(t, client, context.Background())
+			})
+		})
+	}
+}
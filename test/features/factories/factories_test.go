@@ -0,0 +1,135 @@
+package db_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/steebchen/prisma-client-go/test"
+	// the factory package imports the client, so the test is in an external package
+	db "github.com/steebchen/prisma-client-go/test/features/factories"
+	"github.com/steebchen/prisma-client-go/test/features/factories/factory"
+	"github.com/steebchen/prisma-client-go/test/helpers/massert"
+)
+
+type cx = context.Context
+type Func func(t *testing.T, client *db.PrismaClient, ctx cx)
+
+func TestFactories(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		run  Func
+	}{{
+		name: "fake scalars and enums",
+		run: func(t *testing.T, client *db.PrismaClient, ctx cx) {
+			user, err := factory.User().Create(ctx, client)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			if !strings.HasPrefix(user.Email, "user") || !strings.HasSuffix(user.Email, "@example.com") {
+				t.Errorf("expected a fake email, got %q", user.Email)
+			}
+			if !strings.HasPrefix(user.Name, "name-") {
+				t.Errorf("expected a fake name, got %q", user.Name)
+			}
+			massert.Equal(t, db.RoleMember, user.Role)
+			massert.Equal(t, false, user.Active)
+			if user.Age == 0 || user.Score == 0 || user.JoinedAt.IsZero() {
+				t.Errorf("expected fake values, got %+v", user.InnerUser)
+			}
+
+			// optional fields are left to the database
+			_, ok := user.Bio()
+			massert.Equal(t, false, ok)
+
+			// fake values are unique
+			other, err := factory.User().Create(ctx, client)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+			if other.Email == user.Email {
+				t.Errorf("expected unique emails, got %q twice", user.Email)
+			}
+		},
+	}, {
+		name: "set fields",
+		run: func(t *testing.T, client *db.PrismaClient, ctx cx) {
+			user, err := factory.User().
+				WithEmail("alice@example.com").
+				WithRole(db.RoleAdmin).
+				WithAge(30).
+				WithBio("hi").
+				Create(ctx, client)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			massert.Equal(t, "alice@example.com", user.Email)
+			massert.Equal(t, db.RoleAdmin, user.Role)
+			massert.Equal(t, 30, user.Age)
+			bio, _ := user.Bio()
+			massert.Equal(t, "hi", bio)
+		},
+	}, {
+		name: "required relation",
+		run: func(t *testing.T, client *db.PrismaClient, ctx cx) {
+			post, err := factory.Post().WithTitle("hello").Create(ctx, client)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			// the author is created by the user factory
+			author, err := client.User.FindUnique(db.User.ID.Equals(post.AuthorID)).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+			massert.Equal(t, "hello", post.Title)
+			massert.Equal(t, db.RoleMember, author.Role)
+
+			_, ok := post.ReviewerID()
+			massert.Equal(t, false, ok)
+		},
+	}, {
+		name: "linked relations",
+		run: func(t *testing.T, client *db.PrismaClient, ctx cx) {
+			author, err := factory.User().Create(ctx, client)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+			reviewer, err := factory.User().Create(ctx, client)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			post, err := factory.Post().WithAuthor(author).WithReviewer(reviewer).Create(ctx, client)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			massert.Equal(t, author.ID, post.AuthorID)
+			reviewerID, _ := post.ReviewerID()
+			massert.Equal(t, reviewer.ID, reviewerID)
+
+			// no other users are created
+			users, err := client.User.FindMany().Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+			massert.Equal(t, 2, len(users))
+		},
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test.RunSerial(t, test.Databases, func(t *testing.T, d test.Database, ctx context.Context) {
+				client := db.NewClient()
+				mockDBName := test.Start(t, d, client.Engine, []string{})
+				defer test.End(t, d, client.Engine, mockDBName)
+				tt.run(t, client, context.Background())
+			})
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/steebchen/prisma-client-go/test"
+	"github.com/steebchen/prisma-client-go/test/helpers/massert"
+)
+
+type cx = context.Context
+type Func func(t *testing.T, client *PrismaClient, ctx cx)
+
+var keys atomic.Int64
+
+func init() {
+	User.Email.Normalize(func(v string) string {
+		return strings.ToLower(strings.TrimSpace(v))
+	})
+	User.Locale.Default(func() string {
+		return "en"
+	})
+	User.APIKey.DefaultFunc(func() string {
+		return fmt.Sprintf("key_%d", keys.Add(1))
+	})
+}
+
+func TestFieldHooks(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		before []string
+		run    Func
+	}{{
+		name: "create and update",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			created, err := client.User.CreateOne(
+				User.Email.Set(" John@Example.com "),
+				User.ID.Set("a"),
+				User.APIKey.Set("key_a"),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			locale := "en"
+			massert.Equal(t, &UserModel{
+				InnerUser: InnerUser{
+					ID:     "a",
+					Email:  "john@example.com",
+					Locale: &locale,
+					APIKey: "key_a",
+				},
+			}, created)
+
+			updated, err := client.User.FindUnique(
+				User.ID.Equals("a"),
+			).Update(
+				User.Email.Set("JANE@example.com"),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			massert.Equal(t, "jane@example.com", updated.Email)
+		},
+	}, {
+		name: "explicit values are not replaced by defaults",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			created, err := client.User.CreateOne(
+				User.Email.Set("john@example.com"),
+				User.Locale.Set("de"),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			locale, _ := created.Locale()
+			massert.Equal(t, "de", locale)
+		},
+	}, {
+		name: "default func",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			a, err := client.User.CreateOne(
+				User.Email.Set("a@example.com"),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			b, err := client.User.CreateOne(
+				User.Email.Set("b@example.com"),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			massert.Equal(t, true, strings.HasPrefix(a.APIKey, "key_"))
+			massert.Equal(t, true, strings.HasPrefix(b.APIKey, "key_"))
+			massert.Equal(t, false, a.APIKey == b.APIKey)
+		},
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test.RunSerial(t, test.Databases, func(t *testing.T, db test.Database, ctx context.Context) {
+				client := NewClient()
+				mockDBName := test.Start(t, db, client.Engine, tt.before)
+				defer test.End(t, db, client.Engine, mockDBName)
+				tt.run(t, client, context.Background())
+			})
+		})
+	}
+}
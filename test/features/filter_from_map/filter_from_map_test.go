@@ -0,0 +1,101 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/steebchen/prisma-client-go/test"
+	"github.com/steebchen/prisma-client-go/test/helpers/massert"
+)
+
+type cx = context.Context
+type Func func(t *testing.T, client *PrismaClient, ctx cx)
+
+func TestFilterFromMap(t *testing.T) {
+	t.Parallel()
+
+	// language=GraphQL
+	before := []string{`
+		mutation {
+			result: createOneUser(data: {
+				id: "a",
+				email: "alice@example.com",
+				age: 30,
+				referrer: "bob",
+			}) {
+				id
+			}
+		}
+	`, `
+		mutation {
+			result: createOneUser(data: {
+				id: "b",
+				email: "bob@example.org",
+				age: 17,
+			}) {
+				id
+			}
+		}
+	`}
+
+	tests := []struct {
+		name string
+		run  Func
+	}{{
+		name: "map",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			params, err := User.FilterFromMap(map[string]interface{}{
+				"email_endsWith": ".com",
+				"age_gte":        "18",
+			})
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			users, err := client.User.FindMany(params...).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			massert.Equal(t, 1, len(users))
+			massert.Equal(t, "a", users[0].ID)
+		},
+	}, {
+		name: "json",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			params, err := User.FilterFromJSON([]byte(`{"referrer": null, "id_in": ["a", "b"]}`))
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			users, err := client.User.FindMany(params...).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			massert.Equal(t, 1, len(users))
+			massert.Equal(t, "b", users[0].ID)
+		},
+	}, {
+		name: "invalid",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			_, err := User.FilterFromMap(map[string]interface{}{
+				"age_contains": "1",
+			})
+
+			massert.Equal(t, true, errors.Is(err, ErrInvalidFilter))
+		},
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test.RunSerial(t, test.Databases, func(t *testing.T, db test.Database, ctx context.Context) {
+				client := NewClient()
+				mockDBName := test.Start(t, db, client.Engine, before)
+				defer test.End(t, db, client.Engine, mockDBName)
+				tt.run(t, client, context.Background())
+			})
+		})
+	}
+}
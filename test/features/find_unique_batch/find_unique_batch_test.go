@@ -0,0 +1,91 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/steebchen/prisma-client-go/test"
+	"github.com/steebchen/prisma-client-go/test/helpers/massert"
+)
+
+type cx = context.Context
+type Func func(t *testing.T, client *PrismaClient, ctx cx)
+
+func TestFindUniqueBatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		before []string
+		run    Func
+	}{{
+		name: "find batch",
+		// language=GraphQL
+		before: []string{`
+			mutation {
+				result: createOneUser(data: {
+					id: "a",
+					email: "alice@example.com",
+					name: "alice",
+				}) {
+					id
+				}
+			}
+		`, `
+			mutation {
+				result: createOneUser(data: {
+					id: "b",
+					email: "bob@example.com",
+					name: "bob",
+				}) {
+					id
+				}
+			}
+		`},
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			users, err := client.User.FindUniqueBatch(ctx, []UserEqualsUniqueWhereParam{
+				User.ID.Equals("a"),
+				User.Email.Equals("bob@example.com"),
+				User.ID.Equals("a"),
+				User.ID.Equals("c"),
+			})
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			massert.Equal(t, 2, len(users))
+
+			alice, ok := users.Get(User.ID.Equals("a"))
+			massert.Equal(t, true, ok)
+			massert.Equal(t, "alice", alice.Name)
+
+			bob, ok := users.Get(User.Email.Equals("bob@example.com"))
+			massert.Equal(t, true, ok)
+			massert.Equal(t, "bob", bob.Name)
+
+			_, ok = users.Get(User.ID.Equals("c"))
+			massert.Equal(t, false, ok)
+		},
+	}, {
+		name: "empty",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			users, err := client.User.FindUniqueBatch(ctx, nil)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			massert.Equal(t, 0, len(users))
+		},
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test.RunSerial(t, test.Databases, func(t *testing.T, db test.Database, ctx context.Context) {
+				client := NewClient()
+				mockDBName := test.Start(t, db, client.Engine, tt.before)
+				defer test.End(t, db, client.Engine, mockDBName)
+				tt.run(t, client, context.Background())
+			})
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/steebchen/prisma-client-go/runtime/gqlgen"
+	"github.com/steebchen/prisma-client-go/runtime/loader"
+	"github.com/steebchen/prisma-client-go/test"
+	"github.com/steebchen/prisma-client-go/test/helpers/massert"
+)
+
+type cx = context.Context
+type Func func(t *testing.T, client *PrismaClient, ctx cx)
+
+func TestGQLGen(t *testing.T) {
+	t.Parallel()
+
+	// language=GraphQL
+	before := []string{`
+		mutation {
+			result: createOneUser(data: {
+				id: "a",
+				name: "alice",
+				posts: {
+					create: [{
+						id: "p",
+						title: "hello",
+					}],
+				},
+			}) {
+				id
+			}
+		}
+	`}
+
+	tests := []struct {
+		name string
+		run  Func
+	}{{
+		name: "with selection",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			// query { users { name posts { title author { name } } } }
+			fields := []gqlgen.Field{
+				{Name: "name"},
+				{Name: "posts", Fields: []gqlgen.Field{
+					{Name: "title"},
+					{Name: "author", Fields: []gqlgen.Field{{Name: "name"}}},
+				}},
+			}
+
+			users, err := client.User.FindMany().With(UserWith(fields)...).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			massert.Equal(t, 1, len(users))
+			massert.Equal(t, "hello", users[0].Posts()[0].Title)
+			massert.Equal(t, "alice", users[0].Posts()[0].Author().Name)
+		},
+	}, {
+		name: "loaders from context",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			ctx = WithLoaders(ctx, NewLoaders(client, loader.Options{}))
+
+			post, err := LoadersFromContext(ctx).Post.Load(ctx, "p")
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+			author, err := LoadersFromContext(ctx).User.Load(ctx, post.AuthorID)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			massert.Equal(t, "alice", author.Name)
+			massert.Equal(t, (*Loaders)(nil), LoadersFromContext(context.Background()))
+		},
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test.RunSerial(t, test.Databases, func(t *testing.T, db test.Database, ctx context.Context) {
+				client := NewClient()
+				mockDBName := test.Start(t, db, client.Engine, before)
+				defer test.End(t, db, client.Engine, mockDBName)
+				tt.run(t, client, context.Background())
+			})
+		})
+	}
+}
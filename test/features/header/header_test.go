@@ -0,0 +1,26 @@
+package db
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/steebchen/prisma-client-go/test/helpers/massert"
+)
+
+func TestHeader(t *testing.T) {
+	t.Parallel()
+
+	data, err := os.ReadFile("db_gen.go")
+	if err != nil {
+		t.Fatalf("fail %s", err)
+	}
+	src := string(data)
+
+	massert.Equal(t, true, strings.HasPrefix(src, "// Copyright 2024 Acme Inc.\n//\n// Licensed under the MIT license.\n\n"))
+
+	// the generated code marker must be in the canonical format before the package clause
+	head := src[:strings.Index(src, "\npackage db")]
+	massert.Equal(t, true, regexp.MustCompile(`(?m)^// Code generated .* DO NOT EDIT\.$`).MatchString(head))
+}
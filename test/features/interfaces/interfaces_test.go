@@ -0,0 +1,36 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func findUser(ctx context.Context, client DBClient, email string) (*UserModel, error) {
+	return client.UserActions().FindUnique(User.Email.Equals(email)).Exec(ctx)
+}
+
+func TestInterfaces(t *testing.T) {
+	client, mock, ensure := NewMock()
+	defer ensure(t)
+
+	expected := UserModel{
+		InnerUser: InnerUser{
+			ID:    "123",
+			Email: "john@example.com",
+			Name:  "John",
+		},
+	}
+
+	mock.User.Expect(
+		client.User.FindUnique(User.Email.Equals("john@example.com")),
+	).Returns(expected)
+
+	actual, err := findUser(context.Background(), client, "john@example.com")
+	if err != nil {
+		t.Fatalf("fail %s", err)
+	}
+
+	assert.Equal(t, &expected, actual)
+}
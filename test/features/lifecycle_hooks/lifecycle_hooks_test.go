@@ -0,0 +1,93 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/steebchen/prisma-client-go/test"
+	"github.com/steebchen/prisma-client-go/test/helpers/massert"
+)
+
+type cx = context.Context
+type Func func(t *testing.T, client *PrismaClient, ctx cx)
+
+var errBlocked = errors.New("blocked email")
+
+func TestLifecycleHooks(t *testing.T) {
+	t.Parallel()
+
+	User.BeforeCreate(func(ctx context.Context, client *PrismaClient, write UserWrite) error {
+		if email, _ := write.Data["email"].(string); strings.HasSuffix(email, "@blocked.com") {
+			return errBlocked
+		}
+		return nil
+	})
+	User.AfterCreate(func(ctx context.Context, client *PrismaClient, write UserWrite) error {
+		_, err := client.Event.CreateOne(
+			Event.Message.Set(fmt.Sprintf("%s %s", write.Method, write.Record.Email)),
+		).Exec(ctx)
+		return err
+	})
+	User.AfterDelete(func(ctx context.Context, client *PrismaClient, write UserWrite) error {
+		_, err := client.Event.CreateOne(
+			Event.Message.Set(fmt.Sprintf("%s %d", write.Method, write.Count)),
+		).Exec(ctx)
+		return err
+	})
+
+	tests := []struct {
+		name string
+		run  Func
+	}{{
+		name: "after hooks",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			user, err := client.User.CreateOne(
+				User.Email.Set("john@example.com"),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			if _, err := client.User.FindMany(User.ID.Equals(user.ID)).Delete().Exec(ctx); err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			events, err := client.Event.FindMany().OrderBy(Event.Message.Order(SortOrderAsc)).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			massert.Equal(t, 2, len(events))
+			massert.Equal(t, "createOne john@example.com", events[0].Message)
+			massert.Equal(t, "deleteMany 1", events[1].Message)
+		},
+	}, {
+		name: "before hook aborts",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			_, err := client.User.CreateOne(
+				User.Email.Set("john@blocked.com"),
+			).Exec(ctx)
+			massert.Equal(t, true, errors.Is(err, errBlocked))
+
+			users, err := client.User.FindMany().Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+			massert.Equal(t, 0, len(users))
+		},
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test.RunSerial(t, test.Databases, func(t *testing.T, db test.Database, ctx context.Context) {
+				client := NewClient()
+				mockDBName := test.Start(t, db, client.Engine, []string{})
+				defer test.End(t, db, client.Engine, mockDBName)
+				tt.run(t, client, context.Background())
+			})
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package db
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/steebchen/prisma-client-go/test"
+	"github.com/steebchen/prisma-client-go/test/helpers/massert"
+)
+
+type cx = context.Context
+type Func func(t *testing.T, client *PrismaClient, ctx cx)
+
+// language=GraphQL
+var before = []string{`
+	mutation {
+		result: createOneUser(data: {
+			id: "alice",
+			name: "alice",
+		}) {
+			id
+		}
+	}
+`, `
+	mutation {
+		result: createOneUser(data: {
+			id: "bob",
+			name: "bob",
+		}) {
+			id
+		}
+	}
+`, `
+	mutation {
+		result: createOnePost(data: {
+			id: "a",
+			title: "a",
+			author: { connect: { id: "alice" } },
+			editor: { connect: { id: "bob" } },
+		}) {
+			id
+		}
+	}
+`, `
+	mutation {
+		result: createOnePost(data: {
+			id: "b",
+			title: "b",
+			author: { connect: { id: "alice" } },
+		}) {
+			id
+		}
+	}
+`}
+
+func TestLoadRelations(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		before []string
+		run    Func
+	}{{
+		name:   "load list relation",
+		before: before,
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			users, err := client.User.FindMany().OrderBy(
+				User.ID.Order(SortOrderAsc),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			if err := client.LoadPostsForUsers(ctx, users); err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			var alice []string
+			for _, post := range users[0].Posts() {
+				alice = append(alice, post.ID)
+			}
+			sort.Strings(alice)
+			massert.Equal(t, []string{"a", "b"}, alice)
+			massert.Equal(t, []PostModel{}, users[1].Posts())
+		},
+	}, {
+		name:   "load required relation",
+		before: before,
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			posts, err := client.Post.FindMany().Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			if err := client.LoadAuthorForPosts(ctx, posts); err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			for _, post := range posts {
+				massert.Equal(t, "alice", post.Author().Name)
+			}
+		},
+	}, {
+		name:   "load optional relation",
+		before: before,
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			posts, err := client.Post.FindMany().OrderBy(
+				Post.ID.Order(SortOrderAsc),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			if err := client.LoadEditorForPosts(ctx, posts); err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			editor, ok := posts[0].Editor()
+			massert.Equal(t, true, ok)
+			massert.Equal(t, "bob", editor.Name)
+
+			_, ok = posts[1].Editor()
+			massert.Equal(t, false, ok)
+		},
+	}, {
+		name: "load for no records",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			if err := client.LoadPostsForUsers(ctx, []UserModel{}); err != nil {
+				t.Fatalf("fail %s", err)
+			}
+		},
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test.RunSerial(t, test.Databases, func(t *testing.T, db test.Database, ctx context.Context) {
+				client := NewClient()
+				mockDBName := test.Start(t, db, client.Engine, tt.before)
+				defer test.End(t, db, client.Engine, mockDBName)
+				tt.run(t, client, context.Background())
+			})
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/steebchen/prisma-client-go/runtime/loader"
+	"github.com/steebchen/prisma-client-go/test"
+	"github.com/steebchen/prisma-client-go/test/helpers/massert"
+)
+
+type cx = context.Context
+type Func func(t *testing.T, client *PrismaClient, ctx cx)
+
+func TestLoader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		before []string
+		run    Func
+	}{{
+		name: "load concurrently",
+		// language=GraphQL
+		before: []string{`
+			mutation {
+				result: createOneUser(data: {
+					id: "a",
+					name: "alice",
+				}) {
+					id
+				}
+			}
+		`, `
+			mutation {
+				result: createOneUser(data: {
+					id: "b",
+					name: "bob",
+				}) {
+					id
+				}
+			}
+		`},
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			users := client.User.Loader(loader.Options{})
+
+			ids := []string{"a", "b", "a", "c"}
+			names := make([]string, len(ids))
+			errs := make([]error, len(ids))
+
+			var wg sync.WaitGroup
+			for i, id := range ids {
+				wg.Add(1)
+				go func(i int, id string) {
+					defer wg.Done()
+					user, err := users.Load(ctx, id)
+					if err != nil {
+						errs[i] = err
+						return
+					}
+					names[i] = user.Name
+				}(i, id)
+			}
+			wg.Wait()
+
+			massert.Equal(t, []string{"alice", "bob", "alice", ""}, names)
+			for _, err := range errs[:3] {
+				if err != nil {
+					t.Fatalf("fail %s", err)
+				}
+			}
+			massert.Equal(t, true, errors.Is(errs[3], ErrNotFound))
+		},
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test.RunSerial(t, test.Databases, func(t *testing.T, db test.Database, ctx context.Context) {
+				client := NewClient()
+				mockDBName := test.Start(t, db, client.Engine, tt.before)
+				defer test.End(t, db, client.Engine, mockDBName)
+				tt.run(t, client, context.Background())
+			})
+		})
+	}
+}
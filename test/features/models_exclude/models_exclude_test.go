@@ -0,0 +1,166 @@
+package db
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/steebchen/prisma-client-go/test"
+	"github.com/steebchen/prisma-client-go/test/helpers/massert"
+)
+
+type cx = context.Context
+type Func func(t *testing.T, client *PrismaClient, ctx cx)
+
+// language=GraphQL
+var before = []string{`
+	mutation {
+		result: createOneUser(data: {
+			id: "alice",
+			name: "alice",
+		}) {
+			id
+		}
+	}
+`, `
+	mutation {
+		result: createOneUser(data: {
+			id: "bob",
+			name: "bob",
+		}) {
+			id
+		}
+	}
+`}
+
+func TestExcludedModels(t *testing.T) {
+	t.Parallel()
+
+	client := reflect.TypeOf(PrismaClient{})
+	for _, name := range []string{"User", "AuditLog"} {
+		if _, ok := client.FieldByName(name); ok {
+			t.Errorf("expected no actions for the excluded model %s", name)
+		}
+	}
+	if _, ok := client.FieldByName("Post"); !ok {
+		t.Errorf("expected actions for Post")
+	}
+	if _, ok := reflect.TypeOf(RelationsPost{}).FieldByName("Author"); ok {
+		t.Errorf("expected no relation field for the excluded model")
+	}
+}
+
+func TestExcludedModelsQuery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		before []string
+		run    Func
+	}{{
+		name:   "create with the foreign key of an excluded model",
+		before: before,
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			created, err := client.Post.CreateOne(
+				Post.Title.Set("a"),
+				Post.AuthorID.Set("alice"),
+				Post.ID.Set("a"),
+				Post.EditorID.Set("bob"),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			editor := "bob"
+			expected := &PostModel{
+				InnerPost: InnerPost{
+					ID:       "a",
+					Title:    "a",
+					AuthorID: "alice",
+					EditorID: &editor,
+				},
+			}
+			massert.Equal(t, expected, created)
+
+			found, err := client.Post.FindMany(
+				Post.AuthorID.Equals("alice"),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+			massert.Equal(t, []PostModel{*expected}, found)
+		},
+	}, {
+		name:   "update the foreign key of an excluded model",
+		before: before,
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			if _, err := client.Post.CreateOne(
+				Post.Title.Set("a"),
+				Post.AuthorID.Set("alice"),
+				Post.ID.Set("a"),
+			).Exec(ctx); err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			updated, err := client.Post.FindUnique(
+				Post.ID.Equals("a"),
+			).Update(
+				Post.AuthorID.Set("bob"),
+				Post.EditorID.Set("alice"),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			editor := "alice"
+			expected := &PostModel{
+				InnerPost: InnerPost{
+					ID:       "a",
+					Title:    "a",
+					AuthorID: "bob",
+					EditorID: &editor,
+				},
+			}
+			massert.Equal(t, expected, updated)
+
+			count, err := client.Post.FindMany(
+				Post.ID.Equals("a"),
+			).Update(
+				Post.EditorID.SetOptional(nil),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+			massert.Equal(t, 1, count.Count)
+		},
+	}, {
+		name:   "upsert with the foreign key of an excluded model",
+		before: before,
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			actual, err := client.Post.UpsertOne(
+				Post.ID.Equals("a"),
+			).Create(
+				Post.Title.Set("a"),
+				Post.AuthorID.Set("alice"),
+				Post.ID.Set("a"),
+			).Update(
+				Post.AuthorID.Set("bob"),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+			massert.Equal(t, "alice", actual.AuthorID)
+		},
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test.RunSerial(t, test.Databases, func(t *testing.T, db test.Database, ctx context.Context) {
+				client := NewClient()
+				mockDBName := test.Start(t, db, client.Engine, tt.before)
+				defer test.End(t, db, client.Engine, mockDBName)
+				tt.run(t, client, context.Background())
+			})
+		})
+	}
+}
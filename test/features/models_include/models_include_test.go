@@ -0,0 +1,115 @@
+package db
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/steebchen/prisma-client-go/test"
+	"github.com/steebchen/prisma-client-go/test/helpers/massert"
+)
+
+type cx = context.Context
+type Func func(t *testing.T, client *PrismaClient, ctx cx)
+
+func TestIncludedModels(t *testing.T) {
+	t.Parallel()
+
+	client := reflect.TypeOf(PrismaClient{})
+	for _, name := range []string{"Post", "Comment"} {
+		if _, ok := client.FieldByName(name); !ok {
+			t.Errorf("expected actions for the included model %s", name)
+		}
+	}
+	for _, name := range []string{"User", "Event"} {
+		if _, ok := client.FieldByName(name); ok {
+			t.Errorf("expected no actions for %s, which is not included", name)
+		}
+	}
+}
+
+func TestIncludedModelsQuery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		before []string
+		run    Func
+	}{{
+		name: "relations between included models",
+		// language=GraphQL
+		before: []string{`
+			mutation {
+				result: createOneUser(data: {
+					id: "alice",
+					name: "alice",
+				}) {
+					id
+				}
+			}
+		`},
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			if _, err := client.Post.CreateOne(
+				Post.Title.Set("a"),
+				Post.AuthorID.Set("alice"),
+				Post.ID.Set("a"),
+			).Exec(ctx); err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			if _, err := client.Comment.CreateOne(
+				Comment.Text.Set("first"),
+				Comment.Post.Link(Post.ID.Equals("a")),
+				Comment.ID.Set("c"),
+			).Exec(ctx); err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			actual, err := client.Post.FindUnique(
+				Post.ID.Equals("a"),
+			).With(
+				Post.Comments.Fetch(),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			expected := &PostModel{
+				InnerPost: InnerPost{
+					ID:       "a",
+					Title:    "a",
+					AuthorID: "alice",
+				},
+				RelationsPost: RelationsPost{
+					Comments: []CommentModel{{
+						InnerComment: InnerComment{
+							ID:     "c",
+							Text:   "first",
+							PostID: "a",
+						},
+					}},
+				},
+			}
+			massert.Equal(t, expected, actual)
+
+			posts, err := client.Post.FindMany(
+				Post.Comments.Some(Comment.Text.Equals("first")),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+			massert.Equal(t, 1, len(posts))
+		},
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test.RunSerial(t, test.Databases, func(t *testing.T, db test.Database, ctx context.Context) {
+				client := NewClient()
+				mockDBName := test.Start(t, db, client.Engine, tt.before)
+				defer test.End(t, db, client.Engine, mockDBName)
+				tt.run(t, client, context.Background())
+			})
+		})
+	}
+}
@@ -62,3 +62,62 @@ func TestTableCasing(t *testing.T) {
 		massert.Equal(t, expectedPost, actualUpdatedPost)
 	})
 }
+
+func TestUpdateManyNumberOperations(t *testing.T) {
+	test.RunParallel(t, []test.Database{test.MySQL, test.PostgreSQL, test.SQLite}, func(t *testing.T, db test.Database, ctx context.Context) {
+		client := NewClient()
+
+		// language=GraphQL
+		mockDB := test.Start(t, db, client.Engine, []string{`
+			mutation {
+				result: createOnePost(data: {id: "a", int: 10, float: 10, int2: 10, float2: 10}) {
+					id
+				}
+			}
+		`, `
+			mutation {
+				result: createOnePost(data: {id: "b", int: 20, float: 20, int2: 20, float2: 20}) {
+					id
+				}
+			}
+		`, `
+			mutation {
+				result: createOnePost(data: {id: "c", int: 30, float: 30, int2: 30, float2: 30}) {
+					id
+				}
+			}
+		`})
+		defer test.End(t, db, client.Engine, mockDB)
+
+		result, err := client.Post.FindMany(
+			Post.ID.In([]string{"a", "b"}),
+		).Update(
+			Post.Int.Increment(3),
+			Post.Float.Decrement(2.5),
+			Post.Int2.Multiply(2),
+			Post.Float2.Divide(2),
+		).Exec(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		massert.Equal(t, 2, result.Count)
+
+		actual, err := client.Post.FindMany().OrderBy(
+			Post.ID.Order(SortOrderAsc),
+		).Exec(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := []PostModel{{
+			InnerPost: InnerPost{ID: "a", Int: 13, Float: 7.5, Int2: 20, Float2: 5},
+		}, {
+			InnerPost: InnerPost{ID: "b", Int: 23, Float: 17.5, Int2: 40, Float2: 10},
+		}, {
+			InnerPost: InnerPost{ID: "c", Int: 30, Float: 30, Int2: 30, Float2: 30},
+		}}
+
+		massert.Equal(t, expected, actual)
+	})
+}
@@ -0,0 +1,155 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/steebchen/prisma-client-go/test"
+	"github.com/steebchen/prisma-client-go/test/helpers/massert"
+)
+
+type cx = context.Context
+type Func func(t *testing.T, client *PrismaClient, ctx cx)
+
+// language=GraphQL
+var before = []string{`
+	mutation {
+		result: createOneDocument(data: {
+			id: "doc",
+			content: "a",
+			version: 3,
+		}) {
+			id
+		}
+	}
+`}
+
+func TestOptimisticLocking(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		before []string
+		run    Func
+	}{{
+		name:   "update increments the version",
+		before: before,
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			actual, err := client.Document.FindUnique(
+				Document.ID.Equals("doc"),
+			).UpdateIfVersion(3, Document.Content.Set("b")).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			expected := &DocumentModel{
+				InnerDocument: InnerDocument{
+					ID:      "doc",
+					Content: "b",
+					Version: 4,
+				},
+			}
+
+			massert.Equal(t, expected, actual)
+		},
+	}, {
+		name:   "stale version",
+		before: before,
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			_, err := client.Document.FindUnique(
+				Document.ID.Equals("doc"),
+			).UpdateIfVersion(2, Document.Content.Set("b")).Exec(ctx)
+
+			massert.Equal(t, true, errors.Is(err, ErrStaleRecord))
+
+			var stale *StaleRecordError
+			if !errors.As(err, &stale) {
+				t.Fatalf("expected a StaleRecordError, got %v", err)
+			}
+			massert.Equal(t, &StaleRecordError{Model: "Document", Version: 2}, stale)
+
+			actual, err := client.Document.FindUnique(Document.ID.Equals("doc")).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+			massert.Equal(t, "a", actual.Content)
+			massert.Equal(t, 3, actual.Version)
+		},
+	}, {
+		name:   "deleted record",
+		before: before,
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			_, err := client.Document.FindUnique(
+				Document.ID.Equals("other"),
+			).UpdateIfVersion(3, Document.Content.Set("b")).Exec(ctx)
+
+			massert.Equal(t, true, errors.Is(err, ErrStaleRecord))
+		},
+	}, {
+		name:   "version writes in params are ignored",
+		before: before,
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			actual, err := client.Document.FindUnique(
+				Document.ID.Equals("doc"),
+			).UpdateIfVersion(
+				3,
+				Document.Content.Set("b"),
+				Document.Version.Set(10),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			massert.Equal(t, 4, actual.Version)
+		},
+	}, {
+		name:   "transaction",
+		before: before,
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			update := client.Document.FindUnique(
+				Document.ID.Equals("doc"),
+			).UpdateIfVersion(3, Document.Content.Set("b")).Tx()
+
+			if err := client.Prisma.Transaction(update).Exec(ctx); err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			massert.Equal(t, "b", update.Result().Content)
+			massert.Equal(t, 4, update.Result().Version)
+		},
+	}, {
+		name:   "stale version in transaction",
+		before: before,
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			create := client.Document.CreateOne(
+				Document.Content.Set("new"),
+			).Tx()
+			update := client.Document.FindUnique(
+				Document.ID.Equals("doc"),
+			).UpdateIfVersion(2, Document.Content.Set("b")).Tx()
+
+			if err := client.Prisma.Transaction(create, update).Exec(ctx); err == nil {
+				t.Fatalf("expected the transaction to fail")
+			}
+
+			docs, err := client.Document.FindMany().Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+			massert.Equal(t, 1, len(docs))
+			massert.Equal(t, 3, docs[0].Version)
+		},
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test.RunSerial(t, test.Databases, func(t *testing.T, db test.Database, ctx context.Context) {
+				client := NewClient()
+				mockDBName := test.Start(t, db, client.Engine, tt.before)
+				defer test.End(t, db, client.Engine, mockDBName)
+				tt.run(t, client, context.Background())
+			})
+		})
+	}
+}
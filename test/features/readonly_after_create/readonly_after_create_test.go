@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/steebchen/prisma-client-go/test"
+	"github.com/steebchen/prisma-client-go/test/helpers/massert"
+)
+
+type cx = context.Context
+type Func func(t *testing.T, client *PrismaClient, ctx cx)
+
+func TestReadonlyAfterCreate(t *testing.T) {
+	t.Parallel()
+
+	date, _ := time.Parse(RFC3339Milli, "2000-01-01T00:00:00Z")
+
+	tests := []struct {
+		name   string
+		before []string
+		run    Func
+	}{{
+		name: "set on create",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			actual, err := client.Post.CreateOne(
+				Post.OwnerID.Set("owner"),
+				Post.Title.Set("title"),
+				Post.ID.Set("123"),
+				Post.CreatedAt.Set(date),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			expected := &PostModel{
+				InnerPost: InnerPost{
+					ID:        "123",
+					CreatedAt: date,
+					OwnerID:   "owner",
+					Title:     "title",
+				},
+			}
+
+			massert.Equal(t, expected, actual)
+		},
+	}, {
+		name: "update other fields",
+		// language=GraphQL
+		before: []string{`
+			mutation {
+				result: createOnePost(data: {
+					id: "123",
+					createdAt: "2000-01-01T00:00:00Z",
+					ownerID: "owner",
+					title: "title",
+				}) {
+					id
+				}
+			}
+		`},
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			// Post.OwnerID.Set and Post.CreatedAt.Set can't be passed to Update
+			var params []PostUpdateParam
+			params = append(params, Post.Title.Set("new"))
+
+			actual, err := client.Post.FindUnique(
+				Post.ID.Equals("123"),
+			).Update(params...).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			expected := &PostModel{
+				InnerPost: InnerPost{
+					ID:        "123",
+					CreatedAt: date,
+					OwnerID:   "owner",
+					Title:     "new",
+				},
+			}
+
+			massert.Equal(t, expected, actual)
+		},
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test.RunSerial(t, []test.Database{test.SQLite}, func(t *testing.T, db test.Database, ctx context.Context) {
+				client := NewClient()
+				mockDBName := test.Start(t, db, client.Engine, tt.before)
+				defer test.End(t, db, client.Engine, mockDBName)
+				tt.run(t, client, context.Background())
+			})
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/steebchen/prisma-client-go/test"
+	"github.com/steebchen/prisma-client-go/test/helpers/massert"
+)
+
+type cx = context.Context
+type Func func(t *testing.T, client *PrismaClient, ctx cx)
+
+func TestRelationAccessors(t *testing.T) {
+	t.Parallel()
+
+	// language=GraphQL
+	before := []string{`
+		mutation {
+			result: createOneUser(data: {
+				id: "a",
+				name: "alice",
+				posts: {
+					create: [{
+						id: "p",
+						title: "hello",
+					}],
+				},
+			}) {
+				id
+			}
+		}
+	`}
+
+	tests := []struct {
+		name string
+		run  Func
+	}{{
+		name: "fetched",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			post, err := client.Post.FindUnique(
+				Post.ID.Equals("p"),
+			).With(
+				Post.Author.Fetch(),
+				Post.Editor.Fetch(),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			author, err := post.Author()
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+			massert.Equal(t, "alice", author.Name)
+
+			editor, err := post.Editor()
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+			massert.Equal(t, (*UserModel)(nil), editor)
+		},
+	}, {
+		name: "not fetched",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			post, err := client.Post.FindUnique(
+				Post.ID.Equals("p"),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			_, err = post.Author()
+			massert.Equal(t, true, errors.Is(err, ErrNotLoaded))
+
+			_, err = post.Editor()
+			var notLoaded *NotLoadedError
+			massert.Equal(t, true, errors.As(err, &notLoaded))
+			massert.Equal(t, &NotLoadedError{Model: "Post", Relation: "editor"}, notLoaded)
+		},
+	}, {
+		name: "must panics",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			user, err := client.User.FindUnique(
+				User.ID.Equals("a"),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			defer func() {
+				massert.Equal(t, true, recover() != nil)
+			}()
+			user.MustPosts()
+			t.Fatalf("expected panic")
+		},
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test.RunSerial(t, test.Databases, func(t *testing.T, db test.Database, ctx context.Context) {
+				client := NewClient()
+				mockDBName := test.Start(t, db, client.Engine, before)
+				defer test.End(t, db, client.Engine, mockDBName)
+				tt.run(t, client, context.Background())
+			})
+		})
+	}
+}
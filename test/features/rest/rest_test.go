@@ -0,0 +1,151 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/steebchen/prisma-client-go/test"
+	"github.com/steebchen/prisma-client-go/test/helpers/massert"
+)
+
+type cx = context.Context
+type Func func(t *testing.T, client *PrismaClient, ctx cx)
+
+// serve sends a request to the REST handler of the client and returns the status code and the response body
+func serve(client *PrismaClient, method, target, body string) (int, string) {
+	w := httptest.NewRecorder()
+	client.RESTHandler().ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return w.Code, strings.TrimSpace(w.Body.String())
+}
+
+func TestREST(t *testing.T) {
+	t.Parallel()
+
+	// language=GraphQL
+	before := []string{`
+		mutation {
+			result: createOneUser(data: {
+				id: "a",
+				email: "alice@example.com",
+				name: "alice",
+				age: 30,
+				posts: {
+					create: [{
+						id: "p1",
+						title: "first",
+						published: true,
+					}, {
+						id: "p2",
+						title: "second",
+					}],
+				},
+			}) {
+				id
+			}
+		}
+	`, `
+		mutation {
+			result: createOneUser(data: {
+				id: "b",
+				email: "bob@example.com",
+				name: "bob",
+			}) {
+				id
+			}
+		}
+	`}
+
+	tests := []struct {
+		name string
+		run  Func
+	}{{
+		name: "list",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			status, body := serve(client, http.MethodGet, "/users?orderBy=-name&take=1", "")
+			massert.Equal(t, http.StatusOK, status)
+
+			var users []UserModel
+			if err := json.Unmarshal([]byte(body), &users); err != nil {
+				t.Fatalf("fail %s", err)
+			}
+			massert.Equal(t, 1, len(users))
+			massert.Equal(t, "bob", users[0].Name)
+		},
+	}, {
+		name: "list filtered",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			status, body := serve(client, http.MethodGet, "/posts?authorID=a&published=true", "")
+			massert.Equal(t, http.StatusOK, status)
+
+			var posts []PostModel
+			if err := json.Unmarshal([]byte(body), &posts); err != nil {
+				t.Fatalf("fail %s", err)
+			}
+			massert.Equal(t, 1, len(posts))
+			massert.Equal(t, "first", posts[0].Title)
+		},
+	}, {
+		name: "get",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			status, body := serve(client, http.MethodGet, "/users/a", "")
+			massert.Equal(t, http.StatusOK, status)
+			massert.Equal(t, `{"id":"a","email":"alice@example.com","name":"alice","age":30}`, body)
+
+			status, body = serve(client, http.MethodGet, "/users/c", "")
+			massert.Equal(t, http.StatusNotFound, status)
+			massert.Equal(t, `{"error":"record not found"}`, body)
+		},
+	}, {
+		name: "create",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			status, body := serve(client, http.MethodPost, "/posts", `{"id":"p3","title":"third","authorID":"b"}`)
+			massert.Equal(t, http.StatusCreated, status)
+			massert.Equal(t, `{"id":"p3","title":"third","published":false,"authorID":"b"}`, body)
+
+			status, body = serve(client, http.MethodPost, "/posts", `{"title":"fourth"}`)
+			massert.Equal(t, http.StatusBadRequest, status)
+			massert.Equal(t, `{"error":"missing field authorID"}`, body)
+
+			status, _ = serve(client, http.MethodPost, "/users", `{"email":"alice@example.com","name":"alice"}`)
+			massert.Equal(t, http.StatusConflict, status)
+		},
+	}, {
+		name: "update",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			status, body := serve(client, http.MethodPatch, "/users/a", `{"name":"alice2","age":null}`)
+			massert.Equal(t, http.StatusOK, status)
+			massert.Equal(t, `{"id":"a","email":"alice@example.com","name":"alice2"}`, body)
+
+			status, body = serve(client, http.MethodPatch, "/posts/p1", `{"authorID":"b"}`)
+			massert.Equal(t, http.StatusOK, status)
+			massert.Equal(t, `{"id":"p1","title":"first","published":true,"authorID":"b"}`, body)
+
+			status, _ = serve(client, http.MethodPatch, "/users/a", `{"id":"x"}`)
+			massert.Equal(t, http.StatusBadRequest, status)
+		},
+	}, {
+		name: "delete",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			status, _ := serve(client, http.MethodDelete, "/posts/p2", "")
+			massert.Equal(t, http.StatusNoContent, status)
+
+			status, _ = serve(client, http.MethodDelete, "/posts/p2", "")
+			massert.Equal(t, http.StatusNotFound, status)
+		},
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test.RunSerial(t, test.Databases, func(t *testing.T, db test.Database, ctx context.Context) {
+				client := NewClient()
+				mockDBName := test.Start(t, db, client.Engine, before)
+				defer test.End(t, db, client.Engine, mockDBName)
+				tt.run(t, client, context.Background())
+			})
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaMetadata(t *testing.T) {
+	schema := Schema()
+
+	assert.Equal(t, "postgresql", schema.Provider)
+
+	user, ok := schema.Model("User")
+	if !ok {
+		t.Fatalf("expected model User")
+	}
+
+	assert.Equal(t, "users", user.Table)
+	assert.Equal(t, []string{"id"}, user.PrimaryKey)
+	assert.Equal(t, [][]string{{"email"}, {"tenantID", "username"}}, user.Uniques)
+
+	email, ok := user.Field("email")
+	if !ok {
+		t.Fatalf("expected field email")
+	}
+	assert.Equal(t, "email_address", email.Column)
+	assert.Equal(t, "String", email.Type)
+	assert.True(t, email.IsUnique)
+
+	posts, ok := user.Field("posts")
+	if !ok {
+		t.Fatalf("expected field posts")
+	}
+	assert.True(t, posts.IsRelation())
+	assert.True(t, posts.IsList)
+	assert.Equal(t, "Post", posts.Type)
+	assert.Equal(t, "", posts.Column)
+
+	role, ok := schema.Enum("Role")
+	if !ok {
+		t.Fatalf("expected enum Role")
+	}
+	assert.Equal(t, []string{"Admin", "Member"}, role.Values)
+}
@@ -0,0 +1,103 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/steebchen/prisma-client-go/test"
+	"github.com/steebchen/prisma-client-go/test/helpers/massert"
+)
+
+type cx = context.Context
+type Func func(t *testing.T, client *PrismaClient, ctx cx)
+
+func TestStrictDecoding(t *testing.T) {
+	t.Parallel()
+
+	// language=GraphQL
+	before := []string{`
+		mutation {
+			result: createOneUser(data: {
+				id: "a",
+				name: "alice",
+				posts: {
+					create: [{
+						id: "p",
+						title: "hello",
+					}],
+				},
+			}) {
+				id
+			}
+		}
+	`}
+
+	tests := []struct {
+		name string
+		run  Func
+	}{{
+		name: "find with relations",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			user, err := client.User.FindUnique(
+				User.ID.Equals("a"),
+			).With(
+				User.Posts.Fetch(),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			massert.Equal(t, &UserModel{
+				InnerUser: InnerUser{
+					ID:   "a",
+					Name: "alice",
+				},
+				RelationsUser: RelationsUser{
+					Posts: []PostModel{{
+						InnerPost: InnerPost{
+							ID:       "p",
+							Title:    "hello",
+							AuthorID: "a",
+						},
+					}},
+				},
+			}, user)
+		},
+	}, {
+		name: "select",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			users, err := client.User.FindMany().Select(
+				User.Name.Field(),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			massert.Equal(t, []UserModel{{
+				InnerUser: InnerUser{
+					Name: "alice",
+				},
+			}}, users)
+		},
+	}, {
+		name: "not found",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			_, err := client.User.FindUnique(
+				User.ID.Equals("b"),
+			).Exec(ctx)
+
+			massert.Equal(t, ErrNotFound, err)
+		},
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test.RunSerial(t, test.Databases, func(t *testing.T, db test.Database, ctx context.Context) {
+				client := NewClient(WithStrictDecoding())
+				mockDBName := test.Start(t, db, client.Engine, before)
+				defer test.End(t, db, client.Engine, mockDBName)
+				tt.run(t, client, context.Background())
+			})
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/steebchen/prisma-client-go/test"
+	"github.com/steebchen/prisma-client-go/test/helpers/massert"
+)
+
+type cx = context.Context
+type Func func(t *testing.T, client *PrismaClient, ctx cx)
+
+func tag(t *testing.T, v interface{}, name string) reflect.StructTag {
+	t.Helper()
+	field, ok := reflect.TypeOf(v).FieldByName(name)
+	if !ok {
+		t.Fatalf("field %s does not exist", name)
+	}
+	return field.Tag
+}
+
+func TestStructTags(t *testing.T) {
+	massert.Equal(t, reflect.StructTag(`json:"id"`), tag(t, InnerUser{}, "ID"))
+	massert.Equal(t, reflect.StructTag(`json:"email" validate:"required,email" bson:"email"`), tag(t, InnerUser{}, "Email"))
+	massert.Equal(t, reflect.StructTag(`json:"display_name,omitempty" validate:"omitempty,min=2"`), tag(t, InnerUser{}, "Name"))
+	massert.Equal(t, reflect.StructTag(`json:"born,omitempty"`), tag(t, InnerUser{}, "BirthDate"))
+	massert.Equal(t, reflect.StructTag(`json:"posts,omitempty" validate:"dive"`), tag(t, RelationsUser{}, "Posts"))
+	massert.Equal(t, reflect.StructTag(`json:"author_id"`), tag(t, InnerPost{}, "AuthorID"))
+}
+
+func TestStructTagsQuery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		before []string
+		run    Func
+	}{{
+		name: "decode and encode renamed fields",
+		// language=GraphQL
+		before: []string{`
+			mutation {
+				result: createOneUser(data: {
+					id: "a",
+					email: "a@example.com",
+					name: "alice",
+					birthDate: "2000-01-01",
+				}) {
+					id
+				}
+			}
+		`},
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			user, err := client.User.FindUnique(
+				User.ID.Equals("a"),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			name := "alice"
+			birthDate := "2000-01-01"
+			expected := &UserModel{
+				InnerUser: InnerUser{
+					ID:        "a",
+					Email:     "a@example.com",
+					Name:      &name,
+					BirthDate: &birthDate,
+				},
+			}
+			massert.Equal(t, expected, user)
+
+			data, err := json.Marshal(user)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+			massert.Equal(t, `{"id":"a","email":"a@example.com","display_name":"alice","born":"2000-01-01"}`, string(data))
+		},
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test.RunSerial(t, test.Databases, func(t *testing.T, db test.Database, ctx context.Context) {
+				client := NewClient()
+				mockDBName := test.Start(t, db, client.Engine, tt.before)
+				defer test.End(t, db, client.Engine, mockDBName)
+				tt.run(t, client, context.Background())
+			})
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package custom
+
+import (
+	"time"
+)
+
+// Name is a string type which is encoded as is
+type Name string
+
+// Time is a time type without JSON methods, which needs a registered codec
+type Time time.Time
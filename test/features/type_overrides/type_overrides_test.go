@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/steebchen/prisma-client-go/runtime/types"
+	"github.com/steebchen/prisma-client-go/test"
+	"github.com/steebchen/prisma-client-go/test/features/type_overrides/custom"
+	"github.com/steebchen/prisma-client-go/test/helpers/massert"
+)
+
+type cx = context.Context
+type Func func(t *testing.T, client *PrismaClient, ctx cx)
+
+func init() {
+	types.RegisterScalar(func(v custom.Time) (interface{}, error) {
+		return time.Time(v).Format(time.RFC3339Nano), nil
+	}, func(data []byte) (custom.Time, error) {
+		var t time.Time
+		err := json.Unmarshal(data, &t)
+		return custom.Time(t), err
+	})
+}
+
+func TestTypeOverrides(t *testing.T) {
+	t.Parallel()
+
+	date := custom.Time(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	tests := []struct {
+		name   string
+		before []string
+		run    Func
+	}{{
+		name: "create and find with custom types",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			created, err := client.Event.CreateOne(
+				Event.ID.Set(custom.Name("123")),
+				Event.Date.Set(date),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			expected := &EventModel{
+				InnerEvent: InnerEvent{
+					ID:   "123",
+					Date: date,
+				},
+			}
+
+			massert.Equal(t, expected, created)
+
+			actual, err := client.Event.FindMany(
+				Event.ID.In([]custom.Name{"123"}),
+				Event.Date.BeforeEquals(date),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			massert.Equal(t, []EventModel{*expected}, actual)
+		},
+	}, {
+		name: "optional custom type",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			created, err := client.Event.CreateOne(
+				Event.ID.Set("123"),
+				Event.Date.Set(date),
+				Event.End.SetOptional(&date),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			end, ok := created.End()
+			massert.Equal(t, true, ok)
+			massert.Equal(t, date, end)
+		},
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test.RunSerial(t, []test.Database{test.SQLite}, func(t *testing.T, db test.Database, ctx context.Context) {
+				client := NewClient()
+				mockDBName := test.Start(t, db, client.Engine, tt.before)
+				defer test.End(t, db, client.Engine, mockDBName)
+				tt.run(t, client, context.Background())
+			})
+		})
+	}
+}
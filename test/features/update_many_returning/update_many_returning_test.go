@@ -0,0 +1,137 @@
+package db
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/steebchen/prisma-client-go/test"
+	"github.com/steebchen/prisma-client-go/test/helpers/massert"
+)
+
+type cx = context.Context
+type Func func(t *testing.T, client *PrismaClient, ctx cx)
+
+// updateManyAndReturn is only supported by PostgreSQL, CockroachDB and SQLite
+var Databases = []test.Database{
+	test.PostgreSQL,
+	test.SQLite,
+}
+
+func TestUpdateManyReturning(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		before []string
+		run    Func
+	}{{
+		name: "returns the updated records",
+		// language=GraphQL
+		before: []string{`
+			mutation {
+				result: createOnePost(data: {
+					id: "a",
+					title: "draft",
+					views: 1,
+				}) {
+					id
+				}
+			}
+		`, `
+			mutation {
+				result: createOnePost(data: {
+					id: "b",
+					title: "draft",
+					views: 2,
+				}) {
+					id
+				}
+			}
+		`, `
+			mutation {
+				result: createOnePost(data: {
+					id: "c",
+					title: "published",
+					views: 3,
+				}) {
+					id
+				}
+			}
+		`},
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			actual, err := client.Post.FindMany(
+				Post.Title.Equals("draft"),
+			).Update(
+				Post.Title.Set("updated"),
+				Post.Views.Increment(10),
+			).Returning().Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			sort.Slice(actual, func(i, j int) bool {
+				return actual[i].ID < actual[j].ID
+			})
+
+			expected := []PostModel{{
+				InnerPost: InnerPost{
+					ID:    "a",
+					Title: "updated",
+					Views: 11,
+				},
+			}, {
+				InnerPost: InnerPost{
+					ID:    "b",
+					Title: "updated",
+					Views: 12,
+				},
+			}}
+
+			massert.Equal(t, expected, actual)
+
+			untouched, err := client.Post.FindUnique(Post.ID.Equals("c")).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+			massert.Equal(t, "published", untouched.Title)
+		},
+	}, {
+		name: "returns no records if none match",
+		// language=GraphQL
+		before: []string{`
+			mutation {
+				result: createOnePost(data: {
+					id: "a",
+					title: "published",
+					views: 1,
+				}) {
+					id
+				}
+			}
+		`},
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			actual, err := client.Post.FindMany(
+				Post.Title.Equals("draft"),
+			).Update(
+				Post.Views.Increment(1),
+			).Returning().Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			massert.Equal(t, []PostModel{}, actual)
+		},
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test.RunSerial(t, Databases, func(t *testing.T, db test.Database, ctx context.Context) {
+				client := NewClient()
+				mockDBName := test.Start(t, db, client.Engine, tt.before)
+				defer test.End(t, db, client.Engine, mockDBName)
+				tt.run(t, client, context.Background())
+			})
+		})
+	}
+}
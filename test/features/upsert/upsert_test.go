@@ -242,3 +242,39 @@ func TestUpsert(t *testing.T) {
 		})
 	}
 }
+
+func TestUpsertWithResult(t *testing.T) {
+	t.Parallel()
+
+	test.RunSerial(t, Databases, func(t *testing.T, db test.Database, ctx context.Context) {
+		client := NewClient()
+		mockDBName := test.Start(t, db, client.Engine, nil)
+		defer test.End(t, db, client.Engine, mockDBName)
+
+		upsert := func(title string, update ...ArticleUpdateParam) *ArticleUpsertResult {
+			result, err := client.Article.UpsertOne(
+				Article.ID.Equals("upsert"),
+			).Create(
+				Article.Title.Set(title),
+				Article.ID.Set("upsert"),
+			).Update(update...).ExecWithResult(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+			return result
+		}
+
+		created := upsert("a", Article.Title.Set("b"))
+		massert.Equal(t, true, created.Created)
+		massert.Equal(t, "a", created.Record.Title)
+
+		updated := upsert("a", Article.Title.Set("b"))
+		massert.Equal(t, false, updated.Created)
+		massert.Equal(t, "b", updated.Record.Title)
+
+		// an update without data still reports the record as updated
+		unchanged := upsert("a")
+		massert.Equal(t, false, unchanged.Created)
+		massert.Equal(t, "b", unchanged.Record.Title)
+	})
+}
@@ -0,0 +1,136 @@
+package db
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/steebchen/prisma-client-go/test"
+	"github.com/steebchen/prisma-client-go/test/helpers/massert"
+)
+
+type cx = context.Context
+type Func func(t *testing.T, client *PrismaClient, ctx cx)
+
+// the view is created with plain SQL, as Prisma doesn't create views
+var Databases = []test.Database{
+	test.PostgreSQL,
+	test.SQLite,
+}
+
+const createView = `
+	CREATE VIEW "UserInfo" AS
+	SELECT u."id", u."email", CAST((SELECT COUNT(*) FROM "Post" p WHERE p."authorID" = u."id") AS INTEGER) AS "posts"
+	FROM "User" u
+`
+
+// language=GraphQL
+var before = []string{`
+	mutation {
+		result: createOneUser(data: {
+			id: "alice",
+			email: "alice@example.com",
+		}) {
+			id
+		}
+	}
+`, `
+	mutation {
+		result: createOneUser(data: {
+			id: "bob",
+			email: "bob@example.com",
+		}) {
+			id
+		}
+	}
+`, `
+	mutation {
+		result: createOnePost(data: {
+			id: "a",
+			title: "a",
+			author: { connect: { id: "alice" } },
+		}) {
+			id
+		}
+	}
+`, `
+	mutation {
+		result: createOnePost(data: {
+			id: "b",
+			title: "b",
+			author: { connect: { id: "alice" } },
+		}) {
+			id
+		}
+	}
+`}
+
+func TestViews(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		before []string
+		run    Func
+	}{{
+		name:   "find many",
+		before: before,
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			actual, err := client.UserInfo.FindMany(
+				UserInfo.Posts.Gt(0),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			expected := []UserInfoModel{{
+				InnerUserInfo: InnerUserInfo{
+					ID:    "alice",
+					Email: "alice@example.com",
+					Posts: 2,
+				},
+			}}
+
+			massert.Equal(t, expected, actual)
+		},
+	}, {
+		name:   "find unique",
+		before: before,
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			actual, err := client.UserInfo.FindUnique(
+				UserInfo.ID.Equals("bob"),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			massert.Equal(t, 0, actual.Posts)
+		},
+	}, {
+		name: "no write actions",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			actions := reflect.TypeOf(client.UserInfo)
+			for _, name := range []string{"CreateOne", "CreateMany", "UpsertOne"} {
+				if _, ok := actions.MethodByName(name); ok {
+					t.Errorf("expected views to have no %s action", name)
+				}
+			}
+		},
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test.RunSerial(t, Databases, func(t *testing.T, db test.Database, ctx context.Context) {
+				client := NewClient()
+				mockDBName := test.Start(t, db, client.Engine, tt.before)
+				defer test.End(t, db, client.Engine, mockDBName)
+
+				if _, err := client.Prisma.ExecuteRaw(createView).Exec(ctx); err != nil {
+					t.Fatalf("could not create view: %s", err)
+				}
+
+				tt.run(t, client, context.Background())
+			})
+		})
+	}
+}
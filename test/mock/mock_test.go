@@ -4,6 +4,8 @@ import (
 	"context"
 	"testing"
 
+	prismamock "github.com/steebchen/prisma-client-go/engine/mock"
+	"github.com/steebchen/prisma-client-go/runtime/builder"
 	"github.com/steebchen/prisma-client-go/test/helpers/massert"
 )
 
@@ -86,3 +88,113 @@ func TestMockError(t *testing.T) {
 	massert.Equal(t, expectedErr, err)
 	massert.Equal(t, true, actual == nil)
 }
+
+func TestMockPartial(t *testing.T) {
+	expected := []UserModel{{
+		InnerUser: InnerUser{
+			ID:   "123",
+			Name: "foo",
+		},
+	}}
+
+	client, mock, ensure := NewMock()
+	defer ensure(t)
+	mock.User.Expect(
+		client.User.FindMany(User.ID.Equals("123")),
+	).With(prismamock.Partial).ReturnsMany(expected)
+
+	actual, err := client.User.FindMany(User.ID.Equals("123"), User.Name.Equals("foo")).Exec(context.Background())
+	massert.Equal(t, nil, err)
+	massert.Equal(t, expected, actual)
+}
+
+func TestMockAnything(t *testing.T) {
+	expected := []UserModel{{
+		InnerUser: InnerUser{
+			ID:   "123",
+			Name: "foo",
+		},
+	}}
+
+	client, mock, ensure := NewMock()
+	defer ensure(t)
+	mock.User.Expect(
+		client.User.FindMany(),
+	).With(prismamock.Anything).ReturnsMany(expected)
+
+	actual, err := client.User.FindMany(User.Name.Equals("foo")).Exec(context.Background())
+	massert.Equal(t, nil, err)
+	massert.Equal(t, expected, actual)
+}
+
+func TestMockFunc(t *testing.T) {
+	client, mock, ensure := NewMock()
+	defer ensure(t)
+	mock.User.Expect(
+		client.User.FindUnique(User.ID.Equals("foo")),
+	).With(prismamock.Func(func(q builder.Query) bool {
+		return len(q.Inputs) == 1 && q.Inputs[0].Name == "where"
+	})).Errors(ErrNotFound)
+
+	actual, err := client.User.FindUnique(User.ID.Equals("bar")).Exec(context.Background())
+	massert.Equal(t, ErrNotFound, err)
+	massert.Equal(t, true, actual == nil)
+}
+
+func TestMockTimes(t *testing.T) {
+	first := []UserModel{{
+		InnerUser: InnerUser{
+			ID:   "123",
+			Name: "foo",
+		},
+	}}
+	second := []UserModel{{
+		InnerUser: InnerUser{
+			ID:   "456",
+			Name: "bar",
+		},
+	}}
+
+	client, mock, ensure := NewMock()
+	defer ensure(t)
+	mock.User.Expect(
+		client.User.FindMany(),
+	).Times(2).ReturnsMany(first)
+	mock.User.Expect(
+		client.User.FindMany(),
+	).ReturnsMany(second)
+	mock.User.Expect(
+		client.User.FindUnique(User.ID.Equals("123")),
+	).AnyTimes().Errors(ErrNotFound)
+
+	for i := 0; i < 2; i++ {
+		actual, err := client.User.FindMany().Exec(context.Background())
+		massert.Equal(t, nil, err)
+		massert.Equal(t, first, actual)
+	}
+
+	actual, err := client.User.FindMany().Exec(context.Background())
+	massert.Equal(t, nil, err)
+	massert.Equal(t, second, actual)
+}
+
+func TestMockInOrder(t *testing.T) {
+	client, mock, ensure := NewMock()
+	defer ensure(t)
+	mock.InOrder()
+	mock.User.Expect(
+		client.User.FindUnique(User.ID.Equals("123")),
+	).Errors(ErrNotFound)
+	mock.User.Expect(
+		client.User.FindMany(),
+	).ReturnsMany([]UserModel{})
+
+	_, err := client.User.FindMany().Exec(context.Background())
+	massert.Equal(t, true, err != nil)
+
+	_, err = client.User.FindUnique(User.ID.Equals("123")).Exec(context.Background())
+	massert.Equal(t, ErrNotFound, err)
+
+	_, err = client.User.FindMany().Exec(context.Background())
+	massert.Equal(t, nil, err)
+}
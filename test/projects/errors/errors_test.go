@@ -25,6 +25,21 @@ func TestBasic(t *testing.T) {
 
 			massert.Equal(t, ErrNotFound, err)
 		},
+	}, {
+		name: "FindUniqueOrFail not found",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			_, err := client.User.FindUniqueOrFail(User.Email.Equals("404")).Exec(ctx)
+
+			massert.Equal(t, true, IsErrNotFound(err))
+
+			nf, ok := AsNotFound(err)
+			massert.Equal(t, true, ok)
+			massert.Equal(t, &NotFoundError{
+				Model:  "User",
+				Action: "findUnique",
+				Where:  map[string]interface{}{"email": "404"},
+			}, nf)
+		},
 	}, {
 		name: "Update not found",
 		run: func(t *testing.T, client *PrismaClient, ctx cx) {
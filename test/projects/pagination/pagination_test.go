@@ -536,6 +536,138 @@ func TestPagination(t *testing.T) {
 
 			massert.Equal(t, expected, actual)
 		},
+	}, {
+		name: "paged",
+		// language=GraphQL
+		before: []string{`
+			mutation {
+				result: createOnePost(data: {
+					id: "a",
+					title: "a",
+					content: "a",
+				}) {
+					id
+				}
+			}
+		`, `
+			mutation {
+				result: createOnePost(data: {
+					id: "c",
+					title: "c",
+					content: "c",
+				}) {
+					id
+				}
+			}
+		`, `
+			mutation {
+				result: createOnePost(data: {
+					id: "b",
+					title: "b",
+					content: "b",
+				}) {
+					id
+				}
+			}
+		`},
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			actual, total, hasNext, err := client.
+				Post.
+				FindMany().
+				OrderBy(
+					Post.Title.Order(SortOrderAsc),
+				).
+				ExecPaged(ctx, 1, 2)
+
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			expected := []PostModel{{
+				InnerPost: InnerPost{
+					ID:      "a",
+					Title:   "a",
+					Content: "a",
+				},
+			}, {
+				InnerPost: InnerPost{
+					ID:      "b",
+					Title:   "b",
+					Content: "b",
+				},
+			}}
+
+			massert.Equal(t, expected, actual)
+			massert.Equal(t, 3, total)
+			massert.Equal(t, true, hasNext)
+
+			actual, total, hasNext, err = client.Post.FindManyPaged(ctx, 1, 2, Post.Title.Equals("c"))
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			massert.Equal(t, []PostModel{{
+				InnerPost: InnerPost{
+					ID:      "c",
+					Title:   "c",
+					Content: "c",
+				},
+			}}, actual)
+			massert.Equal(t, 1, total)
+			massert.Equal(t, false, hasNext)
+		},
+	}, {
+		name: "iterate",
+		// language=GraphQL
+		before: []string{`
+			mutation {
+				result: createOnePost(data: {
+					id: "a",
+					title: "a",
+					content: "a",
+				}) {
+					id
+				}
+			}
+		`, `
+			mutation {
+				result: createOnePost(data: {
+					id: "c",
+					title: "c",
+					content: "c",
+				}) {
+					id
+				}
+			}
+		`, `
+			mutation {
+				result: createOnePost(data: {
+					id: "b",
+					title: "b",
+					content: "b",
+				}) {
+					id
+				}
+			}
+		`},
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			it := client.
+				Post.
+				FindMany(
+					Post.Title.In([]string{"a", "c"}),
+				).
+				Iter(ctx, 1)
+
+			var ids []string
+			for it.Next() {
+				ids = append(ids, it.Value().ID)
+			}
+			if err := it.Err(); err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			massert.Equal(t, []string{"a", "c"}, ids)
+		},
 	}}
 	for _, tt := range tests {
 		tt := tt
@@ -0,0 +1,93 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/steebchen/prisma-client-go/test"
+	"github.com/steebchen/prisma-client-go/test/helpers/massert"
+	"github.com/steebchen/prisma-client-go/test/types/json_gotype/settings"
+)
+
+type cx = context.Context
+type Func func(t *testing.T, client *PrismaClient, ctx cx)
+
+func TestJSONGoType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		before []string
+		run    Func
+	}{{
+		name: "create and find typed json",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			created, err := client.User.CreateOne(
+				User.Settings.Set(settings.Settings{Theme: "dark", Tags: []string{"a", "b"}}),
+				User.ID.Set("123"),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			expected := &UserModel{
+				InnerUser: InnerUser{
+					ID:       "123",
+					Settings: settings.Settings{Theme: "dark", Tags: []string{"a", "b"}},
+				},
+			}
+
+			massert.Equal(t, expected, created)
+
+			actual, err := client.User.FindUnique(User.ID.Equals("123")).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			massert.Equal(t, expected, actual)
+
+			_, ok := actual.SettingsOpt()
+			massert.Equal(t, false, ok)
+		},
+	}, {
+		name: "update optional typed json",
+		// language=GraphQL
+		before: []string{`
+			mutation {
+				result: createOneUser(data: {
+					id: "123",
+					settings: "{\"theme\":\"light\",\"tags\":[]}",
+				}) {
+					id
+				}
+			}
+		`},
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			updated, err := client.User.FindUnique(
+				User.ID.Equals("123"),
+			).Update(
+				User.SettingsOpt.Set(settings.Settings{Theme: "dark"}),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatalf("fail %s", err)
+			}
+
+			massert.Equal(t, settings.Settings{Theme: "light", Tags: []string{}}, updated.Settings)
+
+			opt, ok := updated.SettingsOpt()
+			massert.Equal(t, true, ok)
+			massert.Equal(t, settings.Settings{Theme: "dark"}, opt)
+		},
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test.RunSerial(t, []test.Database{test.MySQL, test.PostgreSQL, test.MongoDB}, func(t *testing.T, db test.Database, ctx context.Context) {
+				client := NewClient()
+				mockDBName := test.Start(t, db, client.Engine, tt.before)
+				defer test.End(t, db, client.Engine, mockDBName)
+				tt.run(t, client, context.Background())
+			})
+		})
+	}
+}
@@ -0,0 +1,7 @@
+package settings
+
+// Settings is stored in a Json field
+type Settings struct {
+	Theme string   `json:"theme"`
+	Tags  []string `json:"tags"`
+}